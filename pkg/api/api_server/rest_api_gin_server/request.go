@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/access_control"
@@ -98,12 +99,16 @@ func (r *Request) Close(successMessage ...string) {
 	}
 
 	if r.server.VERBOSE {
-		h := r.ginCtx.Writer.Header()
+		redactor := r.server.redactor()
+		h := logger.Fields{}
+		for name, values := range r.ginCtx.Writer.Header() {
+			h[name] = redactor.RedactValue(name, strings.Join(values, ", "))
+		}
 		body := ""
 		if reponseBody != nil {
 			b, e := json.Marshal(reponseBody)
 			if e == nil {
-				body = string(b)
+				body = redactor.RedactString(string(b))
 			}
 		}
 		r.Logger().Debug("Dump HTTP response", logger.Fields{"response_header": h, "response_body": body})
@@ -28,6 +28,15 @@ import (
 
 const TenancyParameter string = "tenancy"
 
+const (
+	// Tenancy is resolved from tenancy path in URL, e.g. /api/v1/tenancy/<tenancy path>/<service>.
+	TenancyResolverPath string = "path"
+	// Tenancy is resolved from domain name in Host header bound to tenancy.
+	TenancyResolverHost string = "host"
+	// Tenancy is resolved from tenancy path in configured HTTP header.
+	TenancyResolverHeader string = "header"
+)
+
 type ServerConfig struct {
 	api_server.ServerBaseConfig
 
@@ -38,7 +47,9 @@ type ServerConfig struct {
 	VERBOSE                  bool
 	VERBOSE_BODY_MAX_LENGTH  int `default:"2048"`
 	ALLOW_NOT_ACTIVE_TENANCY bool
-	AUTH_FROM_TENANCY_DB     bool `default:"true"`
+	AUTH_FROM_TENANCY_DB     bool   `default:"true"`
+	TENANCY_RESOLVER         string `default:"path" validate:"oneof=path host header" vmessage:"Tenancy resolver must be one of path, host or header"`
+	TENANCY_HEADER           string `default:"x-tenancy"`
 }
 
 type AuthParameterGetter = func(r *Request, key string) string
@@ -63,6 +74,8 @@ type Server struct {
 	tenancyResource api.Resource
 
 	dynamicTables api_server.DynamicTables
+
+	defaultRedactor *logger.Redactor
 }
 
 func getHttpHeader(g *gin.Context, name string) string {
@@ -74,6 +87,7 @@ func NewServer() *Server {
 	s := &Server{}
 
 	s.dynamicTables = dynamic_table_gorm.New()
+	s.defaultRedactor = logger.DefaultRedactor()
 
 	csrfKey := func(key string) string {
 		return utils.ConcatStrings("x-", key)
@@ -197,6 +211,9 @@ func (s *Server) Init(ctx app_context.Context, auth auth.Auth, tenancyManager mu
 		parent := api.NewResource(TenancyParameter)
 		s.tenancyResource = api.NewResource(TenancyParameter, api.ResourceConfig{HasId: true, Tenancy: true})
 		parent.AddChild(s.tenancyResource)
+
+		s.AddErrorDescriptions(multitenancy.ErrorDescriptions)
+		s.AddErrorProtocolCodes(multitenancy.ErrorHttpCodes)
 	}
 
 	defaultPath := "rest_api_server"
@@ -220,6 +237,12 @@ func (s *Server) Init(ctx app_context.Context, auth auth.Auth, tenancyManager mu
 		s.AddErrorProtocolCodes(s.csrf.ErrorProtocolCodes())
 	}
 
+	// register configuration keys of auth handlers that can be overridden in tenancies
+	err = s.registerTenancySettings(auth)
+	if err != nil {
+		return ctx.Logger().PushFatalStack("failed to register tenancy settings", err)
+	}
+
 	// init gin router
 	s.ginEngine = gin.New()
 	// trusted proxies are needed for correct logging of client IP address
@@ -235,6 +258,42 @@ func (s *Server) Init(ctx app_context.Context, auth auth.Auth, tenancyManager mu
 	return nil
 }
 
+func (s *Server) registerTenancySettings(authHandler auth.Auth) error {
+
+	if !s.tenancies.IsMultiTenancy() {
+		return nil
+	}
+
+	schema := s.tenancies.SettingsSchema()
+	register := func(obj interface{}) error {
+		withSettings, ok := obj.(multitenancy.WithTenancySettings)
+		if !ok {
+			return nil
+		}
+		return withSettings.RegisterTenancySettings(schema)
+	}
+
+	endpointsAuth, ok := authHandler.(auth.EndpointsAuth)
+	if ok {
+		handlers := endpointsAuth.Manager().Handlers()
+		for _, name := range handlers.HandlerNames() {
+			handler, err := handlers.Handler(name)
+			if err != nil {
+				return err
+			}
+			err = register(handler)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.csrf != nil {
+		return register(s.csrf)
+	}
+	return nil
+}
+
 func (s *Server) Run(fin *finish.Finisher) {
 
 	srv := &http.Server{Addr: s.address(), Handler: s.ginEngine}
@@ -268,15 +327,13 @@ func requestHandler(s *Server, ep api_server.Endpoint) gin.HandlerFunc {
 		if s.VERBOSE {
 			dumpBody := ginCtx.Request.ContentLength > 0 && int(ginCtx.Request.ContentLength) <= s.VERBOSE_BODY_MAX_LENGTH
 			b, _ := httputil.DumpRequest(ginCtx.Request, dumpBody)
-			request.Logger().Debug("Dump HTTP request", logger.Fields{"request": string(b)})
+			request.Logger().Debug("Dump HTTP request", logger.Fields{"request": s.redactor().RedactString(string(b))})
 		}
 
 		// extract tenancy if applicable
 		var tenancy multitenancy.Tenancy
 		if s.tenancies.IsMultiTenancy() && ep.Resource().IsInTenancy() {
-			tenancyInPath := request.GetResourceId(TenancyParameter)
-			request.SetLoggerField("tenancy", tenancyInPath)
-			tenancy, err = s.tenancies.TenancyByPath(tenancyInPath)
+			tenancy, err = s.acquireTenancy(request)
 			if err != nil {
 				request.SetGenericErrorCode(generic_error.ErrorCodeNotFound)
 				c.SetMessage("unknown tenancy")
@@ -284,6 +341,9 @@ func requestHandler(s *Server, ep api_server.Endpoint) gin.HandlerFunc {
 				if !s.ALLOW_NOT_ACTIVE_TENANCY && !tenancy.IsActive() {
 					request.SetGenericErrorCode(generic_error.ErrorCodeNotFound)
 					err = errors.New("tenancy is not active")
+				} else if tenancy.IsMaintenance() && ep.AccessType() != access_control.Get {
+					request.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMaintenance)
+					err = errors.New("tenancy is in maintenance mode")
 				} else {
 					if s.AUTH_FROM_TENANCY_DB {
 						request.SetTenancy(tenancy)
@@ -292,6 +352,25 @@ func requestHandler(s *Server, ep api_server.Endpoint) gin.HandlerFunc {
 			}
 		}
 
+		// check quota of tenancy requests and count request
+		var metering multitenancy.Metering
+		if err == nil && tenancy != nil {
+			metering = s.tenancies.Metering()
+			if metering != nil {
+				var exceeded bool
+				exceeded, err = metering.QuotaExceeded(request, tenancy, multitenancy.MetricRequests)
+				if err != nil {
+					request.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
+					c.SetMessage("failed to check quota of tenancy")
+				} else if exceeded {
+					request.SetGenericErrorCode(multitenancy.ErrorCodeTenancyQuotaExceeded)
+					err = errors.New("quota of tenancy requests exceeded")
+				} else {
+					metering.Count(tenancy.GetID(), multitenancy.MetricRequests, 1)
+				}
+			}
+		}
+
 		// process CSRF
 		if err == nil {
 			if s.csrf != nil {
@@ -324,6 +403,11 @@ func requestHandler(s *Server, ep api_server.Endpoint) gin.HandlerFunc {
 			request.SetTenancy(tenancy)
 		}
 
+		// count active user of tenancy, failure of counting does not break request
+		if err == nil && metering != nil && request.AuthUser() != nil {
+			metering.CountActiveUser(request, tenancy.GetID(), request.AuthUser().GetID())
+		}
+
 		// call endpoint's request handler
 		if err == nil {
 			err = ep.HandleRequest(request)
@@ -338,9 +422,48 @@ func requestHandler(s *Server, ep api_server.Endpoint) gin.HandlerFunc {
 		}
 		request.TraceOutMethod()
 		request.Close()
+
+		// release tenancy after request is processed
+		if tenancy != nil {
+			s.tenancies.ReleaseTenancy(tenancy)
+		}
 	}
 }
 
+// Resolve tenancy of request and acquire it so that database of tenancy is not closed while request is processed.
+func (s *Server) acquireTenancy(request *Request) (multitenancy.Tenancy, error) {
+
+	// tenancy can be unloaded after it is resolved but before it is acquired, then it is resolved again
+	for i := 0; i < 3; i++ {
+		tenancy, err := s.resolveTenancy(request)
+		if err != nil {
+			return nil, err
+		}
+		if s.tenancies.AcquireTenancy(tenancy) {
+			return tenancy, nil
+		}
+	}
+	return nil, errors.New("tenancy unloaded while resolving")
+}
+
+func (s *Server) resolveTenancy(request *Request) (multitenancy.Tenancy, error) {
+
+	switch s.TENANCY_RESOLVER {
+	case TenancyResolverHost:
+		host := request.ginCtx.Request.Host
+		request.SetLoggerField("tenancy_host", host)
+		return s.tenancies.TenancyByDomain(host)
+	case TenancyResolverHeader:
+		tenancyInHeader := getHttpHeader(request.ginCtx, s.TENANCY_HEADER)
+		request.SetLoggerField("tenancy", tenancyInHeader)
+		return s.tenancies.TenancyByPath(tenancyInHeader)
+	}
+
+	tenancyInPath := request.GetResourceId(TenancyParameter)
+	request.SetLoggerField("tenancy", tenancyInPath)
+	return s.tenancies.TenancyByPath(tenancyInPath)
+}
+
 func (s *Server) AddEndpoint(ep api_server.Endpoint, multitenancy ...bool) {
 
 	if ep.TestOnly() && !s.Testing() {
@@ -358,7 +481,13 @@ func (s *Server) AddEndpoint(ep api_server.Endpoint, multitenancy ...bool) {
 		s.tenancyResource.AddChild(ep.Resource().ServiceResource())
 	}
 
-	path := fmt.Sprintf("%s/%s%s", s.PATH_PREFIX, s.ApiVersion(), ep.Resource().FullPathPrototype())
+	// tenancy is not in path when it is resolved from host or header
+	pathPrototype := ep.Resource().FullPathPrototype()
+	if ep.Resource().IsInTenancy() && s.TENANCY_RESOLVER != TenancyResolverPath {
+		pathPrototype = ep.Resource().ServicePathPrototype()
+	}
+
+	path := fmt.Sprintf("%s/%s%s", s.PATH_PREFIX, s.ApiVersion(), pathPrototype)
 	s.ginEngine.Handle(method, path, requestHandler(s, ep))
 }
 
@@ -368,6 +497,15 @@ func (s *Server) MakeResponseError(gerr generic_error.Error) (int, *api.Response
 	return code, err
 }
 
+// Get redactor of dumps in verbose mode. Redactor of application logger is used if it is set, otherwise builtin rules are used.
+func (s *Server) redactor() *logger.Redactor {
+	r, ok := s.App().Logger().(logger.WithRedactor)
+	if ok {
+		return r.Redactor()
+	}
+	return s.defaultRedactor
+}
+
 func (s *Server) AuthParameterGetter(authMethodProtocol string) AuthParameterGetter {
 	handler, ok := s.authParamsGetters[authMethodProtocol]
 	if !ok {
@@ -67,6 +67,9 @@ func (s *BareBonesServerBase) Init(app app_context.Context, tenancyManager multi
 		if err != nil {
 			return app.Logger().PushFatalStack("failed to init SMS manager", err)
 		}
+		if tenancyManager != nil && tenancyManager.Metering() != nil {
+			smsManager.SetMetering(tenancyManager.Metering())
+		}
 		s.pimpl.smsManager = smsManager
 	}
 
@@ -0,0 +1,60 @@
+package bare_bones_server
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server/rest_api_gin_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/auth"
+	"github.com/evgeniums/go-backend-helpers/pkg/auth/auth_methods/auth_csrf"
+	"github.com/evgeniums/go-backend-helpers/pkg/auth/auth_methods/auth_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/sms"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+type withProtocols interface {
+	Protocols() []string
+}
+
+// Register configuration sections of bare bones server in configuration schema.
+// If SMS provider factory is nil then SMS section is not registered.
+func RegisterConfigSchema(schema *config_schema.Schema, smsProviders sms.ProviderFactory, configPath ...string) {
+
+	path := utils.OptionalArg("server", configPath...)
+
+	// SMS manager
+	if smsProviders != nil {
+		schema.Register("sms", sms.NewSmsManager())
+		var protocols []string
+		if p, ok := smsProviders.(withProtocols); ok {
+			protocols = p.Protocols()
+		}
+		schema.RegisterMap("sms.providers", "protocol", func(protocol string) ([]object_config.Object, error) {
+			provider, err := smsProviders.Create(protocol)
+			if err != nil {
+				return nil, err
+			}
+			obj, ok := provider.(object_config.Object)
+			if !ok {
+				return nil, nil
+			}
+			return []object_config.Object{obj}, nil
+		}, protocols...)
+		schema.RegisterList("sms.destinations", "", func(string) ([]object_config.Object, error) {
+			return []object_config.Object{&sms.SmsDestination{}}, nil
+		})
+	}
+
+	// auth
+	authPath := object_config.Key(path, "auth")
+	managerPath := object_config.Key(authPath, "manager")
+	schema.Register(authPath, auth.NewAuth())
+	schema.Register(managerPath)
+	schema.RegisterMap(object_config.Key(managerPath, "methods"), "", auth_factory.ConfigObjects, (&auth_factory.DefaultAuthFactory{}).Protocols()...)
+	schema.RegisterFreeForm(object_config.Key(managerPath, "schemas"))
+	schema.RegisterFreeForm(object_config.Key(authPath, "endpoints"))
+
+	// REST API server
+	serverPath := object_config.Key(path, "rest_api_server")
+	schema.Register(serverPath, rest_api_gin_server.NewServer())
+	schema.RegisterOptional(object_config.Key(serverPath, "csrf"), auth_csrf.New(), &auth.AuthParameterEncryptionBase{})
+}
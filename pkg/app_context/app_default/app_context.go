@@ -18,6 +18,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/db/db_gorm"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger/logger_logrus"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator/validator_playground"
 )
@@ -32,6 +33,20 @@ type contextConfig struct {
 	HOSTNAME     string
 }
 
+// Configuration is reloaded when files change if WATCH is set or when process receives SIGHUP if SIGHUP is set.
+type configReloadConfig struct {
+	WATCH  bool
+	SIGHUP bool
+}
+
+type configReload struct {
+	configReloadConfig
+}
+
+func (c *configReload) Config() interface{} {
+	return &c.configReloadConfig
+}
+
 type WithInitGormDb interface {
 	InitDB(configPath string, gormDbConnector ...*db_gorm.DbConnector) error
 }
@@ -45,6 +60,9 @@ type Context struct {
 	cache        cache.Cache
 	inmemCache   *inmem_cache.InmemCache[string]
 	logrusLogger *logger_logrus.LogrusLogger
+	configViper  *config_viper.ConfigViper
+	cfgWatcher   *config_viper.Watcher
+	oplogHandler op_context.OplogHandler
 
 	contextConfig
 
@@ -64,6 +82,16 @@ func (c *Context) Cache() cache.Cache {
 	return c.cache
 }
 
+// Get handler of oplog used by operation contexts, nil means default handler.
+func (c *Context) OplogHandler() op_context.OplogHandler {
+	return c.oplogHandler
+}
+
+// Set handler of oplog used by operation contexts, must be set before operation contexts are created.
+func (c *Context) SetOplogHandler(handler op_context.OplogHandler) {
+	c.oplogHandler = handler
+}
+
 func (c *Context) Validator() validator.Validator {
 	return c.validator
 }
@@ -159,6 +187,7 @@ func (c *Context) InitWithArgs(configFile string, args []string, configType ...s
 	if err != nil {
 		return c.Logger().PushFatalStack("failed to override confiuration parameters", err)
 	}
+	c.configViper.SetArgs(args)
 
 	// setup logger
 	logConfigPath := "logger"
@@ -166,6 +195,10 @@ func (c *Context) InitWithArgs(configFile string, args []string, configType ...s
 	if err != nil {
 		return c.Logger().PushFatalStack("failed to init application logger", err)
 	}
+	err = c.initRedaction(object_config.Key(logConfigPath, "redaction"))
+	if err != nil {
+		return c.Logger().PushFatalStack("failed to init redaction of logs", err)
+	}
 	log := c.Logger()
 
 	// log build version
@@ -181,6 +214,20 @@ func (c *Context) InitWithArgs(configFile string, args []string, configType ...s
 		return log.PushFatalStack("failed to init application configuration", err)
 	}
 
+	// setup reloading of configuration
+	reload := &configReload{}
+	err = object_config.LoadLogValidate(c.Cfg(), log, c.validator, reload, "config_reload")
+	if err != nil {
+		return log.PushFatalStack("failed to load configuration of reloading", err)
+	}
+	if reload.WATCH || reload.SIGHUP {
+		c.cfgWatcher = config_viper.NewWatcher(c.configViper, log)
+		err = c.cfgWatcher.Start(reload.WATCH, reload.SIGHUP)
+		if err != nil {
+			return log.PushFatalStack("failed to start watching configuration", err)
+		}
+	}
+
 	// setup testing
 	if c.Testing() {
 		log.Info("Running in test mode")
@@ -196,7 +243,18 @@ func (c *Context) Init(configFile string, configType ...string) error {
 	return c.InitWithArgs(configFile, nil, configType...)
 }
 
+// Reload configuration from files.
+func (c *Context) ReloadConfig() error {
+	if c.configViper == nil {
+		return errors.New("configuration was not loaded from file")
+	}
+	return c.configViper.Reload(c.Logger())
+}
+
 func (c *Context) Close() {
+	if c.cfgWatcher != nil {
+		c.cfgWatcher.Stop()
+	}
 	if c.db != nil {
 		c.db.Close()
 	}
@@ -212,6 +270,7 @@ func (c *Context) initConfig(configFile string, configType ...string) error {
 	}
 
 	v := config_viper.New()
+	c.configViper = v
 	c.SetCfg(v)
 	err := v.LoadFile(configFile, configType...)
 	if err != nil {
@@ -0,0 +1,22 @@
+package app_default
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/db/db_gorm"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/logger_logrus"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Register configuration sections of application context in configuration schema.
+func RegisterConfigSchema(schema *config_schema.Schema, dbConfigPath ...string) {
+	schema.Register("", &Context{})
+	schema.Register("logger", logger_logrus.New())
+	schema.RegisterFreeForm("logger.components")
+	schema.RegisterOptional("logger.redaction", &redaction{})
+	schema.RegisterList("logger.redaction.rules", "", func(string) ([]object_config.Object, error) {
+		return []object_config.Object{&redactionRule{}}, nil
+	})
+	schema.Register(utils.OptionalArg("db", dbConfigPath...), db_gorm.New())
+	schema.RegisterOptional("config_reload", &configReload{})
+}
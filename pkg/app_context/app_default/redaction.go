@@ -0,0 +1,92 @@
+package app_default
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+// Redaction of sensitive values in logs.
+// Builtin rules hide passwords, secrets, tokens and SMS codes and mask card numbers and phones, additional rules are set in rules list.
+type redactionConfig struct {
+	DISABLED              bool
+	DISABLE_BUILTIN_RULES bool
+}
+
+type redaction struct {
+	redactionConfig
+}
+
+func (r *redaction) Config() interface{} {
+	return &r.redactionConfig
+}
+
+type redactionRule struct {
+	logger.RedactionRule
+}
+
+func (r *redactionRule) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
+	err := object_config.LoadLogValidate(cfg, log, vld, r, "redaction_rule", configPath...)
+	if err != nil {
+		return log.PushFatalStack("failed to load redaction rule", err)
+	}
+	return nil
+}
+
+// Load redactor from configuration.
+func loadRedactor(cfg config.Config, log logger.Logger, vld validator.Validator, configPath string) (*logger.Redactor, error) {
+
+	r := &redaction{}
+	err := object_config.LoadLogValidate(cfg, log, vld, r, configPath)
+	if err != nil {
+		return nil, log.PushFatalStack("failed to load configuration of redaction", err)
+	}
+	if r.DISABLED {
+		return logger.NewRedactor(), nil
+	}
+
+	redactor := logger.DefaultRedactor()
+	if r.DISABLE_BUILTIN_RULES {
+		redactor = logger.NewRedactor()
+	}
+
+	createRule := func() *redactionRule {
+		return &redactionRule{}
+	}
+	rulesPath := object_config.Key(configPath, "rules")
+	rules, err := object_config.LoadLogValidateSubobjectsList(cfg, log, vld, rulesPath, createRule)
+	if err != nil {
+		return nil, log.PushFatalStack("failed to load redaction rules", err)
+	}
+	for i, rule := range rules {
+		err = redactor.AddRule(&rule.RedactionRule)
+		if err != nil {
+			return nil, log.PushFatalStack("invalid redaction rule", err, logger.Fields{"config_path": object_config.KeyInt(rulesPath, i)})
+		}
+	}
+
+	return redactor, nil
+}
+
+// Wrap application logger with logger redacting sensitive values.
+func (c *Context) initRedaction(configPath string) error {
+
+	redactor, err := loadRedactor(c.Cfg(), c.logrusLogger, c.validator, configPath)
+	if err != nil {
+		return err
+	}
+	redactingLogger := logger.NewRedactingLogger(c.logrusLogger, redactor)
+	c.SetLogger(redactingLogger)
+
+	// reload redaction rules when configuration is reloaded
+	config.SubscribeReload(c.Cfg(), configPath, func(newCfg config.Config) (func(), error) {
+		redactor, err := loadRedactor(newCfg, c.logrusLogger, c.validator, configPath)
+		if err != nil {
+			return nil, err
+		}
+		return func() { redactingLogger.SetRedactor(redactor) }, nil
+	})
+
+	return nil
+}
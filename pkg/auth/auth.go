@@ -6,10 +6,14 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
 )
 
+// Name of auth component in log entries.
+const LogComponent = "auth"
+
 type Auth interface {
 	generic_error.ErrorDefinitions
 	HandleRequest(ctx AuthContext, path string, access access_control.AccessType) error
@@ -42,6 +46,7 @@ func (a *AuthBase) Config() interface{} {
 func (a *AuthBase) Init(cfg config.Config, log logger.Logger, vld validator.Validator, handlerFactory HandlerFactory, configPath ...string) error {
 
 	path := utils.OptionalArg("auth", configPath...)
+	log = logger.NewComponentLogger(log, LogComponent)
 
 	err := object_config.LoadLogValidate(cfg, log, vld, a, path)
 	if err != nil {
@@ -68,6 +73,7 @@ func (a *AuthBase) Init(cfg config.Config, log logger.Logger, vld validator.Vali
 func (a *AuthBase) HandleRequest(ctx AuthContext, path string, access access_control.AccessType) error {
 
 	// setup
+	restoreComponent := op_context.SetLogComponent(ctx, LogComponent)
 	c := ctx.TraceInMethod("AuthBase.Handle", logger.Fields{"path": path})
 	var err error
 	onExit := func() {
@@ -75,6 +81,7 @@ func (a *AuthBase) HandleRequest(ctx AuthContext, path string, access access_con
 			c.SetError(err)
 		}
 		ctx.TraceOutMethod()
+		restoreComponent()
 	}
 	defer onExit()
 
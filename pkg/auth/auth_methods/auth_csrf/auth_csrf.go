@@ -3,12 +3,14 @@ package auth_csrf
 import (
 	"errors"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/auth"
 	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
 )
@@ -25,7 +27,8 @@ type AuthCsrf struct {
 	auth.AuthHandlerBase
 	AuthCsrfConfig
 	Encryption auth.AuthParameterEncryption
-	skipPaths  map[string]bool
+	skipPaths  atomic.Pointer[map[string]bool]
+	configPath string
 }
 
 func New() *AuthCsrf {
@@ -42,6 +45,7 @@ func (a *AuthCsrf) Init(cfg config.Config, log logger.Logger, vld validator.Vali
 	a.AuthHandlerBase.Init(AntiCsrfProtocol)
 
 	path := utils.OptionalArg("csrf", configPath...)
+	a.configPath = path
 
 	err := object_config.LoadLogValidate(cfg, log, vld, a, path)
 	if err != nil {
@@ -55,16 +59,36 @@ func (a *AuthCsrf) Init(cfg config.Config, log logger.Logger, vld validator.Vali
 	}
 	a.Encryption = encryption
 
-	a.skipPaths = make(map[string]bool)
-	if len(a.IGNORE_PATHS) == 0 {
+	a.skipPaths.Store(skipPaths(a.IGNORE_PATHS))
+
+	// reload ignored paths when configuration is reloaded
+	config.SubscribeReload(cfg, path, func(newCfg config.Config) (func(), error) {
+		reloaded := New()
+		err := object_config.LoadLogValidate(newCfg, log, vld, reloaded, path)
+		if err != nil {
+			return nil, err
+		}
+		return func() { a.skipPaths.Store(skipPaths(reloaded.IGNORE_PATHS)) }, nil
+	})
+
+	return nil
+}
+
+// Register configuration keys of CSRF handler that can be overridden in tenancies.
+func (a *AuthCsrf) RegisterTenancySettings(schema *multitenancy.TenancySettingsSchema) error {
+	return schema.RegisterObject(a.configPath, &a.AuthCsrfConfig, "TOKEN_TTL_SECONDS")
+}
+
+func skipPaths(ignorePaths []string) *map[string]bool {
+	paths := make(map[string]bool)
+	if len(ignorePaths) == 0 {
 		// skip default status service
-		a.skipPaths["/status/check"] = true
+		paths["/status/check"] = true
 	}
-	for _, path := range a.IGNORE_PATHS {
-		a.skipPaths[path] = true
+	for _, path := range ignorePaths {
+		paths[path] = true
 	}
-
-	return nil
+	return &paths
 }
 
 const ErrorCodeAntiCsrfRequired = "anti_csrf_token_required"
@@ -103,7 +127,7 @@ func (a *AuthCsrf) Handle(ctx auth.AuthContext) (bool, error) {
 	defer onExit()
 
 	// check token in request
-	_, skip := a.skipPaths[ctx.GetRequestPath()]
+	_, skip := (*a.skipPaths.Load())[ctx.GetRequestPath()]
 	if !skip {
 		prev := &auth.ExpireToken{}
 		exists, err := a.Encryption.GetAuthParameter(ctx, a.Protocol(), AntiCsrfTokenName, prev)
@@ -128,7 +152,7 @@ func (a *AuthCsrf) Handle(ctx auth.AuthContext) (bool, error) {
 
 	// set token in response
 	next := &auth.ExpireToken{}
-	next.SetTTL(a.TOKEN_TTL_SECONDS)
+	next.SetTTL(multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "token_ttl_seconds"), a.TOKEN_TTL_SECONDS))
 	err = a.Encryption.SetAuthParameter(ctx, a.Protocol(), AntiCsrfTokenName, next)
 	if err != nil {
 		c.SetMessage("failed to set encrypted auth parameter")
@@ -10,6 +10,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/auth/auth_methods/auth_sms"
 	"github.com/evgeniums/go-backend-helpers/pkg/auth/auth_methods/auth_token"
 	"github.com/evgeniums/go-backend-helpers/pkg/auth/auth_session"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/sms"
 )
 
@@ -44,3 +45,37 @@ func (f *DefaultAuthFactory) Create(protocol string) (auth.AuthHandler, error) {
 
 	return nil, fmt.Errorf("unknown auth handler %s", protocol)
 }
+
+func (f *DefaultAuthFactory) Protocols() []string {
+	return []string{
+		LoginphashTokenProtocol,
+		LoginphashSmsTokenProtocol,
+		auth_login_phash.LoginProtocol,
+		auth_token.CheckTokenProtocol,
+		auth_token.TokenProtocol,
+		auth_hmac.HmacProtocol,
+		auth_sms.SmsProtocol,
+		auth_signature.SignatureProtocol,
+		auth.NoAuthProtocol,
+	}
+}
+
+// Get configuration objects loaded from configuration path of auth handler.
+// Composite handlers load configurations of their parts from sibling paths so they do not have own configuration objects.
+func ConfigObjects(protocol string) ([]object_config.Object, error) {
+
+	switch protocol {
+	case LoginphashTokenProtocol, LoginphashSmsTokenProtocol, auth_login_phash.LoginProtocol, auth.NoAuthProtocol:
+		return nil, nil
+	case auth_token.CheckTokenProtocol, auth_token.TokenProtocol:
+		return []object_config.Object{auth_token.New(nil), &auth.AuthParameterEncryptionBase{}}, nil
+	case auth_hmac.HmacProtocol:
+		return []object_config.Object{&auth_hmac.AuthHmac{}}, nil
+	case auth_sms.SmsProtocol:
+		return []object_config.Object{auth_sms.New(nil), &auth.AuthParameterEncryptionBase{}}, nil
+	case auth_signature.SignatureProtocol:
+		return []object_config.Object{&auth_signature.AuthSignature{}}, nil
+	}
+
+	return nil, fmt.Errorf("unknown auth handler %s", protocol)
+}
@@ -11,6 +11,7 @@ import (
 
 const SignatureProtocol = "signature"
 const SignatureParameter = "signature"
+const SignatureKeyParameter = "signature-key"
 
 type AuthSignatureConfig struct {
 }
@@ -53,6 +54,7 @@ func (a *AuthSignature) ErrorProtocolCodes() map[string]int {
 // Check signature in request.
 // Call this handler after discovering user (ctx.AuthUser() must be not nil).
 // Public key of user must be set for the user.
+// If user has multiple active keys then ID or hash of the key can be sent in SignatureKeyParameter.
 // signature is calculated as sig(sha256(RequestContent,RequestMethod,RequestPath))
 func (a *AuthSignature) Handle(ctx auth.AuthContext) (bool, error) {
 
@@ -74,7 +76,8 @@ func (a *AuthSignature) Handle(ctx auth.AuthContext) (bool, error) {
 	}
 
 	// verify signature
-	err = a.signatureManager.Verify(ctx, requestSignature, ctx.GetRequestContent(), ctx.GetRequestMethod(), ctx.GetRequestPath())
+	keySelector := ctx.GetAuthParameter(a.Protocol(), SignatureKeyParameter)
+	err = a.signatureManager.VerifyWithKey(ctx, keySelector, requestSignature, ctx.GetRequestContent(), ctx.GetRequestMethod(), ctx.GetRequestPath())
 	if err != nil {
 		return true, err
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/sms"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
@@ -66,6 +67,7 @@ type AuthSms struct {
 	AuthSmsConfig
 	Encryption auth.AuthParameterEncryption
 	smsManager sms.SmsManager
+	configPath string
 }
 
 func (a *AuthSms) Config() interface{} {
@@ -83,6 +85,7 @@ func (a *AuthSms) Init(cfg config.Config, log logger.Logger, vld validator.Valid
 	a.AuthHandlerBase.Init(SmsProtocol)
 
 	path := utils.OptionalArg("auth.methods.sms", configPath...)
+	a.configPath = path
 
 	err := object_config.LoadLogValidate(cfg, log, vld, a, path)
 	if err != nil {
@@ -99,6 +102,11 @@ func (a *AuthSms) Init(cfg config.Config, log logger.Logger, vld validator.Valid
 	return nil
 }
 
+// Register configuration keys of auth SMS handler that can be overridden in tenancies.
+func (a *AuthSms) RegisterTenancySettings(schema *multitenancy.TenancySettingsSchema) error {
+	return schema.RegisterObject(a.configPath, &a.AuthSmsConfig, "TOKEN_TTL_SECONDS", "SMS_DELAY_SECONDS", "MAX_TRIES")
+}
+
 func (a *AuthSms) SetSmsManager(smsManager sms.SmsManager) {
 	a.smsManager = smsManager
 }
@@ -206,7 +214,7 @@ func (a *AuthSms) Handle(ctx auth.AuthContext) (bool, error) {
 		}
 
 		// check tries count
-		if cacheToken.Try >= a.MAX_TRIES {
+		if cacheToken.Try >= a.maxTries(ctx) {
 			ctx.Cache().Unset(oldCacheKey)
 			err = errors.New("too many tries")
 			ctx.SetGenericErrorCode(ErrorCodeTooManyTries)
@@ -230,7 +238,7 @@ func (a *AuthSms) Handle(ctx auth.AuthContext) (bool, error) {
 
 			// regenerate token
 			token.GenerateID()
-			token.SetTTL(a.TOKEN_TTL_SECONDS)
+			token.SetTTL(a.tokenTtl(ctx))
 
 			// keep cache token with increased tries count
 			cacheToken.Try += 1
@@ -271,10 +279,11 @@ func (a *AuthSms) Handle(ctx auth.AuthContext) (bool, error) {
 		now := time.Now()
 		diff := now.Sub(delayItem.GetCreatedAt())
 		delay := int(diff.Seconds())
-		if delay > a.SMS_DELAY_SECONDS {
+		smsDelay := a.smsDelay(ctx)
+		if delay > smsDelay {
 			delay = 0
 		} else {
-			delay = a.SMS_DELAY_SECONDS - delay
+			delay = smsDelay - delay
 		}
 		ctx.SetAuthParameter(SmsProtocol, DelayName, fmt.Sprintf("%d", delay))
 
@@ -342,12 +351,12 @@ func (a *AuthSms) Handle(ctx auth.AuthContext) (bool, error) {
 
 	// save SMS delay in cache
 	delayItem.InitCreatedAt()
-	err1 := ctx.Cache().Set(delayCacheKey, delayItem, a.SMS_DELAY_SECONDS)
+	err1 := ctx.Cache().Set(delayCacheKey, delayItem, a.smsDelay(ctx))
 	if err1 != nil {
 		c.Logger().Error("failed to save SMS delay item in cache", err1)
 	}
 	// set delay parameter
-	ctx.SetAuthParameter(SmsProtocol, DelayName, fmt.Sprintf("%d", a.SMS_DELAY_SECONDS))
+	ctx.SetAuthParameter(SmsProtocol, DelayName, fmt.Sprintf("%d", a.smsDelay(ctx)))
 
 	// set response code
 	ctx.SetGenericErrorCode(ErrorCodeSmsConfirmationRequired)
@@ -370,6 +379,18 @@ func (a *AuthSms) smsTokenCacheKey(userId string) string {
 	return fmt.Sprintf("%s/%s", SmsTokenCacheKey, userId)
 }
 
+func (a *AuthSms) tokenTtl(ctx auth.AuthContext) int {
+	return multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "token_ttl_seconds"), a.TOKEN_TTL_SECONDS)
+}
+
+func (a *AuthSms) smsDelay(ctx auth.AuthContext) int {
+	return multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "sms_delay_seconds"), a.SMS_DELAY_SECONDS)
+}
+
+func (a *AuthSms) maxTries(ctx auth.AuthContext) int {
+	return multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "max_tries"), a.MAX_TRIES)
+}
+
 func (a *AuthSms) genCode() string {
 	r := rand.Uint32()
 	str := fmt.Sprintf("%08d", r)
@@ -380,7 +401,7 @@ func (a *AuthSms) setToken(ctx auth.AuthContext, c op_context.CallContext, cache
 
 	// keep in cache
 	newCacheKey := a.smsTokenCacheKey(requestToken.GetID())
-	err := ctx.Cache().Set(newCacheKey, cacheToken, a.TOKEN_TTL_SECONDS)
+	err := ctx.Cache().Set(newCacheKey, cacheToken, a.tokenTtl(ctx))
 	if err != nil {
 		c.SetMessage("failed to save token in cache")
 		ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
@@ -388,7 +409,7 @@ func (a *AuthSms) setToken(ctx auth.AuthContext, c op_context.CallContext, cache
 	}
 
 	// put token to response
-	requestToken.SetTTL(a.TOKEN_TTL_SECONDS)
+	requestToken.SetTTL(a.tokenTtl(ctx))
 	err = a.Encryption.SetAuthParameter(ctx, a.Protocol(), TokenName, requestToken)
 	if err != nil {
 		c.SetMessage("failed to put token to response")
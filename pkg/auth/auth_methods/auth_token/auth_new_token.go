@@ -57,7 +57,7 @@ func (a *AuthNewTokenHandler) Handle(ctx auth.AuthContext) (bool, error) {
 	var session auth_session.Session
 	if sessionId == "" {
 		// create session
-		session, err = a.users.SessionManager().CreateSession(ctx, a.SessionExpiration())
+		session, err = a.users.SessionManager().CreateSession(ctx, a.SessionExpiration(ctx))
 		if err != nil {
 			ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
 			return true, err
@@ -11,6 +11,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
 )
@@ -34,6 +35,7 @@ type AuthTokenHandler struct {
 	AuthTokenHandlerConfig
 	users      auth_session.WithUserSessionManager
 	encryption auth.AuthParameterEncryption
+	configPath string
 }
 
 type Token struct {
@@ -60,6 +62,7 @@ func (a *AuthTokenHandler) Init(cfg config.Config, log logger.Logger, vld valida
 	a.AuthHandlerBase.Init(CheckTokenProtocol)
 
 	path := utils.OptionalArg("auth.methods.token", configPath...)
+	a.configPath = path
 
 	err := object_config.LoadLogValidate(cfg, log, vld, a, path)
 	if err != nil {
@@ -76,6 +79,11 @@ func (a *AuthTokenHandler) Init(cfg config.Config, log logger.Logger, vld valida
 	return nil
 }
 
+// Register configuration keys of TOKEN handler that can be overridden in tenancies.
+func (a *AuthTokenHandler) RegisterTenancySettings(schema *multitenancy.TenancySettingsSchema) error {
+	return schema.RegisterObject(a.configPath, &a.AuthTokenHandlerConfig, "ACCESS_TOKEN_TTL_SECONDS", "REFRESH_TOKEN_TTL_SECONDS", "AUTO_PROLONGATE_ACCESS", "AUTO_PROLONGATE_REFRESH")
+}
+
 const ErrorCodeTokenExpired = "auth_token_expired"
 const ErrorCodeInvalidToken = "auth_token_invalid"
 const ErrorCodeSessionExpired = "session_expired"
@@ -214,7 +222,7 @@ func (a *AuthTokenHandler) Handle(ctx auth.AuthContext) (bool, error) {
 	// add tokens if applicable
 	if path != a.LOGOUT_PATH {
 
-		if refresh || !refresh && a.AUTO_PROLONGATE_ACCESS {
+		if refresh || !refresh && a.autoProlongateAccess(ctx) {
 			// generate access token
 			err = a.GenAccessToken(ctx)
 			if err != nil {
@@ -223,8 +231,8 @@ func (a *AuthTokenHandler) Handle(ctx auth.AuthContext) (bool, error) {
 			}
 		}
 
-		tokenExpirationTime := now.Add(time.Second * time.Duration(a.ACCESS_TOKEN_TTL_SECONDS))
-		regenerateRefreshToken := a.AUTO_PROLONGATE_REFRESH && (refresh || tokenExpirationTime.After(session.GetExpiration()))
+		tokenExpirationTime := now.Add(time.Second * time.Duration(a.accessTokenTtl(ctx)))
+		regenerateRefreshToken := a.autoProlongateRefresh(ctx) && (refresh || tokenExpirationTime.After(session.GetExpiration()))
 		if regenerateRefreshToken {
 			// generate refresh token
 			err = a.GenRefreshToken(ctx, session)
@@ -254,7 +262,7 @@ func (a *AuthTokenHandler) GenAccessToken(ctx auth.AuthContext) error {
 	c := ctx.TraceInMethod("AuthTokenHandler.GenAccessToken")
 	defer ctx.TraceOutMethod()
 
-	return c.SetError(a.GenToken(ctx, AccessTokenName, a.ACCESS_TOKEN_TTL_SECONDS))
+	return c.SetError(a.GenToken(ctx, AccessTokenName, a.accessTokenTtl(ctx)))
 }
 
 func (a *AuthTokenHandler) GenRefreshToken(ctx auth.AuthContext, session auth_session.Session) error {
@@ -268,8 +276,8 @@ func (a *AuthTokenHandler) GenRefreshToken(ctx auth.AuthContext, session auth_se
 	}
 	defer onExit()
 
-	expirationSeconds := a.REFRESH_TOKEN_TTL_SECONDS
-	session.SetExpiration(a.SessionExpiration())
+	expirationSeconds := a.refreshTokenTtl(ctx)
+	session.SetExpiration(a.SessionExpiration(ctx))
 	err = a.users.SessionManager().UpdateSessionExpiration(ctx, session)
 	if err != nil {
 		c.SetMessage("failed to update session expiration")
@@ -297,11 +305,27 @@ func (a *AuthTokenHandler) GenToken(ctx auth.AuthContext, paramName string, expi
 	return c.SetError(a.encryption.SetAuthParameter(ctx, a.Protocol(), paramName, token))
 }
 
-func (a *AuthTokenHandler) SessionExpiration() time.Time {
-	expirationSeconds := a.REFRESH_TOKEN_TTL_SECONDS
+func (a *AuthTokenHandler) SessionExpiration(ctx auth.AuthContext) time.Time {
+	expirationSeconds := a.refreshTokenTtl(ctx)
 	return time.Now().Add(time.Second * time.Duration(expirationSeconds))
 }
 
+func (a *AuthTokenHandler) accessTokenTtl(ctx auth.AuthContext) int {
+	return multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "access_token_ttl_seconds"), a.ACCESS_TOKEN_TTL_SECONDS)
+}
+
+func (a *AuthTokenHandler) refreshTokenTtl(ctx auth.AuthContext) int {
+	return multitenancy.CfgInt(ctx, object_config.Key(a.configPath, "refresh_token_ttl_seconds"), a.REFRESH_TOKEN_TTL_SECONDS)
+}
+
+func (a *AuthTokenHandler) autoProlongateAccess(ctx auth.AuthContext) bool {
+	return multitenancy.CfgBool(ctx, object_config.Key(a.configPath, "auto_prolongate_access"), a.AUTO_PROLONGATE_ACCESS)
+}
+
+func (a *AuthTokenHandler) autoProlongateRefresh(ctx auth.AuthContext) bool {
+	return multitenancy.CfgBool(ctx, object_config.Key(a.configPath, "auto_prolongate_refresh"), a.AUTO_PROLONGATE_REFRESH)
+}
+
 func (a *AuthTokenHandler) SetAuthManager(manager auth.AuthManager) {
 	manager.Schemas().AddHandler(a)
 }
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"errors"
+	"sync/atomic"
+
 	"github.com/evgeniums/go-backend-helpers/pkg/access_control"
 	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
@@ -24,12 +27,12 @@ func (e *endpointSchema) Config() interface{} {
 }
 
 type EndpointsAuthConfigBase struct {
-	endpoints map[string][]endpointSchema
+	endpoints atomic.Pointer[map[string][]endpointSchema]
 }
 
 func (e *EndpointsAuthConfigBase) Schema(path string, access access_control.AccessType) (string, bool) {
 
-	ep, ok := e.endpoints[path]
+	ep, ok := (*e.endpoints.Load())[path]
 	if !ok {
 		return "", false
 	}
@@ -46,13 +49,36 @@ func (e *EndpointsAuthConfigBase) Schema(path string, access access_control.Acce
 func (e *EndpointsAuthConfigBase) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
 
 	path := utils.OptionalArg("endpoints_auth_config", configPath...)
+	endpoints, err := loadEndpoints(cfg, log, path)
+	if err != nil {
+		return err
+	}
+	e.endpoints.Store(&endpoints)
+
+	// reload schemas when configuration is reloaded
+	config.SubscribeReload(cfg, path, func(newCfg config.Config) (func(), error) {
+		endpoints, err := loadEndpoints(newCfg, log, path)
+		if err != nil {
+			return nil, err
+		}
+		return func() { e.endpoints.Store(&endpoints) }, nil
+	})
+
+	return nil
+}
+
+func loadEndpoints(cfg config.Config, log logger.Logger, path string) (map[string][]endpointSchema, error) {
+
 	fields := logger.Fields{"config_path": path}
 	log.Debug("Init configuration of endpoints authorization", fields)
 
-	e.endpoints = make(map[string][]endpointSchema)
+	result := make(map[string][]endpointSchema)
 
 	endpointsSection := cfg.Get(path)
-	endpoints := endpointsSection.(map[string]interface{})
+	endpoints, ok := endpointsSection.(map[string]interface{})
+	if !ok {
+		return nil, log.PushFatalStack("invalid configuration of endpoints authorization", errors.New("section of endpoints must be a map"), fields)
+	}
 	for endpoint := range endpoints {
 		endpointPath := object_config.Key(path, endpoint)
 		fields := utils.AppendMapNew(fields, logger.Fields{"endpoint": endpoint, "endpoint_path": endpointPath})
@@ -61,14 +87,17 @@ func (e *EndpointsAuthConfigBase) Init(cfg config.Config, log logger.Logger, vld
 		log.Debug("Add auth schemas for endpoint", fields)
 
 		schemasSection := cfg.Get(endpointPath)
-		schemas := schemasSection.([]interface{})
+		schemas, ok := schemasSection.([]interface{})
+		if !ok {
+			return nil, log.PushFatalStack("invalid configuration of endpoint authorization", errors.New("schemas of endpoint must be a list"), fields)
+		}
 		for i := range schemas {
 			schemaPath := object_config.KeyInt(endpointPath, i)
 			fields := utils.AppendMapNew(fields, logger.Fields{"schema_path": schemaPath})
 			epSchema := endpointSchema{}
 			err := object_config.Load(cfg, schemaPath, &epSchema)
 			if err != nil {
-				return log.PushFatalStack("failed to load endpoint authorization schema", err, fields)
+				return nil, log.PushFatalStack("failed to load endpoint authorization schema", err, fields)
 			}
 			fields["access"] = epSchema.ACCESS
 			fields["http_method"] = epSchema.HTTP_METHOD
@@ -81,8 +110,8 @@ func (e *EndpointsAuthConfigBase) Init(cfg config.Config, log logger.Logger, vld
 			log.Info("Add auth schema", fields)
 		}
 
-		e.endpoints[endpoint] = endpointSchemas
+		result[endpoint] = endpointSchemas
 	}
 
-	return nil
+	return result, nil
 }
@@ -39,10 +39,16 @@ func (j *JobRunnerBase) Stopper() BackgroundStopper {
 	return j.stopper
 }
 
+// Leadership is implemented by leader electors, see RunOnlyWhenLeader().
+type Leadership interface {
+	IsLeader() bool
+}
+
 type BackgroundWorker struct {
 	logger.WithLoggerBase
 
 	Period int
+	Leader Leadership
 
 	CondChan *condchan.CondChan
 	Finished chan bool
@@ -80,7 +86,7 @@ func (w *BackgroundWorker) RunInBackground() {
 
 	// run in go routine
 	go func() {
-		w.JobRunner.RunJob()
+		w.runJob()
 		if w.IsStopped() {
 			w.Logger().Debug("Background worker: stopped after first run")
 			w.Finished <- true
@@ -100,7 +106,7 @@ func (w *BackgroundWorker) RunInBackground() {
 				case <-timeoutChan:
 					if !w.IsStopped() {
 						// w.Log.LogDebug("Background worker: run job")
-						w.JobRunner.RunJob()
+						w.runJob()
 					}
 				}
 			})
@@ -115,6 +121,18 @@ func (w *BackgroundWorker) RunInBackground() {
 	}()
 }
 
+// Run job only when this instance is a leader, so that only one of replicated instances runs the job.
+func (w *BackgroundWorker) RunOnlyWhenLeader(leader Leadership) {
+	w.Leader = leader
+}
+
+func (w *BackgroundWorker) runJob() {
+	if w.Leader != nil && !w.Leader.IsLeader() {
+		return
+	}
+	w.JobRunner.RunJob()
+}
+
 func (w *BackgroundWorker) Stop() {
 	w.Logger().Info("Background worker: stopping...")
 	if !w.Running.Load() {
@@ -0,0 +1,60 @@
+package config_console
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_viper"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator/validator_playground"
+)
+
+const CheckCmd string = "check"
+const CheckDescription string = "Check configuration file for unknown keys, missing required values and invalid values without starting application"
+
+func Check() Handler {
+	a := &CheckHandler{}
+	a.Init(CheckCmd, CheckDescription)
+	return a
+}
+
+type CheckData struct {
+	File string `long:"file" description:"Configuration file to check" required:"true"`
+}
+
+type CheckHandler struct {
+	HandlerBase
+	CheckData
+}
+
+func (a *CheckHandler) Data() interface{} {
+	return &a.CheckData
+}
+
+func (a *CheckHandler) Execute(args []string) error {
+
+	// load configuration file including files it includes or extends
+	cfg := config_viper.New()
+	err := cfg.LoadFile(a.File)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration file: %s", err)
+	}
+
+	// check configuration
+	schema := a.HandlerGroup().Schema()
+	result := schema.Check(cfg, validator_playground.New())
+	if result.Ok() {
+		fmt.Printf("Configuration file %s is valid\n", a.File)
+		return nil
+	}
+
+	for _, key := range result.UnknownKeys {
+		fmt.Printf("unknown key: %s\n", key)
+	}
+	for _, key := range result.Missing {
+		fmt.Printf("missing required value: %s\n", key)
+	}
+	for _, issue := range result.Invalid {
+		fmt.Printf("invalid value: %s: %s\n", issue.Key, issue.Message)
+	}
+	return errors.New("configuration check failed")
+}
@@ -0,0 +1,41 @@
+package config_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context/app_default"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Builder of configuration schema of application.
+type SchemaBuilder = func() *config_schema.Schema
+
+// Build schema of configuration sections of default application context.
+func DefaultSchema() *config_schema.Schema {
+	schema := config_schema.New()
+	app_default.RegisterConfigSchema(schema)
+	return schema
+}
+
+type ConfigCommands struct {
+	console_tool.Commands[*ConfigCommands]
+	Schema SchemaBuilder
+}
+
+func NewConfigCommands(schemaBuilder ...SchemaBuilder) *ConfigCommands {
+	p := &ConfigCommands{}
+	p.Construct(p, "config", "Configuration helpers")
+	p.Schema = utils.OptionalArg(DefaultSchema, schemaBuilder...)
+	p.LoadHandlers()
+	return p
+}
+
+func (p *ConfigCommands) LoadHandlers() {
+	p.AddHandlers(EncryptSecret, Check, Schema)
+}
+
+type Handler = console_tool.Handler[*ConfigCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*ConfigCommands]
+}
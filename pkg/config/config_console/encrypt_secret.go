@@ -0,0 +1,50 @@
+package config_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+)
+
+const EncryptSecretCmd string = "encrypt_secret"
+const EncryptSecretDescription string = "Encrypt secret with master key for pasting into configuration"
+
+func EncryptSecret() Handler {
+	a := &EncryptSecretHandler{}
+	a.Init(EncryptSecretCmd, EncryptSecretDescription)
+	return a
+}
+
+type EncryptSecretData struct {
+	Value     string `long:"value" description:"Secret value to encrypt" required:"true"`
+	MasterKey string `long:"master-key" description:"Master key, if not set then master key is taken from CONFIG_MASTER_KEY or CONFIG_MASTER_KEY_FILE environment variables"`
+}
+
+type EncryptSecretHandler struct {
+	HandlerBase
+	EncryptSecretData
+}
+
+func (a *EncryptSecretHandler) Data() interface{} {
+	return &a.EncryptSecretData
+}
+
+func (a *EncryptSecretHandler) Execute(args []string) error {
+
+	// application context is not needed, master key is taken from arguments or environment
+	key := a.MasterKey
+	if key == "" {
+		var err error
+		key, err = config.MasterKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	encrypted, err := config.EncryptSecret(key, a.Value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Encrypted secret:\n\n%s\n\n", encrypted)
+	return nil
+}
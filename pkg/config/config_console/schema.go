@@ -0,0 +1,56 @@
+package config_console
+
+import (
+	"fmt"
+	"os"
+)
+
+const SchemaCmd string = "schema"
+const SchemaDescription string = "Export configuration schema as JSON Schema or Markdown reference"
+
+const (
+	SchemaFormatJson     string = "json"
+	SchemaFormatMarkdown string = "markdown"
+)
+
+func Schema() Handler {
+	a := &SchemaHandler{}
+	a.Init(SchemaCmd, SchemaDescription)
+	return a
+}
+
+type SchemaData struct {
+	Format string `long:"format" description:"Output format" choice:"json" choice:"markdown" default:"json"`
+	Output string `long:"output" description:"Output file, if not set then schema is printed to standard output"`
+}
+
+type SchemaHandler struct {
+	HandlerBase
+	SchemaData
+}
+
+func (a *SchemaHandler) Data() interface{} {
+	return &a.SchemaData
+}
+
+func (a *SchemaHandler) Execute(args []string) error {
+
+	schema := a.HandlerGroup().Schema()
+
+	var text string
+	var err error
+	if a.Format == SchemaFormatMarkdown {
+		text, err = schema.Markdown()
+	} else {
+		text, err = schema.JsonSchemaText()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate configuration schema: %s", err)
+	}
+
+	if a.Output == "" {
+		fmt.Println(text)
+		return nil
+	}
+	return os.WriteFile(a.Output, []byte(text), 0644)
+}
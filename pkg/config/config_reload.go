@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ReloadHandler prepares component for reloaded configuration.
+// Handler must load and validate new configuration without applying it and return a function that applies it.
+// If any handler returns error then reload is rejected and configuration is not applied to any component.
+type ReloadHandler = func(cfg Config) (apply func(), err error)
+
+// Reloadable is implemented by configurations that can be reloaded at runtime.
+type Reloadable interface {
+	SubscribeReload(name string, handler ReloadHandler)
+	UnsubscribeReload(name string)
+}
+
+// Subscribe handler to reloads of configuration. Returns false if configuration can not be reloaded.
+func SubscribeReload(cfg Config, name string, handler ReloadHandler) bool {
+	r, ok := cfg.(Reloadable)
+	if !ok {
+		return false
+	}
+	r.SubscribeReload(name, handler)
+	return true
+}
+
+type ConfigDiff struct {
+	Changed []string `json:"changed,omitempty"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Find keys that differ in two configurations. Values are not included in diff because they can contain secrets.
+func Diff(oldCfg Config, newCfg Config) *ConfigDiff {
+
+	d := &ConfigDiff{}
+
+	oldKeys := make(map[string]bool)
+	for _, key := range oldCfg.AllKeys() {
+		// viper lists root as empty key
+		if key == "" {
+			continue
+		}
+		oldKeys[key] = true
+		if !newCfg.IsSet(key) {
+			d.Removed = append(d.Removed, key)
+		} else if !reflect.DeepEqual(oldCfg.Get(key), newCfg.Get(key)) {
+			d.Changed = append(d.Changed, key)
+		}
+	}
+	for _, key := range newCfg.AllKeys() {
+		if key != "" && !oldKeys[key] {
+			d.Added = append(d.Added, key)
+		}
+	}
+
+	sort.Strings(d.Changed)
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	return d
+}
@@ -0,0 +1,219 @@
+package config_schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+type CheckIssue struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// Result of checking configuration against schema.
+type CheckResult struct {
+	UnknownKeys []string      `json:"unknown_keys,omitempty"`
+	Missing     []string      `json:"missing,omitempty"`
+	Invalid     []*CheckIssue `json:"invalid,omitempty"`
+}
+
+func (r *CheckResult) Ok() bool {
+	return len(r.UnknownKeys) == 0 && len(r.Missing) == 0 && len(r.Invalid) == 0
+}
+
+func (r *CheckResult) addInvalid(key string, message string) {
+	r.Invalid = append(r.Invalid, &CheckIssue{Key: key, Message: message})
+}
+
+// Wrapper of fresh configuration structure used for loading it without side effects on registered objects.
+type configObject struct {
+	cfg interface{}
+}
+
+func (c *configObject) Config() interface{} {
+	return c.cfg
+}
+
+func freshObject(obj object_config.Object) (*configObject, bool) {
+	cfg := obj.Config()
+	if cfg == nil {
+		return nil, false
+	}
+	typ := reflect.TypeOf(cfg)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	return &configObject{cfg: reflect.New(typ.Elem()).Interface()}, true
+}
+
+// Check configuration against schema: find unknown keys in registered sections, missing required values and invalid values.
+// Configuration is modified in the same way as when it is loaded by application, i.e. default values are set in it.
+func (s *Schema) Check(cfg config.Config, vld validator.Validator) *CheckResult {
+
+	result := &CheckResult{}
+
+	for _, section := range s.Sections() {
+
+		present := section.Path == "" || cfg.IsSet(section.Path)
+		if !present && section.Optional {
+			continue
+		}
+
+		switch section.Kind {
+		case SectionObject:
+			s.checkElement(cfg, vld, result, section.Path, section.objects)
+		case SectionMap:
+			elements, ok := cfg.Get(section.Path).(map[string]interface{})
+			if !ok {
+				result.addInvalid(section.Path, "section must be a map")
+				continue
+			}
+			names := make([]string, 0, len(elements))
+			for name := range elements {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				path := object_config.Key(section.Path, name)
+				variant := name
+				if section.Discriminator != "" {
+					variant = cfg.GetString(object_config.Key(path, section.Discriminator))
+				}
+				s.checkVariant(cfg, vld, result, section, path, variant)
+			}
+		case SectionList:
+			elements, ok := cfg.Get(section.Path).([]interface{})
+			if !ok {
+				result.addInvalid(section.Path, "section must be a list")
+				continue
+			}
+			for i := range elements {
+				path := object_config.KeyInt(section.Path, i)
+				variant := ""
+				if section.Discriminator != "" {
+					variant = cfg.GetString(object_config.Key(path, section.Discriminator))
+				}
+				s.checkVariant(cfg, vld, result, section, path, variant)
+			}
+		}
+	}
+
+	sort.Strings(result.UnknownKeys)
+	return result
+}
+
+func (s *Schema) checkVariant(cfg config.Config, vld validator.Validator, result *CheckResult, section *Section, path string, variant string) {
+	if section.builder == nil {
+		return
+	}
+	objects, err := section.builder(variant)
+	if err != nil {
+		result.addInvalid(path, fmt.Sprintf("unknown type %s: %s", variant, err))
+		return
+	}
+	s.checkElement(cfg, vld, result, path, objects)
+}
+
+func (s *Schema) checkElement(cfg config.Config, vld validator.Validator, result *CheckResult, path string, objects []object_config.Object) {
+
+	fields := ObjectsFields(objects...)
+	known := make(map[string]bool)
+	for _, field := range fields {
+		known[field.Key] = true
+	}
+
+	// find unknown keys
+	for key, value := range sectionContent(cfg, path) {
+		if known[key] {
+			continue
+		}
+		keyPath := object_config.Key(path, key)
+		if s.hasSection(keyPath) {
+			continue
+		}
+		// sections of top level that are not registered in schema are not checked
+		if path == "" && isSection(value) {
+			continue
+		}
+		result.UnknownKeys = append(result.UnknownKeys, keyPath)
+	}
+
+	// find missing required values
+	missing := make(map[string]bool)
+	for _, field := range fields {
+		keyPath := object_config.Key(path, field.Key)
+		if field.Required && field.Default == "" && !cfg.IsSet(keyPath) {
+			missing[field.Key] = true
+			result.Missing = append(result.Missing, keyPath)
+		}
+	}
+
+	// load and validate values
+	for _, obj := range objects {
+		fresh, ok := freshObject(obj)
+		if !ok {
+			continue
+		}
+		err := object_config.Load(cfg, path, fresh)
+		if err != nil {
+			result.addInvalid(path, err.Error())
+			continue
+		}
+		value := reflect.ValueOf(fresh.cfg).Elem()
+		for _, field := range structFields(value.Type()) {
+			if field.Rules == "" || missing[field.Key] {
+				continue
+			}
+			err = vld.ValidateValue(value.FieldByName(field.name).Interface(), field.Rules)
+			if err != nil {
+				message := field.Message
+				if message == "" {
+					message = validationMessage(err)
+				}
+				result.addInvalid(object_config.Key(path, field.Key), message)
+			}
+		}
+	}
+}
+
+func isSection(value interface{}) bool {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+func validationMessage(err error) string {
+	var vErr *validator.ValidationError
+	if errors.As(err, &vErr) && vErr.Err != nil {
+		return vErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// Get content of configuration section as map of keys of the first level.
+func sectionContent(cfg config.Config, path string) map[string]interface{} {
+	if path != "" {
+		content, _ := cfg.Get(path).(map[string]interface{})
+		return content
+	}
+	content := make(map[string]interface{})
+	for _, key := range cfg.AllKeys() {
+		if key == "" {
+			continue
+		}
+		top := strings.SplitN(key, ".", 2)[0]
+		if _, ok := content[top]; !ok {
+			content[top] = cfg.Get(top)
+		}
+	}
+	return content
+}
@@ -0,0 +1,278 @@
+package config_schema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+)
+
+const (
+	// Section is a single configuration object.
+	SectionObject string = "object"
+	// Section is a map of named configuration objects.
+	SectionMap string = "map"
+	// Section is a list of configuration objects.
+	SectionList string = "list"
+	// Section has arbitrary content that is not described by schema.
+	SectionFreeForm string = "free_form"
+)
+
+const (
+	FieldString  string = "string"
+	FieldInteger string = "integer"
+	FieldNumber  string = "number"
+	FieldBoolean string = "boolean"
+	FieldArray   string = "array"
+)
+
+// Description of configuration key taken from field of configuration object.
+type Field struct {
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Items    string `json:"items,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Rules    string `json:"rules,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Masked   bool   `json:"masked,omitempty"`
+
+	name string
+}
+
+// Builder of configuration objects for variant of map or list section.
+// Variant is either a name of map element or a value of discriminator key of map or list element.
+type ObjectsBuilder func(variant string) ([]object_config.Object, error)
+
+// Section of configuration at some path.
+type Section struct {
+	Path string
+	Kind string
+
+	// Section can be omitted in configuration, required keys are checked only if section is present.
+	Optional bool
+
+	// Key of element that selects variant of element of map or list section.
+	// If empty for map section then variant is the name of map element.
+	// If empty for list section then all elements are of the same single variant.
+	Discriminator string
+
+	// Known variants of elements of map or list section.
+	Variants []string
+
+	objects []object_config.Object
+	builder ObjectsBuilder
+}
+
+// Get fields of section object or of variant of map or list element.
+func (s *Section) Fields(variant ...string) ([]*Field, error) {
+	if s.Kind == SectionObject {
+		return ObjectsFields(s.objects...), nil
+	}
+	if s.builder == nil {
+		return nil, nil
+	}
+	v := ""
+	if len(variant) != 0 {
+		v = variant[0]
+	}
+	objects, err := s.builder(v)
+	if err != nil {
+		return nil, err
+	}
+	return ObjectsFields(objects...), nil
+}
+
+// Schema of application configuration.
+type Schema struct {
+	mutex    sync.RWMutex
+	sections map[string]*Section
+}
+
+func New() *Schema {
+	s := &Schema{}
+	s.sections = make(map[string]*Section)
+	return s
+}
+
+func (s *Schema) add(section *Section) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sections[section.Path] = section
+}
+
+// Register configuration objects loaded from the same configuration path.
+func (s *Schema) Register(path string, objects ...object_config.Object) {
+	s.register(path, false, objects...)
+}
+
+// Register configuration objects loaded from configuration path only if the path is present in configuration.
+func (s *Schema) RegisterOptional(path string, objects ...object_config.Object) {
+	s.register(path, true, objects...)
+}
+
+func (s *Schema) register(path string, optional bool, objects ...object_config.Object) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	section, ok := s.sections[path]
+	if ok && section.Kind == SectionObject {
+		section.objects = append(section.objects, objects...)
+		section.Optional = section.Optional && optional
+		return
+	}
+	s.sections[path] = &Section{Path: path, Kind: SectionObject, Optional: optional, objects: objects}
+}
+
+// Register map of configuration objects.
+func (s *Schema) RegisterMap(path string, discriminator string, builder ObjectsBuilder, variants ...string) {
+	s.add(&Section{Path: path, Kind: SectionMap, Optional: true, Discriminator: discriminator, Variants: variants, builder: builder})
+}
+
+// Register list of configuration objects.
+func (s *Schema) RegisterList(path string, discriminator string, builder ObjectsBuilder, variants ...string) {
+	s.add(&Section{Path: path, Kind: SectionList, Optional: true, Discriminator: discriminator, Variants: variants, builder: builder})
+}
+
+// Register section whose content is not described by schema.
+func (s *Schema) RegisterFreeForm(path string) {
+	s.add(&Section{Path: path, Kind: SectionFreeForm, Optional: true})
+}
+
+func (s *Schema) Section(path string) (*Section, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	section, ok := s.sections[path]
+	return section, ok
+}
+
+// Get all sections sorted by path.
+func (s *Schema) Sections() []*Section {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	sections := make([]*Section, 0, len(s.sections))
+	for _, section := range s.sections {
+		sections = append(sections, section)
+	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].Path < sections[j].Path })
+	return sections
+}
+
+// Check if there is a registered section at the path or below it.
+func (s *Schema) hasSection(path string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for sectionPath := range s.sections {
+		if sectionPath == path || strings.HasPrefix(sectionPath, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Collect fields of configuration objects loaded from the same path.
+func ObjectsFields(objects ...object_config.Object) []*Field {
+	fields := make([]*Field, 0)
+	keys := make(map[string]bool)
+	for _, obj := range objects {
+		cfg := obj.Config()
+		if cfg == nil {
+			continue
+		}
+		for _, field := range structFields(reflect.TypeOf(cfg)) {
+			if !keys[field.Key] {
+				keys[field.Key] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+func structFields(typ reflect.Type) []*Field {
+
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := make([]*Field, 0)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		// fields of embedded structures are loaded from the same path, other structures are not loaded
+		if field.Type.Kind() == reflect.Struct {
+			if field.Anonymous {
+				fields = append(fields, structFields(field.Type)...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		f := &Field{Key: strings.ToLower(field.Name), name: field.Name}
+		f.Type, f.Items = fieldType(field.Type)
+		if f.Type == "" {
+			continue
+		}
+		f.Default = field.Tag.Get("default")
+		f.Rules = field.Tag.Get("validate")
+		f.Message = field.Tag.Get("vmessage")
+		_, f.Masked = field.Tag.Lookup("mask")
+		f.Required = hasRule(f.Rules, "required")
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func fieldType(typ reflect.Type) (string, string) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return FieldInteger, ""
+	case reflect.Float32, reflect.Float64:
+		return FieldNumber, ""
+	case reflect.String:
+		return FieldString, ""
+	case reflect.Bool:
+		return FieldBoolean, ""
+	case reflect.Slice:
+		// object_config loads slices either as ints or as strings
+		if typ.Elem().Kind() == reflect.Int {
+			return FieldArray, FieldInteger
+		}
+		return FieldArray, FieldString
+	}
+	return "", ""
+}
+
+func splitRules(rules string) []string {
+	if rules == "" {
+		return nil
+	}
+	return strings.Split(rules, ",")
+}
+
+func hasRule(rules string, name string) bool {
+	for _, rule := range splitRules(rules) {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Get allowed values from oneof validation rule.
+func (f *Field) Enum() []string {
+	for _, rule := range splitRules(f.Rules) {
+		if strings.HasPrefix(rule, "oneof=") {
+			return strings.Fields(strings.TrimPrefix(rule, "oneof="))
+		}
+	}
+	return nil
+}
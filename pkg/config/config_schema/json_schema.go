@@ -0,0 +1,196 @@
+package config_schema
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const JsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type jsonNode = map[string]interface{}
+
+func newObjectNode() jsonNode {
+	return jsonNode{"type": "object", "properties": jsonNode{}}
+}
+
+// Get or create child object node of object node.
+func childNode(node jsonNode, key string) jsonNode {
+	properties, ok := node["properties"].(jsonNode)
+	if !ok {
+		properties = jsonNode{}
+		node["properties"] = properties
+	}
+	child, ok := properties[key].(jsonNode)
+	if !ok {
+		child = newObjectNode()
+		properties[key] = child
+	}
+	return child
+}
+
+func typedValue(typ string, value string) interface{} {
+	switch typ {
+	case FieldInteger:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case FieldNumber:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case FieldBoolean:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	case FieldArray:
+		return strings.Split(value, ",")
+	}
+	return value
+}
+
+func fieldNode(field *Field) jsonNode {
+	node := jsonNode{"type": field.Type}
+	if field.Type == FieldArray {
+		node["items"] = jsonNode{"type": field.Items}
+	}
+	if field.Default != "" {
+		node["default"] = typedValue(field.Type, field.Default)
+	}
+	enum := field.Enum()
+	if len(enum) != 0 {
+		values := make([]interface{}, 0, len(enum))
+		for _, value := range enum {
+			values = append(values, typedValue(field.Type, value))
+		}
+		node["enum"] = values
+	}
+	if field.Message != "" {
+		node["description"] = field.Message
+	}
+	if field.Masked {
+		node["writeOnly"] = true
+	}
+	return node
+}
+
+// Fill object node with fields.
+func fillFields(node jsonNode, fields []*Field) {
+	properties, ok := node["properties"].(jsonNode)
+	if !ok {
+		properties = jsonNode{}
+		node["properties"] = properties
+	}
+	required := make([]string, 0)
+	for _, field := range fields {
+		properties[field.Key] = fieldNode(field)
+		if field.Required && field.Default == "" {
+			required = append(required, field.Key)
+		}
+	}
+	if len(required) != 0 {
+		node["required"] = required
+	}
+}
+
+func (s *Schema) variantNode(section *Section, variant string) (jsonNode, error) {
+	fields, err := section.Fields(variant)
+	if err != nil {
+		return nil, err
+	}
+	node := newObjectNode()
+	fillFields(node, fields)
+	if section.Discriminator != "" && variant != "" {
+		properties := node["properties"].(jsonNode)
+		properties[section.Discriminator] = jsonNode{"const": variant}
+	}
+	return node, nil
+}
+
+func (s *Schema) elementNode(section *Section) (jsonNode, error) {
+	if len(section.Variants) == 0 {
+		return s.variantNode(section, "")
+	}
+	variants := make([]interface{}, 0, len(section.Variants))
+	for _, variant := range section.Variants {
+		node, err := s.variantNode(section, variant)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, node)
+	}
+	return jsonNode{"oneOf": variants}, nil
+}
+
+// Generate JSON Schema of configuration.
+func (s *Schema) JsonSchema() (map[string]interface{}, error) {
+
+	root := newObjectNode()
+	root["$schema"] = JsonSchemaDraft
+
+	for _, section := range s.Sections() {
+
+		// find node of section
+		var parent jsonNode
+		node := root
+		key := ""
+		if section.Path != "" {
+			parts := strings.Split(section.Path, ".")
+			for _, part := range parts {
+				parent = node
+				node = childNode(node, part)
+			}
+			key = parts[len(parts)-1]
+		}
+
+		// fill node, only object section can be at the root
+		if parent == nil && section.Kind != SectionObject {
+			continue
+		}
+		switch section.Kind {
+		case SectionObject:
+			fields, _ := section.Fields()
+			fillFields(node, fields)
+		case SectionMap:
+			if section.Discriminator == "" {
+				for _, variant := range section.Variants {
+					variantNode, err := s.variantNode(section, variant)
+					if err != nil {
+						return nil, err
+					}
+					node["properties"].(jsonNode)[variant] = variantNode
+				}
+				node["additionalProperties"] = false
+			} else {
+				element, err := s.elementNode(section)
+				if err != nil {
+					return nil, err
+				}
+				node["additionalProperties"] = element
+			}
+		case SectionList:
+			element, err := s.elementNode(section)
+			if err != nil {
+				return nil, err
+			}
+			parent["properties"].(jsonNode)[key] = jsonNode{"type": "array", "items": element}
+		case SectionFreeForm:
+			parent["properties"].(jsonNode)[key] = jsonNode{}
+		}
+	}
+
+	return root, nil
+}
+
+// Generate JSON Schema of configuration as indented JSON text.
+func (s *Schema) JsonSchemaText() (string, error) {
+	schema, err := s.JsonSchema()
+	if err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,108 @@
+package config_schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+func sectionTitle(path string) string {
+	if path == "" {
+		return "Top level"
+	}
+	return fmt.Sprintf("`%s`", path)
+}
+
+func markdownCell(value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(value, "|", "\\|"))
+}
+
+func writeFieldsTable(b *strings.Builder, fields []*Field) {
+	if len(fields) == 0 {
+		b.WriteString("No parameters.\n\n")
+		return
+	}
+	b.WriteString("| Key | Type | Default | Required | Validation | Secret |\n")
+	b.WriteString("|-----|------|---------|----------|------------|--------|\n")
+	for _, field := range fields {
+		typ := field.Type
+		if field.Type == FieldArray {
+			typ = fmt.Sprintf("array of %s", field.Items)
+		}
+		required := ""
+		if field.Required {
+			required = "yes"
+		}
+		secret := ""
+		if field.Masked {
+			secret = "yes"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s |\n", markdownCell(field.Key), typ, markdownCell(field.Default), required, markdownCell(field.Rules), secret)
+	}
+	b.WriteString("\n")
+}
+
+func writeVariants(b *strings.Builder, section *Section) error {
+	variants := section.Variants
+	if len(variants) == 0 {
+		variants = []string{""}
+	}
+	for _, variant := range variants {
+		fields, err := section.Fields(variant)
+		if err != nil {
+			return err
+		}
+		if variant != "" {
+			fmt.Fprintf(b, "### %s\n\n", sectionTitle(variant))
+		}
+		writeFieldsTable(b, fields)
+	}
+	return nil
+}
+
+// Generate Markdown reference of configuration.
+func (s *Schema) Markdown() (string, error) {
+
+	b := &strings.Builder{}
+	b.WriteString("# Configuration reference\n\n")
+
+	for _, section := range s.Sections() {
+
+		fmt.Fprintf(b, "## %s\n\n", sectionTitle(section.Path))
+		if section.Optional && section.Kind == SectionObject {
+			b.WriteString("Section is optional.\n\n")
+		}
+
+		switch section.Kind {
+		case SectionObject:
+			fields, _ := section.Fields()
+			writeFieldsTable(b, fields)
+		case SectionMap:
+			if section.Discriminator == "" {
+				b.WriteString("Map of objects, name of element selects its type.\n\n")
+			} else {
+				fmt.Fprintf(b, "Map of named objects, key %s of element selects its type.\n\n", markdownCell(section.Discriminator))
+			}
+			err := writeVariants(b, section)
+			if err != nil {
+				return "", err
+			}
+		case SectionList:
+			if section.Discriminator == "" {
+				b.WriteString("List of objects.\n\n")
+			} else {
+				fmt.Fprintf(b, "List of objects, key %s of element selects its type.\n\n", markdownCell(section.Discriminator))
+			}
+			err := writeVariants(b, section)
+			if err != nil {
+				return "", err
+			}
+		case SectionFreeForm:
+			b.WriteString("Content of section is not described by schema.\n\n")
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,132 @@
+package config_viper
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+)
+
+type reloadSubscriber struct {
+	name    string
+	handler config.ReloadHandler
+}
+
+type reloadState struct {
+	files  []string
+	args   []string
+	loaded *ConfigViper
+
+	reloadMutex      sync.Mutex
+	subscribersMutex sync.Mutex
+	subscribers      []reloadSubscriber
+}
+
+func (c *ConfigViper) keepLoaded() error {
+	c.loaded = New()
+	return c.loaded.Load(c)
+}
+
+// Files of configuration including files of includes and extensions.
+func (c *ConfigViper) Files() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.files
+}
+
+// Keep command line arguments to apply them again after reload.
+func (c *ConfigViper) SetArgs(args []string) {
+	c.args = args
+}
+
+func (c *ConfigViper) SubscribeReload(name string, handler config.ReloadHandler) {
+	c.subscribersMutex.Lock()
+	defer c.subscribersMutex.Unlock()
+	for i := range c.subscribers {
+		if c.subscribers[i].name == name {
+			c.subscribers[i].handler = handler
+			return
+		}
+	}
+	c.subscribers = append(c.subscribers, reloadSubscriber{name: name, handler: handler})
+}
+
+func (c *ConfigViper) UnsubscribeReload(name string) {
+	c.subscribersMutex.Lock()
+	defer c.subscribersMutex.Unlock()
+	for i := range c.subscribers {
+		if c.subscribers[i].name == name {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reload configuration from files.
+// New configuration is applied only if all subscribers accept it, otherwise reload is rejected and diff of configuration is logged.
+// Values that were not loaded from files, e.g. defaults set by components, are kept as defaults of new configuration.
+func (c *ConfigViper) Reload(log logger.Logger) error {
+
+	c.reloadMutex.Lock()
+	defer c.reloadMutex.Unlock()
+
+	if c.configFile == "" {
+		return errors.New("configuration was not loaded from file")
+	}
+
+	// load new configuration
+	newCfg := New()
+	err := newCfg.LoadFile(c.configFile, c.configType)
+	if err != nil {
+		return log.PushFatalStack("failed to reload configuration", err)
+	}
+	diff := config.Diff(c.loaded, newCfg)
+	fields := logger.Fields{"changed": diff.Changed, "added": diff.Added, "removed": diff.Removed}
+	if diff.Empty() {
+		log.Info("Configuration not changed")
+		return nil
+	}
+
+	// apply command line arguments and keep values that were not loaded from files
+	err = config.LoadArgs(newCfg, c.args)
+	if err != nil {
+		return log.PushFatalStack("failed to apply command line arguments to reloaded configuration", err)
+	}
+	for _, key := range c.AllKeys() {
+		if key != "" && !c.loaded.IsSet(key) && !newCfg.IsSet(key) {
+			newCfg.SetDefault(key, c.Get(key))
+		}
+	}
+
+	// prepare subscribers
+	c.subscribersMutex.Lock()
+	subscribers := append([]reloadSubscriber{}, c.subscribers...)
+	c.subscribersMutex.Unlock()
+	applies := make([]func(), 0, len(subscribers))
+	for _, subscriber := range subscribers {
+		apply, err := subscriber.handler(newCfg)
+		if err != nil {
+			fields["subscriber"] = subscriber.name
+			log.Error("Rejected reload of configuration", err, fields)
+			return err
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+
+	// apply new configuration
+	c.mutex.Lock()
+	c.v = newCfg.v
+	c.files = newCfg.files
+	c.mutex.Unlock()
+	c.loaded = newCfg.loaded
+	for _, apply := range applies {
+		apply()
+	}
+
+	// done
+	log.Info("Configuration reloaded", fields)
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/viper"
 	"github.com/tidwall/jsonc"
@@ -30,16 +32,142 @@ type IncludeMerge struct {
 	Map  map[string]string `json:"map"`
 }
 
+// Configuration based on viper.
+// Viper instance is replaced when configuration is reloaded, so it is accessed only through methods of ConfigViper that are safe for concurrent use.
 type ConfigViper struct {
-	*viper.Viper
+	v          *viper.Viper
+	mutex      sync.RWMutex
 	configFile string
 	configType string
+
+	reloadState
 }
 
 func New() *ConfigViper {
-	v := &ConfigViper{}
-	v.Viper = viper.New()
-	return v
+	c := &ConfigViper{}
+	c.v = viper.New()
+	return c
+}
+
+// Get current viper instance. Instance is replaced on reload, so it must not be kept by callers.
+func (c *ConfigViper) Viper() *viper.Viper {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v
+}
+
+func (c *ConfigViper) setViper(v *viper.Viper) {
+	c.mutex.Lock()
+	c.v = v
+	c.mutex.Unlock()
+}
+
+func (c *ConfigViper) Get(key string) interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.Get(key)
+}
+
+func (c *ConfigViper) GetString(key string) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetString(key)
+}
+
+func (c *ConfigViper) GetBool(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetBool(key)
+}
+
+func (c *ConfigViper) GetInt(key string) int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetInt(key)
+}
+
+func (c *ConfigViper) GetInt32(key string) int32 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetInt32(key)
+}
+
+func (c *ConfigViper) GetInt64(key string) int64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetInt64(key)
+}
+
+func (c *ConfigViper) GetUint(key string) uint {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetUint(key)
+}
+
+func (c *ConfigViper) GetUint32(key string) uint32 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetUint32(key)
+}
+
+func (c *ConfigViper) GetUint64(key string) uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetUint64(key)
+}
+
+func (c *ConfigViper) GetFloat64(key string) float64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetFloat64(key)
+}
+
+func (c *ConfigViper) GetIntSlice(key string) []int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetIntSlice(key)
+}
+
+func (c *ConfigViper) GetStringSlice(key string) []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetStringSlice(key)
+}
+
+func (c *ConfigViper) GetStringMapString(key string) map[string]string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.GetStringMapString(key)
+}
+
+func (c *ConfigViper) IsSet(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.IsSet(key)
+}
+
+func (c *ConfigViper) AllKeys() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.AllKeys()
+}
+
+func (c *ConfigViper) AllSettings() map[string]interface{} {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.v.AllSettings()
+}
+
+func (c *ConfigViper) SetDefault(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.v.SetDefault(key, value)
+}
+
+func (c *ConfigViper) Set(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.v.Set(key, value)
 }
 
 func ReadJsonc(path string) ([]byte, error) {
@@ -116,7 +244,7 @@ func MakeIncludePath(configFile string, path string) (string, error) {
 func MergeConfigs(fromCfg *ConfigViper, toCfg *ConfigViper, mode string, keysMap map[string]string) error {
 
 	if mode == MergeDirect {
-		err := MergeConfigFile(toCfg.Viper, fromCfg.configFile, fromCfg.configType)
+		err := MergeConfigFile(toCfg.v, fromCfg.configFile, fromCfg.configType)
 		if err != nil {
 			return fmt.Errorf("failed to include config file %s to %s: %s", fromCfg.configFile, toCfg.configFile, err)
 		}
@@ -163,35 +291,50 @@ func (c *ConfigViper) Load(fromCfg *ConfigViper) error {
 
 	json := fromCfg.ToString()
 
-	c.Viper = viper.New()
-	c.Viper.SetConfigType("json")
-	if err := c.Viper.ReadConfig(strings.NewReader(json)); err != nil {
+	v := viper.New()
+	v.SetConfigType("json")
+	if err := v.ReadConfig(strings.NewReader(json)); err != nil {
 		return fmt.Errorf("failed to re-read viper settings: %s", err)
 	}
+	c.setViper(v)
 	return nil
 }
 
+// Load configuration file and resolve references to secrets, see config.ResolveSecret().
 func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
+	err := c.loadFile(configFile, configType...)
+	if err != nil {
+		return err
+	}
+	err = c.ResolveSecrets()
+	if err != nil {
+		return err
+	}
+	return c.keepLoaded()
+}
+
+func (c *ConfigViper) loadFile(configFile string, configType ...string) error {
 
 	// setup
 	cfgType := utils.OptionalArg("json", configType...)
-	c.Viper.SetConfigFile(configFile)
-	c.Viper.SetConfigType(cfgType)
+	c.v.SetConfigFile(configFile)
+	c.v.SetConfigType(cfgType)
 
 	// read main configuration file
-	err := ReadConfigFromFile(c.Viper, configFile, cfgType)
+	err := ReadConfigFromFile(c.v, configFile, cfgType)
 	if err != nil {
 		return fmt.Errorf("fatal error while reading config file: %s", err)
 	}
 	c.configFile = configFile
 	c.configType = cfgType
+	c.files = []string{configFile}
 
 	// check if this config extends other config
 	extendKey := "extend"
-	if c.Viper.IsSet(extendKey) {
+	if c.v.IsSet(extendKey) {
 
 		extend := &ExtendMerge{}
-		err = c.Viper.UnmarshalKey(extendKey, extend)
+		err = c.v.UnmarshalKey(extendKey, extend)
 		if err != nil {
 			return fmt.Errorf("invalid format of extend section")
 		}
@@ -202,10 +345,11 @@ func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
 		}
 
 		mainCfg := New()
-		err = mainCfg.LoadFile(path, cfgType)
+		err = mainCfg.loadFile(path, cfgType)
 		if err != nil {
 			return err
 		}
+		c.files = append(c.files, mainCfg.files...)
 
 		i := 0
 		hasDirect := false
@@ -232,23 +376,24 @@ func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
 	}
 
 	// load includes
-	includes := c.Viper.GetStringSlice("include")
+	includes := c.v.GetStringSlice("include")
 	for _, include := range includes {
 		path, err := MakeIncludePath(configFile, include)
 		if err != nil {
 			return err
 		}
-		err = MergeConfigFile(c.Viper, path, cfgType)
+		err = MergeConfigFile(c.v, path, cfgType)
 		if err != nil {
 			return fmt.Errorf("failed to include config file %s: %s", path, err)
 		}
+		c.files = append(c.files, path)
 	}
 
 	// load includes for advanced merging
 	mergeSectionKey := "include_advanced"
-	if c.Viper.IsSet(mergeSectionKey) {
+	if c.v.IsSet(mergeSectionKey) {
 
-		mergeSection := c.Viper.Get(mergeSectionKey)
+		mergeSection := c.v.Get(mergeSectionKey)
 		includeItems, ok := mergeSection.([]interface{})
 		if !ok {
 			return fmt.Errorf("invalid format of %s section", mergeSectionKey)
@@ -256,7 +401,7 @@ func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
 		for i := range includeItems {
 			item := &IncludeMerge{}
 			itemKey := fmt.Sprintf("%s.%d", mergeSectionKey, i)
-			err = c.Viper.UnmarshalKey(itemKey, item)
+			err = c.v.UnmarshalKey(itemKey, item)
 			if err != nil {
 				return fmt.Errorf("invalid format of %s", itemKey)
 			}
@@ -267,10 +412,11 @@ func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
 			}
 
 			cfg := New()
-			err = cfg.LoadFile(path, cfgType)
+			err = cfg.loadFile(path, cfgType)
 			if err != nil {
 				return fmt.Errorf("failed to read included configuration %s: %s", item.Path, err)
 			}
+			c.files = append(c.files, cfg.files...)
 
 			err = MergeConfigs(cfg, c, item.Mode, item.Map)
 			if err != nil {
@@ -292,9 +438,9 @@ func (c *ConfigViper) LoadFile(configFile string, configType ...string) error {
 func (c *ConfigViper) LoadString(configStr string, configType ...string) error {
 
 	cfgType := utils.OptionalArg("json", configType...)
-	c.Viper.SetConfigType(cfgType)
+	c.v.SetConfigType(cfgType)
 
-	err := c.Viper.ReadConfig(strings.NewReader(configStr))
+	err := c.v.ReadConfig(strings.NewReader(configStr))
 	if err != nil {
 		msg := fmt.Errorf("fatal error while reading config string: %s", err)
 		return msg
@@ -303,6 +449,58 @@ func (c *ConfigViper) LoadString(configStr string, configType ...string) error {
 	return nil
 }
 
+// Replace references to secrets with resolved values.
+// Escaped values are kept as is and resolved values that look like references are escaped,
+// so that values are not resolved again when configuration of objects is loaded with object_config.
+func (c *ConfigViper) ResolveSecrets() error {
+
+	resolve := func(key string, value string) (string, bool, error) {
+		if !config.IsSecretRef(value) || strings.HasPrefix(value, config.SecretEscapePrefix) {
+			return value, false, nil
+		}
+		secret, err := config.ResolveSecret(value)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to resolve secret of %s: %s", key, err)
+		}
+		return config.EscapeSecret(secret), true, nil
+	}
+
+	for _, key := range c.AllKeys() {
+		switch value := c.Get(key).(type) {
+		case string:
+			secret, ok, err := resolve(key, value)
+			if err != nil {
+				return err
+			}
+			if ok {
+				c.Set(key, secret)
+			}
+		case []interface{}:
+			resolved := false
+			values := make([]interface{}, len(value))
+			for i, item := range value {
+				values[i] = item
+				str, ok := item.(string)
+				if ok {
+					secret, ok, err := resolve(key, str)
+					if err != nil {
+						return err
+					}
+					if ok {
+						values[i] = secret
+						resolved = true
+					}
+				}
+			}
+			if resolved {
+				c.Set(key, values)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *ConfigViper) Rebuild() error {
 	return c.Load(c)
 }
@@ -0,0 +1,154 @@
+package config_viper
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Changes of files are collected during this delay before reloading, because editors and deployment tools write files in several steps.
+const WatchDelay = 500 * time.Millisecond
+
+// Watcher reloads configuration when configuration files change or when process receives SIGHUP.
+type Watcher struct {
+	cfg *ConfigViper
+	log logger.Logger
+
+	watcher *fsnotify.Watcher
+	targets map[string]string
+	signals chan os.Signal
+	stop    chan bool
+	done    sync.WaitGroup
+}
+
+func NewWatcher(cfg *ConfigViper, log logger.Logger) *Watcher {
+	return &Watcher{cfg: cfg, log: log}
+}
+
+// Start watching. Files of configuration are watched if watchFiles is true, SIGHUP is handled if sighup is true.
+func (w *Watcher) Start(watchFiles bool, sighup bool) error {
+
+	w.stop = make(chan bool)
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if watchFiles {
+		var err error
+		w.watcher, err = fsnotify.NewWatcher()
+		if err != nil {
+			return w.log.PushFatalStack("failed to create watcher of configuration files", err)
+		}
+		// directories are watched because files can be replaced, e.g. when Kubernetes updates mounted config maps
+		// by switching symlink ..data to new directory, in that case targets of symlinked files are changed
+		w.targets = make(map[string]string)
+		w.targetsChanged()
+		dirs := make(map[string]bool)
+		for _, file := range w.cfg.Files() {
+			dir := filepath.Dir(file)
+			if !dirs[dir] {
+				dirs[dir] = true
+				err = w.watcher.Add(dir)
+				if err != nil {
+					w.watcher.Close()
+					return w.log.PushFatalStack("failed to watch directory of configuration file", err, logger.Fields{"dir": dir})
+				}
+			}
+		}
+		events = w.watcher.Events
+		errs = w.watcher.Errors
+	}
+
+	if sighup {
+		w.signals = make(chan os.Signal, 1)
+		signal.Notify(w.signals, syscall.SIGHUP)
+	}
+
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+		var timer <-chan time.Time
+		for {
+			select {
+			case <-w.stop:
+				return
+			case event, ok := <-events:
+				if ok && (w.isConfigFile(event.Name) || w.targetsChanged()) {
+					timer = time.After(WatchDelay)
+				}
+			case err, ok := <-errs:
+				if ok {
+					w.log.Error("Error of watcher of configuration files", err)
+				}
+			case <-w.signals:
+				w.log.Info("Reload configuration on SIGHUP")
+				w.reload()
+			case <-timer:
+				timer = nil
+				w.log.Info("Reload configuration on change of files")
+				w.reload()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (w *Watcher) isConfigFile(name string) bool {
+	for _, file := range w.cfg.Files() {
+		if filepath.Clean(name) == filepath.Clean(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if resolved paths of configuration files changed since last check.
+func (w *Watcher) targetsChanged() bool {
+	changed := false
+	for _, file := range w.cfg.Files() {
+		target, err := filepath.EvalSymlinks(file)
+		if err != nil {
+			// file can be missing while it is being replaced
+			continue
+		}
+		if w.targets[file] != target {
+			w.targets[file] = target
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (w *Watcher) reload() {
+	// error is already logged
+	w.cfg.Reload(w.log)
+}
+
+func (w *Watcher) Stop() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.done.Wait()
+	w.stop = nil
+	if w.signals != nil {
+		signal.Stop(w.signals)
+		w.signals = nil
+	}
+	if w.watcher != nil {
+		w.watcher.Close()
+		w.watcher = nil
+	}
+}
+
+func (w *Watcher) Shutdown(ctx context.Context) error {
+	w.Stop()
+	return nil
+}
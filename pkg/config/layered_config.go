@@ -0,0 +1,123 @@
+package config
+
+import "fmt"
+
+// LayeredConfig is a view of configuration that resolves keys from the top layer first and then from the base configuration.
+// Values set through the view are written to the top layer.
+type LayeredConfig struct {
+	top  Config
+	base Config
+}
+
+func NewLayeredConfig(top Config, base Config) *LayeredConfig {
+	return &LayeredConfig{top: top, base: base}
+}
+
+func (l *LayeredConfig) Top() Config {
+	return l.top
+}
+
+func (l *LayeredConfig) Base() Config {
+	return l.base
+}
+
+func (l *LayeredConfig) layer(key string) Config {
+	if l.top.IsSet(key) {
+		return l.top
+	}
+	return l.base
+}
+
+func (l *LayeredConfig) Get(key string) interface{} {
+	return l.layer(key).Get(key)
+}
+
+func (l *LayeredConfig) GetString(key string) string {
+	return l.layer(key).GetString(key)
+}
+
+func (l *LayeredConfig) GetBool(key string) bool {
+	return l.layer(key).GetBool(key)
+}
+
+func (l *LayeredConfig) GetInt(key string) int {
+	return l.layer(key).GetInt(key)
+}
+
+func (l *LayeredConfig) GetInt32(key string) int32 {
+	return l.layer(key).GetInt32(key)
+}
+
+func (l *LayeredConfig) GetInt64(key string) int64 {
+	return l.layer(key).GetInt64(key)
+}
+
+func (l *LayeredConfig) GetUint(key string) uint {
+	return l.layer(key).GetUint(key)
+}
+
+func (l *LayeredConfig) GetUint32(key string) uint32 {
+	return l.layer(key).GetUint32(key)
+}
+
+func (l *LayeredConfig) GetUint64(key string) uint64 {
+	return l.layer(key).GetUint64(key)
+}
+
+func (l *LayeredConfig) GetFloat64(key string) float64 {
+	return l.layer(key).GetFloat64(key)
+}
+
+func (l *LayeredConfig) GetIntSlice(key string) []int {
+	return l.layer(key).GetIntSlice(key)
+}
+
+func (l *LayeredConfig) GetStringSlice(key string) []string {
+	return l.layer(key).GetStringSlice(key)
+}
+
+func (l *LayeredConfig) GetFloat64Slice(key string) []float64 {
+	return l.layer(key).GetFloat64Slice(key)
+}
+
+func (l *LayeredConfig) GetStringMapString(key string) map[string]string {
+	return l.layer(key).GetStringMapString(key)
+}
+
+func (l *LayeredConfig) SetDefault(key string, value interface{}) {
+	l.top.SetDefault(key, value)
+}
+
+func (l *LayeredConfig) Set(key string, value interface{}) {
+	l.top.Set(key, value)
+}
+
+func (l *LayeredConfig) IsSet(key string) bool {
+	return l.top.IsSet(key) || l.base.IsSet(key)
+}
+
+func (l *LayeredConfig) AllKeys() []string {
+	keys := l.base.AllKeys()
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[key] = true
+	}
+	for _, key := range l.top.AllKeys() {
+		if !known[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (l *LayeredConfig) Rebuild() error {
+	err := l.base.Rebuild()
+	if err != nil {
+		return err
+	}
+	return l.top.Rebuild()
+}
+
+func (l *LayeredConfig) ToString() string {
+	return fmt.Sprintf("%s\n%s", l.top.ToString(), l.base.ToString())
+}
@@ -110,7 +110,11 @@ func loadValue(cfg config.Config, configPath string, objectValue reflect.Value)
 		case reflect.Float64, reflect.Float32:
 			fieldValue.SetFloat(cfg.GetFloat64(fieldConfigPath))
 		case reflect.String:
-			fieldValue.SetString(cfg.GetString(fieldConfigPath))
+			value, err := config.ResolveSecret(cfg.GetString(fieldConfigPath))
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve secret of %s: %s", fieldConfigPath, err)
+			}
+			fieldValue.SetString(value)
 		case reflect.Bool:
 			fieldValue.SetBool(cfg.GetBool(fieldConfigPath))
 		case reflect.Slice:
@@ -118,7 +122,10 @@ func loadValue(cfg config.Config, configPath string, objectValue reflect.Value)
 				slice := cfg.GetIntSlice(fieldConfigPath)
 				fieldValue.Set(reflect.ValueOf(slice))
 			} else {
-				slice := cfg.GetStringSlice(fieldConfigPath)
+				slice, err := resolveSecrets(cfg.GetStringSlice(fieldConfigPath))
+				if err != nil {
+					return nil, fmt.Errorf("failed to resolve secret of %s: %s", fieldConfigPath, err)
+				}
 				fieldValue.Set(reflect.ValueOf(slice))
 			}
 		case reflect.Struct:
@@ -138,3 +145,23 @@ func loadValue(cfg config.Config, configPath string, objectValue reflect.Value)
 
 	return skippedKeys, nil
 }
+
+func resolveSecrets(values []string) ([]string, error) {
+	var resolved []string
+	for i, value := range values {
+		if config.IsSecretRef(value) {
+			if resolved == nil {
+				resolved = append([]string{}, values...)
+			}
+			secret, err := config.ResolveSecret(value)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = secret
+		}
+	}
+	if resolved == nil {
+		return values, nil
+	}
+	return resolved, nil
+}
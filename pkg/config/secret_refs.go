@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Prefixes of references to secrets that can be used instead of plaintext values in configuration:
+//   - env:NAME - value of environment variable NAME;
+//   - file:/run/secrets/x - content of file with trailing line breaks trimmed;
+//   - enc:<base64> - value encrypted with master key, see EncryptSecret().
+//
+// Value that must literally start with one of the prefixes is escaped with backslash, e.g. \env:NAME is loaded as env:NAME.
+const (
+	SecretEnvPrefix    string = "env:"
+	SecretFilePrefix   string = "file:"
+	SecretEncPrefix    string = "enc:"
+	SecretEscapePrefix string = "\\"
+)
+
+// Master key for encrypted values is taken either from environment variable CONFIG_MASTER_KEY or from file named in CONFIG_MASTER_KEY_FILE
+// unless it is set with SetMasterKey().
+const (
+	MasterKeyEnv     string = "CONFIG_MASTER_KEY"
+	MasterKeyFileEnv string = "CONFIG_MASTER_KEY_FILE"
+)
+
+const secretSaltSize int = 16
+
+var masterKeyMutex sync.RWMutex
+var masterKey string
+
+func SetMasterKey(key string) {
+	masterKeyMutex.Lock()
+	masterKey = key
+	masterKeyMutex.Unlock()
+}
+
+func MasterKey() (string, error) {
+
+	masterKeyMutex.RLock()
+	key := masterKey
+	masterKeyMutex.RUnlock()
+	if key != "" {
+		return key, nil
+	}
+
+	key = os.Getenv(MasterKeyEnv)
+	if key != "" {
+		return key, nil
+	}
+
+	keyFile := os.Getenv(MasterKeyFileEnv)
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read master key file: %s", err)
+		}
+		key = strings.TrimRight(string(data), "\r\n")
+		if key != "" {
+			return key, nil
+		}
+	}
+
+	return "", errors.New("master key for encrypted configuration values is not set")
+}
+
+// Check if value must be resolved with ResolveSecret(), i.e. if it is either a reference to secret or an escaped value.
+func IsSecretRef(value string) bool {
+	if strings.HasPrefix(value, SecretEscapePrefix) {
+		return IsSecretRef(value[len(SecretEscapePrefix):])
+	}
+	return strings.HasPrefix(value, SecretEnvPrefix) || strings.HasPrefix(value, SecretFilePrefix) || strings.HasPrefix(value, SecretEncPrefix)
+}
+
+// Escape value so that ResolveSecret() returns it as is.
+func EscapeSecret(value string) string {
+	if IsSecretRef(value) {
+		return SecretEscapePrefix + value
+	}
+	return value
+}
+
+// Resolve reference to secret. If value is not a reference then it is returned as is, escaped value is unescaped.
+func ResolveSecret(value string) (string, error) {
+
+	switch {
+	case strings.HasPrefix(value, SecretEscapePrefix) && IsSecretRef(value):
+		return value[len(SecretEscapePrefix):], nil
+
+	case strings.HasPrefix(value, SecretEnvPrefix):
+		name := strings.TrimPrefix(value, SecretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+
+	case strings.HasPrefix(value, SecretFilePrefix):
+		path := strings.TrimPrefix(value, SecretFilePrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %s", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(value, SecretEncPrefix):
+		key, err := MasterKey()
+		if err != nil {
+			return "", err
+		}
+		return DecryptSecret(key, value)
+	}
+
+	return value, nil
+}
+
+// Encrypt secret with master key. Result has enc: prefix and can be pasted into configuration.
+func EncryptSecret(key string, secret string) (string, error) {
+
+	salt, err := crypt_utils.GenerateCryptoRand(secretSaltSize)
+	if err != nil {
+		return "", err
+	}
+	aead, err := crypt_utils.NewAEAD(key, salt)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := aead.Encrypt([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return SecretEncPrefix + utils.Base64Encode(append(salt, ciphertext...)), nil
+}
+
+// Decrypt secret with enc: prefix made with EncryptSecret().
+func DecryptSecret(key string, value string) (string, error) {
+
+	if !strings.HasPrefix(value, SecretEncPrefix) {
+		return "", errors.New("invalid format of encrypted secret")
+	}
+	data, err := utils.Base64Decode(strings.TrimPrefix(value, SecretEncPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid encoding of encrypted secret: %s", err)
+	}
+	if len(data) < secretSaltSize {
+		return "", errors.New("encrypted secret too short")
+	}
+
+	aead, err := crypt_utils.NewAEAD(key, data[:secretSaltSize])
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aead.Decrypt(data[secretSaltSize:])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %s", err)
+	}
+
+	return string(plaintext), nil
+}
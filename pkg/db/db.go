@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
@@ -14,6 +15,9 @@ const (
 	SORT_DESC string = "DESC"
 )
 
+// Name of database component in log entries.
+const LogComponent = "db"
+
 type Fields = map[string]interface{}
 
 func IsFieldSet(f Fields, key string) bool {
@@ -30,6 +34,12 @@ type DBConfig struct {
 	DB_PASSWORD     string `mask:"true"`
 	DB_EXTRA_CONFIG string
 	DB_DSN          string
+	DB_SCHEMA       string
+
+	// Limits of connection pool, zero means default limits of database driver.
+	DB_MAX_OPEN_CONNS     int
+	DB_MAX_IDLE_CONNS     int
+	DB_CONN_MAX_IDLE_TIME int
 }
 
 type DBHandlers interface {
@@ -42,6 +52,7 @@ type DBHandlers interface {
 
 	Create(ctx logger.WithLogger, obj interface{}) error
 	CreateDup(ctx logger.WithLogger, obj interface{}) (bool, error)
+	CreateIfNotExists(ctx logger.WithLogger, obj interface{}) (bool, error)
 
 	Delete(ctx logger.WithLogger, obj common.Object) error
 	DeleteByField(ctx logger.WithLogger, field string, value interface{}, model interface{}) error
@@ -59,6 +70,11 @@ type DBHandlers interface {
 	Joiner() Joiner
 
 	CreateDatabase(ctx logger.WithLogger, dbName string) error
+	CreateSchema(ctx logger.WithLogger, schema string) error
+	DropDatabase(ctx logger.WithLogger, dbName string) error
+	DropSchema(ctx logger.WithLogger, schema string) error
+	DatabaseSize(ctx logger.WithLogger, dbName string) (int64, error)
+	SchemaSize(ctx logger.WithLogger, schema string) (int64, error)
 	MakeExpression(expr string, args ...interface{}) interface{}
 
 	Sum(ctx logger.WithLogger, groupFields []string, sumFields []string, filter *Filter, model interface{}, dest ...interface{}) (int64, error)
@@ -92,12 +108,27 @@ type DB interface {
 
 	AutoMigrate(ctx logger.WithLogger, models []interface{}) error
 	PartitionedMonthAutoMigrate(ctx logger.WithLogger, models []interface{}) error
+	// Create partitions of partitioned models for given months if they do not exist yet.
+	CreateMonthPartitions(ctx logger.WithLogger, months []utils.Month, models []interface{}) error
+	// List month partitions of partitioned model sorted by month.
+	ListMonthPartitions(ctx logger.WithLogger, model interface{}) ([]*MonthPartitionInfo, error)
+	// Drop month partition of partitioned model. Rows of the month are deleted if database does not support partitioning natively.
+	DropMonthPartition(ctx logger.WithLogger, model interface{}, month utils.Month) error
 
 	NativeHandler() interface{}
 
+	Ping(ctx logger.WithLogger) error
+	// Ping database, ping is aborted when system context is done.
+	PingContext(ctx logger.WithLogger, sysCtx context.Context) error
+
 	Close()
 }
 
+// Database that can be connected without checking connection, e.g. to check connection later with PingContext().
+type WithLazyConnect interface {
+	SetLazyConnect(enable bool)
+}
+
 type WithDB interface {
 	Db() DB
 }
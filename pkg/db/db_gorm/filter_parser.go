@@ -169,7 +169,7 @@ func (f *FilterParser) Parse(query *db.Query) (*db.Filter, error) {
 	}
 
 	extractOptional := func(field string, opt *optional.String) (string, bool, error) {
-		if !opt.Present() {
+		if opt == nil || !opt.Present() {
 			return "", false, nil
 		}
 		str, err := opt.Get()
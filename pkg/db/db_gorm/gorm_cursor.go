@@ -19,7 +19,7 @@ func (c *GormCursor) Close(ctx logger.WithLogger) error {
 	err := c.rows.Close()
 	if err != nil {
 		err = fmt.Errorf("failed to close rows")
-		ctx.Logger().Error("GormDB.Cursor", err)
+		dbLogger(ctx).Error("GormDB.Cursor", err)
 	}
 	return err
 }
@@ -28,7 +28,7 @@ func (c *GormCursor) Scan(ctx logger.WithLogger, obj interface{}) error {
 	err := c.gormDB.db.ScanRows(c.sql, obj)
 	if err != nil {
 		err = fmt.Errorf("failed to scan rows to object %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB.Cursor", err)
+		dbLogger(ctx).Error("GormDB.Cursor", err)
 	}
 	return err
 }
@@ -38,7 +38,7 @@ func (c *GormCursor) Next(ctx logger.WithLogger) (bool, error) {
 	err := c.rows.Err()
 	if err != nil {
 		err = fmt.Errorf("failed to read next rows")
-		ctx.Logger().Error("GormDB.Cursor", err)
+		dbLogger(ctx).Error("GormDB.Cursor", err)
 	}
 	return next, err
 }
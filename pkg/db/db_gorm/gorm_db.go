@@ -1,17 +1,26 @@
 package db_gorm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
 	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
 	"gorm.io/gorm"
 )
 
+// Logger of context that tags entries with database component.
+func dbLogger(ctx logger.WithLogger) logger.Logger {
+	return logger.NewComponentLogger(ctx.Logger(), db.LogComponent)
+}
+
 type baseDBConfig struct {
 	ENABLE_DEBUG     bool
 	VERBOSE_ERRORS   bool
@@ -29,6 +38,17 @@ type DbConnector struct {
 	DbCreator                func(provider string, db *gorm.DB, dbName string) error
 	CheckDuplicateKeyError   func(provider string, result *gorm.DB) (bool, error)
 	PartitionedMonthMigrator func(provider string, ctx logger.WithLogger, db *gorm.DB, models ...interface{}) error
+	MonthPartitionsCreator   func(provider string, ctx logger.WithLogger, db *gorm.DB, months []utils.Month, models ...interface{}) error
+	SchemaCreator            func(provider string, db *gorm.DB, schema string) error
+	DbDropper                func(provider string, db *gorm.DB, dbName string) error
+	SchemaDropper            func(provider string, db *gorm.DB, schema string) error
+	DbSizer                  func(provider string, db *gorm.DB, dbName string) (int64, error)
+	SchemaSizer              func(provider string, db *gorm.DB, schema string) (int64, error)
+	// Build prefix of table names for providers that do not support schemas natively.
+	SchemaTablePrefix func(provider string, schema string) string
+	// Partitions are listed and dropped by rows of partitioned tables if lister and dropper are not set.
+	MonthPartitionsLister func(provider string, db *gorm.DB, model interface{}) ([]*db.MonthPartitionInfo, error)
+	MonthPartitionDropper func(provider string, ctx logger.WithLogger, db *gorm.DB, model interface{}, month utils.Month) error
 }
 
 type GormDB struct {
@@ -39,6 +59,8 @@ type GormDB struct {
 	joinQueries   *db.JoinQueries
 	filterManager *FilterManager
 	paginator     *Paginator
+
+	lazyConnect bool
 }
 
 func (g *GormDB) Config() interface{} {
@@ -103,12 +125,12 @@ func (g *GormDB) db_() *gorm.DB {
 
 func (g *GormDB) Init(ctx logger.WithLogger, cfg config.Config, vld validator.Validator, configPath ...string) error {
 
-	ctx.Logger().Info("Init GormDB")
+	dbLogger(ctx).Info("Init GormDB")
 
 	// load configuration
-	err := object_config.LoadLogValidate(cfg, ctx.Logger(), vld, g, "db", configPath...)
+	err := object_config.LoadLogValidate(cfg, dbLogger(ctx), vld, g, "db", configPath...)
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed to load GormDB configuration", err)
+		return dbLogger(ctx).PushFatalStack("failed to load GormDB configuration", err)
 	}
 	g.paginator.MaxLimit = g.MAX_FILTER_LIMIT
 
@@ -118,7 +140,7 @@ func (g *GormDB) Init(ctx logger.WithLogger, cfg config.Config, vld validator.Va
 
 func (g *GormDB) InitWithConfig(ctx logger.WithLogger, vld validator.Validator, cfg *db.DBConfig) error {
 
-	ctx.Logger().Info("Connect GormDB with DBConfig")
+	dbLogger(ctx).Info("Connect GormDB with DBConfig")
 
 	// convert configuration
 	g.gormDBConfig.DBConfig = *cfg
@@ -126,7 +148,7 @@ func (g *GormDB) InitWithConfig(ctx logger.WithLogger, vld validator.Validator,
 	// validate configuration
 	err := vld.Validate(g.Config())
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed to validate GormDB configuration", err)
+		return dbLogger(ctx).PushFatalStack("failed to validate GormDB configuration", err)
 	}
 
 	// connect database
@@ -144,24 +166,70 @@ func (g *GormDB) Connect(ctx logger.WithLogger) error {
 	} else {
 		dsn, err = g.dbConnector.DsnBuilder(&g.DBConfig)
 		if err != nil {
-			return ctx.Logger().PushFatalStack("failed to build DSN to connect to database", err)
+			return dbLogger(ctx).PushFatalStack("failed to build DSN to connect to database", err)
 		}
 	}
 
 	dbDialector, err := g.dbConnector.DialectorOpener(g.DB_PROVIDER, dsn)
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed open dialector to connect to database", err, logger.Fields{"db_provider": g.DB_PROVIDER})
+		return dbLogger(ctx).PushFatalStack("failed open dialector to connect to database", err, logger.Fields{"db_provider": g.DB_PROVIDER})
 	}
 
-	g.db, err = ConnectDB(dbDialector)
+	tablePrefix := ""
+	if g.DB_SCHEMA != "" && g.dbConnector.SchemaTablePrefix != nil {
+		tablePrefix = g.dbConnector.SchemaTablePrefix(g.DB_PROVIDER, g.DB_SCHEMA)
+	}
+	g.db, err = connectDB(dbDialector, tablePrefix, g.lazyConnect)
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed to connect to database", err)
+		return dbLogger(ctx).PushFatalStack("failed to connect to database", err)
+	}
+
+	// setup connection pool
+	sqlDb, err := g.db.DB()
+	if err != nil {
+		return dbLogger(ctx).PushFatalStack("failed to get connection pool of database", err)
+	}
+	if g.DB_MAX_OPEN_CONNS > 0 {
+		sqlDb.SetMaxOpenConns(g.DB_MAX_OPEN_CONNS)
+	}
+	if g.DB_MAX_IDLE_CONNS > 0 {
+		sqlDb.SetMaxIdleConns(g.DB_MAX_IDLE_CONNS)
+	}
+	if g.DB_CONN_MAX_IDLE_TIME > 0 {
+		sqlDb.SetConnMaxIdleTime(time.Duration(g.DB_CONN_MAX_IDLE_TIME) * time.Second)
 	}
 
 	// done
 	return nil
 }
 
+func (g *GormDB) Ping(ctx logger.WithLogger) error {
+	if g.db == nil {
+		return errors.New("database not connected")
+	}
+	db, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Ping()
+}
+
+func (g *GormDB) PingContext(ctx logger.WithLogger, sysCtx context.Context) error {
+	if g.db == nil {
+		return errors.New("database not connected")
+	}
+	db, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.PingContext(sysCtx)
+}
+
+// Connect database without checking connection, connection is established on first use.
+func (g *GormDB) SetLazyConnect(enable bool) {
+	g.lazyConnect = enable
+}
+
 func (g *GormDB) Close() {
 	if g.db != nil {
 		db, err := g.db.DB()
@@ -174,7 +242,7 @@ func (g *GormDB) Close() {
 func (g *GormDB) AutoMigrate(ctx logger.WithLogger, models []interface{}) error {
 	err := g.db_().AutoMigrate(models...)
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed to migrate database", err)
+		return dbLogger(ctx).PushFatalStack("failed to migrate database", err)
 	}
 	return nil
 }
@@ -183,11 +251,47 @@ func (g *GormDB) PartitionedMonthAutoMigrate(ctx logger.WithLogger, models []int
 	return g.dbConnector.PartitionedMonthMigrator(g.DB_PROVIDER, ctx, g.db_(), models...)
 }
 
+func (g *GormDB) CreateMonthPartitions(ctx logger.WithLogger, months []utils.Month, models []interface{}) error {
+	if g.dbConnector.MonthPartitionsCreator == nil {
+		return nil
+	}
+	return g.dbConnector.MonthPartitionsCreator(g.DB_PROVIDER, ctx, g.db_(), months, models...)
+}
+
+func (g *GormDB) ListMonthPartitions(ctx logger.WithLogger, model interface{}) ([]*db.MonthPartitionInfo, error) {
+	var partitions []*db.MonthPartitionInfo
+	var err error
+	if g.dbConnector.MonthPartitionsLister == nil {
+		partitions, err = RowsMonthPartitions(g.db_(), model)
+	} else {
+		partitions, err = g.dbConnector.MonthPartitionsLister(g.DB_PROVIDER, g.db_(), model)
+	}
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to ListMonthPartitions %v", ObjectTypeName(model))
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return partitions, err
+}
+
+func (g *GormDB) DropMonthPartition(ctx logger.WithLogger, model interface{}, month utils.Month) error {
+	var err error
+	if g.dbConnector.MonthPartitionDropper == nil {
+		err = RowsDropMonthPartition(g.db_(), model, month)
+	} else {
+		err = g.dbConnector.MonthPartitionDropper(g.DB_PROVIDER, ctx, g.db_(), model, month)
+	}
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to DropMonthPartition %v", ObjectTypeName(model))
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err, "month": month})
+	}
+	return err
+}
+
 func (g *GormDB) FindByField(ctx logger.WithLogger, field string, value interface{}, obj interface{}, dest ...interface{}) (bool, error) {
 	found, err := FindByField(g.db_(), field, value, obj, dest...)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to FindByField %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"field": field, "value": value, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"field": field, "value": value, "error": err})
 	}
 	return found, err
 }
@@ -196,7 +300,7 @@ func (g *GormDB) FindByFields(ctx logger.WithLogger, fields db.Fields, obj inter
 	found, err := FindByFields(g.db_(), fields, obj, dest...)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to FindByFields %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
 	}
 	return found, err
 }
@@ -205,7 +309,7 @@ func (g *GormDB) FindForUpdate(ctx logger.WithLogger, fields db.Fields, obj inte
 	found, err := FindForUpdate(g.db_(), fields, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to FindForUpdate %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
 	}
 	return found, err
 }
@@ -214,7 +318,7 @@ func (g *GormDB) FindForShare(ctx logger.WithLogger, fields db.Fields, obj inter
 	found, err := FindForUpdate(g.db_(), fields, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to FindForShare %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
 	}
 	return found, err
 }
@@ -226,7 +330,7 @@ func (g *GormDB) RowsByFields(ctx logger.WithLogger, fields db.Fields, obj inter
 	rows, err := RowsByFields(g.db_(), fields, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to RowsByFields %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
 	}
 	cursor.rows = rows
 	cursor.sql = rows
@@ -241,7 +345,7 @@ func (g *GormDB) AllRows(ctx logger.WithLogger, obj interface{}) (db.Cursor, err
 	rows, err := AllRows(g.db_(), obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to AllRows %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	cursor.rows = rows
 	cursor.sql = rows
@@ -253,7 +357,7 @@ func (g *GormDB) Create(ctx logger.WithLogger, obj interface{}) error {
 	result := Create(g.db_(), obj)
 	if result.Error != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to Create %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": result.Error})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": result.Error})
 	}
 	return result.Error
 }
@@ -263,16 +367,26 @@ func (g *GormDB) CreateDup(ctx logger.WithLogger, obj interface{}) (bool, error)
 	duplicate, err := g.dbConnector.CheckDuplicateKeyError(g.DB_PROVIDER, result)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to Create %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return duplicate, err
 }
 
+// Create object ignoring conflict with existing row, returns true if object was created.
+func (g *GormDB) CreateIfNotExists(ctx logger.WithLogger, obj interface{}) (bool, error) {
+	created, err := CreateIfNotExists(g.db_(), obj)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to CreateIfNotExists %v", ObjectTypeName(obj))
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return created, err
+}
+
 func (g *GormDB) DeleteByField(ctx logger.WithLogger, field string, value interface{}, model interface{}) error {
 	err := DeleteByField(g.db_(), field, value, model)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to DeleteByField %v", ObjectTypeName(model))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"field": field, "value": value, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"field": field, "value": value, "error": err})
 	}
 	return err
 }
@@ -281,7 +395,7 @@ func (g *GormDB) Delete(ctx logger.WithLogger, obj common.Object) error {
 	err := Delete(g.db_(), obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to Delete %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"id": obj.GetID(), "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"id": obj.GetID(), "error": err})
 	}
 	return err
 }
@@ -290,7 +404,7 @@ func (g *GormDB) DeleteByFields(ctx logger.WithLogger, fields db.Fields, obj int
 	err := DeleteAllByFields(g.db_(), fields, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to DeleteByFields %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"fields": fields, "error": err})
 	}
 	return err
 }
@@ -312,7 +426,7 @@ func (g *GormDB) RowsWithFilter(ctx logger.WithLogger, filter *Filter, obj inter
 	rows, err := RowsWithFilter(g.db_(), filter, g.paginator, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to RowsWithFilter %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	cursor.rows = rows
 	cursor.sql = rows
@@ -324,7 +438,7 @@ func (g *GormDB) FindWithFilter(ctx logger.WithLogger, filter *Filter, obj inter
 	count, err := FindWithFilter(g.db_(), filter, g.paginator, obj, dest...)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to FindWithFilter %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return count, err
 }
@@ -333,7 +447,7 @@ func (g *GormDB) Update(ctx logger.WithLogger, obj interface{}, filter db.Fields
 	err := UpdateFielsdMulti(g.db_(), filter, obj, newFields)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to UpdateFieldsWithFilter %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return err
 }
@@ -342,7 +456,7 @@ func (g *GormDB) UpdateWithFilter(ctx logger.WithLogger, obj interface{}, filter
 	err := UpdateWithFilter(g.db_(), filter, obj, newFields)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to UpdateWithFilter %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return err
 }
@@ -351,7 +465,7 @@ func (g *GormDB) UpdateAll(ctx logger.WithLogger, obj interface{}, newFields db.
 	err := UpdateFieldsAll(g.db_(), obj, newFields)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to UpdateAll %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return err
 }
@@ -360,7 +474,7 @@ func (g *GormDB) Exists(ctx logger.WithLogger, filter *Filter, obj interface{})
 	exists, err := Exists(g.db_(), filter, obj)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to Exists %v", ObjectTypeName(obj))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return exists, err
 }
@@ -369,11 +483,71 @@ func (g *GormDB) CreateDatabase(ctx logger.WithLogger, dbName string) error {
 	err := g.dbConnector.DbCreator(g.DB_PROVIDER, g.db_(), dbName)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to CreateDatabase %v", dbName)
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return err
+}
+
+func (g *GormDB) CreateSchema(ctx logger.WithLogger, schema string) error {
+	if g.dbConnector.SchemaCreator == nil {
+		return fmt.Errorf("schemas are not supported by database provider %v", g.DB_PROVIDER)
+	}
+	err := g.dbConnector.SchemaCreator(g.DB_PROVIDER, g.db_(), schema)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to CreateSchema %v", schema)
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return err
+}
+
+func (g *GormDB) DropDatabase(ctx logger.WithLogger, dbName string) error {
+	if g.dbConnector.DbDropper == nil {
+		return fmt.Errorf("dropping databases is not supported by database provider %v", g.DB_PROVIDER)
+	}
+	err := g.dbConnector.DbDropper(g.DB_PROVIDER, g.db_(), dbName)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to DropDatabase %v", dbName)
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return err
 }
 
+func (g *GormDB) DropSchema(ctx logger.WithLogger, schema string) error {
+	if g.dbConnector.SchemaDropper == nil {
+		return fmt.Errorf("schemas are not supported by database provider %v", g.DB_PROVIDER)
+	}
+	err := g.dbConnector.SchemaDropper(g.DB_PROVIDER, g.db_(), schema)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to DropSchema %v", schema)
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return err
+}
+
+func (g *GormDB) DatabaseSize(ctx logger.WithLogger, dbName string) (int64, error) {
+	if g.dbConnector.DbSizer == nil {
+		return 0, fmt.Errorf("size of databases is not supported by database provider %v", g.DB_PROVIDER)
+	}
+	size, err := g.dbConnector.DbSizer(g.DB_PROVIDER, g.db_(), dbName)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to DatabaseSize %v", dbName)
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return size, err
+}
+
+func (g *GormDB) SchemaSize(ctx logger.WithLogger, schema string) (int64, error) {
+	if g.dbConnector.SchemaSizer == nil {
+		return 0, fmt.Errorf("size of schemas is not supported by database provider %v", g.DB_PROVIDER)
+	}
+	size, err := g.dbConnector.SchemaSizer(g.DB_PROVIDER, g.db_(), schema)
+	if err != nil && g.VERBOSE_ERRORS {
+		e := fmt.Errorf("failed to SchemaSize %v", schema)
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
+	}
+	return size, err
+}
+
 func (g *GormDB) MakeExpression(expr string, args ...interface{}) interface{} {
 	return gorm.Expr(expr, args...)
 }
@@ -382,7 +556,7 @@ func (g *GormDB) Sum(ctx logger.WithLogger, groupFields []string, sumFields []st
 	count, err := Sum(g.db_(), g.paginator, groupFields, sumFields, filter, model, dest...)
 	if err != nil && g.VERBOSE_ERRORS {
 		e := fmt.Errorf("failed to Sum %v", ObjectTypeName(model))
-		ctx.Logger().Error("GormDB", e, logger.Fields{"error": err})
+		dbLogger(ctx).Error("GormDB", e, logger.Fields{"error": err})
 	}
 	return count, err
 }
@@ -11,11 +11,18 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
-func ConnectDB(dialector gorm.Dialector) (*gorm.DB, error) {
+func ConnectDB(dialector gorm.Dialector, tablePrefix ...string) (*gorm.DB, error) {
+	return connectDB(dialector, utils.OptionalArg("", tablePrefix...), false)
+}
+
+func connectDB(dialector gorm.Dialector, tablePrefix string, lazy bool) (*gorm.DB, error) {
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+		Logger:               logger.Default.LogMode(logger.Silent),
+		NamingStrategy:       schema.NamingStrategy{TablePrefix: tablePrefix},
+		DisableAutomaticPing: lazy,
 	})
 	return db, err
 }
@@ -99,7 +106,7 @@ func prepareInterval(db *gorm.DB, name string, interval *Interval) *gorm.DB {
 		if interval.From == interval.To {
 			h = h.Where(fmt.Sprintf("\"%v\" = ?", name), interval.From)
 		} else {
-			h = h.Where(fmt.Sprintf("\"%v\" %s ? AND \"%v\" %s ? ", name, compareOp(interval.FromOpen, ">"), compareOp(interval.ToOpen, "<"), name), interval.From, interval.To)
+			h = h.Where(fmt.Sprintf("\"%v\" %s ? AND \"%v\" %s ? ", name, compareOp(interval.FromOpen, ">"), name, compareOp(interval.ToOpen, "<")), interval.From, interval.To)
 		}
 	} else if interval.From != nil {
 		h = h.Where(fmt.Sprintf("\"%v\" %s ? ", name, compareOp(interval.FromOpen, ">")), interval.From)
@@ -325,6 +332,14 @@ func Create(db *gorm.DB, doc interface{}) *gorm.DB {
 	return db.Create(doc)
 }
 
+func CreateIfNotExists(db *gorm.DB, doc interface{}) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(doc)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected != 0, nil
+}
+
 func UpdateFields(db *gorm.DB, fields db.Fields, doc interface{}) error {
 	result := db.Model(doc).Updates(fields)
 	return result.Error
@@ -435,3 +450,41 @@ func Sum(g *gorm.DB, paginator *Paginator, groupFields []string, sumFields []str
 	dst := utils.OptionalArg(model, dest...)
 	return find(h, filter, paginator, dst)
 }
+
+func tableName(g *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: g}
+	err := stmt.Parse(model)
+	if err != nil {
+		return "", err
+	}
+	return stmt.Table, nil
+}
+
+// List months of partitioned table by its rows, used for databases without native partitioning.
+func RowsMonthPartitions(g *gorm.DB, model interface{}) ([]*db.MonthPartitionInfo, error) {
+
+	table, err := tableName(g, model)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Month utils.Month
+		Count int64
+	}
+	result := g.Model(model).Select(`"month", COUNT(*) AS "count"`).Group("month").Order("month").Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	partitions := make([]*db.MonthPartitionInfo, len(rows))
+	for i, row := range rows {
+		partitions[i] = &db.MonthPartitionInfo{Table: table, Month: row.Month, Rows: row.Count}
+	}
+	return partitions, nil
+}
+
+// Delete rows of month from partitioned table, used for databases without native partitioning.
+func RowsDropMonthPartition(g *gorm.DB, model interface{}, month utils.Month) error {
+	return g.Where("month = ?", month).Delete(model).Error
+}
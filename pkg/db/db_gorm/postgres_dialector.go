@@ -3,6 +3,8 @@ package db_gorm
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
@@ -36,9 +38,25 @@ func PostgresOpener(provider string, dsn string) (gorm.Dialector, error) {
 
 func PostgresDsnBuilder(config *db.DBConfig) (string, error) {
 	dsn := fmt.Sprintf("host=%v port=%v user=%v dbname=%v password=%v sslmode=disable TimeZone=UTC", config.DB_HOST, config.DB_PORT, config.DB_USER, config.DB_NAME, config.DB_PASSWORD)
+	if config.DB_SCHEMA != "" {
+		dsn = fmt.Sprintf("%v search_path=%v", dsn, config.DB_SCHEMA)
+	}
 	return dsn, nil
 }
 
+func PostgresSchemaCreator(provider string, db *gorm.DB, schema string) error {
+
+	if provider != "postgres" {
+		return errors.New("unknown database provider")
+	}
+
+	rs := db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", schema))
+	if rs.Error != nil {
+		return fmt.Errorf("failed to create schema: %s", rs.Error)
+	}
+	return nil
+}
+
 func PostgresDbCreator(provider string, db *gorm.DB, dbName string) error {
 
 	if provider != "postgres" {
@@ -66,6 +84,60 @@ func PostgresDbCreator(provider string, db *gorm.DB, dbName string) error {
 	return nil
 }
 
+func PostgresSchemaDropper(provider string, db *gorm.DB, schema string) error {
+
+	if provider != "postgres" {
+		return errors.New("unknown database provider")
+	}
+
+	rs := db.Exec(fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE;", schema))
+	if rs.Error != nil {
+		return fmt.Errorf("failed to drop schema: %s", rs.Error)
+	}
+	return nil
+}
+
+func PostgresDbDropper(provider string, db *gorm.DB, dbName string) error {
+
+	if provider != "postgres" {
+		return errors.New("unknown database provider")
+	}
+
+	rs := db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s;", dbName))
+	if rs.Error != nil {
+		return fmt.Errorf("failed to drop postgres database: %s", rs.Error)
+	}
+	return nil
+}
+
+func PostgresDbSizer(provider string, db *gorm.DB, dbName string) (int64, error) {
+
+	if provider != "postgres" {
+		return 0, errors.New("unknown database provider")
+	}
+
+	var size int64
+	rs := db.Raw("SELECT pg_database_size(?);", dbName).Scan(&size)
+	if rs.Error != nil {
+		return 0, fmt.Errorf("failed to get size of postgres database: %s", rs.Error)
+	}
+	return size, nil
+}
+
+func PostgresSchemaSizer(provider string, db *gorm.DB, schema string) (int64, error) {
+
+	if provider != "postgres" {
+		return 0, errors.New("unknown database provider")
+	}
+
+	var size int64
+	rs := db.Raw("SELECT COALESCE(SUM(pg_total_relation_size(quote_ident(schemaname) || '.' || quote_ident(tablename))), 0) FROM pg_tables WHERE schemaname = ?;", schema).Scan(&size)
+	if rs.Error != nil {
+		return 0, fmt.Errorf("failed to get size of schema: %s", rs.Error)
+	}
+	return size, nil
+}
+
 func PostgresCheckDuplicateKeyError(provider string, result *gorm.DB) (bool, error) {
 
 	if provider != "postgres" {
@@ -101,24 +173,33 @@ func PostgresPartitionedMonthAutoMigrate(ctx logger.WithLogger, db *gorm.DB, mod
 
 	err := db.Set("gorm:table_options", " PARTITION BY LIST (month)").Migrator().AutoMigrate(models...)
 	if err != nil {
-		return ctx.Logger().PushFatalStack("failed to migrate partitioned database models", err)
+		return dbLogger(ctx).PushFatalStack("failed to migrate partitioned database models", err)
 	}
 
+	months := make([]utils.Month, 12)
+	tableMonth := utils.CurrentMonth()
+	for i := range months {
+		months[i] = tableMonth
+		tableMonth = tableMonth.Next()
+	}
+
+	return PostgresCreateMonthPartitions(ctx, db, months, models...)
+}
+
+func PostgresCreateMonthPartitions(ctx logger.WithLogger, db *gorm.DB, months []utils.Month, models ...interface{}) error {
+
 	schemaCache := &sync.Map{}
 	schemaNamer := &schema.NamingStrategy{}
 
-	currentMonth := utils.CurrentMonth()
-
 	for _, model := range models {
 
 		sc, err := schema.Parse(model, schemaCache, schemaNamer)
 		if err != nil {
-			return ctx.Logger().PushFatalStack("failed to migrate partitioned database models", err)
+			return dbLogger(ctx).PushFatalStack("failed to migrate partitioned database models", err)
 		}
 
 		fields := logger.Fields{"table": sc.Table}
-		tableMonth := currentMonth
-		for i := 0; i < 12; i++ {
+		for _, tableMonth := range months {
 
 			subfields := utils.CopyMap(fields)
 			subfields["month"] = tableMonth
@@ -126,21 +207,95 @@ func PostgresPartitionedMonthAutoMigrate(ctx logger.WithLogger, db *gorm.DB, mod
 			subfields["partition_table"] = partitionTableName
 			partitionExists, err := PostgresTableExists(db, partitionTableName)
 			if err != nil {
-				return ctx.Logger().PushFatalStack("failed to check if partition exists in database", err)
+				return dbLogger(ctx).PushFatalStack("failed to check if partition exists in database", err)
 			}
 
 			if !partitionExists {
 				sqlExpr := fmt.Sprintf("CREATE TABLE %s PARTITION OF %s FOR VALUES IN (%d);", partitionTableName, sc.Table, tableMonth)
 				subfields["sql"] = sqlExpr
-				ctx.Logger().Info("Creating partition", subfields)
+				dbLogger(ctx).Info("Creating partition", subfields)
 				result := db.Exec(sqlExpr)
 				if result.Error != nil {
-					return ctx.Logger().PushFatalStack("failed to create partition for database model", err, subfields)
+					return dbLogger(ctx).PushFatalStack("failed to create partition for database model", result.Error, subfields)
 				}
 			}
+		}
+	}
 
-			tableMonth = tableMonth.Next()
+	return nil
+}
+
+func PostgresListMonthPartitions(g *gorm.DB, model interface{}) ([]*db.MonthPartitionInfo, error) {
+
+	sc, err := schema.Parse(model, &sync.Map{}, &schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Partition string
+		Rows      int64
+		Size      int64
+	}
+	sqlStr := `SELECT c.relname AS partition, GREATEST(c.reltuples, 0)::bigint AS rows, pg_total_relation_size(c.oid) AS size
+		FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent JOIN pg_namespace n ON n.oid = p.relnamespace
+		WHERE n.nspname = current_schema() AND p.relname = ? ORDER BY c.relname`
+	result := g.Raw(sqlStr, sc.Table).Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	prefix := utils.ConcatStrings(sc.Table, "_")
+	partitions := make([]*db.MonthPartitionInfo, 0, len(rows))
+	for _, row := range rows {
+		month, err := strconv.Atoi(strings.TrimPrefix(row.Partition, prefix))
+		if err != nil || !strings.HasPrefix(row.Partition, prefix) {
+			continue
 		}
+		partitions = append(partitions, &db.MonthPartitionInfo{Table: sc.Table, Month: utils.Month(month), Partition: row.Partition, Rows: row.Rows, Size: row.Size})
+	}
+	return partitions, nil
+}
+
+// Quote identifier of postgres object.
+func postgresQuoteIdentifier(name string) string {
+	return utils.ConcatStrings(`"`, strings.ReplaceAll(name, `"`, `""`), `"`)
+}
+
+// Detach month partition from partitioned table in current schema and drop it.
+func PostgresDropMonthPartition(ctx logger.WithLogger, db *gorm.DB, model interface{}, month utils.Month) error {
+
+	sc, err := schema.Parse(model, &sync.Map{}, &schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+
+	partitionTableName := fmt.Sprintf("%s_%d", sc.Table, month)
+	fields := logger.Fields{"table": sc.Table, "month": month, "partition_table": partitionTableName}
+
+	// find partition in current schema
+	var schemas []string
+	sqlStr := `SELECT n.nspname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid JOIN pg_class p ON p.oid = i.inhparent JOIN pg_namespace n ON n.oid = p.relnamespace
+		WHERE n.nspname = current_schema() AND p.relname = ? AND c.relname = ?`
+	result := db.Raw(sqlStr, sc.Table, partitionTableName).Scan(&schemas)
+	if result.Error != nil {
+		return dbLogger(ctx).PushFatalStack("failed to check if partition exists in database", result.Error, fields)
+	}
+	if len(schemas) == 0 {
+		return nil
+	}
+	schemaName := postgresQuoteIdentifier(schemas[0])
+	table := utils.ConcatStrings(schemaName, ".", postgresQuoteIdentifier(sc.Table))
+	partition := utils.ConcatStrings(schemaName, ".", postgresQuoteIdentifier(partitionTableName))
+
+	dbLogger(ctx).Info("Dropping partition", fields)
+	result = db.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s;", table, partition))
+	if result.Error != nil {
+		return dbLogger(ctx).PushFatalStack("failed to detach partition", result.Error, fields)
+	}
+	result = db.Exec(fmt.Sprintf("DROP TABLE %s;", partition))
+	if result.Error != nil {
+		return dbLogger(ctx).PushFatalStack("failed to drop partition", result.Error, fields)
 	}
 
 	return nil
@@ -153,11 +308,40 @@ func PostgresPartitionedMonthMigrator(provider string, ctx logger.WithLogger, db
 	return PostgresPartitionedMonthAutoMigrate(ctx, db, models...)
 }
 
+func PostgresMonthPartitionsCreator(provider string, ctx logger.WithLogger, db *gorm.DB, months []utils.Month, models ...interface{}) error {
+	if provider != "postgres" {
+		return errors.New("unknown database provider")
+	}
+	return PostgresCreateMonthPartitions(ctx, db, months, models...)
+}
+
+func PostgresMonthPartitionsLister(provider string, g *gorm.DB, model interface{}) ([]*db.MonthPartitionInfo, error) {
+	if provider != "postgres" {
+		return nil, errors.New("unknown database provider")
+	}
+	return PostgresListMonthPartitions(g, model)
+}
+
+func PostgresMonthPartitionDropper(provider string, ctx logger.WithLogger, db *gorm.DB, model interface{}, month utils.Month) error {
+	if provider != "postgres" {
+		return errors.New("unknown database provider")
+	}
+	return PostgresDropMonthPartition(ctx, db, model, month)
+}
+
 func PostgresDbConnector() *DbConnector {
 	c := &DbConnector{}
 	c.DialectorOpener = PostgresOpener
 	c.DsnBuilder = PostgresDsnBuilder
 	c.CheckDuplicateKeyError = PostgresCheckDuplicateKeyError
 	c.PartitionedMonthMigrator = PostgresPartitionedMonthMigrator
+	c.MonthPartitionsCreator = PostgresMonthPartitionsCreator
+	c.MonthPartitionsLister = PostgresMonthPartitionsLister
+	c.MonthPartitionDropper = PostgresMonthPartitionDropper
+	c.SchemaCreator = PostgresSchemaCreator
+	c.DbDropper = PostgresDbDropper
+	c.SchemaDropper = PostgresSchemaDropper
+	c.DbSizer = PostgresDbSizer
+	c.SchemaSizer = PostgresSchemaSizer
 	return c
 }
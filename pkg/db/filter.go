@@ -3,6 +3,7 @@ package db
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
@@ -126,6 +127,10 @@ func (f *Filter) AddOrFields(value interface{}, names ...string) {
 }
 
 func filterValueToString(value interface{}) string {
+	// time is formatted so that it can be parsed by utils.ParseTime
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format("2006-01-02 15:04:05")
+	}
 	return fmt.Sprintf("%v", value)
 }
 
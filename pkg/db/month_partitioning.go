@@ -10,3 +10,15 @@ type MonthPartition struct {
 	Table string      `gorm:"index;uniqueIndex:u_month_partition"`
 	Month utils.Month `gorm:"index;uniqueIndex:u_month_partition"`
 }
+
+// Month partition of partitioned table.
+// For databases without native partitioning months are taken from rows of the table, partition name and size are not set.
+type MonthPartitionInfo struct {
+	Table     string      `json:"table"`
+	Month     utils.Month `json:"month"`
+	Partition string      `json:"partition,omitempty"`
+	// Number of rows, estimated for databases with native partitioning.
+	Rows int64 `json:"rows"`
+	// Size of partition in bytes including indexes.
+	Size int64 `json:"size"`
+}
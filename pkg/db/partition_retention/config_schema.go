@@ -0,0 +1,11 @@
+package partition_retention
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Register configuration section of partition retention in configuration schema.
+func RegisterConfigSchema(schema *config_schema.Schema, configPath ...string) {
+	schema.RegisterOptional(utils.OptionalArg("partition_retention", configPath...), New())
+}
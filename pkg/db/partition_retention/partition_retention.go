@@ -0,0 +1,367 @@
+package partition_retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+type RetentionRuleConfig struct {
+	// Name of partitioned table.
+	TABLE string `validate:"required"`
+	// Number of months to keep including current month.
+	KEEP_MONTHS int `validate:"required,gte=1"`
+	// Rows of expired month are written in JSON lines to file in this directory before partition is dropped, rows are not archived if directory is empty.
+	ARCHIVE_DIR string
+}
+
+// Retention rule of partitioned table.
+type RetentionRule struct {
+	RetentionRuleConfig
+}
+
+func (r *RetentionRule) Config() interface{} {
+	return &r.RetentionRuleConfig
+}
+
+func (r *RetentionRule) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
+	err := object_config.LoadLogValidate(cfg, log, vld, r, "partition_retention.rule", configPath...)
+	if err != nil {
+		return log.PushFatalStack("failed to init retention rule", err)
+	}
+	return nil
+}
+
+// Check if month partition is expired at given current month.
+func (r *RetentionRule) Expired(month utils.Month, current utils.Month) bool {
+	oldest := current
+	for i := 1; i < r.KEEP_MONTHS; i++ {
+		oldest = oldest.Prev()
+	}
+	return month < oldest
+}
+
+type RetentionConfig struct {
+	// Period of retention job in seconds, zero disables background job.
+	PERIOD int `default:"86400" validate:"gte=0"`
+	// Number of future months whose partitions are created in advance.
+	PRECREATE_MONTHS int `default:"3" validate:"gte=0"`
+}
+
+// Partition dropped by retention.
+type DroppedPartition struct {
+	*db.MonthPartitionInfo
+	// Scope of database of partition: empty for main database, ID of tenancy for tenancy database, shared_<pool>_<database> for database shared by tenancies.
+	Scope string `json:"scope,omitempty"`
+}
+
+// Retention of month-partitioned tables.
+// Expired partitions are dropped on databases with native partitioning, on other databases rows of expired months are deleted.
+// Retention is applied to main database and, if tenancies are set, to databases of currently loaded tenancies.
+type Retention struct {
+	RetentionConfig
+	app           app_context.Context
+	models        []interface{}
+	tenancies     multitenancy.Multitenancy
+	tenancyModels []interface{}
+	rules         map[string]*RetentionRule
+	worker        *background_worker.BackgroundWorker
+}
+
+func New() *Retention {
+	return &Retention{rules: make(map[string]*RetentionRule)}
+}
+
+func (r *Retention) Config() interface{} {
+	return &r.RetentionConfig
+}
+
+func (r *Retention) Init(app app_context.Context, configPath ...string) error {
+
+	r.app = app
+	path := utils.OptionalArg("partition_retention", configPath...)
+	err := object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), r, path)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load configuration of partition retention", err)
+	}
+
+	createRule := func() *RetentionRule {
+		return &RetentionRule{}
+	}
+	rules, err := object_config.LoadLogValidateSubobjectsList(app.Cfg(), app.Logger(), app.Validator(), object_config.Key(path, "rules"), createRule)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load retention rules", err)
+	}
+	for _, rule := range rules {
+		r.rules[rule.TABLE] = rule
+	}
+
+	return nil
+}
+
+// Add partitioned models whose partitions are managed by retention.
+func (r *Retention) AddModels(models ...interface{}) {
+	r.models = append(r.models, models...)
+}
+
+// Apply retention also to databases of loaded tenancies, models are partitioned models of tenancy databases.
+// Tenancies that are not loaded, e.g. in lazy loading mode, are processed when they are loaded during next retention run.
+func (r *Retention) SetTenancies(tenancies multitenancy.Multitenancy, models ...interface{}) {
+	r.tenancies = tenancies
+	r.tenancyModels = models
+}
+
+func (r *Retention) AddRule(rule *RetentionRule) {
+	r.rules[rule.TABLE] = rule
+}
+
+func (r *Retention) Rule(table string) *RetentionRule {
+	return r.rules[table]
+}
+
+// Run retention job in background. If leader is set then the job runs only when this instance is a leader.
+func (r *Retention) Start(leader ...background_worker.Leadership) {
+	if r.PERIOD == 0 || r.worker != nil {
+		return
+	}
+	r.worker = background_worker.New(r.app.Logger(), &retentionRunner{retention: r}, r.PERIOD)
+	if len(leader) != 0 {
+		r.worker.RunOnlyWhenLeader(leader[0])
+	}
+	r.worker.RunInBackground()
+}
+
+func (r *Retention) Close() {
+	if r.worker != nil {
+		r.worker.Stop()
+		r.worker = nil
+	}
+}
+
+// List partitions of all managed models in main database.
+func (r *Retention) Partitions(ctx op_context.Context) ([]*db.MonthPartitionInfo, error) {
+
+	partitions := make([]*db.MonthPartitionInfo, 0)
+	for _, model := range r.models {
+		modelPartitions, err := ctx.Db().ListMonthPartitions(ctx, model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list partitions of %s: %s", utils.ObjectTypeName(model), err)
+		}
+		partitions = append(partitions, modelPartitions...)
+	}
+	return partitions, nil
+}
+
+// Create partitions of future months, then archive and drop expired partitions according to retention rules.
+// Returns dropped partitions. Partitions that failed to be archived or dropped are retried next time.
+// Failures of tenancy databases are logged and do not stop retention of other databases.
+func (r *Retention) Apply(ctx op_context.Context) ([]*DroppedPartition, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("PartitionRetention.Apply")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	current := utils.CurrentMonth()
+
+	// apply to main database
+	dropped, err := r.applyDb(ctx, ctx.Db(), "", r.models, current)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply to databases of loaded tenancies
+	if r.tenancies != nil && len(r.tenancyModels) != 0 {
+		done := make(map[db.DB]bool)
+		for _, tenancy := range r.tenancies.Tenancies() {
+			tenancyDropped, tErr := r.applyTenancy(ctx, tenancy, done, current)
+			if tErr != nil {
+				c.Logger().Error("failed to apply retention to tenancy database", tErr, logger.Fields{"tenancy": tenancy.GetID()})
+				ctx.ClearError()
+				continue
+			}
+			dropped = append(dropped, tenancyDropped...)
+		}
+	}
+
+	// done
+	return dropped, nil
+}
+
+func (r *Retention) applyTenancy(ctx op_context.Context, tenancy multitenancy.Tenancy, done map[db.DB]bool, current utils.Month) ([]*DroppedPartition, error) {
+
+	// database of tenancy must not be closed if tenancy is evicted meanwhile
+	if !r.tenancies.AcquireTenancy(tenancy) {
+		return nil, nil
+	}
+	defer r.tenancies.ReleaseTenancy(tenancy)
+
+	if tenancy.Db() == nil {
+		return nil, nil
+	}
+
+	// database shared by tenancies is processed once without tenancy scoping
+	database := multitenancy.UnscopedDb(tenancy.Db())
+	if done[database] {
+		return nil, nil
+	}
+	done[database] = true
+	scope := tenancy.GetID()
+	if tenancy.IsShared() {
+		scope = fmt.Sprintf("shared_%s_%s", tenancy.PoolId(), tenancy.DbName())
+	}
+
+	return r.applyDb(ctx, database, scope, r.tenancyModels, current)
+}
+
+func (r *Retention) applyDb(ctx op_context.Context, database db.DB, scope string, models []interface{}, current utils.Month) ([]*DroppedPartition, error) {
+
+	// create partitions in advance
+	if r.PRECREATE_MONTHS > 0 && len(models) != 0 {
+		months := make([]utils.Month, r.PRECREATE_MONTHS+1)
+		month := current
+		for i := range months {
+			months[i] = month
+			month = month.Next()
+		}
+		err := database.CreateMonthPartitions(ctx, months, models)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create partitions: %s", err)
+		}
+	}
+
+	// drop expired partitions
+	dropped := make([]*DroppedPartition, 0)
+	for _, model := range models {
+
+		partitions, err := database.ListMonthPartitions(ctx, model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list partitions: %s", err)
+		}
+
+		for _, partition := range partitions {
+			rule := r.rules[partition.Table]
+			if rule == nil || !rule.Expired(partition.Month, current) {
+				continue
+			}
+			pErr := r.dropPartition(ctx, database, scope, model, rule, partition)
+			if pErr != nil {
+				ctx.Logger().Error("failed to drop expired partition", pErr, logger.Fields{"table": partition.Table, "month": partition.Month, "scope": scope})
+				ctx.ClearError()
+				continue
+			}
+			dropped = append(dropped, &DroppedPartition{MonthPartitionInfo: partition, Scope: scope})
+		}
+	}
+
+	return dropped, nil
+}
+
+func (r *Retention) dropPartition(ctx op_context.Context, database db.DB, scope string, model interface{}, rule *RetentionRule, partition *db.MonthPartitionInfo) error {
+
+	if rule.ARCHIVE_DIR != "" {
+		dir := rule.ARCHIVE_DIR
+		if scope != "" {
+			dir = filepath.Join(dir, scope)
+		}
+		err := r.archivePartition(ctx, database, dir, model, partition)
+		if err != nil {
+			return fmt.Errorf("failed to archive partition: %s", err)
+		}
+	}
+
+	ctx.Logger().Info("dropping expired partition", logger.Fields{"table": partition.Table, "month": partition.Month, "scope": scope})
+	return database.DropMonthPartition(ctx, model, partition.Month)
+}
+
+// Path of archive file of month partition.
+func ArchivePath(dir string, table string, month utils.Month) string {
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.jsonl", table, month.String()))
+}
+
+func (r *Retention) archivePartition(ctx op_context.Context, database db.DB, dir string, model interface{}, partition *db.MonthPartitionInfo) error {
+
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	// archive is written to temporary file first so that incomplete archive never replaces complete one
+	path := ArchivePath(dir, partition.Table, partition.Month)
+	tmpPath := utils.ConcatStrings(path, ".tmp")
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	newModel := func() interface{} {
+		return reflect.New(reflect.TypeOf(model).Elem()).Interface()
+	}
+
+	filter := db.NewFilter()
+	filter.AddField("month", partition.Month)
+	cursor, err := database.RowsWithFilter(ctx, filter, newModel())
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	encoder := json.NewEncoder(file)
+	for {
+		next, err := cursor.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if !next {
+			break
+		}
+		row := newModel()
+		err = cursor.Scan(ctx, row)
+		if err != nil {
+			return err
+		}
+		err = encoder.Encode(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+type retentionRunner struct {
+	background_worker.JobRunnerBase
+	retention *Retention
+}
+
+func (p *retentionRunner) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(p.retention.app, "PartitionRetention.Apply")
+	defer ctx.Close()
+	p.retention.Apply(ctx)
+}
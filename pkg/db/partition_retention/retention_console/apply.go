@@ -0,0 +1,35 @@
+package retention_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ApplyCmd string = "apply"
+const ApplyDescription string = "Create future partitions, archive and drop expired partitions"
+
+func Apply() Handler {
+	a := &ApplyHandler{}
+	a.Init(ApplyCmd, ApplyDescription)
+	return a
+}
+
+type ApplyHandler struct {
+	HandlerBase
+}
+
+func (a *ApplyHandler) Execute(args []string) error {
+
+	ctx, retention, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	dropped, err := retention.Apply(ctx)
+	if err == nil {
+		fmt.Printf("Dropped partitions:\n\n%s\n\n", utils.DumpPrettyJson(dropped))
+	}
+	return err
+}
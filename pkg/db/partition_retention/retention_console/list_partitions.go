@@ -0,0 +1,63 @@
+package retention_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ListPartitionsCmd string = "list"
+const ListPartitionsDescription string = "List month partitions with sizes and retention rules"
+
+func ListPartitions() Handler {
+	a := &ListPartitionsHandler{}
+	a.Init(ListPartitionsCmd, ListPartitionsDescription)
+	return a
+}
+
+type ListPartitionsData struct {
+	Table string `long:"table" description:"Show only partitions of this table"`
+}
+
+type ListPartitionsHandler struct {
+	HandlerBase
+	ListPartitionsData
+}
+
+func (a *ListPartitionsHandler) Data() interface{} {
+	return &a.ListPartitionsData
+}
+
+func (a *ListPartitionsHandler) Execute(args []string) error {
+
+	ctx, retention, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	partitions, err := retention.Partitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	type item struct {
+		*db.MonthPartitionInfo
+		KeepMonths int `json:"keep_months,omitempty"`
+	}
+	items := make([]*item, 0, len(partitions))
+	for _, partition := range partitions {
+		if a.Table != "" && partition.Table != a.Table {
+			continue
+		}
+		i := &item{MonthPartitionInfo: partition}
+		if rule := retention.Rule(partition.Table); rule != nil {
+			i.KeepMonths = rule.KEEP_MONTHS
+		}
+		items = append(items, i)
+	}
+
+	fmt.Printf("Partitions:\n\n%s\n\n", utils.DumpPrettyJson(items))
+	return nil
+}
@@ -0,0 +1,38 @@
+package retention_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/db/partition_retention"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+type RetentionCommands struct {
+	console_tool.Commands[*RetentionCommands]
+	GetRetention func() *partition_retention.Retention
+}
+
+func NewRetentionCommands(retention func() *partition_retention.Retention) *RetentionCommands {
+	p := &RetentionCommands{}
+	p.Construct(p, "partitions", "Manage month partitions of partitioned tables")
+	p.GetRetention = retention
+	p.LoadHandlers()
+	return p
+}
+
+func (p *RetentionCommands) LoadHandlers() {
+	p.AddHandlers(ListPartitions, Apply)
+}
+
+type Handler = console_tool.Handler[*RetentionCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*RetentionCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, *partition_retention.Retention, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, b.Group.GetRetention(), nil
+}
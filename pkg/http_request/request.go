@@ -176,6 +176,11 @@ func (r *Request) SetAuthHeader(key string, value string) {
 func HttpHeadersSet(req *http.Request, headers ...map[string]string) {
 	if len(headers) > 0 {
 		for k, v := range headers[0] {
+			// host must be set explicitly because Host header is ignored by http package
+			if http.CanonicalHeaderKey(k) == "Host" {
+				req.Host = v
+				continue
+			}
 			req.Header.Set(k, v)
 		}
 	}
@@ -0,0 +1,188 @@
+package job_queue
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed cron expression with five fields: minute, hour, day of month, month and day of week.
+// Each field supports "*", lists "1,2,3", ranges "1-5" and steps "*/15" or "1-30/5". Day of week is 0-6, 0 is Sunday, 7 is also accepted as Sunday.
+// Shortcuts @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are supported too.
+// If both day of month and day of week are restricted then a day matching either of them matches the schedule.
+type CronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	domAny bool
+	dowAny bool
+}
+
+type cronField struct {
+	name string
+	min  int
+	max  int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func ParseCron(expr string) (*CronSchedule, error) {
+
+	expr = strings.TrimSpace(expr)
+	if shortcut, ok := cronShortcuts[strings.ToLower(expr)]; ok {
+		expr = shortcut
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression must have %d fields", len(cronFields))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		var err error
+		bits[i], err = parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s := &CronSchedule{minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4]}
+	s.domAny = parts[2] == "*"
+	s.dowAny = parts[4] == "*"
+
+	// 7 is Sunday as well as 0
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+func parseCronField(value string, field cronField) (uint64, error) {
+
+	var bits uint64
+	for _, item := range strings.Split(value, ",") {
+
+		invalid := func() (uint64, error) {
+			return 0, fmt.Errorf("invalid %s in cron expression: %s", field.name, item)
+		}
+
+		// parse step
+		rangePart := item
+		step := 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			rangePart = item[:idx]
+			var err error
+			step, err = strconv.Atoi(item[idx+1:])
+			if err != nil || step <= 0 {
+				return invalid()
+			}
+		}
+
+		// parse range
+		from, to := field.min, field.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return invalid()
+			}
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return invalid()
+				}
+			} else if step != 1 {
+				to = field.max
+			}
+		}
+		if from < field.min || to > field.max || from > to {
+			return invalid()
+		}
+
+		for i := from; i <= to; i += step {
+			bits |= 1 << i
+		}
+	}
+
+	return bits, nil
+}
+
+func cronMatch(bits uint64, value int) bool {
+	return bits&(1<<value) != 0
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := cronMatch(s.dom, t.Day())
+	dowMatch := cronMatch(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first time after t matching the schedule. Zero time is returned if schedule never matches, e.g. for 30th of February.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !cronMatch(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !cronMatch(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !cronMatch(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Get next time of cron expression after t.
+func NextCronTime(expr string, t time.Time) (time.Time, error) {
+	s, err := ParseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := s.Next(t)
+	if next.IsZero() {
+		return next, errors.New("cron expression never matches")
+	}
+	return next, nil
+}
@@ -0,0 +1,175 @@
+package job_queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+)
+
+const (
+	JobPending   string = "pending"
+	JobRunning   string = "running"
+	JobDone      string = "done"
+	JobFailed    string = "failed"
+	JobCancelled string = "cancelled"
+)
+
+const DefaultQueue string = "default"
+
+// Job is a persistent job stored in main database.
+// Jobs with cron expression are rescheduled after each run and are never finished unless cancelled.
+type Job struct {
+	common.ObjectBase
+	NAME             string    `gorm:"index" json:"name"`
+	TYPE             string    `gorm:"index" json:"type"`
+	QUEUE            string    `gorm:"index" json:"queue"`
+	TENANCY_ID       string    `gorm:"index" json:"tenancy_id"`
+	PAYLOAD          string    `json:"payload"`
+	STATUS           string    `gorm:"index" json:"status"`
+	CRON             string    `json:"cron"`
+	RUN_AT           time.Time `gorm:"index" json:"run_at"`
+	ATTEMPTS         int       `json:"attempts"`
+	MAX_ATTEMPTS     int       `json:"max_attempts"`
+	LAST_ERROR       string    `json:"last_error"`
+	LEASE_OWNER      string    `gorm:"index" json:"lease_owner"`
+	LEASE_TOKEN      string    `json:"-"`
+	LEASE_EXPIRES_AT time.Time `gorm:"index" json:"lease_expires_at"`
+	STARTED_AT       time.Time `json:"started_at"`
+	FINISHED_AT      time.Time `json:"finished_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+func (j *Job) Name() string {
+	return j.NAME
+}
+
+func (j *Job) Type() string {
+	return j.TYPE
+}
+
+func (j *Job) Queue() string {
+	return j.QUEUE
+}
+
+func (j *Job) TenancyId() string {
+	return j.TENANCY_ID
+}
+
+func (j *Job) Status() string {
+	return j.STATUS
+}
+
+func (j *Job) Cron() string {
+	return j.CRON
+}
+
+func (j *Job) RunAt() time.Time {
+	return j.RUN_AT
+}
+
+func (j *Job) Attempts() int {
+	return j.ATTEMPTS
+}
+
+func (j *Job) LastError() string {
+	return j.LAST_ERROR
+}
+
+func (j *Job) IsFinished() bool {
+	return j.STATUS == JobDone || j.STATUS == JobFailed || j.STATUS == JobCancelled
+}
+
+// Decode payload of job into object.
+func (j *Job) Payload(obj interface{}) error {
+	if j.PAYLOAD == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(j.PAYLOAD), obj)
+}
+
+type OpLogJob struct {
+	oplog.OplogBase
+	JobId     string `gorm:"index" json:"job_id"`
+	Name      string `gorm:"index" json:"name"`
+	Type      string `gorm:"index" json:"type"`
+	Queue     string `gorm:"index" json:"queue"`
+	TenancyId string `gorm:"index" json:"tenancy_id"`
+}
+
+func DbModels() []interface{} {
+	return []interface{}{&Job{}, &OpLogJob{}}
+}
+
+const (
+	OpEnqueue  string = "enqueue"
+	OpSchedule string = "schedule"
+	OpCancel   string = "cancel"
+)
+
+const (
+	ErrorCodeJobNotFound    string = "job_not_found"
+	ErrorCodeJobFinished    string = "job_finished"
+	ErrorCodeJobInvalidCron string = "job_invalid_cron"
+)
+
+var ErrorDescriptions = map[string]string{
+	ErrorCodeJobNotFound:    "Job not found.",
+	ErrorCodeJobFinished:    "Job is already finished.",
+	ErrorCodeJobInvalidCron: "Invalid cron expression of job.",
+}
+
+var ErrorHttpCodes = map[string]int{
+	ErrorCodeJobNotFound:    http.StatusNotFound,
+	ErrorCodeJobFinished:    http.StatusConflict,
+	ErrorCodeJobInvalidCron: http.StatusBadRequest,
+}
+
+// JobHandler executes jobs of some type.
+// Job is retried with back-off if handler returns error until max number of attempts is reached.
+// If job belongs to tenancy then context is a tenancy context with database of the tenancy.
+type JobHandler interface {
+	Handle(ctx op_context.Context, job *Job) error
+}
+
+type JobHandlerFunc func(ctx op_context.Context, job *Job) error
+
+func (f JobHandlerFunc) Handle(ctx op_context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+type JobOptions struct {
+	// Name of queue, default queue is used if empty.
+	Queue string
+	// Tenancy of job, if empty then tenancy of context is used if context has tenancy.
+	TenancyId string
+	// Time to run job at, if zero then job runs after delay.
+	RunAt time.Time
+	// Delay before running the job.
+	Delay time.Duration
+	// Max number of attempts, if zero then MAX_ATTEMPTS from configuration is used.
+	MaxAttempts int
+}
+
+type JobQueue interface {
+
+	// Enqueue job. Job is always saved in main database outside of database transaction of context,
+	// so job is enqueued even if that transaction is rolled back.
+	Enqueue(ctx op_context.Context, jobType string, payload interface{}, options ...*JobOptions) (*Job, error)
+
+	// Schedule named job with cron expression. If job with the same name exists then it is updated and rescheduled.
+	Schedule(ctx op_context.Context, name string, cron string, jobType string, payload interface{}, options ...*JobOptions) (*Job, error)
+}
+
+type JobController interface {
+	Find(ctx op_context.Context, id string) (*Job, error)
+	List(ctx op_context.Context, filter *db.Filter) ([]*Job, int64, error)
+	Cancel(ctx op_context.Context, id string) (*Job, error)
+}
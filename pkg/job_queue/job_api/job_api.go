@@ -0,0 +1,22 @@
+package job_api
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+)
+
+const ServiceName string = "jobs"
+const JobResource string = "job"
+
+type JobResponse struct {
+	api.ResponseBase
+	*job_queue.Job
+}
+
+type ListJobsResponse = api.ResponseList[*job_queue.Job]
+
+var (
+	List   = func() api.Operation { return api.List("list_jobs") }
+	Find   = func() api.Operation { return api.Find("find_job") }
+	Cancel = func() api.Operation { return api.Update("cancel_job") }
+)
@@ -0,0 +1,35 @@
+package job_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (j *JobClient) Find(ctx op_context.Context, id string) (*job_queue.Job, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobClient.Find")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&job_queue.Job{})
+	op := api.NamedResourceOperation(j.JobResource, id, job_api.Find())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(j.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result, nil
+}
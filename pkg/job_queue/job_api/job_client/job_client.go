@@ -0,0 +1,31 @@
+package job_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+)
+
+type JobClient struct {
+	api_client.ServiceClient
+
+	JobsResource api.Resource
+	JobResource  api.Resource
+
+	list api.Operation
+}
+
+func NewJobClient(client api_client.Client) *JobClient {
+
+	c := &JobClient{}
+
+	c.Init(client, job_api.ServiceName)
+	c.JobResource = api.NamedResource(job_api.JobResource)
+	c.JobsResource = c.JobResource.Parent()
+	c.AddChild(c.JobsResource)
+
+	c.list = job_api.List()
+	c.JobsResource.AddOperation(c.list)
+
+	return c
+}
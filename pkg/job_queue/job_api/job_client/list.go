@@ -0,0 +1,38 @@
+package job_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (j *JobClient) List(ctx op_context.Context, filter *db.Filter) ([]*job_queue.Job, int64, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobClient.List")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// set query
+	cmd := api.NewDbQuery(filter)
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(cmd, &job_api.ListJobsResponse{})
+	err = j.list.Exec(ctx, api_client.MakeOperationHandler(j.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, 0, err
+	}
+
+	// done
+	return handler.Result.Items, handler.Result.Count, nil
+}
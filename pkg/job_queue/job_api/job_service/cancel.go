@@ -0,0 +1,38 @@
+package job_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+)
+
+type CancelEndpoint struct {
+	JobEndpoint
+}
+
+func (e *CancelEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("job.Cancel")
+	defer request.TraceOutMethod()
+
+	// cancel job
+	resp := &job_api.JobResponse{}
+	resp.Job, err = e.service.Jobs.Cancel(request, request.GetResourceId(job_api.JobResource))
+	if err != nil {
+		c.SetMessage("failed to cancel job")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Cancel(s *JobService) *CancelEndpoint {
+	e := &CancelEndpoint{}
+	e.Construct(s, job_api.Cancel())
+	return e
+}
@@ -0,0 +1,38 @@
+package job_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+)
+
+type FindEndpoint struct {
+	JobEndpoint
+}
+
+func (e *FindEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("job.Find")
+	defer request.TraceOutMethod()
+
+	// find job
+	resp := &job_api.JobResponse{}
+	resp.Job, err = e.service.Jobs.Find(request, request.GetResourceId(job_api.JobResource))
+	if err != nil {
+		c.SetMessage("failed to find job")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Find(s *JobService) *FindEndpoint {
+	e := &FindEndpoint{}
+	e.Construct(s, job_api.Find())
+	return e
+}
@@ -0,0 +1,50 @@
+package job_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+)
+
+type JobEndpoint struct {
+	service *JobService
+	api_server.EndpointBase
+}
+
+func (e *JobEndpoint) Construct(service *JobService, op api.Operation) {
+	e.service = service
+	e.EndpointBase.Construct(op)
+}
+
+type JobService struct {
+	api_server.ServiceBase
+	Jobs job_queue.JobController
+
+	JobsResource api.Resource
+	JobResource  api.Resource
+}
+
+func NewJobService(jobController job_queue.JobController) *JobService {
+
+	s := &JobService{}
+	s.ErrorsExtenderBase.Init(job_queue.ErrorDescriptions, job_queue.ErrorHttpCodes)
+	s.Jobs = jobController
+
+	s.Init(job_api.ServiceName)
+	s.JobResource = api.NamedResource(job_api.JobResource)
+	s.JobsResource = s.JobResource.Parent()
+	s.AddChild(s.JobsResource)
+
+	listOp := List(s)
+	s.JobsResource.AddOperation(listOp)
+	s.JobResource.AddOperation(Find(s), true)
+
+	cancelResource := api.NewResource("cancel")
+	cancelResource.AddOperation(Cancel(s))
+	s.JobResource.AddChild(cancelResource)
+
+	s.AddDynamicTables(&api_server.DynamicTableConfig{Model: &job_queue.Job{}, Operation: listOp})
+
+	return s
+}
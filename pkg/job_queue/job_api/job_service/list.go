@@ -0,0 +1,44 @@
+package job_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api"
+)
+
+type ListEndpoint struct {
+	JobEndpoint
+}
+
+func (e *ListEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("job.List")
+	defer request.TraceOutMethod()
+
+	// parse query
+	queryName := request.Endpoint().Resource().ServicePathPrototype()
+	filter, err := api_server.ParseDbQuery(request, &job_queue.Job{}, queryName)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// get jobs
+	resp := &job_api.ListJobsResponse{}
+	resp.Items, resp.Count, err = e.service.Jobs.List(request, filter)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func List(s *JobService) *ListEndpoint {
+	e := &ListEndpoint{}
+	e.Construct(s, job_api.List())
+	return e
+}
@@ -0,0 +1,39 @@
+package job_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const CancelJobCmd string = "cancel_job"
+const CancelJobDescription string = "Cancel pending or running job"
+
+func CancelJob() Handler {
+	a := &CancelJobHandler{}
+	a.Init(CancelJobCmd, CancelJobDescription)
+	return a
+}
+
+type CancelJobHandler struct {
+	HandlerBase
+	JobData
+}
+
+func (a *CancelJobHandler) Data() interface{} {
+	return &a.JobData
+}
+
+func (a *CancelJobHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	job, err := controller.Cancel(ctx, a.Id)
+	if err == nil {
+		fmt.Printf("Cancelled job:\n\n%s\n\n", utils.DumpPrettyJson(job))
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package job_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+type JobCommands struct {
+	console_tool.Commands[*JobCommands]
+	GetJobController func() job_queue.JobController
+}
+
+func NewJobCommands(jobController func() job_queue.JobController) *JobCommands {
+	p := &JobCommands{}
+	p.Construct(p, "job", "Manage jobs")
+	p.GetJobController = jobController
+	p.LoadHandlers()
+	return p
+}
+
+func (p *JobCommands) LoadHandlers() {
+	p.AddHandlers(ListJobs,
+		ShowJob,
+		CancelJob)
+}
+
+type Handler = console_tool.Handler[*JobCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*JobCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, job_queue.JobController, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, b.Group.GetJobController(), nil
+}
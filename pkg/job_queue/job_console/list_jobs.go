@@ -0,0 +1,48 @@
+package job_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ListJobsCmd string = "list_jobs"
+const ListJobsDescription string = "List jobs"
+
+func ListJobs() Handler {
+	a := &ListJobsHandler{}
+	a.Init(ListJobsCmd, ListJobsDescription)
+	return a
+}
+
+type ListJobsHandler struct {
+	HandlerBase
+	console_tool.QueryData
+}
+
+func (a *ListJobsHandler) Data() interface{} {
+	return &a.QueryData
+}
+
+func (a *ListJobsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	filter, err := db.ParseQuery(ctx.Db(), a.Query, &job_queue.Job{}, "")
+	if err != nil {
+		return fmt.Errorf("failed to parse query: %s", err)
+	}
+
+	jobs, count, err := controller.List(ctx, filter)
+	if err == nil {
+		fmt.Printf("Jobs:\n\n%s\n\nTotal count %d\n\n", utils.DumpPrettyJson(jobs), count)
+	}
+	return err
+}
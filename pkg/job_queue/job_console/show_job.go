@@ -0,0 +1,43 @@
+package job_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ShowJobCmd string = "show_job"
+const ShowJobDescription string = "Show job"
+
+func ShowJob() Handler {
+	a := &ShowJobHandler{}
+	a.Init(ShowJobCmd, ShowJobDescription)
+	return a
+}
+
+type JobData struct {
+	Id string `long:"id" description:"ID of the job" required:"true"`
+}
+
+type ShowJobHandler struct {
+	HandlerBase
+	JobData
+}
+
+func (a *ShowJobHandler) Data() interface{} {
+	return &a.JobData
+}
+
+func (a *ShowJobHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	job, err := controller.Find(ctx, a.Id)
+	if err == nil {
+		fmt.Printf("Job:\n\n%s\n\n", utils.DumpPrettyJson(job))
+	}
+	return err
+}
@@ -0,0 +1,771 @@
+package job_queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+type JobManagerConfig struct {
+
+	// Period of polling queues for jobs in seconds.
+	POLL_PERIOD int `validate:"gt=0" vmessage:"Invalid period of polling jobs" default:"5"`
+
+	// Lease of running job expires in LEASE_TIMEOUT seconds unless it is extended with heartbeats every HEARTBEAT_PERIOD seconds.
+	// Jobs with expired leases are considered abandoned by crashed instances and are picked again.
+	LEASE_TIMEOUT    int `validate:"gt=0" vmessage:"Invalid timeout of jobs lease" default:"60"`
+	HEARTBEAT_PERIOD int `validate:"gt=0" vmessage:"Invalid period of jobs heartbeat" default:"20"`
+
+	// Failed job is retried after RETRY_DELAY seconds, the delay is doubled on each attempt up to MAX_RETRY_DELAY seconds.
+	RETRY_DELAY     int `validate:"gte=0" vmessage:"Invalid delay of jobs retry" default:"10"`
+	MAX_RETRY_DELAY int `validate:"gte=0" vmessage:"Invalid max delay of jobs retry" default:"3600"`
+
+	// Default max number of attempts to run job.
+	MAX_ATTEMPTS int `validate:"gt=0" vmessage:"Invalid max number of job attempts" default:"5"`
+
+	// Default number of jobs that can run concurrently in each queue.
+	// Concurrency of individual queues can be set in "queues" section as map of queue names to numbers.
+	CONCURRENCY int `validate:"gt=0" vmessage:"Invalid concurrency of jobs" default:"4"`
+}
+
+// JobManager is a persistent job queue and scheduler.
+// Jobs are stored in main database, so they can be enqueued by any application instance and are run by instances that run workers of queues.
+// Jobs are claimed with leases, so each job is run by only one instance at a time.
+type JobManager struct {
+	JobManagerConfig
+
+	app          app_context.Context
+	crud         *crud.DbCRUD
+	owner        string
+	multitenancy multitenancy.Multitenancy
+
+	handlersMutex sync.RWMutex
+	handlers      map[string]JobHandler
+
+	queuesMutex sync.Mutex
+	queues      map[string]*queueRunner
+
+	runningMutex sync.Mutex
+	running      map[string]string
+	heartbeat    *background_worker.BackgroundWorker
+	jobs         sync.WaitGroup
+}
+
+func NewJobManager() *JobManager {
+	m := &JobManager{}
+	m.crud = &crud.DbCRUD{ForceMainDb: true}
+	m.handlers = make(map[string]JobHandler)
+	m.queues = make(map[string]*queueRunner)
+	m.running = make(map[string]string)
+	return m
+}
+
+func (m *JobManager) Config() interface{} {
+	return &m.JobManagerConfig
+}
+
+func (m *JobManager) Init(app app_context.Context, configPath ...string) error {
+
+	m.app = app
+	m.owner = fmt.Sprintf("%s/%s/%s", app.Application(), app.Hostname(), utils.GenerateID())
+
+	path := utils.OptionalArg("jobs", configPath...)
+	err := object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), m, path)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load configuration of job manager", err)
+	}
+	if m.HEARTBEAT_PERIOD+1 >= m.LEASE_TIMEOUT {
+		err = errors.New("heartbeat period must be less than lease timeout")
+		return app.Logger().PushFatalStack("invalid configuration of job manager", err)
+	}
+
+	// load queues
+	m.AddQueue(DefaultQueue, m.CONCURRENCY)
+	queuesPath := object_config.Key(path, "queues")
+	for name, value := range app.Cfg().GetStringMapString(queuesPath) {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency <= 0 {
+			err = fmt.Errorf("invalid concurrency of queue %s", name)
+			return app.Logger().PushFatalStack("invalid configuration of job manager", err)
+		}
+		m.AddQueue(name, concurrency)
+	}
+
+	return nil
+}
+
+// Set multitenancy to run jobs of tenancies. Only jobs of tenancies known to this instance are run.
+func (m *JobManager) SetMultitenancy(tenancies multitenancy.Multitenancy) {
+	m.multitenancy = tenancies
+}
+
+// Register handler of jobs of given type.
+func (m *JobManager) RegisterHandler(jobType string, handler JobHandler) {
+	m.handlersMutex.Lock()
+	m.handlers[jobType] = handler
+	m.handlersMutex.Unlock()
+}
+
+func (m *JobManager) handler(jobType string) JobHandler {
+	m.handlersMutex.RLock()
+	defer m.handlersMutex.RUnlock()
+	return m.handlers[jobType]
+}
+
+// Add queue or change concurrency of existing queue. Must be called before Run.
+func (m *JobManager) AddQueue(name string, concurrency int) {
+	m.queuesMutex.Lock()
+	defer m.queuesMutex.Unlock()
+	q, ok := m.queues[name]
+	if !ok {
+		q = &queueRunner{manager: m, name: name}
+		m.queues[name] = q
+	}
+	q.slots = make(chan struct{}, concurrency)
+}
+
+func (m *JobManager) queue(name string) (*queueRunner, error) {
+	m.queuesMutex.Lock()
+	defer m.queuesMutex.Unlock()
+	q, ok := m.queues[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown queue %s", name)
+	}
+	return q, nil
+}
+
+// Run workers of all queues in background.
+func (m *JobManager) Run() {
+
+	m.queuesMutex.Lock()
+	for _, q := range m.queues {
+		q.worker = background_worker.New(m.app.Logger(), q, m.POLL_PERIOD)
+		q.worker.RunInBackground()
+	}
+	m.queuesMutex.Unlock()
+
+	m.heartbeat = background_worker.New(m.app.Logger(), &jobsHeartbeat{manager: m}, m.HEARTBEAT_PERIOD)
+	m.heartbeat.RunInBackground()
+}
+
+// Stop workers and wait for running jobs.
+func (m *JobManager) Close() {
+
+	m.queuesMutex.Lock()
+	for _, q := range m.queues {
+		if q.worker != nil {
+			q.worker.Stop()
+			q.worker = nil
+		}
+	}
+	m.queuesMutex.Unlock()
+
+	m.jobs.Wait()
+
+	if m.heartbeat != nil {
+		m.heartbeat.Stop()
+		m.heartbeat = nil
+	}
+}
+
+func (m *JobManager) SetGenericError(ctx op_context.Context, code string, err error) {
+	genErr := generic_error.NewFromOriginal(code, ErrorDescriptions[code], err)
+	ctx.SetGenericError(genErr)
+}
+
+func (m *JobManager) OpLog(ctx op_context.Context, operation string, job *Job) {
+	oplog := &OpLogJob{JobId: job.GetID(), Name: job.NAME, Type: job.TYPE, Queue: job.QUEUE, TenancyId: job.TENANCY_ID}
+	oplog.SetOperation(operation)
+	ctx.Oplog(oplog)
+}
+
+func (m *JobManager) newJob(ctx op_context.Context, jobType string, payload interface{}, options ...*JobOptions) (*Job, error) {
+
+	opts := utils.OptionalArg(&JobOptions{}, options...)
+
+	job := &Job{}
+	job.InitObject()
+	job.TYPE = jobType
+	job.STATUS = JobPending
+
+	job.QUEUE = opts.Queue
+	if job.QUEUE == "" {
+		job.QUEUE = DefaultQueue
+	}
+
+	job.TENANCY_ID = opts.TenancyId
+	if job.TENANCY_ID == "" {
+		tenancyCtx, ok := ctx.(multitenancy.TenancyContext)
+		if ok {
+			job.TENANCY_ID = multitenancy.ContextTenancy(tenancyCtx)
+		}
+	}
+
+	job.MAX_ATTEMPTS = opts.MaxAttempts
+	if job.MAX_ATTEMPTS <= 0 {
+		job.MAX_ATTEMPTS = m.MAX_ATTEMPTS
+	}
+
+	job.RUN_AT = opts.RunAt
+	if job.RUN_AT.IsZero() {
+		job.RUN_AT = time.Now().Add(opts.Delay)
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize payload: %s", err)
+		}
+		job.PAYLOAD = string(data)
+	}
+
+	return job, nil
+}
+
+func (m *JobManager) Enqueue(ctx op_context.Context, jobType string, payload interface{}, options ...*JobOptions) (*Job, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobManager.Enqueue", logger.Fields{"type": jobType})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// create job
+	job, err := m.newJob(ctx, jobType, payload, options...)
+	if err != nil {
+		return nil, err
+	}
+	err = m.crud.Create(ctx, job)
+	if err != nil {
+		c.SetMessage("failed to save job")
+		return nil, err
+	}
+
+	// save oplog
+	m.OpLog(ctx, OpEnqueue, job)
+
+	// done
+	return job, nil
+}
+
+func (m *JobManager) Schedule(ctx op_context.Context, name string, cron string, jobType string, payload interface{}, options ...*JobOptions) (*Job, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobManager.Schedule", logger.Fields{"name": name, "type": jobType, "cron": cron})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check cron expression
+	runAt, err := NextCronTime(cron, time.Now())
+	if err != nil {
+		m.SetGenericError(ctx, ErrorCodeJobInvalidCron, err)
+		return nil, err
+	}
+
+	// prepare job
+	job, err := m.newJob(ctx, jobType, payload, options...)
+	if err != nil {
+		return nil, err
+	}
+	job.NAME = name
+	job.CRON = cron
+	job.RUN_AT = runAt
+
+	// find existing job
+	existing := &Job{}
+	found, err := m.crud.Read(ctx, db.Fields{"name": name}, existing)
+	if err != nil {
+		c.SetMessage("failed to find job")
+		return nil, err
+	}
+
+	// save job
+	if found {
+		job.SetID(existing.GetID())
+		job.SetCreatedAt(existing.GetCreatedAt())
+		fields := db.Fields{"type": job.TYPE, "queue": job.QUEUE, "tenancy_id": job.TENANCY_ID, "payload": job.PAYLOAD,
+			"cron": job.CRON, "run_at": job.RUN_AT, "status": job.STATUS, "attempts": 0, "max_attempts": job.MAX_ATTEMPTS,
+			"last_error": "", "lease_owner": "", "lease_token": "", "lease_expires_at": time.Time{}, "finished_at": time.Time{}}
+		err = m.crud.Update(ctx, existing, fields)
+	} else {
+		err = m.crud.Create(ctx, job)
+	}
+	if err != nil {
+		c.SetMessage("failed to save job")
+		return nil, err
+	}
+
+	// save oplog
+	m.OpLog(ctx, OpSchedule, job)
+
+	// done
+	return job, nil
+}
+
+func (m *JobManager) Find(ctx op_context.Context, id string) (*Job, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobManager.Find", logger.Fields{"job": id})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find job
+	job := &Job{}
+	found, err := m.crud.Read(ctx, db.Fields{"id": id}, job)
+	if err != nil {
+		c.SetMessage("failed to find job")
+		return nil, err
+	}
+	if !found {
+		ctx.SetGenericErrorCode(ErrorCodeJobNotFound)
+		err = errors.New("job not found")
+		return nil, err
+	}
+
+	// done
+	return job, nil
+}
+
+func (m *JobManager) List(ctx op_context.Context, filter *db.Filter) ([]*Job, int64, error) {
+
+	// setup
+	c := ctx.TraceInMethod("JobManager.List")
+	defer ctx.TraceOutMethod()
+
+	// list jobs
+	var jobs []*Job
+	count, err := m.crud.List(ctx, filter, &jobs)
+	if err != nil {
+		c.SetMessage("failed to list jobs")
+		return nil, 0, c.SetError(err)
+	}
+
+	// done
+	return jobs, count, nil
+}
+
+// Cancel pending or running job. Handler of running job is not interrupted but result of the job is discarded.
+func (m *JobManager) Cancel(ctx op_context.Context, id string) (*Job, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("JobManager.Cancel", logger.Fields{"job": id})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find job
+	job, err := m.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		ctx.SetGenericErrorCode(ErrorCodeJobFinished)
+		err = errors.New("job is finished")
+		return nil, err
+	}
+
+	// cancel job if its status was not changed
+	now := time.Now()
+	fields := db.Fields{"status": JobCancelled, "finished_at": now, "lease_owner": "", "lease_token": "", "lease_expires_at": time.Time{}}
+	err = m.crud.UpdateMulti(ctx, &Job{}, db.Fields{"id": id, "status": job.STATUS}, fields)
+	if err != nil {
+		c.SetMessage("failed to update job")
+		return nil, err
+	}
+	job, err = m.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.STATUS != JobCancelled {
+		ctx.SetGenericErrorCode(ErrorCodeJobFinished)
+		err = errors.New("job is finished")
+		return nil, err
+	}
+
+	// save oplog
+	m.OpLog(ctx, OpCancel, job)
+
+	// done
+	return job, nil
+}
+
+func (m *JobManager) tenancyFilter(filter *db.Filter) {
+	if m.multitenancy == nil {
+		filter.AddField("tenancy_id", "")
+		return
+	}
+	ids := []interface{}{""}
+	for _, id := range m.multitenancy.TenancyIds() {
+		ids = append(ids, id)
+	}
+	filter.AddFieldIn("tenancy_id", ids...)
+}
+
+// Claim up to limit jobs of queue that are due to run or whose leases expired.
+func (m *JobManager) claimJobs(ctx op_context.Context, queue string, limit int) ([]*Job, error) {
+
+	// setup
+	c := ctx.TraceInMethod("JobManager.claimJobs", logger.Fields{"queue": queue})
+	defer ctx.TraceOutMethod()
+
+	// find candidates
+	now := time.Now()
+	var candidates []*Job
+	for _, status := range []string{JobPending, JobRunning} {
+		filter := db.NewFilter()
+		filter.AddField("queue", queue)
+		filter.AddField("status", status)
+		m.tenancyFilter(filter)
+		if status == JobPending {
+			filter.Intervals = map[string]*db.Interval{"run_at": {To: now}}
+			filter.SetSorting("run_at")
+		} else {
+			filter.Intervals = map[string]*db.Interval{"lease_expires_at": {To: now, ToOpen: true}}
+			filter.SetSorting("lease_expires_at")
+		}
+		filter.Limit = limit - len(candidates)
+		var jobs []*Job
+		_, err := m.crud.List(ctx, filter, &jobs)
+		if err != nil {
+			c.SetMessage("failed to find jobs")
+			return nil, c.SetError(err)
+		}
+		candidates = append(candidates, jobs...)
+		if len(candidates) >= limit {
+			break
+		}
+	}
+
+	// claim candidates, job is claimed only if it was not changed by other instances
+	claimed := make([]*Job, 0, len(candidates))
+	for _, candidate := range candidates {
+		token := utils.GenerateID()
+		fields := db.Fields{"status": JobRunning, "lease_owner": m.owner, "lease_token": token,
+			"lease_expires_at": now.Add(time.Duration(m.LEASE_TIMEOUT) * time.Second), "started_at": now, "attempts": candidate.ATTEMPTS + 1}
+		filter := db.Fields{"id": candidate.GetID(), "status": candidate.STATUS, "lease_token": candidate.LEASE_TOKEN}
+		err := m.crud.UpdateMulti(ctx, &Job{}, filter, fields)
+		if err != nil {
+			c.SetMessage("failed to claim job")
+			return claimed, c.SetError(err)
+		}
+		job := &Job{}
+		found, err := m.crud.Read(ctx, db.Fields{"id": candidate.GetID()}, job)
+		if err != nil {
+			c.SetMessage("failed to find claimed job")
+			return claimed, c.SetError(err)
+		}
+		if found && job.LEASE_TOKEN == token {
+			claimed = append(claimed, job)
+		}
+	}
+
+	// done
+	return claimed, nil
+}
+
+func (m *JobManager) retryDelay(attempts int) time.Duration {
+	delay := time.Duration(m.RETRY_DELAY) * time.Second
+	maxDelay := time.Duration(m.MAX_RETRY_DELAY) * time.Second
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// Save result of job if job is still leased with the token.
+func (m *JobManager) finishJob(ctx op_context.Context, job *Job, jobErr error) error {
+
+	// setup
+	c := ctx.TraceInMethod("JobManager.finishJob", logger.Fields{"job": job.GetID(), "type": job.TYPE})
+	defer ctx.TraceOutMethod()
+
+	// prepare fields
+	now := time.Now()
+	fields := db.Fields{"lease_owner": "", "lease_token": "", "lease_expires_at": time.Time{}}
+	reschedule := func() {
+		next, err := NextCronTime(job.CRON, now)
+		if err != nil {
+			fields["status"] = JobFailed
+			fields["finished_at"] = now
+			fields["last_error"] = err.Error()
+			return
+		}
+		fields["status"] = JobPending
+		fields["run_at"] = next
+		fields["attempts"] = 0
+	}
+	if jobErr == nil {
+		fields["last_error"] = ""
+		if job.CRON != "" {
+			reschedule()
+		} else {
+			fields["status"] = JobDone
+			fields["finished_at"] = now
+		}
+	} else {
+		fields["last_error"] = jobErr.Error()
+		if job.ATTEMPTS < job.MAX_ATTEMPTS {
+			fields["status"] = JobPending
+			fields["run_at"] = now.Add(m.retryDelay(job.ATTEMPTS))
+		} else if job.CRON != "" {
+			reschedule()
+		} else {
+			fields["status"] = JobFailed
+			fields["finished_at"] = now
+		}
+	}
+
+	// update job
+	filter := db.Fields{"id": job.GetID(), "status": JobRunning, "lease_token": job.LEASE_TOKEN}
+	err := m.crud.UpdateMulti(ctx, &Job{}, filter, fields)
+	if err != nil {
+		c.SetMessage("failed to update job")
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+// Create context of job. If job belongs to tenancy then the tenancy is acquired so that its database is not closed while the job is running,
+// the acquired tenancy must be released when the job is done.
+func (m *JobManager) jobContext(job *Job) (op_context.Context, multitenancy.Tenancy, error) {
+
+	name := utils.ConcatStrings("Job.", job.TYPE)
+	if job.TENANCY_ID == "" {
+		return default_op_context.NewBackgroundContext(m.app, name), nil, nil
+	}
+
+	if m.multitenancy == nil {
+		return nil, nil, errors.New("multitenancy is not set")
+	}
+	tenancy, err := m.acquireTenancy(job.TENANCY_ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := multitenancy.NewContext()
+	ctx.Init(m.app, m.app.Logger(), tenancy.Db())
+	ctx.SetName(name)
+	errManager := &generic_error.ErrorManagerBase{}
+	errManager.Init(http.StatusInternalServerError)
+	ctx.SetErrorManager(errManager)
+	origin := default_op_context.NewOrigin(m.app)
+	origin.SetUser(background_worker.ContextUser)
+	origin.SetUserType(op_context.AutoUserType)
+	ctx.SetOrigin(origin)
+	ctx.SetTenancy(tenancy)
+	return ctx, tenancy, nil
+}
+
+func (m *JobManager) acquireTenancy(id string) (multitenancy.Tenancy, error) {
+
+	// tenancy can be unloaded after it is found but before it is acquired, then it is found again
+	for i := 0; i < 3; i++ {
+		tenancy, err := m.multitenancy.Tenancy(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find tenancy: %s", err)
+		}
+		if m.multitenancy.AcquireTenancy(tenancy) {
+			return tenancy, nil
+		}
+	}
+	return nil, errors.New("tenancy unloaded while loading")
+}
+
+// Handle job, returns tenancy of the job that was acquired in job context.
+func (m *JobManager) handleJob(job *Job) (tenancy multitenancy.Tenancy, err error) {
+
+	// job abandoned too many times
+	if job.ATTEMPTS > job.MAX_ATTEMPTS {
+		return nil, errors.New("lease of job expired too many times")
+	}
+
+	handler := m.handler(job.TYPE)
+	if handler == nil {
+		return nil, fmt.Errorf("no handler for jobs of type %s", job.TYPE)
+	}
+
+	ctx, tenancy, err := m.jobContext(job)
+	if err != nil {
+		return nil, err
+	}
+	defer ctx.Close()
+	c := ctx.TraceInMethod("JobManager.handleJob", logger.Fields{"job": job.GetID(), "type": job.TYPE, "attempt": job.ATTEMPTS})
+	defer ctx.TraceOutMethod()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+			c.SetError(err)
+		}
+	}()
+
+	err = handler.Handle(ctx, job)
+	if err != nil {
+		c.SetError(err)
+	}
+	return tenancy, err
+}
+
+func (m *JobManager) runJob(job *Job) {
+
+	m.runningMutex.Lock()
+	m.running[job.GetID()] = job.LEASE_TOKEN
+	m.runningMutex.Unlock()
+
+	tenancy, jobErr := m.handleJob(job)
+	if tenancy != nil {
+		m.multitenancy.ReleaseTenancy(tenancy)
+	}
+
+	m.runningMutex.Lock()
+	delete(m.running, job.GetID())
+	m.runningMutex.Unlock()
+
+	ctx := default_op_context.NewBackgroundContext(m.app, "JobManager.FinishJob")
+	defer ctx.Close()
+	if jobErr != nil {
+		ctx.Logger().Warn("job failed", logger.Fields{"job": job.GetID(), "type": job.TYPE, "attempt": job.ATTEMPTS, "error": jobErr.Error()})
+	}
+	m.finishJob(ctx, job, jobErr)
+}
+
+func (m *JobManager) processQueue(ctx op_context.Context, q *queueRunner, wait bool) (int, error) {
+
+	// setup
+	c := ctx.TraceInMethod("JobManager.processQueue", logger.Fields{"queue": q.name})
+	defer ctx.TraceOutMethod()
+
+	// claim jobs for free slots
+	free := cap(q.slots) - len(q.slots)
+	if free <= 0 {
+		return 0, nil
+	}
+	jobs, err := m.claimJobs(ctx, q.name, free)
+
+	// run claimed jobs
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		q.slots <- struct{}{}
+		wg.Add(1)
+		m.jobs.Add(1)
+		go func(job *Job) {
+			defer func() {
+				<-q.slots
+				wg.Done()
+				m.jobs.Done()
+			}()
+			m.runJob(job)
+		}(job)
+	}
+	if wait {
+		wg.Wait()
+	}
+
+	if err != nil {
+		return len(jobs), c.SetError(err)
+	}
+	return len(jobs), nil
+}
+
+// Claim and run due jobs of queue and wait until they finish, returns number of processed jobs.
+func (m *JobManager) ProcessJobs(ctx op_context.Context, queue ...string) (int, error) {
+	q, err := m.queue(utils.OptionalArg(DefaultQueue, queue...))
+	if err != nil {
+		return 0, err
+	}
+	return m.processQueue(ctx, q, true)
+}
+
+// Extend leases of jobs running in this instance.
+func (m *JobManager) ExtendLeases(ctx op_context.Context) error {
+
+	// setup
+	c := ctx.TraceInMethod("JobManager.ExtendLeases")
+	defer ctx.TraceOutMethod()
+
+	// collect running jobs
+	m.runningMutex.Lock()
+	running := utils.CopyMapOneLevel(m.running)
+	m.runningMutex.Unlock()
+
+	// extend leases
+	expiresAt := time.Now().Add(time.Duration(m.LEASE_TIMEOUT) * time.Second)
+	var err error
+	for id, token := range running {
+		err1 := m.crud.UpdateMulti(ctx, &Job{}, db.Fields{"id": id, "status": JobRunning, "lease_token": token}, db.Fields{"lease_expires_at": expiresAt})
+		if err1 != nil {
+			err = err1
+			c.Logger().Error("failed to extend lease of job", err1, logger.Fields{"job": id})
+		}
+	}
+
+	return err
+}
+
+type queueRunner struct {
+	background_worker.JobRunnerBase
+	manager *JobManager
+	name    string
+	slots   chan struct{}
+	worker  *background_worker.BackgroundWorker
+}
+
+func (q *queueRunner) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(q.manager.app, "JobManager.ProcessJobs")
+	defer ctx.Close()
+	q.manager.processQueue(ctx, q, false)
+}
+
+type jobsHeartbeat struct {
+	background_worker.JobRunnerBase
+	manager *JobManager
+}
+
+func (h *jobsHeartbeat) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(h.manager.app, "JobManager.ExtendLeases")
+	defer ctx.Close()
+	h.manager.ExtendLeases(ctx)
+}
@@ -0,0 +1,110 @@
+package leader_election
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// DbLeaseStore keeps leases in main database.
+// Lease is changed only if its token was not changed since lease was read, so concurrent instances can not take the same lease.
+type DbLeaseStore struct {
+	crud *crud.DbCRUD
+}
+
+func NewDbLeaseStore() *DbLeaseStore {
+	return &DbLeaseStore{crud: &crud.DbCRUD{ForceMainDb: true}}
+}
+
+func (d *DbLeaseStore) TryAcquire(ctx op_context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("DbLeaseStore.TryAcquire", logger.Fields{"name": name, "holder": holder})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find lease
+	now := time.Now()
+	lease := &Lease{}
+	found, err := d.crud.Read(ctx, db.Fields{"id": name}, lease)
+	if err != nil {
+		c.SetMessage("failed to find lease")
+		return false, err
+	}
+	token := utils.GenerateID()
+
+	// create lease
+	if !found {
+		lease.InitObject()
+		lease.SetID(name)
+		lease.HOLDER = holder
+		lease.TOKEN = token
+		lease.EXPIRES_AT = now.Add(ttl)
+		lease.ELECTED_AT = now
+		var duplicate bool
+		duplicate, err = op_context.DB(ctx, true).CreateDup(ctx, lease)
+		if err != nil {
+			if duplicate {
+				// lease was created by other instance
+				err = nil
+				return false, nil
+			}
+			c.SetMessage("failed to create lease")
+			return false, err
+		}
+		return true, nil
+	}
+
+	// lease is held by other holder
+	fields := db.Fields{"token": token, "expires_at": now.Add(ttl)}
+	if lease.HOLDER != holder {
+		if lease.EXPIRES_AT.After(now) {
+			return false, nil
+		}
+		fields["holder"] = holder
+		fields["elected_at"] = now
+	}
+
+	// renew or take over lease
+	err = d.crud.UpdateMulti(ctx, &Lease{}, db.Fields{"id": name, "token": lease.TOKEN}, fields)
+	if err != nil {
+		c.SetMessage("failed to update lease")
+		return false, err
+	}
+	found, err = d.crud.Read(ctx, db.Fields{"id": name}, lease)
+	if err != nil {
+		c.SetMessage("failed to find updated lease")
+		return false, err
+	}
+
+	// done
+	return found && lease.TOKEN == token, nil
+}
+
+func (d *DbLeaseStore) Release(ctx op_context.Context, name string, holder string) error {
+
+	// setup
+	c := ctx.TraceInMethod("DbLeaseStore.Release", logger.Fields{"name": name, "holder": holder})
+	defer ctx.TraceOutMethod()
+
+	// release lease
+	fields := db.Fields{"holder": "", "token": utils.GenerateID(), "expires_at": time.Time{}}
+	err := d.crud.UpdateMulti(ctx, &Lease{}, db.Fields{"id": name, "holder": holder}, fields)
+	if err != nil {
+		c.SetMessage("failed to release lease")
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
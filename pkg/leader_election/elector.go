@@ -0,0 +1,204 @@
+package leader_election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pool_pubsub"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	finish "github.com/evgeniums/go-finish-service"
+)
+
+const (
+	ProviderDb    string = "db"
+	ProviderRedis string = "redis"
+)
+
+type ElectorConfig struct {
+
+	// Name of leadership. Instances compete for leadership with the same name.
+	NAME string `validate:"required" vmessage:"Name of leadership must be set" default:"main"`
+
+	// Lease of leader expires in TTL seconds unless it is renewed every RENEW_PERIOD seconds.
+	TTL          int `validate:"gt=0" vmessage:"Invalid TTL of leader lease" default:"15"`
+	RENEW_PERIOD int `validate:"gt=0" vmessage:"Invalid period of renewing leader lease" default:"5"`
+
+	// Provider of leases: "db" for main database or "redis". Configuration of Redis is loaded from "redis" section.
+	PROVIDER string `validate:"omitempty,oneof=db redis" vmessage:"Invalid provider of leader leases" default:"db"`
+}
+
+// Elector elects a leader among replicated instances of application.
+// Leader holds a lease that is periodically renewed, if leader crashes then other instance takes the lease over when the lease expires.
+type Elector struct {
+	background_worker.JobRunnerBase
+	ElectorConfig
+
+	app    app_context.Context
+	holder string
+	store  LeaseStore
+	pubsub pool_pubsub.PoolPubsub
+
+	leader atomic.Bool
+	worker *background_worker.BackgroundWorker
+
+	handlersMutex sync.Mutex
+	handlers      []func(leader bool)
+}
+
+// Create elector with optional lease store. If store is not set then it is created according to PROVIDER in configuration.
+func NewElector(store ...LeaseStore) *Elector {
+	e := &Elector{}
+	e.store = utils.OptionalArg(nil, store...)
+	return e
+}
+
+func (e *Elector) Config() interface{} {
+	return &e.ElectorConfig
+}
+
+func (e *Elector) Init(app app_context.Context, configPath ...string) error {
+
+	e.app = app
+	e.holder = fmt.Sprintf("%s/%s/%s", app.Application(), app.Hostname(), utils.GenerateID())
+
+	path := utils.OptionalArg("leader_election", configPath...)
+	err := object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), e, path)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load configuration of leader elector", err)
+	}
+	if e.RENEW_PERIOD+1 >= e.TTL {
+		err = errors.New("renew period must be less than TTL")
+		return app.Logger().PushFatalStack("invalid configuration of leader elector", err)
+	}
+
+	// create lease store
+	if e.store == nil {
+		if e.PROVIDER == ProviderRedis {
+			redisStore := NewRedisLeaseStore()
+			err = redisStore.Init(app.Cfg(), app.Logger(), app.Validator(), object_config.Key(path, "redis"))
+			if err != nil {
+				return app.Logger().PushFatalStack("failed to init Redis store of leader leases", err)
+			}
+			e.store = redisStore
+		} else {
+			e.store = NewDbLeaseStore()
+		}
+	}
+
+	return nil
+}
+
+// Set pubsub to publish notifications on leadership changes to own pool.
+func (e *Elector) SetPubsub(pubsub pool_pubsub.PoolPubsub) {
+	e.pubsub = pubsub
+}
+
+// Add handler to call when this instance becomes or stops being a leader.
+func (e *Elector) OnChange(handler func(leader bool)) {
+	e.handlersMutex.Lock()
+	e.handlers = append(e.handlers, handler)
+	e.handlersMutex.Unlock()
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *Elector) Holder() string {
+	return e.holder
+}
+
+// Try to become a leader or renew leadership.
+func (e *Elector) Elect(ctx op_context.Context) (bool, error) {
+
+	ttl := time.Duration(e.TTL) * time.Second
+	leader, err := e.store.TryAcquire(ctx, e.NAME, e.holder, ttl)
+	if err != nil {
+		// lease can not be renewed, so leadership is lost to let other instances take it over after lease expiration
+		leader = false
+	}
+	e.setLeader(ctx, leader)
+	return leader, err
+}
+
+// Give up leadership and release the lease so that other instance can take it over immediately.
+func (e *Elector) Resign(ctx op_context.Context) error {
+	if !e.IsLeader() {
+		return nil
+	}
+	e.setLeader(ctx, false)
+	return e.store.Release(ctx, e.NAME, e.holder)
+}
+
+func (e *Elector) setLeader(ctx op_context.Context, leader bool) {
+
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+
+	if leader {
+		ctx.Logger().Info("Leader elector: became leader", logger.Fields{"name": e.NAME, "holder": e.holder})
+	} else {
+		ctx.Logger().Warn("Leader elector: lost leadership", logger.Fields{"name": e.NAME, "holder": e.holder})
+	}
+
+	e.handlersMutex.Lock()
+	handlers := append([]func(bool){}, e.handlers...)
+	e.handlersMutex.Unlock()
+	for _, handler := range handlers {
+		handler(leader)
+	}
+
+	if e.pubsub != nil {
+		msg := &LeadershipNotification{Name: e.NAME, Holder: e.holder, Leader: leader}
+		err := e.pubsub.PublishSelfPool(LeadershipTopicName, msg)
+		if err != nil {
+			ctx.Logger().Error("Leader elector: failed to publish leadership notification", err)
+		}
+	}
+}
+
+func (e *Elector) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(e.app, "Elector.Elect")
+	defer ctx.Close()
+	e.Elect(ctx)
+}
+
+func (e *Elector) Worker() *background_worker.BackgroundWorker {
+	return e.worker
+}
+
+// Run election in background and add elector to finisher to release leadership on shutdown.
+func (e *Elector) Run(fin *finish.Finisher) {
+	e.worker = background_worker.New(e.app.Logger(), e, e.RENEW_PERIOD)
+	e.worker.RunInBackground()
+	if fin != nil {
+		fin.Add(e)
+	}
+}
+
+// Stop election and release leadership.
+func (e *Elector) Stop() {
+	if e.worker != nil {
+		e.worker.Stop()
+		e.worker = nil
+	}
+	ctx := default_op_context.NewBackgroundContext(e.app, "Elector.Resign")
+	defer ctx.Close()
+	e.Resign(ctx)
+}
+
+func (e *Elector) Shutdown(ctx context.Context) error {
+	e.Stop()
+	return nil
+}
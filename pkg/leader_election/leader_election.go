@@ -0,0 +1,70 @@
+package leader_election
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_subscriber"
+)
+
+// Lease of leadership. ID of lease is a name of leadership.
+type Lease struct {
+	common.ObjectBase
+	HOLDER     string    `gorm:"index" json:"holder"`
+	TOKEN      string    `json:"-"`
+	EXPIRES_AT time.Time `json:"expires_at"`
+	ELECTED_AT time.Time `json:"elected_at"`
+}
+
+func (Lease) TableName() string {
+	return "leader_leases"
+}
+
+func (l *Lease) Holder() string {
+	return l.HOLDER
+}
+
+func (l *Lease) ExpiresAt() time.Time {
+	return l.EXPIRES_AT
+}
+
+func (l *Lease) ElectedAt() time.Time {
+	return l.ELECTED_AT
+}
+
+func DbModels() []interface{} {
+	return []interface{}{&Lease{}}
+}
+
+// LeaseStore keeps leases of leadership shared by application instances.
+type LeaseStore interface {
+
+	// Acquire lease for holder or renew lease if holder already has it. Returns false if lease is held by other holder.
+	TryAcquire(ctx op_context.Context, name string, holder string, ttl time.Duration) (bool, error)
+
+	// Release lease if it is held by holder.
+	Release(ctx op_context.Context, name string, holder string) error
+}
+
+type LeadershipNotification struct {
+	Name   string `json:"name"`
+	Holder string `json:"holder"`
+	Leader bool   `json:"leader"`
+}
+
+const LeadershipTopicName = "leadership"
+
+type LeadershipTopic struct {
+	*pubsub_subscriber.TopicBase[*LeadershipNotification]
+}
+
+func NewLeadershipNotification() *LeadershipNotification {
+	return &LeadershipNotification{}
+}
+
+func NewLeadershipTopic() *LeadershipTopic {
+	t := &LeadershipTopic{}
+	t.TopicBase = pubsub_subscriber.New(LeadershipTopicName, NewLeadershipNotification)
+	return t
+}
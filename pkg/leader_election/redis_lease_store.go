@@ -0,0 +1,71 @@
+package leader_election
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_redis"
+	"github.com/redis/go-redis/v9"
+)
+
+const RedisKeyPrefix string = "leader:"
+
+// Set key if it does not exist or prolong key if it is held by the same holder.
+var redisAcquireScript = redis.NewScript(`
+local v = redis.call("GET", KEYS[1])
+if not v then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if v == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// Delete key only if it is held by the holder.
+var redisReleaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaseStore keeps leases in Redis as keys with expiration.
+type RedisLeaseStore struct {
+	pubsub_redis.RedisClient
+}
+
+func NewRedisLeaseStore() *RedisLeaseStore {
+	return &RedisLeaseStore{}
+}
+
+func (r *RedisLeaseStore) TryAcquire(ctx op_context.Context, name string, holder string, ttl time.Duration) (bool, error) {
+
+	c := ctx.TraceInMethod("RedisLeaseStore.TryAcquire", logger.Fields{"name": name, "holder": holder})
+	defer ctx.TraceOutMethod()
+
+	result, err := redisAcquireScript.Run(r.Context(), r.Client(), []string{RedisKeyPrefix + name}, holder, ttl.Milliseconds()).Int()
+	if err != nil {
+		c.SetMessage("failed to acquire lease in Redis")
+		return false, c.SetError(err)
+	}
+
+	return result == 1, nil
+}
+
+func (r *RedisLeaseStore) Release(ctx op_context.Context, name string, holder string) error {
+
+	c := ctx.TraceInMethod("RedisLeaseStore.Release", logger.Fields{"name": name, "holder": holder})
+	defer ctx.TraceOutMethod()
+
+	err := redisReleaseScript.Run(r.Context(), r.Client(), []string{RedisKeyPrefix + name}, holder).Err()
+	if err != nil {
+		c.SetMessage("failed to release lease in Redis")
+		return c.SetError(err)
+	}
+
+	return nil
+}
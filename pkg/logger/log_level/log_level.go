@@ -0,0 +1,131 @@
+package log_level
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+const (
+	ErrorCodeRuntimeLevelsNotSupported string = "runtime_log_levels_not_supported"
+)
+
+var ErrorDescriptions = map[string]string{
+	ErrorCodeRuntimeLevelsNotSupported: "Logger does not support changing levels at runtime.",
+}
+
+var ErrorHttpCodes = map[string]int{
+	ErrorCodeRuntimeLevelsNotSupported: http.StatusNotImplemented,
+}
+
+// Levels of application logger.
+type LogLevels struct {
+	LEVEL      string            `json:"level"`
+	COMPONENTS map[string]string `json:"components,omitempty"`
+}
+
+// Command to change global level of logger or level of component.
+type LogLevelCmd struct {
+	LEVEL     string `json:"level" validate:"omitempty,oneof=panic fatal error warn warning info debug trace" vmessage:"Invalid log level, must be one of: panic | fatal | error | warn | info | debug | trace" long:"level" description:"Log level, if empty for component then override of component level is removed"`
+	COMPONENT string `json:"component" long:"component" description:"Component, if not set then global level is changed"`
+}
+
+// LevelController views and changes levels of application logger at runtime.
+type LevelController interface {
+	Levels(ctx op_context.Context) (*LogLevels, error)
+	SetLevel(ctx op_context.Context, cmd *LogLevelCmd) (*LogLevels, error)
+}
+
+// Controller of levels of logger of current process.
+type LevelControllerBase struct {
+	Logger logger.Logger
+}
+
+func NewLevelController(log logger.Logger) *LevelControllerBase {
+	return &LevelControllerBase{Logger: log}
+}
+
+func (l *LevelControllerBase) runtimeLevels(ctx op_context.Context) (logger.WithRuntimeLevels, error) {
+	next := l.Logger
+	for next != nil {
+		log, ok := next.(logger.WithRuntimeLevels)
+		if ok {
+			return log, nil
+		}
+		wrapper, ok := next.(logger.WithNextLogger)
+		if !ok {
+			break
+		}
+		next = wrapper.NextLogger()
+	}
+	ctx.SetGenericErrorCode(ErrorCodeRuntimeLevelsNotSupported)
+	return nil, errors.New("logger does not support runtime levels")
+}
+
+func currentLevels(log logger.WithRuntimeLevels) *LogLevels {
+	levels := &LogLevels{}
+	levels.LEVEL, levels.COMPONENTS = log.Levels()
+	return levels
+}
+
+func (l *LevelControllerBase) Levels(ctx op_context.Context) (*LogLevels, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("LevelController.Levels")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// get levels
+	log, err := l.runtimeLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// done
+	return currentLevels(log), nil
+}
+
+func (l *LevelControllerBase) SetLevel(ctx op_context.Context, cmd *LogLevelCmd) (*LogLevels, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("LevelController.SetLevel", logger.Fields{"level": cmd.LEVEL, "component": cmd.COMPONENT})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// set level
+	log, err := l.runtimeLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.COMPONENT == "" {
+		err = log.SetLevel(cmd.LEVEL)
+	} else {
+		err = log.SetLevel(cmd.LEVEL, cmd.COMPONENT)
+	}
+	if err != nil {
+		ctx.SetGenericErrorCode(generic_error.ErrorCodeFormat)
+		c.SetMessage("failed to set log level")
+		return nil, err
+	}
+
+	// log change of level
+	ctx.Logger().Info("Log level changed", logger.Fields{"level": cmd.LEVEL, "component": cmd.COMPONENT})
+
+	// done
+	return currentLevels(log), nil
+}
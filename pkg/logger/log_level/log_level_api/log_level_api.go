@@ -0,0 +1,19 @@
+package log_level_api
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+)
+
+const ServiceName string = "logger"
+const LevelsResource string = "levels"
+
+type LogLevelsResponse struct {
+	api.ResponseBase
+	*log_level.LogLevels
+}
+
+var (
+	Levels   = func() api.Operation { return api.Find("log_levels") }
+	SetLevel = func() api.Operation { return api.Update("set_log_level") }
+)
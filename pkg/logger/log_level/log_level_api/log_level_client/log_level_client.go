@@ -0,0 +1,83 @@
+package log_level_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level/log_level_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+type LogLevelClient struct {
+	api_client.ServiceClient
+
+	LevelsResource api.Resource
+
+	levels   api.Operation
+	setLevel api.Operation
+}
+
+func NewLogLevelClient(client api_client.Client) *LogLevelClient {
+
+	c := &LogLevelClient{}
+
+	c.Init(client, log_level_api.ServiceName)
+	c.LevelsResource = api.NewResource(log_level_api.LevelsResource)
+	c.AddChild(c.LevelsResource)
+
+	c.levels = log_level_api.Levels()
+	c.setLevel = log_level_api.SetLevel()
+	c.LevelsResource.AddOperations(c.levels, c.setLevel)
+
+	return c
+}
+
+func (l *LogLevelClient) Levels(ctx op_context.Context) (*log_level.LogLevels, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("LogLevelClient.Levels")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&log_level_api.LogLevelsResponse{})
+	err = l.levels.Exec(ctx, api_client.MakeOperationHandler(l.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.LogLevels, nil
+}
+
+func (l *LogLevelClient) SetLevel(ctx op_context.Context, cmd *log_level.LogLevelCmd) (*log_level.LogLevels, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("LogLevelClient.SetLevel")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(cmd, &log_level_api.LogLevelsResponse{})
+	err = l.setLevel.Exec(ctx, api_client.MakeOperationHandler(l.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.LogLevels, nil
+}
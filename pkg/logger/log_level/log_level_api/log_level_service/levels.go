@@ -0,0 +1,37 @@
+package log_level_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level/log_level_api"
+)
+
+type LevelsEndpoint struct {
+	LogLevelEndpoint
+}
+
+func (e *LevelsEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("logger.Levels")
+	defer request.TraceOutMethod()
+
+	// get levels
+	resp := &log_level_api.LogLevelsResponse{}
+	resp.LogLevels, err = e.service.Levels.Levels(request)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Levels(s *LogLevelService) *LevelsEndpoint {
+	e := &LevelsEndpoint{}
+	e.Construct(s, log_level_api.Levels())
+	return e
+}
@@ -0,0 +1,41 @@
+package log_level_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level/log_level_api"
+)
+
+type LogLevelEndpoint struct {
+	service *LogLevelService
+	api_server.EndpointBase
+}
+
+func (e *LogLevelEndpoint) Construct(service *LogLevelService, op api.Operation) {
+	e.service = service
+	e.EndpointBase.Construct(op)
+}
+
+// Service for viewing and changing levels of application logger without restart.
+type LogLevelService struct {
+	api_server.ServiceBase
+	Levels log_level.LevelController
+
+	LevelsResource api.Resource
+}
+
+func NewLogLevelService(levelController log_level.LevelController) *LogLevelService {
+
+	s := &LogLevelService{}
+	s.ErrorsExtenderBase.Init(log_level.ErrorDescriptions, log_level.ErrorHttpCodes)
+	s.Levels = levelController
+
+	s.Init(log_level_api.ServiceName)
+	s.LevelsResource = api.NewResource(log_level_api.LevelsResource)
+	s.AddChild(s.LevelsResource)
+
+	s.LevelsResource.AddOperations(Levels(s), SetLevel(s))
+
+	return s
+}
@@ -0,0 +1,45 @@
+package log_level_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level/log_level_api"
+)
+
+type SetLevelEndpoint struct {
+	LogLevelEndpoint
+}
+
+func (e *SetLevelEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("logger.SetLevel")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &log_level.LogLevelCmd{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return err
+	}
+
+	// set level
+	resp := &log_level_api.LogLevelsResponse{}
+	resp.LogLevels, err = e.service.Levels.SetLevel(request, cmd)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func SetLevel(s *LogLevelService) *SetLevelEndpoint {
+	e := &SetLevelEndpoint{}
+	e.Construct(s, log_level_api.SetLevel())
+	return e
+}
@@ -0,0 +1,40 @@
+package log_level_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+type LogLevelCommands struct {
+	console_tool.Commands[*LogLevelCommands]
+	GetLevelController func() log_level.LevelController
+}
+
+// Create commands for log levels. Level controller is usually a client of log level service of running application.
+func NewLogLevelCommands(levelController func() log_level.LevelController) *LogLevelCommands {
+	p := &LogLevelCommands{}
+	p.Construct(p, "logger", "Manage log levels of running application")
+	p.GetLevelController = levelController
+	p.LoadHandlers()
+	return p
+}
+
+func (p *LogLevelCommands) LoadHandlers() {
+	p.AddHandlers(ShowLevels,
+		SetLevel)
+}
+
+type Handler = console_tool.Handler[*LogLevelCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*LogLevelCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, log_level.LevelController, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, b.Group.GetLevelController(), nil
+}
@@ -0,0 +1,40 @@
+package log_level_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const SetLevelCmd string = "set_level"
+const SetLevelDescription string = "Set global log level or log level of component"
+
+func SetLevel() Handler {
+	a := &SetLevelHandler{}
+	a.Init(SetLevelCmd, SetLevelDescription)
+	return a
+}
+
+type SetLevelHandler struct {
+	HandlerBase
+	log_level.LogLevelCmd
+}
+
+func (a *SetLevelHandler) Data() interface{} {
+	return &a.LogLevelCmd
+}
+
+func (a *SetLevelHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	levels, err := controller.SetLevel(ctx, &a.LogLevelCmd)
+	if err == nil {
+		fmt.Printf("Log levels:\n\n%s\n\n", utils.DumpPrettyJson(levels))
+	}
+	return err
+}
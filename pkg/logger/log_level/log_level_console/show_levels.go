@@ -0,0 +1,40 @@
+package log_level_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ShowLevelsCmd string = "levels"
+const ShowLevelsDescription string = "Show log levels"
+
+func ShowLevels() Handler {
+	a := &ShowLevelsHandler{}
+	a.Init(ShowLevelsCmd, ShowLevelsDescription)
+	return a
+}
+
+type ShowLevelsHandler struct {
+	HandlerBase
+	console_tool.Dummy
+}
+
+func (a *ShowLevelsHandler) Data() interface{} {
+	return &a.Dummy
+}
+
+func (a *ShowLevelsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+	levels, err := controller.Levels(ctx)
+	if err == nil {
+		fmt.Printf("Log levels:\n\n%s\n\n", utils.DumpPrettyJson(levels))
+	}
+	return err
+}
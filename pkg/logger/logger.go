@@ -51,6 +51,29 @@ type Logger interface {
 	CheckFatalStack(logger Logger, message ...string) bool
 }
 
+// Field of log entry with name of application component, e.g. db, auth or sms.
+// Levels of logger can be overridden per component, see WithRuntimeLevels.
+const ComponentField = "component"
+
+// Logger whose levels can be changed at runtime.
+type WithRuntimeLevels interface {
+	// Get global level and levels overridden per component.
+	Levels() (level string, components map[string]string)
+	// Set global level or level of component if component is set. Empty level of component removes override.
+	SetLevel(level string, component ...string) error
+}
+
+// Logger that can check if entries of level are written, e.g. to skip expensive preparation of entries that are filtered out.
+type WithLevelCheck interface {
+	// Check if entries of level are written, fields are used to find level of component.
+	LevelEnabled(level Level, fields ...Fields) bool
+}
+
+// Logger that wraps other logger, e.g. proxy or redacting logger.
+type WithNextLogger interface {
+	NextLogger() Logger
+}
+
 type WithLogger interface {
 	Logger() Logger
 	SetLogger(logger Logger)
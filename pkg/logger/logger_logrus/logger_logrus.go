@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
@@ -17,12 +21,61 @@ type logrusConfig struct {
 	DESTINATION string `default:"stdout" validate:"oneof=stdout file" vmessage:"logger destination must be one of: stdout | file"`
 	FILE        string
 	LEVEL       string `validate:"omitempty,oneof=panic fatal error warn info debug trace" vmessage:"invalid log level, must be one of: panic | fatal | error | warn | info | debug | trace"`
+	FORMAT      string `default:"text" validate:"oneof=text json logfmt" vmessage:"logger format must be one of: text | json | logfmt"`
+
+	// Rotation of log file by size and/or by time, rotation is disabled if both are zero.
+	ROTATE_SIZE_MB          int `validate:"gte=0"`
+	ROTATE_INTERVAL_SECONDS int `validate:"gte=0"`
+	// Retention of rotated log files, zero means no limit.
+	MAX_BACKUPS  int `validate:"gte=0"`
+	MAX_AGE_DAYS int `validate:"gte=0"`
+
+	// Field of log entry with component name used to select level overridden in components section of logger configuration.
+	COMPONENT_FIELD string `default:"component"`
+
+	// Sampling of repetitive messages is disabled if period is zero.
+	// In each period the first SAMPLING_FIRST entries with the same level and message are logged, then every SAMPLING_THEREAFTER-th entry.
+	SAMPLING_PERIOD_SECONDS int `validate:"gte=0"`
+	SAMPLING_FIRST          int `default:"100" validate:"gte=0"`
+	SAMPLING_THEREAFTER     int `default:"100" validate:"gte=0"`
+}
+
+func (c *logrusConfig) sameOutput(other *logrusConfig) bool {
+	return c.DESTINATION == other.DESTINATION &&
+		c.FILE == other.FILE &&
+		c.ROTATE_SIZE_MB == other.ROTATE_SIZE_MB &&
+		c.ROTATE_INTERVAL_SECONDS == other.ROTATE_INTERVAL_SECONDS &&
+		c.MAX_BACKUPS == other.MAX_BACKUPS &&
+		c.MAX_AGE_DAYS == other.MAX_AGE_DAYS
+}
+
+// Levels of logger, global level is overridden by levels of components.
+type levels struct {
+	level      logrus.Level
+	components map[string]logrus.Level
+	field      string
+}
+
+func (v *levels) maxLevel() logrus.Level {
+	max := v.level
+	for _, level := range v.components {
+		if level > max {
+			max = level
+		}
+	}
+	return max
 }
 
 type LogrusLogger struct {
 	logger.LoggerBase
 	logrusConfig
 	logRus *logrus.Logger
+
+	components map[string]string
+	writer     *utils.FileWriteReopen
+	levelsLock sync.Mutex
+	levels     atomic.Pointer[levels]
+	sampler    atomic.Pointer[sampler]
 }
 
 func (l *LogrusLogger) Config() interface{} {
@@ -33,23 +86,71 @@ func New() *LogrusLogger {
 	l := &LogrusLogger{}
 	l.logRus = logrus.New()
 	l.LoggerBase.Init()
+	l.levels.Store(&levels{level: l.logRus.GetLevel()})
 	return l
 }
 
+// Check if level is enabled taking into account level of component.
+func (l *LogrusLogger) levelEnabled(level logrus.Level, fields logger.Fields) bool {
+	v := l.levels.Load()
+	max := v.level
+	if len(v.components) != 0 {
+		if component, ok := fields[v.field]; ok {
+			if componentLevel, ok := v.components[fmt.Sprintf("%v", component)]; ok {
+				max = componentLevel
+			}
+		}
+	}
+	return level <= max
+}
+
+func (l *LogrusLogger) LevelEnabled(level logger.Level, fields ...logger.Fields) bool {
+	return l.levelEnabled(logrus.Level(int(level)), utils.OptionalArg(nil, fields...))
+}
+
+// Check if entry must be logged taking into account level of component and sampling.
+func (l *LogrusLogger) enabled(level logrus.Level, message string, fields logger.Fields) bool {
+
+	if !l.levelEnabled(level, fields) {
+		return false
+	}
+
+	s := l.sampler.Load()
+	if s != nil && level > logrus.FatalLevel {
+		return s.allow(level, message)
+	}
+	return true
+}
+
 func (l *LogrusLogger) ErrorRaw(data ...interface{}) {
-	l.logRus.Error(data)
+	if !l.enabled(logrus.ErrorLevel, "", nil) {
+		return
+	}
+	l.logRus.Error(data...)
 }
 
 func (l *LogrusLogger) Log(level logger.Level, message string, fields ...logger.Fields) {
-	l.logRus.WithFields(logger.NewFields(fields...)).Log(logrus.Level(int(level)), message)
+	f := logger.NewFields(fields...)
+	if !l.enabled(logrus.Level(int(level)), message, f) {
+		return
+	}
+	l.logRus.WithFields(f).Log(logrus.Level(int(level)), message)
 }
 
 func (l *LogrusLogger) Debug(message string, fields ...logger.Fields) {
-	l.logRus.WithFields(logger.NewFields(fields...)).Debug(message)
+	f := logger.NewFields(fields...)
+	if !l.enabled(logrus.DebugLevel, message, f) {
+		return
+	}
+	l.logRus.WithFields(f).Debug(message)
 }
 
 func (l *LogrusLogger) Trace(message string, fields ...logger.Fields) {
-	l.logRus.WithFields(logger.NewFields(fields...)).Trace(message)
+	f := logger.NewFields(fields...)
+	if !l.enabled(logrus.TraceLevel, message, f) {
+		return
+	}
+	l.logRus.WithFields(f).Trace(message)
 }
 
 func (l *LogrusLogger) Error(message string, err error, fields ...logger.Fields) error {
@@ -62,6 +163,9 @@ func (l *LogrusLogger) Error(message string, err error, fields ...logger.Fields)
 		}
 	}
 	f := logger.AppendFieldsNew(logger.Fields{"error": e}, fields...)
+	if !l.enabled(logrus.ErrorLevel, message, f) {
+		return e
+	}
 	if message != "" && err != nil {
 		l.logRus.WithFields(f).Error(message)
 	} else {
@@ -71,11 +175,19 @@ func (l *LogrusLogger) Error(message string, err error, fields ...logger.Fields)
 }
 
 func (l *LogrusLogger) Warn(message string, fields ...logger.Fields) {
-	l.logRus.WithFields(logger.NewFields(fields...)).Warn(message)
+	f := logger.NewFields(fields...)
+	if !l.enabled(logrus.WarnLevel, message, f) {
+		return
+	}
+	l.logRus.WithFields(f).Warn(message)
 }
 
 func (l *LogrusLogger) Info(message string, fields ...logger.Fields) {
-	l.logRus.WithFields(logger.NewFields(fields...)).Info(message)
+	f := logger.NewFields(fields...)
+	if !l.enabled(logrus.InfoLevel, message, f) {
+		return
+	}
+	l.logRus.WithFields(f).Info(message)
 }
 
 func (l *LogrusLogger) Fatal(message string, err error, fields ...logger.Fields) error {
@@ -90,6 +202,9 @@ func (l *LogrusLogger) Fatal(message string, err error, fields ...logger.Fields)
 		e = errors.New("unknown error")
 		f["error"] = e
 	}
+	if !l.enabled(logrus.FatalLevel, message, f) {
+		return e
+	}
 	if message != "" {
 		l.logRus.WithFields(f).Log(logrus.FatalLevel, message)
 	} else {
@@ -119,41 +234,213 @@ func (l *LogrusLogger) Fatal(message string, err error, fields ...logger.Fields)
 func (l *LogrusLogger) Init(cfg config.Config, vld validator.Validator, configPath ...string) error {
 
 	// load configuration
-	err := object_config.LoadValidate(cfg, vld, l, "logger", configPath...)
+	path := utils.OptionalArg("logger", configPath...)
+	err := object_config.LoadValidate(cfg, vld, l, path)
 	if err != nil {
 		return err
 	}
+	components, err := loadComponents(cfg, l, path)
+	if err != nil {
+		return err
+	}
+	l.components = components
+
+	// setup logger
+	l.setupFormatter()
+	err = l.setupOutput()
+	l.setupLevels()
+	l.setupSampler()
+
+	// reload logger when configuration is reloaded
+	config.SubscribeReload(cfg, fmt.Sprintf("%s/%p", path, l), func(newCfg config.Config) (func(), error) {
+		reloaded := &reloadedConfig{}
+		err := object_config.LoadLogValidate(newCfg, l, vld, reloaded, path)
+		if err != nil {
+			return nil, err
+		}
+		components, err := loadComponents(newCfg, l, path)
+		if err != nil {
+			return nil, err
+		}
+		apply := func() {
+			outputChanged := !reloaded.sameOutput(&l.logrusConfig)
+			// levels changed at runtime are kept unless levels are changed in configuration
+			levelsChanged := reloaded.LEVEL != l.LEVEL || reloaded.COMPONENT_FIELD != l.COMPONENT_FIELD || !reflect.DeepEqual(components, l.components)
+			l.logrusConfig = reloaded.logrusConfig
+			l.components = components
+			l.setupFormatter()
+			if outputChanged {
+				l.setupOutput()
+			}
+			if levelsChanged {
+				l.setupLevels()
+			}
+			l.setupSampler()
+		}
+		return apply, nil
+	})
 
-	// setup output
+	// done
+	return err
+}
+
+type reloadedConfig struct {
+	logrusConfig
+}
+
+func (r *reloadedConfig) Config() interface{} {
+	return &r.logrusConfig
+}
+
+// Load levels of components from components section of logger configuration.
+func loadComponents(cfg config.Config, log logger.Logger, path string) (map[string]string, error) {
+	components, err := object_config.LoadLogStringMapString(cfg, log, object_config.Key(path, "components"))
+	if err != nil {
+		return nil, err
+	}
+	for component, level := range components {
+		_, err := logrus.ParseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of component %s: %s", component, err)
+		}
+	}
+	return components, nil
+}
+
+func (l *LogrusLogger) setupFormatter() {
+	var formatter logrus.Formatter
+	switch l.FORMAT {
+	case "json":
+		formatter = &logrus.JSONFormatter{}
+	case "logfmt":
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	default:
+		formatter = &logrus.TextFormatter{}
+	}
+	l.logRus.SetFormatter(formatter)
+	logrus.SetFormatter(formatter)
+}
+
+func (l *LogrusLogger) setupOutput() error {
+	var err error
+	prevWriter := l.writer
 	if l.DESTINATION == "file" {
-		writer := &utils.FileWriteReopen{Path: l.FILE}
+		writer := &utils.FileWriteReopen{
+			Path:       l.FILE,
+			MaxSize:    int64(l.ROTATE_SIZE_MB) * 1024 * 1024,
+			Interval:   time.Duration(l.ROTATE_INTERVAL_SECONDS) * time.Second,
+			MaxBackups: l.MAX_BACKUPS,
+			MaxAge:     time.Duration(l.MAX_AGE_DAYS) * 24 * time.Hour,
+		}
 		writer.File, err = os.OpenFile(l.FILE, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 		if err == nil {
-			l.logRus.Out = writer
+			l.writer = writer
+			l.logRus.SetOutput(writer)
 			logrus.SetOutput(writer)
 			fmt.Printf("Using log file %v\n", l.FILE)
 		} else {
 			fmt.Println("failed to log to file, using default console")
 		}
 	} else {
-		l.logRus.Out = os.Stdout
+		l.writer = nil
+		l.logRus.SetOutput(os.Stdout)
 		logrus.SetOutput(os.Stdout)
 	}
+	if prevWriter != nil && prevWriter != l.writer {
+		prevWriter.Close()
+	}
+	return err
+}
+
+func (l *LogrusLogger) setupLevels() {
 
-	// setup log level
+	v := &levels{level: logrus.InfoLevel, field: l.COMPONENT_FIELD}
 	if l.LEVEL != "" {
 		logLevel, err := logrus.ParseLevel(l.LEVEL)
 		if err != nil {
 			fmt.Printf("Invalid log level %v\n", err.Error())
 		} else {
 			fmt.Printf("Using log level %v\n", logLevel)
-			l.logRus.SetLevel(logLevel)
-			logrus.SetLevel(logLevel)
+			v.level = logLevel
+		}
+	}
+	if len(l.components) != 0 {
+		v.components = make(map[string]logrus.Level)
+		for component, level := range l.components {
+			// levels of components are validated when loaded
+			v.components[component], _ = logrus.ParseLevel(level)
 		}
 	}
 
-	// done
-	return err
+	l.levelsLock.Lock()
+	l.storeLevels(v)
+	l.levelsLock.Unlock()
+}
+
+func (l *LogrusLogger) storeLevels(v *levels) {
+	l.levels.Store(v)
+	// logrus filters entries before they reach this logger, so its level must be the most verbose one
+	l.logRus.SetLevel(v.maxLevel())
+	logrus.SetLevel(v.level)
+}
+
+func (l *LogrusLogger) setupSampler() {
+	if l.SAMPLING_PERIOD_SECONDS == 0 {
+		l.sampler.Store(nil)
+		return
+	}
+	l.sampler.Store(newSampler(time.Duration(l.SAMPLING_PERIOD_SECONDS)*time.Second, l.SAMPLING_FIRST, l.SAMPLING_THEREAFTER))
+}
+
+// Get name of level as it is used in configuration.
+func levelName(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "warn"
+	}
+	return level.String()
+}
+
+func (l *LogrusLogger) Levels() (string, map[string]string) {
+	v := l.levels.Load()
+	components := make(map[string]string)
+	for component, level := range v.components {
+		components[component] = levelName(level)
+	}
+	return levelName(v.level), components
+}
+
+func (l *LogrusLogger) SetLevel(level string, component ...string) error {
+
+	name := utils.OptionalArg("", component...)
+
+	var logLevel logrus.Level
+	var err error
+	if level != "" || name == "" {
+		logLevel, err = logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+	}
+
+	// copy current levels and update them
+	l.levelsLock.Lock()
+	defer l.levelsLock.Unlock()
+	current := l.levels.Load()
+	v := &levels{level: current.level, field: current.field}
+	v.components = make(map[string]logrus.Level)
+	for c, cl := range current.components {
+		v.components[c] = cl
+	}
+	if name == "" {
+		v.level = logLevel
+	} else if level == "" {
+		delete(v.components, name)
+	} else {
+		v.components[name] = logLevel
+	}
+
+	l.storeLevels(v)
+	return nil
 }
 
 func (l *LogrusLogger) Native() interface{} {
@@ -162,6 +449,9 @@ func (l *LogrusLogger) Native() interface{} {
 
 func (l *LogrusLogger) ErrorNative(err error, fields ...logger.Fields) {
 	f := logger.AppendFieldsNew(logger.Fields{"error": err}, fields...)
+	if !l.enabled(logrus.ErrorLevel, "", f) {
+		return
+	}
 	l.logRus.WithFields(f).Error()
 }
 
@@ -0,0 +1,53 @@
+package logger_logrus
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type samplerKey struct {
+	level   logrus.Level
+	message string
+}
+
+// Sampler of repetitive log entries.
+// In each period the first entries with the same level and message are logged, then only every n-th of them is logged.
+type sampler struct {
+	period     time.Duration
+	first      int
+	thereafter int
+
+	mutex    sync.Mutex
+	counters map[samplerKey]int
+	resetAt  time.Time
+}
+
+func newSampler(period time.Duration, first int, thereafter int) *sampler {
+	s := &sampler{period: period, first: first, thereafter: thereafter}
+	s.counters = make(map[samplerKey]int)
+	return s
+}
+
+func (s *sampler) allow(level logrus.Level, message string) bool {
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// counters are reset every period
+	now := time.Now()
+	if !now.Before(s.resetAt) {
+		s.counters = make(map[samplerKey]int)
+		s.resetAt = now.Add(s.period)
+	}
+
+	key := samplerKey{level, message}
+	count := s.counters[key] + 1
+	s.counters[key] = count
+
+	if count <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (count-s.first)%s.thereafter == 0
+}
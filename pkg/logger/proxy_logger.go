@@ -13,6 +13,11 @@ func NewProxy(logger Logger, fields ...Fields) *ProxyLogger {
 	return &ProxyLogger{logger, utils.OptionalArg(Fields{}, fields...)}
 }
 
+// Create logger that adds component field to all entries.
+func NewComponentLogger(logger Logger, component string) *ProxyLogger {
+	return NewProxy(logger, Fields{ComponentField: component})
+}
+
 func (p *ProxyLogger) Reset() {
 	p.staticFields = Fields{}
 }
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+type WithRedactor interface {
+	Redactor() *Redactor
+}
+
+// Logger that redacts sensitive values in messages and fields before passing them to next logger.
+// Entries of levels that are disabled in next logger are not redacted.
+type RedactingLogger struct {
+	logger   Logger
+	redactor atomic.Pointer[Redactor]
+}
+
+func NewRedactingLogger(logger Logger, redactor *Redactor) *RedactingLogger {
+	l := &RedactingLogger{logger: logger}
+	l.SetRedactor(redactor)
+	return l
+}
+
+func (r *RedactingLogger) Redactor() *Redactor {
+	return r.redactor.Load()
+}
+
+// Replace redactor, e.g. when configuration of redaction is reloaded.
+func (r *RedactingLogger) SetRedactor(redactor *Redactor) {
+	r.redactor.Store(redactor)
+}
+
+func (r *RedactingLogger) NextLogger() Logger {
+	return r.logger
+}
+
+func (r *RedactingLogger) LevelEnabled(level Level, fields ...Fields) bool {
+	checker, ok := r.logger.(WithLevelCheck)
+	return !ok || checker.LevelEnabled(level, fields...)
+}
+
+func (r *RedactingLogger) fields(fields ...Fields) []Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+	return []Fields{r.Redactor().RedactFields(fields[0])}
+}
+
+func (r *RedactingLogger) ErrorRaw(data ...interface{}) {
+	if !r.LevelEnabled(ErrorLevel) {
+		return
+	}
+	redactor := r.Redactor()
+	redacted := make([]interface{}, len(data))
+	for i, value := range data {
+		redacted[i] = redactor.RedactValue("", value)
+	}
+	r.logger.ErrorRaw(redacted...)
+}
+
+func (r *RedactingLogger) Log(level Level, message string, fields ...Fields) {
+	if !r.LevelEnabled(level, fields...) {
+		return
+	}
+	r.logger.Log(level, r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Debug(message string, fields ...Fields) {
+	if !r.LevelEnabled(DebugLevel, fields...) {
+		return
+	}
+	r.logger.Debug(r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Trace(message string, fields ...Fields) {
+	if !r.LevelEnabled(TraceLevel, fields...) {
+		return
+	}
+	r.logger.Trace(r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Error(message string, err error, fields ...Fields) error {
+	redactor := r.Redactor()
+	if !r.LevelEnabled(ErrorLevel, fields...) {
+		return skippedError(redactor, message, err)
+	}
+	e := r.logger.Error(redactor.RedactString(message), redactor.RedactError(err), r.fields(fields...)...)
+	if err == nil {
+		return e
+	}
+	return err
+}
+
+// Error returned by Error or Fatal when entry is not written.
+func skippedError(redactor *Redactor, message string, err error) error {
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		return errors.New("unknown error")
+	}
+	return errors.New(redactor.RedactString(message))
+}
+
+func (r *RedactingLogger) ErrorNative(err error, fields ...Fields) {
+	if !r.LevelEnabled(ErrorLevel, fields...) {
+		return
+	}
+	r.logger.ErrorNative(r.Redactor().RedactError(err), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) ErrorMessage(message string, fields ...Fields) {
+	if !r.LevelEnabled(ErrorLevel, fields...) {
+		return
+	}
+	r.logger.ErrorMessage(r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Warn(message string, fields ...Fields) {
+	if !r.LevelEnabled(WarnLevel, fields...) {
+		return
+	}
+	r.logger.Warn(r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Info(message string, fields ...Fields) {
+	if !r.LevelEnabled(InfoLevel, fields...) {
+		return
+	}
+	r.logger.Info(r.Redactor().RedactString(message), r.fields(fields...)...)
+}
+
+func (r *RedactingLogger) Fatal(message string, err error, fields ...Fields) error {
+	redactor := r.Redactor()
+	if !r.LevelEnabled(FatalLevel, fields...) {
+		return skippedError(redactor, message, err)
+	}
+	e := r.logger.Fatal(redactor.RedactString(message), redactor.RedactError(err), r.fields(fields...)...)
+	if err == nil {
+		return e
+	}
+	return err
+}
+
+func (r *RedactingLogger) Native() interface{} {
+	return r.logger.Native()
+}
+
+func (r *RedactingLogger) PushFatalStack(message string, err error, fields ...Fields) error {
+	redactor := r.Redactor()
+	e := r.logger.PushFatalStack(redactor.RedactString(message), redactor.RedactError(err), r.fields(fields...)...)
+	if err == nil {
+		return e
+	}
+	return err
+}
+
+func (r *RedactingLogger) CheckFatalStack(logger Logger, message ...string) bool {
+	return r.logger.CheckFatalStack(logger, message...)
+}
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const (
+	// Value is replaced with HiddenValue.
+	MaskHide string = "hide"
+	// Value is masked with utils.MaskCard.
+	MaskCard string = "card"
+	// Value is masked with utils.MaskPhone.
+	MaskPhone string = "phone"
+)
+
+const HiddenValue = "********"
+
+func maskFunc(mask string) (func(string) string, error) {
+	switch mask {
+	case MaskHide, "":
+		return func(string) string { return HiddenValue }, nil
+	case MaskCard:
+		return utils.MaskCard, nil
+	case MaskPhone:
+		return utils.MaskPhone, nil
+	}
+	return nil, fmt.Errorf("unknown mask %s", mask)
+}
+
+// Rule of redaction. Either FIELD or PATTERN must be set.
+// FIELD is a name of log field, it matches field with the same name or with name containing it as a word separated by "_" or "-", case insensitive.
+// PATTERN is a regular expression applied to messages and string values. If pattern has a group named "value" then only that group is masked, otherwise the whole match is masked.
+type RedactionRuleConfig struct {
+	FIELD   string
+	PATTERN string
+	// Value is hidden if mask is not set.
+	MASK string `validate:"omitempty,oneof=hide card phone" vmessage:"redaction mask must be one of: hide | card | phone"`
+}
+
+type RedactionRule struct {
+	RedactionRuleConfig
+}
+
+func (r *RedactionRule) Config() interface{} {
+	return &r.RedactionRuleConfig
+}
+
+type fieldRule struct {
+	name string
+	mask func(string) string
+}
+
+type patternRule struct {
+	re    *regexp.Regexp
+	group int
+	check func(string) bool
+	mask  func(string) string
+}
+
+// Redactor of sensitive values in log messages, log fields and dumps of requests.
+type Redactor struct {
+	fields   []fieldRule
+	patterns []patternRule
+}
+
+// Create redactor without rules.
+func NewRedactor() *Redactor {
+	return &Redactor{}
+}
+
+var builtinFields = map[string]string{
+	"password":      MaskHide,
+	"passwd":        MaskHide,
+	"secret":        MaskHide,
+	"token":         MaskHide,
+	"csrf":          MaskHide,
+	"sms_code":      MaskHide,
+	"phash":         MaskHide,
+	"signature":     MaskHide,
+	"authorization": MaskHide,
+	"cookie":        MaskHide,
+	"phone":         MaskPhone,
+	"card":          MaskCard,
+	"pan":           MaskCard,
+}
+
+const builtinKeys = `[\w-]*(?:password|passwd|secret|token|csrf|sms[_-]code|phash|signature)[\w-]*`
+
+var builtinPatterns = []string{
+	// string values in JSON
+	`(?i)"` + builtinKeys + `"\s*:\s*"(?P<value>(?:[^"\\]|\\.)*)"`,
+	// key-value pairs in URL forms and HTTP headers
+	`(?i)\b` + builtinKeys + `\s*[:=]\s*(?P<value>[^"&,;\s]+)`,
+	// authorization and cookie headers
+	`(?im)^(?:authorization|cookie|set-cookie):\s*(?:(?:bearer|basic)\s+)?(?P<value>[^\r\n]+)`,
+}
+
+var cardPattern = `\b\d{13,19}\b`
+var phonePattern = `\+\d{10,15}\b`
+
+// Create redactor with builtin rules for passwords, secrets, tokens, CSRF tokens, SMS codes, card numbers and phones.
+func DefaultRedactor() *Redactor {
+	r := NewRedactor()
+	for name, mask := range builtinFields {
+		r.AddFieldRule(name, mask)
+	}
+	for _, pattern := range builtinPatterns {
+		r.AddPatternRule(pattern, MaskHide)
+	}
+	// only numbers with valid checksums are considered as cards
+	r.addPatternRule(cardPattern, MaskCard, utils.CheckLuhn)
+	r.AddPatternRule(phonePattern, MaskPhone)
+	return r
+}
+
+func normalizeFieldName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+func (r *Redactor) AddFieldRule(name string, mask string) error {
+	f, err := maskFunc(mask)
+	if err != nil {
+		return err
+	}
+	r.fields = append(r.fields, fieldRule{name: normalizeFieldName(name), mask: f})
+	return nil
+}
+
+func (r *Redactor) AddPatternRule(pattern string, mask string) error {
+	return r.addPatternRule(pattern, mask, nil)
+}
+
+func (r *Redactor) addPatternRule(pattern string, mask string, check func(string) bool) error {
+	f, err := maskFunc(mask)
+	if err != nil {
+		return err
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	group := re.SubexpIndex("value")
+	if group < 0 {
+		group = 0
+	}
+	r.patterns = append(r.patterns, patternRule{re: re, group: group, check: check, mask: f})
+	return nil
+}
+
+func (r *Redactor) AddRule(rule *RedactionRule) error {
+	if rule.FIELD == "" && rule.PATTERN == "" {
+		return errors.New("either field or pattern must be set in redaction rule")
+	}
+	if rule.FIELD != "" {
+		err := r.AddFieldRule(rule.FIELD, rule.MASK)
+		if err != nil {
+			return err
+		}
+	}
+	if rule.PATTERN != "" {
+		err := r.AddPatternRule(rule.PATTERN, rule.MASK)
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern %s: %s", rule.PATTERN, err)
+		}
+	}
+	return nil
+}
+
+func (r *Redactor) fieldMask(name string) func(string) string {
+	name = normalizeFieldName(name)
+	for _, rule := range r.fields {
+		if name == rule.name ||
+			strings.HasPrefix(name, rule.name+"_") ||
+			strings.HasSuffix(name, "_"+rule.name) ||
+			strings.Contains(name, "_"+rule.name+"_") {
+			return rule.mask
+		}
+	}
+	return nil
+}
+
+// Redact sensitive values in string using pattern rules.
+func (r *Redactor) RedactString(s string) string {
+	for _, rule := range r.patterns {
+		s = rule.redact(s)
+	}
+	return s
+}
+
+func (p *patternRule) redact(s string) string {
+	matches := p.re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*p.group], match[2*p.group+1]
+		if start < 0 || start < last {
+			continue
+		}
+		value := s[start:end]
+		if p.check != nil && !p.check(value) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(p.mask(value))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Redact value of field: value of field matching field rule is masked, pattern rules are applied to other string values.
+func (r *Redactor) RedactValue(name string, value interface{}) interface{} {
+	if value == nil {
+		return value
+	}
+	mask := r.fieldMask(name)
+	if mask != nil {
+		str, ok := value.(string)
+		if !ok {
+			str = fmt.Sprintf("%v", value)
+		}
+		return mask(str)
+	}
+	switch v := value.(type) {
+	case string:
+		return r.RedactString(v)
+	case error:
+		return r.RedactString(v.Error())
+	}
+	return value
+}
+
+// Redact fields, original fields are not modified.
+func (r *Redactor) RedactFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+	redacted := make(Fields, len(fields))
+	for name, value := range fields {
+		redacted[name] = r.RedactValue(name, value)
+	}
+	return redacted
+}
+
+// Redact error message. If message does not contain sensitive values then the same error is returned.
+func (r *Redactor) RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	redacted := r.RedactString(msg)
+	if redacted == msg {
+		return err
+	}
+	return errors.New(redacted)
+}
@@ -1,13 +1,42 @@
 package multitenancy
 
-import "github.com/evgeniums/go-backend-helpers/pkg/common"
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+)
 
 type TenancyMeta struct {
 	common.ObjectBase
+	WithTenancyScopeBase
+	WithIsolation
+}
+
+// Check if database with given tenancy metas can be used by tenancy.
+// Dedicated database can belong only to one tenancy, shared database can be used only by shared tenancies.
+func CheckTenancyMetas(metas []*TenancyMeta, tenancyId string, shared bool) bool {
+	for _, meta := range metas {
+		if meta.GetID() == tenancyId {
+			continue
+		}
+		if !shared || !meta.IsShared() {
+			return false
+		}
+	}
+	return true
 }
 
 func DbModels() []interface{} {
-	return []interface{}{&TenancyDb{}, &OpLogTenancy{}}
+	return []interface{}{&TenancyDb{}, &OpLogTenancy{}, &TenancyMove{}, &TenancyDomain{}, &TenancyBackup{}, &TenancySetting{}}
+}
+
+// Migrate models of multitenancy in main database.
+func MigrateDbModels(ctx logger.WithLogger, database db.DB) error {
+	err := database.AutoMigrate(ctx, DbModels())
+	if err != nil {
+		return err
+	}
+	return database.PartitionedMonthAutoMigrate(ctx, PartitionedDbModels())
 }
 
 func DbInternalModels() []interface{} {
@@ -18,6 +18,17 @@ const (
 	OpSetRole        string = "set_role"
 	OpSetCustomer    string = "set_customer"
 	OpChangePoolOrDb string = "change_pool_or_db"
+	OpSetMaintenance string = "set_maintenance"
+	OpMove           string = "move"
+	OpAddDomain      string = "add_domain"
+	OpDeleteDomain   string = "delete_domain"
+	OpBackup         string = "backup"
+	OpRestore        string = "restore"
+	OpUndelete       string = "undelete"
+	OpPurge          string = "purge"
+	OpSetSetting     string = "set_setting"
+	OpUnsetSetting   string = "unset_setting"
+	OpApplyTemplate  string = "apply_template"
 )
 
 const (
@@ -32,6 +43,29 @@ const (
 	ErrorCodeForeignDatabase               = "foreign_tenancy_database"
 	ErrorCodeNoDbserviceInPool             = "no_db_service_in_pool"
 	ErrorCodeCreateTenancyDatabaseFailed   = "create_tenancy_database_dailed"
+	ErrorCodeTenancyMaintenance            = "tenancy_maintenance"
+	ErrorCodeTenancyMoveInProgress         = "tenancy_move_in_progress"
+	ErrorCodeTenancyMoveSameDatabase       = "tenancy_move_same_database"
+	ErrorCodeTenancyMoveFailed             = "tenancy_move_failed"
+	ErrorCodeTenancyMoveVerificationFailed = "tenancy_move_verification_failed"
+	ErrorCodeTenancyConflictDomain         = "tenancy_conflict_domain"
+	ErrorCodeTenancyDomainNotFound         = "tenancy_domain_not_found"
+	ErrorCodeTenancyConflictId             = "tenancy_conflict_id"
+	ErrorCodeTenancyArchiveInvalid         = "tenancy_archive_invalid"
+	ErrorCodeTenancyArchiveNotFound        = "tenancy_archive_not_found"
+	ErrorCodeTenancyRestoreNotEmpty        = "tenancy_restore_not_empty"
+	ErrorCodeTenancyBackupNotFound         = "tenancy_backup_not_found"
+	ErrorCodeTenancyBackupFailed           = "tenancy_backup_failed"
+	ErrorCodeTenancyDeleted                = "tenancy_deleted"
+	ErrorCodeTenancyNotDeleted             = "tenancy_not_deleted"
+	ErrorCodeTenancyPurgeFailed            = "tenancy_purge_failed"
+	ErrorCodeTenancySettingUnknown         = "tenancy_setting_unknown"
+	ErrorCodeTenancySettingInvalid         = "tenancy_setting_invalid"
+	ErrorCodeTenancySettingNotFound        = "tenancy_setting_not_found"
+	ErrorCodeTenancyQuotaExceeded          = "tenancy_quota_exceeded"
+	ErrorCodeTenancyTemplateNotFound       = "tenancy_template_not_found"
+	ErrorCodeTenancyTemplateInvalid        = "tenancy_template_invalid"
+	ErrorCodeTenancyTemplateFailed         = "tenancy_template_failed"
 )
 
 var ErrorDescriptions = map[string]string{
@@ -42,6 +76,29 @@ var ErrorDescriptions = map[string]string{
 	ErrorCodeForeignDatabase:               "Database does not belong to this tenancy.",
 	ErrorCodeNoDbserviceInPool:             "Pool does not contain service for tenancy database.",
 	ErrorCodeCreateTenancyDatabaseFailed:   "Failed to create tenancy database.",
+	ErrorCodeTenancyMaintenance:            "Tenancy is in maintenance mode, only read operations are allowed.",
+	ErrorCodeTenancyMoveInProgress:         "Tenancy is being moved to other database.",
+	ErrorCodeTenancyMoveSameDatabase:       "Tenancy already uses that database.",
+	ErrorCodeTenancyMoveFailed:             "Failed to move tenancy data to new database.",
+	ErrorCodeTenancyMoveVerificationFailed: "Data copied to new database does not match original data.",
+	ErrorCodeTenancyConflictDomain:         "Domain is already bound to tenancy.",
+	ErrorCodeTenancyDomainNotFound:         "Domain is not bound to this tenancy.",
+	ErrorCodeTenancyConflictId:             "Tenancy with such ID already exists.",
+	ErrorCodeTenancyArchiveInvalid:         "Invalid tenancy archive.",
+	ErrorCodeTenancyArchiveNotFound:        "Tenancy archive not found.",
+	ErrorCodeTenancyRestoreNotEmpty:        "Tenancy must not contain data to restore archive into it.",
+	ErrorCodeTenancyBackupNotFound:         "Tenancy backup job not found.",
+	ErrorCodeTenancyBackupFailed:           "Failed to backup or restore tenancy.",
+	ErrorCodeTenancyDeleted:                "Tenancy is deleted.",
+	ErrorCodeTenancyNotDeleted:             "Tenancy is not deleted.",
+	ErrorCodeTenancyPurgeFailed:            "Failed to purge deleted tenancy.",
+	ErrorCodeTenancySettingUnknown:         "Configuration key can not be overridden in tenancy.",
+	ErrorCodeTenancySettingInvalid:         "Invalid value of tenancy setting.",
+	ErrorCodeTenancySettingNotFound:        "Setting is not overridden in tenancy.",
+	ErrorCodeTenancyQuotaExceeded:          "Usage quota of tenancy exceeded.",
+	ErrorCodeTenancyTemplateNotFound:       "Tenancy template not found.",
+	ErrorCodeTenancyTemplateInvalid:        "Invalid tenancy template.",
+	ErrorCodeTenancyTemplateFailed:         "Failed to apply tenancy template.",
 }
 
 var ErrorHttpCodes = map[string]int{
@@ -50,6 +107,29 @@ var ErrorHttpCodes = map[string]int{
 	ErrorCodeTenancyDbInitializationFailed: http.StatusInternalServerError,
 	ErrorCodeForeignDatabase:               http.StatusInternalServerError,
 	ErrorCodeCreateTenancyDatabaseFailed:   http.StatusInternalServerError,
+	ErrorCodeTenancyMaintenance:            http.StatusServiceUnavailable,
+	ErrorCodeTenancyMoveInProgress:         http.StatusConflict,
+	ErrorCodeTenancyMoveSameDatabase:       http.StatusBadRequest,
+	ErrorCodeTenancyMoveFailed:             http.StatusInternalServerError,
+	ErrorCodeTenancyMoveVerificationFailed: http.StatusInternalServerError,
+	ErrorCodeTenancyConflictDomain:         http.StatusConflict,
+	ErrorCodeTenancyDomainNotFound:         http.StatusNotFound,
+	ErrorCodeTenancyConflictId:             http.StatusConflict,
+	ErrorCodeTenancyArchiveInvalid:         http.StatusBadRequest,
+	ErrorCodeTenancyArchiveNotFound:        http.StatusNotFound,
+	ErrorCodeTenancyRestoreNotEmpty:        http.StatusConflict,
+	ErrorCodeTenancyBackupNotFound:         http.StatusNotFound,
+	ErrorCodeTenancyBackupFailed:           http.StatusInternalServerError,
+	ErrorCodeTenancyDeleted:                http.StatusConflict,
+	ErrorCodeTenancyNotDeleted:             http.StatusBadRequest,
+	ErrorCodeTenancyPurgeFailed:            http.StatusInternalServerError,
+	ErrorCodeTenancySettingUnknown:         http.StatusBadRequest,
+	ErrorCodeTenancySettingInvalid:         http.StatusBadRequest,
+	ErrorCodeTenancySettingNotFound:        http.StatusNotFound,
+	ErrorCodeTenancyQuotaExceeded:          http.StatusTooManyRequests,
+	ErrorCodeTenancyTemplateNotFound:       http.StatusNotFound,
+	ErrorCodeTenancyTemplateInvalid:        http.StatusBadRequest,
+	ErrorCodeTenancyTemplateFailed:         http.StatusInternalServerError,
 }
 
 type Multitenancy interface {
@@ -57,20 +137,33 @@ type Multitenancy interface {
 	// Check if multiple tenancies are enabled
 	IsMultiTenancy() bool
 
-	// Get all tenancies
+	// Get loaded tenancies
 	Tenancies() []Tenancy
 
+	// Get IDs of all tenancies including tenancies that are not loaded yet
+	TenancyIds() []string
+
 	// Find tenancy by ID.
 	Tenancy(id string) (Tenancy, error)
 
 	// Find tenancy by path.
 	TenancyByPath(path string) (Tenancy, error)
 
+	// Find tenancy by domain name bound to tenancy.
+	TenancyByDomain(domain string) (Tenancy, error)
+
 	// Load tenancy.
 	LoadTenancy(ctx op_context.Context, id string) (Tenancy, error)
 
-	// Unload tenancy.
-	UnloadTenancy(id string)
+	// Unload tenancy, returns true if tenancy was loaded.
+	UnloadTenancy(id string) bool
+
+	// Acquire tenancy for use, e.g. while request is processed. If tenancy is unloaded while acquired then its database is closed only after tenancy is released.
+	// Returns false if tenancy was already unloaded and its database closed, in that case tenancy must be found again.
+	AcquireTenancy(tenancy Tenancy) bool
+
+	// Release tenancy acquired with AcquireTenancy().
+	ReleaseTenancy(tenancy Tenancy)
 
 	// Create tenancy
 	CreateTenancy(ctx op_context.Context, data *TenancyData) (*TenancyItem, error)
@@ -78,6 +171,12 @@ type Multitenancy interface {
 	// Get tenancy controller.
 	TenancyController() TenancyController
 
+	// Get metering of tenancies usage, nil if metering is disabled.
+	Metering() Metering
+
+	// Get schema of configuration keys that can be overridden in tenancies.
+	SettingsSchema() *TenancySettingsSchema
+
 	// Close tenancies, e.g. close tenancy databases.
 	Close()
 }
@@ -97,6 +196,20 @@ func NewPubsubNotification() *PubsubNotification {
 	return &PubsubNotification{}
 }
 
+// Statistics of tenancies in tenancy manager.
+type TenancyStats struct {
+	// Number of known tenancies.
+	Known int `json:"known"`
+	// Number of tenancies currently loaded.
+	Loaded int `json:"loaded"`
+	// Number of loads of tenancies.
+	Loads int64 `json:"loads"`
+	// Number of lookups of tenancies that were already loaded.
+	Hits int64 `json:"hits"`
+	// Number of tenancies unloaded on eviction.
+	Evictions int64 `json:"evictions"`
+}
+
 type TenancyController interface {
 	generic_error.ErrorsExtender
 	Add(ctx op_context.Context, tenancy *TenancyData) (*TenancyItem, error)
@@ -104,7 +217,13 @@ type TenancyController interface {
 	List(ctx op_context.Context, filter *db.Filter) ([]*TenancyItem, int64, error)
 
 	Exists(ctx op_context.Context, fields db.Fields) (bool, error)
+
+	// Mark tenancy as deleted, deleted tenancy is purged after grace period. Database is dropped on purge if withDb is set.
 	Delete(ctx op_context.Context, id string, withDb bool, idIsDisplay ...bool) error
+	// Undelete tenancy that is not purged yet, tenancy stays inactive after undeletion.
+	Undelete(ctx op_context.Context, id string, idIsDisplay ...bool) error
+	// Purge deleted tenancy immediately without waiting for grace period.
+	Purge(ctx op_context.Context, id string, idIsDisplay ...bool) error
 
 	SetPath(ctx op_context.Context, id string, path string, idIsDisplay ...bool) error
 	SetCustomer(ctx op_context.Context, id string, customerId string, idIsDisplay ...bool) error
@@ -112,4 +231,40 @@ type TenancyController interface {
 	ChangePoolOrDb(ctx op_context.Context, id string, poolId string, dbName string, idIsDisplay ...bool) error
 	Activate(ctx op_context.Context, id string, idIsDisplay ...bool) error
 	Deactivate(ctx op_context.Context, id string, idIsDisplay ...bool) error
+
+	// Move tenancy data to other pool and/or database in background. Unfinished move is resumed.
+	// Move is returned right after it is started, use FindMove to check its progress.
+	Move(ctx op_context.Context, id string, poolId string, dbName string, idIsDisplay ...bool) (*TenancyMove, error)
+	// Find the last move of tenancy, nil is returned if tenancy was never moved.
+	FindMove(ctx op_context.Context, id string, idIsDisplay ...bool) (*TenancyMove, error)
+
+	// Bind domain name to tenancy, domain must be unique among all tenancies.
+	AddDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error
+	// Unbind domain name from tenancy.
+	DeleteDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error
+	// List domain names bound to tenancy.
+	ListDomains(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*TenancyDomain, error)
+
+	// Override configuration key in tenancy, the key must be registered in settings schema.
+	SetSetting(ctx op_context.Context, id string, key string, value string, idIsDisplay ...bool) error
+	// Remove override of configuration key from tenancy.
+	UnsetSetting(ctx op_context.Context, id string, key string, idIsDisplay ...bool) error
+	// List configuration keys overridden in tenancy.
+	ListSettings(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*TenancySetting, error)
+
+	// Apply template to existing tenancy, if template is empty then template of tenancy is re-applied.
+	ApplyTemplate(ctx op_context.Context, id string, template string, idIsDisplay ...bool) (*TenancyItem, error)
+
+	// List usage of tenancies in months, filter can select tenancy_id, metric and month.
+	ListUsage(ctx op_context.Context, filter *db.Filter) ([]*TenancyUsage, int64, error)
+
+	// Get statistics of loading and eviction of tenancies in tenancy manager.
+	Stats(ctx op_context.Context) (*TenancyStats, error)
+
+	// Start background job exporting tenancy metadata and data to archive.
+	Backup(ctx op_context.Context, id string, idIsDisplay ...bool) (*TenancyBackup, error)
+	// Start background job importing tenancy archive into new or existing tenancy.
+	Restore(ctx op_context.Context, options *TenancyRestoreOptions) (*TenancyBackup, error)
+	// Find backup or restore job.
+	FindBackup(ctx op_context.Context, jobId string) (*TenancyBackup, error)
 }
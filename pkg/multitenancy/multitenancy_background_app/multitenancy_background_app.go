@@ -8,6 +8,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/app_context/app_default"
 	"github.com/evgeniums/go-backend-helpers/pkg/db/db_gorm"
+	"github.com/evgeniums/go-backend-helpers/pkg/leader_election"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/app_with_multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
@@ -20,7 +21,24 @@ type MainRunner interface {
 
 type BuildMainRunner = func(app app_with_multitenancy.AppWithMultitenancy, opCtx op_context.Context) (MainRunner, error)
 
+// Main runner with leader elector. Runner should start singleton background jobs with BackgroundWorker.RunOnlyWhenLeader(elector)
+// or subscribe to leadership changes with elector.OnChange().
+type BuildMainRunnerWithLeader = func(app app_with_multitenancy.AppWithMultitenancy, opCtx op_context.Context, elector *leader_election.Elector) (MainRunner, error)
+
 func Exec(buildConfig *app_context.BuildConfig, tenancyDbModels *multitenancy.TenancyDbModels, buildMainRunner BuildMainRunner, appConfig ...app_with_multitenancy.AppConfigI) {
+	buildRunner := func(app app_with_multitenancy.AppWithMultitenancy, opCtx op_context.Context, elector *leader_election.Elector) (MainRunner, error) {
+		return buildMainRunner(app, opCtx)
+	}
+	exec(buildConfig, tenancyDbModels, buildRunner, false, appConfig...)
+}
+
+// Exec application with leader election so that only one of replicated instances runs singleton background jobs.
+// Leader election is configured in "leader_election" section. Models from leader_election.DbModels() must be migrated in main database.
+func ExecWithLeader(buildConfig *app_context.BuildConfig, tenancyDbModels *multitenancy.TenancyDbModels, buildMainRunner BuildMainRunnerWithLeader, appConfig ...app_with_multitenancy.AppConfigI) {
+	exec(buildConfig, tenancyDbModels, buildMainRunner, true, appConfig...)
+}
+
+func exec(buildConfig *app_context.BuildConfig, tenancyDbModels *multitenancy.TenancyDbModels, buildMainRunner BuildMainRunnerWithLeader, withLeader bool, appConfig ...app_with_multitenancy.AppConfigI) {
 
 	appPath := app_default.Application()
 	configPath := fmt.Sprintf("%s.jsonc", appPath[:len(appPath)-len(filepath.Ext(appPath))])
@@ -42,8 +60,20 @@ func Exec(buildConfig *app_context.BuildConfig, tenancyDbModels *multitenancy.Te
 	}
 	defer app.Close()
 
+	// create leader elector
+	var elector *leader_election.Elector
+	if withLeader {
+		elector = leader_election.NewElector()
+		err = elector.Init(app, "leader_election")
+		if err != nil {
+			initOpCtx.Close()
+			app_context.AbortFatal(app, "failed to init leader elector", err)
+		}
+		elector.SetPubsub(app.Pubsub())
+	}
+
 	// create main runner
-	runner, err := buildMainRunner(app, initOpCtx)
+	runner, err := buildMainRunner(app, initOpCtx, elector)
 	initOpCtx.Close()
 	if err != nil {
 		app_context.AbortFatal(app, "failed to init main runner", err)
@@ -54,7 +84,14 @@ func Exec(buildConfig *app_context.BuildConfig, tenancyDbModels *multitenancy.Te
 	app.Logger().Info(startedMsg)
 	fmt.Println(startedMsg)
 	fin := finish.New()
+	if elector != nil {
+		elector.Run(nil)
+	}
 	runner.Run(fin)
+	if elector != nil {
+		// release leadership after main runner is stopped
+		fin.Add(elector)
+	}
 	fin.Add(app.Pubsub())
 
 	// wait for signals
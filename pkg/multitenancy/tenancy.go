@@ -3,9 +3,11 @@ package multitenancy
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/cache"
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
@@ -21,15 +23,23 @@ type Tenancy interface {
 	common.WithDescription
 
 	Path() string
+	IsMaintenance() bool
+	IsShared() bool
+	IsSchema() bool
+	Schema() string
 	CustomerId() string
 	CustomerDisplay() string
 	Role() string
 	DbName() string
 	PoolId() string
+	Domains() []string
 
 	Db() db.DB
 	Pool() pool.Pool
 	Cache() cache.Cache
+
+	// Configuration of tenancy: tenancy settings layered over application configuration.
+	Cfg() config.Config
 }
 
 type WithPath struct {
@@ -49,12 +59,54 @@ type WithPoolAndDb struct {
 	DBNAME  string `json:"dbname" gorm:"index;column:dbname" validate:"omitempty,alphanum_" vmessage:"Database name must be alhanumeric" long:"dbname" description:"Name of tenancy's database, if empty then will be generated automatically" display:"Database"`
 }
 
+const (
+	IsolationDatabase string = "database"
+	IsolationSchema   string = "schema"
+	IsolationShared   string = "shared"
+)
+
+type WithIsolation struct {
+	ISOLATION string `json:"isolation" gorm:"index" validate:"omitempty,oneof=database schema shared" vmessage:"Isolation must be one of database, schema or shared" long:"isolation" description:"Isolation mode of tenancy: database - tenancy has own database, schema - tenancy has own schema in shared database, shared - tenancy shares database tables with other tenancies"`
+	SCHEMA    string `json:"schema,omitempty" gorm:"index" validate:"omitempty,alphanum_" vmessage:"Schema name must be alhanumeric" long:"schema" description:"Name of tenancy's schema in schema isolation mode, if empty then will be generated automatically"`
+}
+
+func (t *WithIsolation) Isolation() string {
+	if t.ISOLATION == "" {
+		return IsolationDatabase
+	}
+	return t.ISOLATION
+}
+
+// Shared tenancy keeps data in database shared with other tenancies, all queries are scoped by tenancy ID.
+func (t *WithIsolation) IsShared() bool {
+	return t.ISOLATION == IsolationShared
+}
+
+// Schema tenancy keeps data in own schema of database shared with other tenancies.
+func (t *WithIsolation) IsSchema() bool {
+	return t.ISOLATION == IsolationSchema
+}
+
+func (t *WithIsolation) Schema() string {
+	return t.SCHEMA
+}
+
+type WithTemplate struct {
+	TEMPLATE string `json:"template,omitempty" gorm:"index" validate:"omitempty,alphanum_" vmessage:"Template name must be alphanumeric" long:"template" description:"Name of template with seed data applied to tenancy on creation"`
+}
+
+func (t *WithTemplate) Template() string {
+	return t.TEMPLATE
+}
+
 type TenancyData struct {
 	common.WithDescriptionBase
 	WithPath
 	WithRole
 	WithCustomerId
 	WithPoolAndDb
+	WithIsolation
+	WithTemplate
 }
 
 func (t *WithCustomerId) CustomerId() string {
@@ -94,10 +146,38 @@ func ParseTenancyDisplay(display string) (string, string, *validator.ValidationE
 	return parts[0], parts[1], nil
 }
 
+type WithMaintenance struct {
+	MAINTENANCE bool `json:"maintenance" gorm:"index"`
+}
+
+// Tenancy in maintenance mode allows only read operations, e.g. when tenancy data is being moved to other database.
+func (t *WithMaintenance) IsMaintenance() bool {
+	return t.MAINTENANCE
+}
+
+type WithDeletion struct {
+	DELETED_AT     time.Time `json:"deleted_at" gorm:"index"`
+	PURGE_DATABASE bool      `json:"purge_database"`
+}
+
+// Deleted tenancy is inaccessible and can be undeleted until it is purged after grace period.
+func (t *WithDeletion) IsDeleted() bool {
+	return !t.DELETED_AT.IsZero()
+}
+
+// Database of deleted tenancy must be dropped when tenancy is purged.
+func (t *WithDeletion) IsPurgeDatabase() bool {
+	return t.PURGE_DATABASE
+}
+
 type TenancyDb struct {
 	common.ObjectBase
 	common.WithActiveBase
+	WithMaintenance
+	WithDeletion
 	TenancyData
+	// Version of template applied to tenancy, zero if template was not applied.
+	TEMPLATE_VERSION int `json:"template_version"`
 }
 
 func (TenancyDb) TableName() string {
@@ -143,6 +223,7 @@ type WithTenancy interface {
 type TenancyContext interface {
 	op_context.Context
 	WithTenancy
+	config.WithCfg
 }
 
 func ContextTenancy(ctx TenancyContext) string {
@@ -161,9 +242,21 @@ func (u *TenancyContextBase) GetTenancy() Tenancy {
 	return u.Tenancy
 }
 
+// Get configuration of tenancy if context has tenancy, otherwise get configuration of application.
+func (u *TenancyContextBase) Cfg() config.Config {
+	if u.Tenancy != nil && u.Tenancy.Cfg() != nil {
+		return u.Tenancy.Cfg()
+	}
+	if u.App() == nil {
+		return nil
+	}
+	return u.App().Cfg()
+}
+
 func (u *TenancyContextBase) SetTenancy(tenancy Tenancy) {
 	u.Tenancy = tenancy
 	u.SetLoggerField("tenancy", TenancyDisplay(tenancy))
+	u.SetOplogTenancy(tenancy.GetID())
 	if tenancy.Cache() != nil {
 		u.SetCache(tenancy.Cache())
 	}
@@ -7,16 +7,39 @@ import (
 
 const ServiceName string = "tenancies"
 const TenancyResource string = "tenancy"
+const BackupResource string = "tenancy_backup"
 
 type TenancyResponse struct {
 	api.ResponseBase
 	*multitenancy.TenancyItem
 }
 
+type TenancyMoveResponse struct {
+	api.ResponseBase
+	*multitenancy.TenancyMove
+}
+
+type TenancyBackupResponse struct {
+	api.ResponseBase
+	*multitenancy.TenancyBackup
+}
+
+type TenancyStatsResponse struct {
+	api.ResponseBase
+	*multitenancy.TenancyStats
+}
+
 type ListTenanciesResponse = api.ResponseList[*multitenancy.TenancyItem]
 
+type ListTenancyDomainsResponse = api.ResponseList[*multitenancy.TenancyDomain]
+
+type ListTenancySettingsResponse = api.ResponseList[*multitenancy.TenancySetting]
+
+// Usage of tenancies. Storage of tenancies in shared databases is not measured, so there is no storage usage of such tenancies in the list.
+type ListTenancyUsageResponse = api.ResponseList[*multitenancy.TenancyUsage]
+
 type DeleteTenancyCmd struct {
-	WithDatabase bool `json:"with_database"`
+	WithDatabase bool `json:"with_database" url:"with_database"`
 }
 
 var (
@@ -30,4 +53,20 @@ var (
 	SetRole        = func() api.Operation { return api.Update("set_tenancy_role") }
 	SetCustomer    = func() api.Operation { return api.Update("set_tenancy_customer") }
 	ChangePoolOrDb = func() api.Operation { return api.UpdatePartial("change_tenancy_pool_or_db") }
+	Move           = func() api.Operation { return api.UpdatePartial("move_tenancy") }
+	FindMove       = func() api.Operation { return api.Find("find_tenancy_move") }
+	AddDomain      = func() api.Operation { return api.Add("add_tenancy_domain") }
+	DeleteDomain   = func() api.Operation { return api.Delete("delete_tenancy_domain") }
+	ListDomains    = func() api.Operation { return api.List("list_tenancy_domains") }
+	Backup         = func() api.Operation { return api.Add("backup_tenancy") }
+	Restore        = func() api.Operation { return api.Add("restore_tenancy") }
+	FindBackup     = func() api.Operation { return api.Find("find_tenancy_backup") }
+	Undelete       = func() api.Operation { return api.Update("undelete_tenancy") }
+	Purge          = func() api.Operation { return api.Delete("purge_tenancy") }
+	SetSetting     = func() api.Operation { return api.Update("set_tenancy_setting") }
+	UnsetSetting   = func() api.Operation { return api.Delete("unset_tenancy_setting") }
+	ListSettings   = func() api.Operation { return api.List("list_tenancy_settings") }
+	ListUsage      = func() api.Operation { return api.List("list_tenancy_usage") }
+	ApplyTemplate  = func() api.Operation { return api.Update("apply_tenancy_template") }
+	Stats          = func() api.Operation { return api.Find("tenancy_stats") }
 )
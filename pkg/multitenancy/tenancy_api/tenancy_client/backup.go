@@ -0,0 +1,94 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) Backup(ctx op_context.Context, id string, idIsDisplay ...bool) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Backup")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.TenancyBackupResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "backup", tenancyId, tenancy_api.Backup())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyBackup, nil
+}
+
+func (t *TenancyClient) Restore(ctx op_context.Context, options *multitenancy.TenancyRestoreOptions) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Restore")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(options, &tenancy_api.TenancyBackupResponse{})
+	err = t.restore.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyBackup, nil
+}
+
+func (t *TenancyClient) FindBackup(ctx op_context.Context, jobId string) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.FindBackup")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.TenancyBackupResponse{})
+	op := api.NamedResourceOperation(t.BackupResource, jobId, tenancy_api.FindBackup())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyBackup, nil
+}
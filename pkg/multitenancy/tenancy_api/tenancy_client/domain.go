@@ -0,0 +1,109 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) AddDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.AddDomain")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerCmd(&multitenancy.WithDomain{DOMAIN: domain})
+	op := api.OperationAsResource(t.TenancyResource, "domain", tenancyId, tenancy_api.AddDomain())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyClient) DeleteDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.DeleteDomain")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerCmd(&multitenancy.WithDomain{DOMAIN: domain})
+	op := api.OperationAsResource(t.TenancyResource, "domain", tenancyId, tenancy_api.DeleteDomain())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyClient) ListDomains(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*multitenancy.TenancyDomain, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.ListDomains")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.ListTenancyDomainsResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "domain", tenancyId, tenancy_api.ListDomains())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.Items, nil
+}
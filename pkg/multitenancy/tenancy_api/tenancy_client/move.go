@@ -0,0 +1,76 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) Move(ctx op_context.Context, id string, poolId string, dbName string, idIsDisplay ...bool) (*multitenancy.TenancyMove, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Move")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(&multitenancy.WithPoolAndDb{POOL_ID: poolId, DBNAME: dbName}, &tenancy_api.TenancyMoveResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "move", tenancyId, tenancy_api.Move())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyMove, nil
+}
+
+func (t *TenancyClient) FindMove(ctx op_context.Context, id string, idIsDisplay ...bool) (*multitenancy.TenancyMove, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.FindMove")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.TenancyMoveResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "move", tenancyId, tenancy_api.FindMove())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyMove, nil
+}
@@ -0,0 +1,109 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) SetSetting(ctx op_context.Context, id string, key string, value string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.SetSetting")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerCmd(&multitenancy.TenancySettingData{KEY: key, VALUE: value})
+	op := api.OperationAsResource(t.TenancyResource, "setting", tenancyId, tenancy_api.SetSetting())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyClient) UnsetSetting(ctx op_context.Context, id string, key string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.UnsetSetting")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerCmd(&multitenancy.TenancySettingData{KEY: key})
+	op := api.OperationAsResource(t.TenancyResource, "setting", tenancyId, tenancy_api.UnsetSetting())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyClient) ListSettings(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*multitenancy.TenancySetting, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.ListSettings")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.ListTenancySettingsResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "setting", tenancyId, tenancy_api.ListSettings())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.Items, nil
+}
@@ -0,0 +1,33 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) Stats(ctx op_context.Context) (*multitenancy.TenancyStats, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Stats")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerResult(&tenancy_api.TenancyStatsResponse{})
+	err = t.stats.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyStats, nil
+}
@@ -0,0 +1,43 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) ApplyTemplate(ctx op_context.Context, id string, template string, idIsDisplay ...bool) (*multitenancy.TenancyItem, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.ApplyTemplate")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// setup ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get ID")
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(&multitenancy.WithTemplate{TEMPLATE: template}, &tenancy_api.TenancyResponse{})
+	op := api.OperationAsResource(t.TenancyResource, "template", tenancyId, tenancy_api.ApplyTemplate())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.Result.TenancyItem, nil
+}
@@ -11,10 +11,14 @@ type TenancyClient struct {
 
 	TenanciesResource api.Resource
 	TenancyResource   api.Resource
-
-	add    api.Operation
-	list   api.Operation
-	exists api.Operation
+	BackupResource    api.Resource
+
+	add     api.Operation
+	list    api.Operation
+	exists  api.Operation
+	restore api.Operation
+	usage   api.Operation
+	stats   api.Operation
 }
 
 func NewTenancyClient(client api_client.Client) *TenancyClient {
@@ -37,5 +41,23 @@ func NewTenancyClient(client api_client.Client) *TenancyClient {
 	c.exists = tenancy_api.Exists()
 	existsResource.AddOperation(c.exists)
 
+	restoreResource := api.NewResource("restore")
+	c.TenanciesResource.AddChild(restoreResource)
+	c.restore = tenancy_api.Restore()
+	restoreResource.AddOperation(c.restore)
+
+	usageResource := api.NewResource("usage")
+	c.TenanciesResource.AddChild(usageResource)
+	c.usage = tenancy_api.ListUsage()
+	usageResource.AddOperation(c.usage)
+
+	statsResource := api.NewResource("stats")
+	c.TenanciesResource.AddChild(statsResource)
+	c.stats = tenancy_api.Stats()
+	statsResource.AddOperation(c.stats)
+
+	c.BackupResource = api.NamedResource(tenancy_api.BackupResource)
+	c.AddChild(c.BackupResource.Parent())
+
 	return c
 }
@@ -0,0 +1,75 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) Undelete(ctx op_context.Context, id string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Undelete")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// adjust ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get tenancy ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerNil()
+	op := api.OperationAsResource(t.TenancyResource, "undelete", tenancyId, tenancy_api.Undelete())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyClient) Purge(ctx op_context.Context, id string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.Purge")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// adjust ID
+	tenancyId, _, err := tenancy_manager.TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		c.SetMessage("failed to get tenancy ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := api_client.NewHandlerNil()
+	op := api.OperationAsResource(t.TenancyResource, "purge", tenancyId, tenancy_api.Purge())
+	err = op.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
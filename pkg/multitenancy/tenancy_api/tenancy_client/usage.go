@@ -0,0 +1,38 @@
+package tenancy_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyClient) ListUsage(ctx op_context.Context, filter *db.Filter) ([]*multitenancy.TenancyUsage, int64, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyClient.ListUsage")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// set query
+	cmd := api.NewDbQuery(filter)
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(cmd, &tenancy_api.ListTenancyUsageResponse{})
+	err = t.usage.Exec(ctx, api_client.MakeOperationHandler(t.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, 0, err
+	}
+
+	// done
+	return handler.Result.Items, handler.Result.Count, nil
+}
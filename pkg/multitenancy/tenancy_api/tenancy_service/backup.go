@@ -0,0 +1,111 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type BackupEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *BackupEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.Backup")
+	defer request.TraceOutMethod()
+
+	// start backup
+	resp := &tenancy_api.TenancyBackupResponse{}
+	resp.TenancyBackup, err = s.service.Tenancies.Backup(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		c.SetMessage("failed to start tenancy backup")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Backup(s *TenancyService) *BackupEndpoint {
+	e := &BackupEndpoint{}
+	e.Construct(s, tenancy_api.Backup())
+	return e
+}
+
+type RestoreEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *RestoreEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.Restore")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.TenancyRestoreOptions{}
+	err = request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return c.SetError(err)
+	}
+
+	// start restore
+	resp := &tenancy_api.TenancyBackupResponse{}
+	resp.TenancyBackup, err = s.service.Tenancies.Restore(request, cmd)
+	if err != nil {
+		c.SetMessage("failed to start tenancy restore")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Restore(s *TenancyService) *RestoreEndpoint {
+	e := &RestoreEndpoint{}
+	e.Construct(s, tenancy_api.Restore())
+	return e
+}
+
+type FindBackupEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *FindBackupEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.FindBackup")
+	defer request.TraceOutMethod()
+
+	// find
+	resp := &tenancy_api.TenancyBackupResponse{}
+	resp.TenancyBackup, err = s.service.Tenancies.FindBackup(request, request.GetResourceId(tenancy_api.BackupResource))
+	if err != nil {
+		c.SetMessage("failed to find tenancy backup job")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func FindBackup(s *TenancyService) *FindBackupEndpoint {
+	e := &FindBackupEndpoint{}
+	e.Construct(s, tenancy_api.FindBackup())
+	return e
+}
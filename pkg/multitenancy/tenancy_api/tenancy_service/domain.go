@@ -0,0 +1,107 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type AddDomainEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *AddDomainEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.AddDomain")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.WithDomain{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return err
+	}
+
+	// add
+	err = s.service.Tenancies.AddDomain(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.Domain())
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func AddDomain(s *TenancyService) *AddDomainEndpoint {
+	e := &AddDomainEndpoint{}
+	e.Construct(s, tenancy_api.AddDomain())
+	return e
+}
+
+type DeleteDomainEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *DeleteDomainEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.DeleteDomain")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.WithDomain{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return err
+	}
+
+	// delete
+	err = s.service.Tenancies.DeleteDomain(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.Domain())
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func DeleteDomain(s *TenancyService) *DeleteDomainEndpoint {
+	e := &DeleteDomainEndpoint{}
+	e.Construct(s, tenancy_api.DeleteDomain())
+	return e
+}
+
+type ListDomainsEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *ListDomainsEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.ListDomains")
+	defer request.TraceOutMethod()
+
+	// list
+	resp := &tenancy_api.ListTenancyDomainsResponse{}
+	resp.Items, err = s.service.Tenancies.ListDomains(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		return c.SetError(err)
+	}
+	resp.Count = int64(len(resp.Items))
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func ListDomains(s *TenancyService) *ListDomainsEndpoint {
+	e := &ListDomainsEndpoint{}
+	e.Construct(s, tenancy_api.ListDomains())
+	return e
+}
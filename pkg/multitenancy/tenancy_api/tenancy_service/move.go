@@ -0,0 +1,79 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type MoveEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *MoveEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.Move")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.WithPoolAndDb{}
+	err = request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return c.SetError(err)
+	}
+
+	// start move
+	resp := &tenancy_api.TenancyMoveResponse{}
+	resp.TenancyMove, err = s.service.Tenancies.Move(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.PoolId(), cmd.DbName())
+	if err != nil {
+		c.SetMessage("failed to start tenancy move")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Move(s *TenancyService) *MoveEndpoint {
+	e := &MoveEndpoint{}
+	e.Construct(s, tenancy_api.Move())
+	return e
+}
+
+type FindMoveEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *FindMoveEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.FindMove")
+	defer request.TraceOutMethod()
+
+	// find
+	resp := &tenancy_api.TenancyMoveResponse{}
+	resp.TenancyMove, err = s.service.Tenancies.FindMove(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		c.SetMessage("failed to find tenancy move")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func FindMove(s *TenancyService) *FindMoveEndpoint {
+	e := &FindMoveEndpoint{}
+	e.Construct(s, tenancy_api.FindMove())
+	return e
+}
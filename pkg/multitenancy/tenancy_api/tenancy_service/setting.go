@@ -0,0 +1,107 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type SetSettingEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *SetSettingEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.SetSetting")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.TenancySettingData{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return err
+	}
+
+	// set
+	err = s.service.Tenancies.SetSetting(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.Key(), cmd.Value())
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func SetSetting(s *TenancyService) *SetSettingEndpoint {
+	e := &SetSettingEndpoint{}
+	e.Construct(s, tenancy_api.SetSetting())
+	return e
+}
+
+type UnsetSettingEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *UnsetSettingEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.UnsetSetting")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.TenancySettingData{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return err
+	}
+
+	// unset
+	err = s.service.Tenancies.UnsetSetting(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.Key())
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func UnsetSetting(s *TenancyService) *UnsetSettingEndpoint {
+	e := &UnsetSettingEndpoint{}
+	e.Construct(s, tenancy_api.UnsetSetting())
+	return e
+}
+
+type ListSettingsEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *ListSettingsEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.ListSettings")
+	defer request.TraceOutMethod()
+
+	// list
+	resp := &tenancy_api.ListTenancySettingsResponse{}
+	resp.Items, err = s.service.Tenancies.ListSettings(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		return c.SetError(err)
+	}
+	resp.Count = int64(len(resp.Items))
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func ListSettings(s *TenancyService) *ListSettingsEndpoint {
+	e := &ListSettingsEndpoint{}
+	e.Construct(s, tenancy_api.ListSettings())
+	return e
+}
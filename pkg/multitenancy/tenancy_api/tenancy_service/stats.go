@@ -0,0 +1,38 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type StatsEndpoint struct {
+	TenancyEndpoint
+}
+
+func (e *StatsEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.Stats")
+	defer request.TraceOutMethod()
+
+	// get statistics
+	resp := &tenancy_api.TenancyStatsResponse{}
+	resp.TenancyStats, err = e.service.Tenancies.Stats(request)
+	if err != nil {
+		c.SetMessage("failed to get statistics of tenancies")
+		return c.SetError(err)
+	}
+
+	// set response message
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func Stats(s *TenancyService) *StatsEndpoint {
+	e := &StatsEndpoint{}
+	e.Construct(s, tenancy_api.Stats())
+	return e
+}
@@ -0,0 +1,47 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type ApplyTemplateEndpoint struct {
+	TenancyEndpoint
+}
+
+func (s *ApplyTemplateEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("tenancy.ApplyTemplate")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &multitenancy.WithTemplate{}
+	err = request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return c.SetError(err)
+	}
+
+	// apply template
+	resp := &tenancy_api.TenancyResponse{}
+	resp.TenancyItem, err = s.service.Tenancies.ApplyTemplate(request, request.GetResourceId(tenancy_api.TenancyResource), cmd.Template())
+	if err != nil {
+		c.SetMessage("failed to apply template")
+		return c.SetError(err)
+	}
+
+	// set response
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func ApplyTemplate(s *TenancyService) *ApplyTemplateEndpoint {
+	e := &ApplyTemplateEndpoint{}
+	e.Construct(s, tenancy_api.ApplyTemplate())
+	return e
+}
@@ -34,6 +34,7 @@ type TenancyService struct {
 
 	TenanciesResource api.Resource
 	TenancyResource   api.Resource
+	BackupResource    api.Resource
 }
 
 func NewTenancyService(tenancyController multitenancy.TenancyController) *TenancyService {
@@ -63,6 +64,50 @@ func NewTenancyService(tenancyController multitenancy.TenancyController) *Tenanc
 		ChangePoolOrDb(s),
 	)
 
+	undeleteResource := api.NewResource("undelete")
+	undeleteResource.AddOperation(Undelete(s))
+	s.TenancyResource.AddChild(undeleteResource)
+
+	purgeResource := api.NewResource("purge")
+	purgeResource.AddOperation(Purge(s))
+	s.TenancyResource.AddChild(purgeResource)
+
+	moveResource := api.NewResource("move")
+	moveResource.AddOperations(Move(s), FindMove(s))
+	s.TenancyResource.AddChild(moveResource)
+
+	domainResource := api.NewResource("domain")
+	domainResource.AddOperations(AddDomain(s), DeleteDomain(s), ListDomains(s))
+	s.TenancyResource.AddChild(domainResource)
+
+	settingResource := api.NewResource("setting")
+	settingResource.AddOperations(SetSetting(s), UnsetSetting(s), ListSettings(s))
+	s.TenancyResource.AddChild(settingResource)
+
+	templateResource := api.NewResource("template")
+	templateResource.AddOperation(ApplyTemplate(s))
+	s.TenancyResource.AddChild(templateResource)
+
+	backupResource := api.NewResource("backup")
+	backupResource.AddOperation(Backup(s))
+	s.TenancyResource.AddChild(backupResource)
+
+	restoreResource := api.NewResource("restore")
+	restoreResource.AddOperation(Restore(s))
+	s.TenanciesResource.AddChild(restoreResource)
+
+	usageResource := api.NewResource("usage")
+	usageResource.AddOperation(ListUsage(s))
+	s.TenanciesResource.AddChild(usageResource)
+
+	statsResource := api.NewResource("stats")
+	statsResource.AddOperation(Stats(s))
+	s.TenanciesResource.AddChild(statsResource)
+
+	s.BackupResource = api.NamedResource(tenancy_api.BackupResource)
+	s.BackupResource.AddOperation(FindBackup(s), true)
+	s.AddChild(s.BackupResource.Parent())
+
 	tenancyTableConfig := &api_server.DynamicTableConfig{Model: &multitenancy.TenancyItem{}, Operation: listOp}
 	s.AddDynamicTables(tenancyTableConfig)
 
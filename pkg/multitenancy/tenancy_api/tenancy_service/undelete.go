@@ -0,0 +1,58 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type UndeleteEndpoint struct {
+	TenancyEndpoint
+}
+
+func (e *UndeleteEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.Undelete")
+	defer request.TraceOutMethod()
+
+	// undelete
+	err := e.service.Tenancies.Undelete(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func Undelete(s *TenancyService) *UndeleteEndpoint {
+	e := &UndeleteEndpoint{}
+	e.Construct(s, tenancy_api.Undelete())
+	return e
+}
+
+type PurgeEndpoint struct {
+	TenancyEndpoint
+}
+
+func (e *PurgeEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.Purge")
+	defer request.TraceOutMethod()
+
+	// purge
+	err := e.service.Tenancies.Purge(request, request.GetResourceId(tenancy_api.TenancyResource))
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func Purge(s *TenancyService) *PurgeEndpoint {
+	e := &PurgeEndpoint{}
+	e.Construct(s, tenancy_api.Purge())
+	return e
+}
@@ -0,0 +1,44 @@
+package tenancy_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api"
+)
+
+type ListUsageEndpoint struct {
+	TenancyEndpoint
+}
+
+func (e *ListUsageEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("tenancy.ListUsage")
+	defer request.TraceOutMethod()
+
+	// parse query
+	queryName := request.Endpoint().Resource().ServicePathPrototype()
+	filter, err := api_server.ParseDbQuery(request, &multitenancy.TenancyUsage{}, queryName)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// get
+	resp := &tenancy_api.ListTenancyUsageResponse{}
+	resp.Items, resp.Count, err = e.service.Tenancies.ListUsage(request, filter)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func ListUsage(s *TenancyService) *ListUsageEndpoint {
+	e := &ListUsageEndpoint{}
+	e.Construct(s, tenancy_api.ListUsage())
+	return e
+}
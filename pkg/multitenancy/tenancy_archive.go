@@ -0,0 +1,209 @@
+package multitenancy
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const TenancyArchiveFormat string = "tenancy_archive"
+const TenancyArchiveVersion int = 1
+const TenancyArchiveExtension string = ".tenancy.gz"
+
+// Header of tenancy archive, it is the first record of archive.
+type TenancyArchiveHeader struct {
+	Format    string       `json:"format"`
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Tenancy   *TenancyItem `json:"tenancy"`
+	Domains   []string     `json:"domains,omitempty"`
+	Tables    []string     `json:"tables"`
+}
+
+// Record of tenancy archive following the header. The last record must have End flag and total number of rows.
+type TenancyArchiveRecord struct {
+	Table string                     `json:"table,omitempty"`
+	Row   map[string]json.RawMessage `json:"row,omitempty"`
+	End   bool                       `json:"end,omitempty"`
+	Rows  int64                      `json:"rows,omitempty"`
+}
+
+// Tenancy archive is a gzip compressed stream of JSON records: header, rows of tables and end record.
+type TenancyArchiveWriter struct {
+	gz      *gzip.Writer
+	encoder *json.Encoder
+	rows    int64
+}
+
+func NewTenancyArchiveWriter(w io.Writer, header *TenancyArchiveHeader) (*TenancyArchiveWriter, error) {
+	a := &TenancyArchiveWriter{}
+	a.gz = gzip.NewWriter(w)
+	a.encoder = json.NewEncoder(a.gz)
+	header.Format = TenancyArchiveFormat
+	header.Version = TenancyArchiveVersion
+	if header.CreatedAt.IsZero() {
+		header.CreatedAt = time.Now()
+	}
+	err := a.encoder.Encode(header)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *TenancyArchiveWriter) WriteRow(table string, row interface{}) error {
+	data, err := EncodeArchiveRow(row)
+	if err != nil {
+		return err
+	}
+	err = a.encoder.Encode(&TenancyArchiveRecord{Table: table, Row: data})
+	if err != nil {
+		return err
+	}
+	a.rows++
+	return nil
+}
+
+func (a *TenancyArchiveWriter) Rows() int64 {
+	return a.rows
+}
+
+// Write end record and flush archive.
+func (a *TenancyArchiveWriter) Close() error {
+	err := a.encoder.Encode(&TenancyArchiveRecord{End: true, Rows: a.rows})
+	if err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+type TenancyArchiveReader struct {
+	Header  TenancyArchiveHeader
+	gz      *gzip.Reader
+	decoder *json.Decoder
+	rows    int64
+}
+
+func NewTenancyArchiveReader(r io.Reader) (*TenancyArchiveReader, error) {
+	a := &TenancyArchiveReader{}
+	var err error
+	a.gz, err = gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	a.decoder = json.NewDecoder(a.gz)
+	err = a.decoder.Decode(&a.Header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive header: %s", err)
+	}
+	if a.Header.Format != TenancyArchiveFormat {
+		return nil, errors.New("invalid format of tenancy archive")
+	}
+	if a.Header.Version > TenancyArchiveVersion {
+		return nil, fmt.Errorf("unsupported version of tenancy archive %d", a.Header.Version)
+	}
+	if a.Header.Tenancy == nil {
+		return nil, errors.New("tenancy is missing in archive header")
+	}
+	return a, nil
+}
+
+// Read next row from archive. Nil record is returned when all rows are read.
+func (a *TenancyArchiveReader) Next() (*TenancyArchiveRecord, error) {
+	record := &TenancyArchiveRecord{}
+	err := a.decoder.Decode(record)
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("tenancy archive is truncated")
+		}
+		return nil, err
+	}
+	if record.End {
+		if record.Rows != a.rows {
+			return nil, fmt.Errorf("number of rows in tenancy archive mismatch: expected %d, read %d", record.Rows, a.rows)
+		}
+		return nil, nil
+	}
+	a.rows++
+	return record, nil
+}
+
+func (a *TenancyArchiveReader) Close() error {
+	return a.gz.Close()
+}
+
+// Archive rows are keyed by names of struct fields, not by JSON names, so that fields hidden from JSON are also kept.
+func archiveFields(v reflect.Value) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(v.Type()) {
+		if !field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func archiveStruct(row interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(row)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("invalid type of archive row %s", v.Type())
+	}
+	return v, nil
+}
+
+func EncodeArchiveRow(row interface{}) (map[string]json.RawMessage, error) {
+	v, err := archiveStruct(row)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]json.RawMessage)
+	for _, field := range archiveFields(v) {
+		// marshal field by pointer to use marshalers with pointer receivers
+		f := v.FieldByIndex(field.Index)
+		if f.CanAddr() {
+			f = f.Addr()
+		}
+		b, err := json.Marshal(f.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %s", field.Name, err)
+		}
+		data[field.Name] = b
+	}
+	return data, nil
+}
+
+func DecodeArchiveRow(data map[string]json.RawMessage, row interface{}) error {
+	v, err := archiveStruct(row)
+	if err != nil {
+		return err
+	}
+	for _, field := range archiveFields(v) {
+		b, ok := data[field.Name]
+		if !ok {
+			continue
+		}
+		err = json.Unmarshal(b, v.FieldByIndex(field.Index).Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("failed to decode field %s: %s", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// Archives are kept in backup directory, so archive name must be a plain file name.
+func CheckArchiveName(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return errors.New("invalid name of tenancy archive")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package multitenancy
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+)
+
+const (
+	BackupOperationBackup  string = "backup"
+	BackupOperationRestore string = "restore"
+)
+
+const (
+	BackupStatusRunning string = "running"
+	BackupStatusDone    string = "done"
+	BackupStatusFailed  string = "failed"
+)
+
+type TenancyBackupData struct {
+	TENANCY_ID    string    `gorm:"index" json:"tenancy_id"`
+	OPERATION     string    `gorm:"index" json:"operation"`
+	STATUS        string    `gorm:"index" json:"status"`
+	ARCHIVE       string    `json:"archive"`
+	CURRENT_TABLE string    `json:"current_table"`
+	ROWS          int64     `json:"rows"`
+	LAST_ERROR    string    `json:"last_error"`
+	FINISHED_AT   time.Time `json:"finished_at"`
+}
+
+// TenancyBackup keeps state and progress of background job of tenancy backup or restore.
+type TenancyBackup struct {
+	common.ObjectBase
+	TenancyBackupData
+}
+
+func (TenancyBackup) TableName() string {
+	return "tenancy_backups"
+}
+
+func (b *TenancyBackup) Status() string {
+	return b.STATUS
+}
+
+func (b *TenancyBackup) IsRunning() bool {
+	return b.STATUS == BackupStatusRunning
+}
+
+// Options of restoring tenancy from archive.
+// If target tenancy is set then data is restored into that tenancy,
+// otherwise new tenancy is created using archived tenancy data with overrides of not empty fields.
+// Tables of tenancy database must be empty before restoring.
+// Restored data is remapped to ID of target or new tenancy unless KEEP_ID is set for new tenancy.
+type TenancyRestoreOptions struct {
+	ARCHIVE     string `json:"archive" validate:"required" vmessage:"Archive name must be specified" long:"archive" description:"Name of archive file in backup directory" required:"true"`
+	TARGET      string `json:"target,omitempty" long:"target" description:"ID or customer/role of existing tenancy to restore data into"`
+	KEEP_ID     bool   `json:"keep_id,omitempty" long:"keep-id" description:"Keep ID of archived tenancy for new tenancy"`
+	CUSTOMER_ID string `json:"customer_id,omitempty" validate:"omitempty,alphanum_|email" vmessage:"Invalid customer ID" long:"customer" description:"ID or name of a customer that will own new tenancy"`
+	ROLE        string `json:"role,omitempty" validate:"omitempty,alphanum_" vmessage:"Role must be alphanumeric" long:"role" description:"Role of new tenancy"`
+	PATH        string `json:"path,omitempty" validate:"omitempty,alphanum_" vmessage:"Path must be alhanumeric" long:"path" description:"Path of new tenancy"`
+	POOL_ID     string `json:"pool_id,omitempty" validate:"omitempty,alphanum" vmessage:"Pool ID must be alhanumeric" long:"pool" description:"Name or ID of a pool of new tenancy"`
+	DBNAME      string `json:"dbname,omitempty" validate:"omitempty,alphanum_" vmessage:"Database name must be alhanumeric" long:"dbname" description:"Name of database of new tenancy"`
+}
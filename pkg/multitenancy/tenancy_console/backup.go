@@ -0,0 +1,157 @@
+package tenancy_console
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Wait until backup or restore job is finished.
+func waitBackup(ctx op_context.Context, controller multitenancy.TenancyController, job *multitenancy.TenancyBackup) (*multitenancy.TenancyBackup, error) {
+	var err error
+	for job.IsRunning() {
+		time.Sleep(time.Second)
+		job, err = controller.FindBackup(ctx, job.GetID())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+func printBackup(job *multitenancy.TenancyBackup) error {
+	fmt.Printf("Job:\n%s\n", utils.DumpPrettyJson(job))
+	if job.Status() == multitenancy.BackupStatusFailed {
+		return fmt.Errorf("%s failed: %s", job.OPERATION, job.LAST_ERROR)
+	}
+	return nil
+}
+
+const BackupCmd string = "backup"
+const BackupDescription string = "Export tenancy metadata and data to archive in backup directory"
+
+func Backup() Handler {
+	a := &BackupHandler{}
+	a.Init(BackupCmd, BackupDescription)
+	return a
+}
+
+type BackupData struct {
+	TenancySelector
+	NoWait bool `long:"no-wait" description:"Do not wait for backup job to finish"`
+}
+
+type BackupHandler struct {
+	HandlerBase
+	BackupData
+}
+
+func (a *BackupHandler) Data() interface{} {
+	return &a.BackupData
+}
+
+func (a *BackupHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	job, err := controller.Backup(ctx, id, idIsDisplay)
+	if err != nil {
+		return err
+	}
+	if !a.NoWait {
+		job, err = waitBackup(ctx, controller, job)
+		if err != nil {
+			return err
+		}
+	}
+	return printBackup(job)
+}
+
+const RestoreCmd string = "restore"
+const RestoreDescription string = "Import tenancy archive from backup directory into new or existing tenancy"
+
+func Restore() Handler {
+	a := &RestoreHandler{}
+	a.Init(RestoreCmd, RestoreDescription)
+	return a
+}
+
+type RestoreData struct {
+	multitenancy.TenancyRestoreOptions
+	NoWait bool `long:"no-wait" description:"Do not wait for restore job to finish"`
+}
+
+type RestoreHandler struct {
+	HandlerBase
+	RestoreData
+}
+
+func (a *RestoreHandler) Data() interface{} {
+	return &a.RestoreData
+}
+
+func (a *RestoreHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	job, err := controller.Restore(ctx, &a.TenancyRestoreOptions)
+	if err != nil {
+		return err
+	}
+	if !a.NoWait {
+		job, err = waitBackup(ctx, controller, job)
+		if err != nil {
+			return err
+		}
+	}
+	return printBackup(job)
+}
+
+const BackupStatusCmd string = "backup-status"
+const BackupStatusDescription string = "Show status of backup or restore job"
+
+func BackupStatus() Handler {
+	a := &BackupStatusHandler{}
+	a.Init(BackupStatusCmd, BackupStatusDescription)
+	return a
+}
+
+type BackupStatusData struct {
+	Job string `long:"job" description:"ID of backup or restore job" required:"true"`
+}
+
+type BackupStatusHandler struct {
+	HandlerBase
+	BackupStatusData
+}
+
+func (a *BackupStatusHandler) Data() interface{} {
+	return &a.BackupStatusData
+}
+
+func (a *BackupStatusHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	job, err := controller.FindBackup(ctx, a.Job)
+	if err != nil {
+		return err
+	}
+	return printBackup(job)
+}
@@ -8,7 +8,7 @@ import (
 )
 
 const DeleteCmd string = "delete"
-const DeleteDescription string = "Delete tenancy"
+const DeleteDescription string = "Delete tenancy, deleted tenancy can be restored until it is purged"
 
 func Delete() Handler {
 	a := &DeleteHandler{}
@@ -18,7 +18,7 @@ func Delete() Handler {
 
 type DeleteData struct {
 	TenancySelector
-	WithDb bool `long:"with-database" description:"Delete tenancy's database. ATTENTION! Deleted data can not be recovered later!"`
+	WithDb bool `long:"with-database" description:"Drop tenancy's database when tenancy is purged. ATTENTION! Dropped data can not be recovered later!"`
 }
 
 type DeleteHandler struct {
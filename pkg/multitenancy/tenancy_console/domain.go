@@ -0,0 +1,108 @@
+package tenancy_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+)
+
+type DomainData struct {
+	TenancySelector
+	multitenancy.WithDomain
+}
+
+const AddDomainCmd string = "add-domain"
+const AddDomainDescription string = "Bind domain name to tenancy"
+
+func AddDomain() Handler {
+	a := &AddDomainHandler{}
+	a.Init(AddDomainCmd, AddDomainDescription)
+	return a
+}
+
+type AddDomainHandler struct {
+	HandlerBase
+	DomainData
+}
+
+func (a *AddDomainHandler) Data() interface{} {
+	return &a.DomainData
+}
+
+func (a *AddDomainHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	return controller.AddDomain(ctx, id, a.DOMAIN, idIsDisplay)
+}
+
+const DeleteDomainCmd string = "delete-domain"
+const DeleteDomainDescription string = "Unbind domain name from tenancy"
+
+func DeleteDomain() Handler {
+	a := &DeleteDomainHandler{}
+	a.Init(DeleteDomainCmd, DeleteDomainDescription)
+	return a
+}
+
+type DeleteDomainHandler struct {
+	HandlerBase
+	DomainData
+}
+
+func (a *DeleteDomainHandler) Data() interface{} {
+	return &a.DomainData
+}
+
+func (a *DeleteDomainHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	return controller.DeleteDomain(ctx, id, a.DOMAIN, idIsDisplay)
+}
+
+const DomainsCmd string = "domains"
+const DomainsDescription string = "List domain names bound to tenancy"
+
+func Domains() Handler {
+	a := &DomainsHandler{}
+	a.Init(DomainsCmd, DomainsDescription)
+	return a
+}
+
+type DomainsHandler struct {
+	HandlerBase
+	TenancySelector
+}
+
+func (a *DomainsHandler) Data() interface{} {
+	return &a.TenancySelector
+}
+
+func (a *DomainsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	domains, err := controller.ListDomains(ctx, id, idIsDisplay)
+	if err == nil {
+		for _, domain := range domains {
+			fmt.Println(domain.Domain())
+		}
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package tenancy_console
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Wait until move of tenancy is finished.
+func waitMove(ctx op_context.Context, controller multitenancy.TenancyController, move *multitenancy.TenancyMove) (*multitenancy.TenancyMove, error) {
+	var err error
+	for move.IsRunning() {
+		time.Sleep(time.Second)
+		move, err = controller.FindMove(ctx, move.TENANCY_ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return move, nil
+}
+
+const MoveCmd string = "move"
+const MoveDescription string = "Move tenancy data to other pool and/or database, unfinished move is resumed"
+
+func Move() Handler {
+	a := &MoveHandler{}
+	a.Init(MoveCmd, MoveDescription)
+	return a
+}
+
+type MoveData struct {
+	TenancySelector
+	multitenancy.WithPoolAndDb
+	NoWait bool `long:"no-wait" description:"Do not wait for move to finish"`
+}
+
+type MoveHandler struct {
+	HandlerBase
+	MoveData
+}
+
+func (a *MoveHandler) Data() interface{} {
+	return &a.MoveData
+}
+
+func (a *MoveHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	move, err := controller.Move(ctx, id, a.POOL_ID, a.DBNAME, idIsDisplay)
+	if err != nil {
+		return err
+	}
+	if !a.NoWait {
+		move, err = waitMove(ctx, controller, move)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Move:\n%s\n", utils.DumpPrettyJson(move))
+	if move.Status() == multitenancy.MoveStatusFailed {
+		return fmt.Errorf("move failed: %s", move.LAST_ERROR)
+	}
+	return nil
+}
+
+const MoveStatusCmd string = "move-status"
+const MoveStatusDescription string = "Show status of the last move of tenancy"
+
+func MoveStatus() Handler {
+	a := &MoveStatusHandler{}
+	a.Init(MoveStatusCmd, MoveStatusDescription)
+	return a
+}
+
+type MoveStatusHandler struct {
+	HandlerBase
+	TenancySelector
+}
+
+func (a *MoveStatusHandler) Data() interface{} {
+	return &a.TenancySelector
+}
+
+func (a *MoveStatusHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	move, err := controller.FindMove(ctx, id, idIsDisplay)
+	if err == nil {
+		if move == nil {
+			fmt.Println("Tenancy was never moved")
+		} else {
+			fmt.Printf("Move:\n%s\n", utils.DumpPrettyJson(move))
+		}
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package tenancy_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+)
+
+type SettingData struct {
+	TenancySelector
+	multitenancy.TenancySettingData
+}
+
+const SetSettingCmd string = "set-setting"
+const SetSettingDescription string = "Override configuration setting in tenancy"
+
+func SetSetting() Handler {
+	a := &SetSettingHandler{}
+	a.Init(SetSettingCmd, SetSettingDescription)
+	return a
+}
+
+type SetSettingHandler struct {
+	HandlerBase
+	SettingData
+}
+
+func (a *SetSettingHandler) Data() interface{} {
+	return &a.SettingData
+}
+
+func (a *SetSettingHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	return controller.SetSetting(ctx, id, a.KEY, a.VALUE, idIsDisplay)
+}
+
+type UnsetSettingData struct {
+	TenancySelector
+	KEY string `long:"key" description:"Configuration key of setting" required:"true"`
+}
+
+const UnsetSettingCmd string = "unset-setting"
+const UnsetSettingDescription string = "Remove override of configuration setting in tenancy"
+
+func UnsetSetting() Handler {
+	a := &UnsetSettingHandler{}
+	a.Init(UnsetSettingCmd, UnsetSettingDescription)
+	return a
+}
+
+type UnsetSettingHandler struct {
+	HandlerBase
+	UnsetSettingData
+}
+
+func (a *UnsetSettingHandler) Data() interface{} {
+	return &a.UnsetSettingData
+}
+
+func (a *UnsetSettingHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	return controller.UnsetSetting(ctx, id, a.KEY, idIsDisplay)
+}
+
+const SettingsCmd string = "settings"
+const SettingsDescription string = "List configuration settings overridden in tenancy"
+
+func Settings() Handler {
+	a := &SettingsHandler{}
+	a.Init(SettingsCmd, SettingsDescription)
+	return a
+}
+
+type SettingsHandler struct {
+	HandlerBase
+	TenancySelector
+}
+
+func (a *SettingsHandler) Data() interface{} {
+	return &a.TenancySelector
+}
+
+func (a *SettingsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	settings, err := controller.ListSettings(ctx, id, idIsDisplay)
+	if err == nil {
+		for _, setting := range settings {
+			fmt.Printf("%s=%s\n", setting.Key(), setting.Value())
+		}
+	}
+	return err
+}
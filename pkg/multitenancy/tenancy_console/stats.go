@@ -0,0 +1,35 @@
+package tenancy_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const StatsCmd string = "stats"
+const StatsDescription string = "Show statistics of loading and eviction of tenancies"
+
+func Stats() Handler {
+	a := &StatsHandler{}
+	a.Init(StatsCmd, StatsDescription)
+	return a
+}
+
+type StatsHandler struct {
+	HandlerBase
+}
+
+func (a *StatsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	stats, err := controller.Stats(ctx)
+	if err == nil {
+		fmt.Printf("Statistics:\n\n%s\n\n", utils.DumpPrettyJson(stats))
+	}
+	return err
+}
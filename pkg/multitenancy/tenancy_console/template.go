@@ -0,0 +1,46 @@
+package tenancy_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ApplyTemplateCmd string = "apply-template"
+const ApplyTemplateDescription string = "Apply template to tenancy, only data missing in tenancy is added"
+
+func ApplyTemplate() Handler {
+	a := &ApplyTemplateHandler{}
+	a.Init(ApplyTemplateCmd, ApplyTemplateDescription)
+	return a
+}
+
+type ApplyTemplateData struct {
+	TenancySelector
+	TEMPLATE string `long:"template" description:"Name of template, if not set then the current template of tenancy is re-applied"`
+}
+
+type ApplyTemplateHandler struct {
+	HandlerBase
+	ApplyTemplateData
+}
+
+func (a *ApplyTemplateHandler) Data() interface{} {
+	return &a.ApplyTemplateData
+}
+
+func (a *ApplyTemplateHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := PrepareId(a.Id, a.Customer, a.Role)
+	tenancy, err := controller.ApplyTemplate(ctx, id, a.TEMPLATE, idIsDisplay)
+	if err == nil {
+		fmt.Printf("Applied template to tenancy:\n%s\n", utils.DumpPrettyJson(tenancy))
+	}
+	return err
+}
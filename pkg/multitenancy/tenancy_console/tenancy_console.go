@@ -130,7 +130,23 @@ func (p *TenancyCommands) LoadHandlers() {
 		Role,
 		Path,
 		ChangePoolOrDb,
+		Move,
+		MoveStatus,
+		AddDomain,
+		DeleteDomain,
+		Domains,
+		SetSetting,
+		UnsetSetting,
+		Settings,
+		Usage,
+		Stats,
+		ApplyTemplate,
+		Backup,
+		Restore,
+		BackupStatus,
 		Delete,
+		Undelete,
+		Purge,
 	)
 }
 
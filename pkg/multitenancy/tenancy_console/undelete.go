@@ -0,0 +1,66 @@
+package tenancy_console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const UndeleteCmd string = "undelete"
+const UndeleteDescription string = "Restore deleted tenancy that is not purged yet, restored tenancy must be activated explicitly"
+
+func Undelete() Handler {
+	a := &UndeleteHandler{}
+	a.Init(UndeleteCmd, UndeleteDescription)
+	return a
+}
+
+type UndeleteHandler struct {
+	FindHandler
+}
+
+func (a *UndeleteHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	id, idIsDisplay := a.PrepareId()
+	return controller.Undelete(ctx, id, idIsDisplay)
+}
+
+const PurgeCmd string = "purge"
+const PurgeDescription string = "Purge deleted tenancy immediately without waiting for end of grace period"
+
+func Purge() Handler {
+	a := &PurgeHandler{}
+	a.Init(PurgeCmd, PurgeDescription)
+	return a
+}
+
+type PurgeHandler struct {
+	FindHandler
+}
+
+func (a *PurgeHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Type YES to confirm operation: ")
+	text, _ := reader.ReadString('\n')
+	if strings.TrimSpace(text) == "YES" {
+		id, idIsDisplay := a.PrepareId()
+		return controller.Purge(ctx, id, idIsDisplay)
+	}
+
+	return errors.New("operation cancelled")
+}
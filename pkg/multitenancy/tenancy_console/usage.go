@@ -0,0 +1,48 @@
+package tenancy_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const UsageCmd string = "usage"
+const UsageDescription string = "List usage of tenancies in months"
+
+func Usage() Handler {
+	a := &UsageHandler{}
+	a.Init(UsageCmd, UsageDescription)
+	return a
+}
+
+type UsageHandler struct {
+	HandlerBase
+	console_tool.QueryData
+}
+
+func (a *UsageHandler) Data() interface{} {
+	return &a.QueryData
+}
+
+func (a *UsageHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	filter, err := db.ParseQuery(ctx.Db(), a.Query, &multitenancy.TenancyUsage{}, "")
+	if err != nil {
+		return fmt.Errorf("failed to parse query: %s", err)
+	}
+
+	usage, count, err := controller.ListUsage(ctx, filter)
+	if err == nil {
+		fmt.Printf("Usage:\n\n%s\n\nTotal count %d\n\n", utils.DumpPrettyJson(usage), count)
+	}
+	return err
+}
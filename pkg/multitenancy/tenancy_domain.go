@@ -0,0 +1,36 @@
+package multitenancy
+
+import (
+	"net"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+)
+
+type WithDomain struct {
+	DOMAIN string `gorm:"uniqueIndex" json:"domain" validate:"required,fqdn" vmessage:"Invalid domain name" long:"domain" description:"Domain name bound to tenancy" required:"true"`
+}
+
+func (d *WithDomain) Domain() string {
+	return d.DOMAIN
+}
+
+// TenancyDomain binds domain name to tenancy, each domain can be bound only to one tenancy.
+type TenancyDomain struct {
+	common.ObjectBase
+	TENANCY_ID string `gorm:"index" json:"tenancy_id"`
+	WithDomain
+}
+
+func (TenancyDomain) TableName() string {
+	return "tenancy_domains"
+}
+
+// Normalize domain name or HTTP host: convert to lower case, strip port and trailing dot.
+func NormalizeDomain(domain string) string {
+	host, _, err := net.SplitHostPort(domain)
+	if err == nil {
+		domain = host
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
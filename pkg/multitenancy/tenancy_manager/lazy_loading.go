@@ -0,0 +1,275 @@
+package tenancy_manager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+)
+
+type TenancyManagerStats = multitenancy.TenancyStats
+
+// Index entry of tenancy used to load tenancy on demand.
+type tenancyRecord struct {
+	data    *multitenancy.TenancyDb
+	domains []string
+}
+
+// Usage of acquired tenancy.
+type tenancyUsage struct {
+	refs     int
+	unloaded bool
+}
+
+type lruItem struct {
+	id       string
+	lastUsed time.Time
+}
+
+// Get counters of known, loaded, hit and evicted tenancies.
+func (t *TenancyManager) Stats() TenancyManagerStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	stats := t.stats
+	stats.Known = len(t.records)
+	if !t.LAZY_LOADING {
+		stats.Known = len(t.tenanciesById)
+		stats.Loaded = len(t.tenanciesById)
+	}
+	return stats
+}
+
+func (t *TenancyManager) resetRecords() {
+	t.records = make(map[string]*tenancyRecord)
+	t.idsByPath = make(map[string]string)
+	t.idsByDomain = make(map[string]string)
+}
+
+// Must be called under locked mutex.
+func (t *TenancyManager) addToLru(id string) {
+	t.removeFromLru(id)
+	t.lruItems[id] = t.lru.PushFront(&lruItem{id: id, lastUsed: time.Now()})
+	t.stats.Loaded = len(t.lruItems)
+	t.stats.Loads++
+}
+
+// Must be called under locked mutex.
+func (t *TenancyManager) removeFromLru(id string) {
+	element, ok := t.lruItems[id]
+	if ok {
+		t.lru.Remove(element)
+		delete(t.lruItems, id)
+		t.stats.Loaded = len(t.lruItems)
+	}
+}
+
+// Must be called under locked mutex.
+func (t *TenancyManager) touchTenancy(id string) {
+	t.stats.Hits++
+	element, ok := t.lruItems[id]
+	if ok {
+		element.Value.(*lruItem).lastUsed = time.Now()
+		t.lru.MoveToFront(element)
+	}
+}
+
+func (t *TenancyManager) indexTenancy(ctx op_context.Context, tenancyDb *multitenancy.TenancyDb) error {
+
+	// deleted tenancies are not indexed
+	if tenancyDb.IsDeleted() {
+		t.forgetTenancy(tenancyDb.GetID())
+		return nil
+	}
+
+	// load domains
+	domains, err := t.Controller.ListDomains(ctx, tenancyDb.GetID())
+	if err != nil {
+		return err
+	}
+	record := &tenancyRecord{data: tenancyDb}
+	for _, domain := range domains {
+		record.domains = append(record.domains, domain.Domain())
+	}
+
+	// keep record
+	t.mutex.Lock()
+	t.forgetRecord(tenancyDb.GetID())
+	t.records[tenancyDb.GetID()] = record
+	t.idsByPath[tenancyDb.Path()] = tenancyDb.GetID()
+	for _, domain := range record.domains {
+		t.idsByDomain[domain] = tenancyDb.GetID()
+	}
+	t.mutex.Unlock()
+
+	return nil
+}
+
+// Data of known tenancies that are not loaded, always empty if tenancies are not loaded lazily.
+func (t *TenancyManager) unloadedTenancies() []*multitenancy.TenancyDb {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var tenancies []*multitenancy.TenancyDb
+	for id, record := range t.records {
+		_, loaded := t.tenanciesById[id]
+		if !loaded {
+			tenancies = append(tenancies, record.data)
+		}
+	}
+	return tenancies
+}
+
+// Must be called under locked mutex.
+func (t *TenancyManager) forgetRecord(id string) {
+	record, ok := t.records[id]
+	if ok {
+		delete(t.records, id)
+		delete(t.idsByPath, record.data.Path())
+		for _, domain := range record.domains {
+			delete(t.idsByDomain, domain)
+		}
+	}
+}
+
+func (t *TenancyManager) forgetTenancy(id string) {
+	t.mutex.Lock()
+	t.forgetRecord(id)
+	t.mutex.Unlock()
+}
+
+func (t *TenancyManager) refreshTenancyRecord(ctx op_context.Context, id string, reload bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyManager.refreshTenancyRecord", logger.Fields{"tenancy": id})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// load from database
+	tenancyItem, err := t.Controller.Find(ctx, id)
+	if err != nil {
+		c.SetMessage("failed to find tenancy")
+		return err
+	}
+	err = t.indexTenancy(ctx, &tenancyItem.TenancyDb)
+	if err != nil {
+		c.SetMessage("failed to index tenancy")
+		return err
+	}
+
+	// reload tenancy that was loaded before
+	if reload {
+		_, err = t.LoadTenancyFromData(ctx, &tenancyItem.TenancyDb)
+		if err != nil {
+			return err
+		}
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyManager) lazyLoadTenancy(id string) (multitenancy.Tenancy, error) {
+
+	// load tenancies one by one
+	t.loadMutex.Lock()
+	defer t.loadMutex.Unlock()
+
+	// check if tenancy was loaded while waiting
+	t.mutex.Lock()
+	tenancy, loaded := t.tenanciesById[id]
+	record, known := t.records[id]
+	if loaded {
+		t.touchTenancy(id)
+	}
+	t.mutex.Unlock()
+	if loaded {
+		return tenancy, nil
+	}
+	if !known {
+		return nil, errors.New("unknown tenancy")
+	}
+
+	// load tenancy
+	ctx := default_op_context.NewBackgroundContext(t.app, "TenancyManager.LazyLoad")
+	defer ctx.Close()
+	tenancy, err := t.LoadTenancyFromData(ctx, record.data)
+	if err != nil {
+		return nil, err
+	}
+	if tenancy == nil {
+		return nil, errors.New("tenancy can not be loaded")
+	}
+	return tenancy, nil
+}
+
+func (t *TenancyManager) evictTenancy(ctx op_context.Context, id string, reason string) {
+	if t.UnloadTenancy(id) {
+		t.mutex.Lock()
+		t.stats.Evictions++
+		t.mutex.Unlock()
+		ctx.Logger().Debug("evicted tenancy", logger.Fields{"tenancy": id, "reason": reason})
+	}
+}
+
+// Evict least recently used tenancies if number of loaded tenancies exceeds the limit.
+func (t *TenancyManager) evictExcessTenancies(ctx op_context.Context) {
+
+	if t.MAX_LOADED_TENANCIES == 0 {
+		return
+	}
+
+	for {
+		t.mutex.Lock()
+		var id string
+		if t.lru.Len() > t.MAX_LOADED_TENANCIES {
+			id = t.lru.Back().Value.(*lruItem).id
+		}
+		t.mutex.Unlock()
+		if id == "" {
+			return
+		}
+		t.evictTenancy(ctx, id, "limit")
+	}
+}
+
+// Evict tenancies that were not used longer than idle timeout.
+func (t *TenancyManager) EvictIdleTenancies(ctx op_context.Context) {
+
+	if t.IDLE_TIMEOUT == 0 {
+		return
+	}
+
+	deadline := time.Now().Add(-time.Duration(t.IDLE_TIMEOUT) * time.Second)
+	for {
+		t.mutex.Lock()
+		var id string
+		back := t.lru.Back()
+		if back != nil && back.Value.(*lruItem).lastUsed.Before(deadline) {
+			id = back.Value.(*lruItem).id
+		}
+		t.mutex.Unlock()
+		if id == "" {
+			return
+		}
+		t.evictTenancy(ctx, id, "idle")
+	}
+}
+
+func (t *TenancyManager) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(t.app, "TenancyManager.Eviction")
+	defer ctx.Close()
+	t.EvictIdleTenancies(ctx)
+}
+
+func (t *TenancyController) Stats(ctx op_context.Context) (*multitenancy.TenancyStats, error) {
+	stats := t.Manager.Stats()
+	return &stats, nil
+}
@@ -0,0 +1,625 @@
+package tenancy_manager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+func (t *TenancyController) FindBackup(ctx op_context.Context, jobId string) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.FindBackup", logger.Fields{"job": jobId})
+	defer ctx.TraceOutMethod()
+
+	// find
+	job := &multitenancy.TenancyBackup{}
+	found, err := t.CRUD.Read(ctx, db.Fields{"id": jobId}, job)
+	if err != nil {
+		c.SetMessage("failed to find backup job")
+		return nil, c.SetError(err)
+	}
+	if !found {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyBackupNotFound)
+		return nil, c.SetError(errors.New("backup job not found"))
+	}
+
+	// done
+	return job, nil
+}
+
+func (t *TenancyController) ArchivePath(archive string) string {
+	return filepath.Join(t.Manager.BACKUP_PATH, archive)
+}
+
+func (t *TenancyController) updateBackup(ctx op_context.Context, job *multitenancy.TenancyBackup, fields db.Fields) error {
+	return t.CRUD.Update(ctx, job, fields)
+}
+
+func (t *TenancyController) createBackup(ctx op_context.Context, operation string, tenancyId string, archive string) (*multitenancy.TenancyBackup, error) {
+	job := &multitenancy.TenancyBackup{}
+	job.InitObject()
+	job.OPERATION = operation
+	job.TENANCY_ID = tenancyId
+	job.ARCHIVE = archive
+	job.STATUS = multitenancy.BackupStatusRunning
+	err := t.CRUD.Create(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Run backup or restore job in background and save its final status.
+func (t *TenancyController) runBackup(job *multitenancy.TenancyBackup, handler func(ctx op_context.Context) error) {
+
+	t.Manager.backupJobs.Add(1)
+	go func() {
+		defer t.Manager.backupJobs.Done()
+
+		ctx := default_op_context.NewBackgroundContext(t.Manager.app, utils.ConcatStrings("TenancyController.", job.OPERATION))
+		defer ctx.Close()
+
+		err := handler(ctx)
+		fields := db.Fields{}
+		if err != nil {
+			job.STATUS = multitenancy.BackupStatusFailed
+			job.LAST_ERROR = err.Error()
+		} else {
+			job.STATUS = multitenancy.BackupStatusDone
+			job.CURRENT_TABLE = ""
+			fields["current_table"] = job.CURRENT_TABLE
+		}
+		job.FINISHED_AT = time.Now()
+		fields["status"] = job.STATUS
+		fields["last_error"] = job.LAST_ERROR
+		fields["finished_at"] = job.FINISHED_AT
+		uErr := t.updateBackup(ctx, job, fields)
+		if uErr != nil {
+			ctx.Logger().Error("failed to save status of tenancy backup job", uErr, logger.Fields{"job": job.GetID()})
+		}
+	}()
+}
+
+func (t *TenancyController) Backup(ctx op_context.Context, id string, idIsDisplay ...bool) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.Backup", logger.Fields{"tenancy": id})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, err
+	}
+
+	// prepare backup directory
+	err = os.MkdirAll(t.Manager.BACKUP_PATH, 0700)
+	if err != nil {
+		c.SetMessage("failed to create backup directory")
+		return nil, err
+	}
+
+	// create job
+	archive := utils.ConcatStrings(tenancy.CustomerDisplay(), "_", tenancy.Role(), "_", time.Now().Format("20060102150405"), multitenancy.TenancyArchiveExtension)
+	job, err := t.createBackup(ctx, multitenancy.BackupOperationBackup, tenancy.GetID(), archive)
+	if err != nil {
+		c.SetMessage("failed to save backup job in database")
+		return nil, err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpBackup, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Archive: archive})
+
+	// run job
+	t.runBackup(job, func(ctx op_context.Context) error {
+
+		// write to temporary file and rename it when archive is complete
+		path := t.ArchivePath(archive)
+		tmpPath := utils.ConcatStrings(path, ".tmp")
+		file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		err = t.ExportTenancy(ctx, tenancy, file, job)
+		cErr := file.Close()
+		if err == nil {
+			err = cErr
+		}
+		if err == nil {
+			err = os.Rename(tmpPath, path)
+		}
+		if err != nil {
+			os.Remove(tmpPath)
+		}
+		return err
+	})
+
+	// done
+	return job, nil
+}
+
+// Export tenancy metadata and rows of all tenancy tables to archive.
+func (t *TenancyController) ExportTenancy(ctx op_context.Context, tenancy *multitenancy.TenancyItem, w io.Writer, job *multitenancy.TenancyBackup) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.ExportTenancy", logger.Fields{"tenancy": tenancy.GetID()})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// connect to tenancy database
+	source := NewTenancy(t.Manager)
+	skip, err := source.Init(ctx, &tenancy.TenancyDb)
+	if err != nil {
+		c.SetMessage("failed to connect to tenancy database")
+		return err
+	}
+	if skip {
+		err = errors.New("pool of tenancy database is not active")
+		return err
+	}
+	defer source.Db().Close()
+
+	// write header
+	domains, err := t.ListDomains(ctx, tenancy.GetID())
+	if err != nil {
+		c.SetMessage("failed to list tenancy domains")
+		return err
+	}
+	models := t.archiveModels()
+	header := &multitenancy.TenancyArchiveHeader{Tenancy: tenancy}
+	for _, domain := range domains {
+		header.Domains = append(header.Domains, domain.Domain())
+	}
+	for _, model := range models {
+		header.Tables = append(header.Tables, tableName(model))
+	}
+	archive, err := multitenancy.NewTenancyArchiveWriter(w, header)
+	if err != nil {
+		c.SetMessage("failed to write archive header")
+		return err
+	}
+
+	// write tables
+	for _, model := range models {
+		err = t.exportTable(ctx, source, model, archive, job)
+		if err != nil {
+			return err
+		}
+	}
+
+	// finish archive
+	err = archive.Close()
+	if err != nil {
+		c.SetMessage("failed to finish archive")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+// Tenancy meta is not archived because target database gets meta of target tenancy.
+func (t *TenancyController) archiveModels() []interface{} {
+	models := append([]interface{}{}, t.Manager.tenancyDbModels.DbModels...)
+	return append(models, t.Manager.tenancyDbModels.PartitionedDbModels...)
+}
+
+func (t *TenancyController) isPartitioned(model interface{}) bool {
+	for _, partitioned := range t.Manager.tenancyDbModels.PartitionedDbModels {
+		if reflect.TypeOf(partitioned) == reflect.TypeOf(model) {
+			return true
+		}
+	}
+	return false
+}
+
+func newModel(model interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(model).Elem()).Interface()
+}
+
+func (t *TenancyController) exportTable(ctx op_context.Context, source *TenancyBase, model interface{}, archive *multitenancy.TenancyArchiveWriter, job *multitenancy.TenancyBackup) error {
+
+	// setup
+	var err error
+	table := tableName(model)
+	c := ctx.TraceInMethod("TenancyController.exportTable", logger.Fields{"table": table})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	job.CURRENT_TABLE = table
+	err = t.updateBackup(ctx, job, db.Fields{"current_table": job.CURRENT_TABLE})
+	if err != nil {
+		c.SetMessage("failed to save progress of backup job")
+		return err
+	}
+
+	// stream rows to archive
+	cursor, err := source.Db().AllRows(ctx, newModel(model))
+	if err != nil {
+		c.SetMessage("failed to read table")
+		return err
+	}
+	defer cursor.Close(ctx)
+	for {
+		var next bool
+		next, err = cursor.Next(ctx)
+		if err != nil {
+			c.SetMessage("failed to read next row")
+			return err
+		}
+		if !next {
+			break
+		}
+		row := newModel(model)
+		err = cursor.Scan(ctx, row)
+		if err != nil {
+			c.SetMessage("failed to scan row")
+			return err
+		}
+		err = archive.WriteRow(table, row)
+		if err != nil {
+			c.SetMessage("failed to write row to archive")
+			return err
+		}
+	}
+
+	// save progress
+	job.ROWS = archive.Rows()
+	err = t.updateBackup(ctx, job, db.Fields{"rows": job.ROWS})
+	if err != nil {
+		c.SetMessage("failed to save progress of backup job")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) Restore(ctx op_context.Context, options *multitenancy.TenancyRestoreOptions) (*multitenancy.TenancyBackup, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.Restore", logger.Fields{"archive": options.ARCHIVE, "target": options.TARGET})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check archive
+	err = multitenancy.CheckArchiveName(options.ARCHIVE)
+	if err != nil {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+		return nil, err
+	}
+	path := t.ArchivePath(options.ARCHIVE)
+	_, err = os.Stat(path)
+	if err != nil {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveNotFound)
+		return nil, err
+	}
+
+	// find target tenancy
+	tenancyId := ""
+	if options.TARGET != "" {
+		var target *multitenancy.TenancyItem
+		target, err = t.Find(ctx, options.TARGET, strings.Contains(options.TARGET, "/"))
+		if err != nil {
+			return nil, err
+		}
+		tenancyId = target.GetID()
+	}
+
+	// create job
+	job, err := t.createBackup(ctx, multitenancy.BackupOperationRestore, tenancyId, options.ARCHIVE)
+	if err != nil {
+		c.SetMessage("failed to save restore job in database")
+		return nil, err
+	}
+
+	// run job
+	t.runBackup(job, func(ctx op_context.Context) error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = t.ImportTenancy(ctx, file, options, job)
+		return err
+	})
+
+	// done
+	return job, nil
+}
+
+// Import tenancy archive into new or existing tenancy.
+func (t *TenancyController) ImportTenancy(ctx op_context.Context, r io.Reader, options *multitenancy.TenancyRestoreOptions, job *multitenancy.TenancyBackup) (*multitenancy.TenancyItem, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.ImportTenancy", logger.Fields{"archive": options.ARCHIVE, "target": options.TARGET})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// open archive
+	archive, err := multitenancy.NewTenancyArchiveReader(r)
+	if err != nil {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+		return nil, err
+	}
+	defer archive.Close()
+	models := make(map[string]interface{})
+	for _, model := range t.archiveModels() {
+		models[tableName(model)] = model
+	}
+	for _, table := range archive.Header.Tables {
+		if _, ok := models[table]; !ok {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+			err = fmt.Errorf("unknown table %s in archive", table)
+			return nil, err
+		}
+	}
+
+	// find or create target tenancy
+	var tenancy *multitenancy.TenancyItem
+	if options.TARGET != "" {
+		tenancy, err = t.Find(ctx, options.TARGET, strings.Contains(options.TARGET, "/"))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		tenancy, err = t.restoreTenancy(ctx, &archive.Header, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if job.TENANCY_ID != tenancy.GetID() {
+		job.TENANCY_ID = tenancy.GetID()
+		err = t.updateBackup(ctx, job, db.Fields{"tenancy_id": job.TENANCY_ID})
+		if err != nil {
+			c.SetMessage("failed to save progress of restore job")
+			return nil, err
+		}
+	}
+
+	// connect to tenancy database
+	target := NewTenancy(t.Manager)
+	skip, err := target.Init(ctx, &tenancy.TenancyDb)
+	if err != nil {
+		c.SetMessage("failed to connect to tenancy database")
+		return nil, err
+	}
+	if skip {
+		err = errors.New("pool of tenancy database is not active")
+		return nil, err
+	}
+	defer target.Db().Close()
+
+	// tenancy must not contain data, new tenancy can reuse existing database
+	for _, table := range archive.Header.Tables {
+		rows := newModelSlice(models[table])
+		filter := db.NewFilter()
+		filter.Limit = 1
+		_, err = target.Db().FindWithFilter(ctx, filter, rows.Interface())
+		if err != nil {
+			c.SetMessage("failed to check tenancy table")
+			return nil, err
+		}
+		if rows.Elem().Len() != 0 {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyRestoreNotEmpty)
+			err = fmt.Errorf("table %s of tenancy is not empty", table)
+			return nil, err
+		}
+	}
+
+	// restore rows in batches
+	var batch reflect.Value
+	table := ""
+	flush := func() error {
+		if !batch.IsValid() || batch.Elem().Len() == 0 {
+			return nil
+		}
+		err := t.importBatch(ctx, target, models[table], batch)
+		if err != nil {
+			return err
+		}
+		job.ROWS += int64(batch.Elem().Len())
+		batch = reflect.Value{}
+		return t.updateBackup(ctx, job, db.Fields{"rows": job.ROWS, "current_table": job.CURRENT_TABLE})
+	}
+	for {
+		var record *multitenancy.TenancyArchiveRecord
+		record, err = archive.Next()
+		if err != nil {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+			return nil, err
+		}
+		if record == nil {
+			break
+		}
+		model, ok := models[record.Table]
+		if !ok {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+			err = fmt.Errorf("unknown table %s in archive", record.Table)
+			return nil, err
+		}
+		if record.Table != table || (batch.IsValid() && batch.Elem().Len() >= t.Manager.MOVE_BATCH_SIZE) {
+			err = flush()
+			if err != nil {
+				return nil, err
+			}
+			table = record.Table
+			job.CURRENT_TABLE = table
+		}
+		if !batch.IsValid() {
+			batch = newModelSlice(model)
+		}
+		row := newModel(model)
+		err = multitenancy.DecodeArchiveRow(record.Row, row)
+		if err != nil {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyArchiveInvalid)
+			return nil, err
+		}
+		// remap rows to target tenancy
+		scoped, ok := row.(multitenancy.TenancyScoped)
+		if ok && scoped.TenancyScope() != "" {
+			scoped.SetTenancyScope(tenancy.GetID())
+		}
+		batch.Elem().Set(reflect.Append(batch.Elem(), reflect.ValueOf(row)))
+	}
+	err = flush()
+	if err != nil {
+		return nil, err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpRestore, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Archive: options.ARCHIVE})
+
+	// done
+	return tenancy, nil
+}
+
+func (t *TenancyController) importBatch(ctx op_context.Context, target *TenancyBase, model interface{}, batch reflect.Value) error {
+
+	// create partitions for rows
+	if t.isPartitioned(model) {
+		rows := batch.Elem()
+		months := make(map[utils.Month]bool)
+		for i := 0; i < rows.Len(); i++ {
+			monthData, ok := rows.Index(i).Interface().(utils.MonthData)
+			if ok {
+				months[monthData.GetMonth()] = true
+			}
+		}
+		err := target.Db().CreateMonthPartitions(ctx, utils.AllMapKeys(months), []interface{}{model})
+		if err != nil {
+			return err
+		}
+	}
+
+	// write rows
+	return target.Db().Create(ctx, batch.Interface())
+}
+
+// Create new tenancy from archived tenancy data overriden by restore options.
+func (t *TenancyController) restoreTenancy(ctx op_context.Context, header *multitenancy.TenancyArchiveHeader, options *multitenancy.TenancyRestoreOptions) (*multitenancy.TenancyItem, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.restoreTenancy")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// fill tenancy data
+	data := header.Tenancy.TenancyData
+	data.DBNAME = options.DBNAME
+	data.SCHEMA = ""
+	if options.CUSTOMER_ID != "" {
+		data.CUSTOMER_ID = options.CUSTOMER_ID
+	}
+	if options.ROLE != "" {
+		data.ROLE = options.ROLE
+	}
+	if options.PATH != "" {
+		data.PATH = options.PATH
+	}
+	if options.POOL_ID != "" {
+		data.POOL_ID = options.POOL_ID
+	}
+
+	// check if tenancy with archived ID exists
+	id := ""
+	if options.KEEP_ID {
+		id = header.Tenancy.GetID()
+		var found bool
+		found, err = t.CRUD.Read(ctx, db.Fields{"id": id}, &multitenancy.TenancyDb{})
+		if err != nil {
+			c.SetMessage("failed to check if tenancy exists")
+			return nil, err
+		}
+		if found {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyConflictId)
+			err = errors.New("tenancy with such ID already exists")
+			return nil, err
+		}
+	}
+
+	// create tenancy, template is not applied because archive already contains data of tenancy
+	tenancy, err := t.add(ctx, &data, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if header.Tenancy.TEMPLATE_VERSION != 0 {
+		err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"template_version": header.Tenancy.TEMPLATE_VERSION})
+		if err != nil {
+			c.SetMessage("failed to save template version of tenancy")
+			return nil, err
+		}
+	}
+
+	// bind archived domains that are not bound to other tenancies
+	for _, domain := range header.Domains {
+		var existing *multitenancy.TenancyDomain
+		existing, err = t.findDomain(ctx, domain)
+		if err != nil {
+			c.SetMessage("failed to find domain")
+			return nil, err
+		}
+		if existing != nil {
+			c.Logger().Warn("skipping domain bound to other tenancy", logger.Fields{"domain": domain, "other_tenancy": existing.TENANCY_ID})
+			continue
+		}
+		err = t.AddDomain(ctx, tenancy.GetID(), domain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// done
+	return tenancy, nil
+}
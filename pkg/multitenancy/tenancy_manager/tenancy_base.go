@@ -2,6 +2,7 @@ package tenancy_manager
 
 import (
 	"github.com/evgeniums/go-backend-helpers/pkg/cache"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
 	"github.com/evgeniums/go-backend-helpers/pkg/customer"
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
@@ -16,10 +17,12 @@ type TenancyBaseData struct {
 	multitenancy.TenancyDb
 
 	db.WithDBBase
+	config.WithCfgBase
 	Cache          cache.Cache
 	Pool           pool.Pool
 	Customer       *customer.Customer
 	TenancyManager *TenancyManager
+	DomainNames    []string
 }
 
 type TenancyBase struct {
@@ -40,6 +43,10 @@ func (t *TenancyBase) Pool() pool.Pool {
 	return t.TenancyBaseData.Pool
 }
 
+func (t *TenancyBase) Domains() []string {
+	return t.DomainNames
+}
+
 func (t *TenancyBase) Cache() cache.Cache {
 	return t.TenancyBaseData.Cache
 }
@@ -105,18 +112,8 @@ func (t *TenancyBase) Init(ctx op_context.Context, data *multitenancy.TenancyDb)
 	return false, nil
 }
 
-func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) error {
-
-	// setup
-	var err error
-	c := ctx.TraceInMethod("TenancyBase.ConnectDatabase", logger.Fields{"customer": t.CUSTOMER_ID, "role": t.ROLE})
-	onExit := func() {
-		if err != nil {
-			c.SetError(err)
-		}
-		ctx.TraceOutMethod()
-	}
-	defer onExit()
+// Find service for tenancy database in tenancy's pool and parse its configuration.
+func (t *TenancyBase) dbService(ctx op_context.Context) (*pool.PoolServiceBinding, *db.DBConfig, error) {
 
 	// find service for database role
 	dbService, err := t.Pool().Service(multitenancy.TENANCY_DATABASE_ROLE)
@@ -124,14 +121,12 @@ func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) err
 		genErr := generic_error.NewFromOriginal(pool.ErrorCodeNoServiceWithRole, "Pool does not include service for tenancy database", err)
 		genErr.SetDetails(multitenancy.TENANCY_DATABASE_ROLE)
 		ctx.SetGenericError(genErr)
-		err = genErr
-		return err
+		return nil, nil, genErr
 	}
 	if !dbService.IsActive() {
 		genErr := generic_error.New(pool.ErrorCodeServiceNotActive, "Service for tenancy database in the pool is not active.")
 		ctx.SetGenericError(genErr)
-		err = genErr
-		return err
+		return nil, nil, genErr
 	}
 
 	// parse db config
@@ -140,7 +135,39 @@ func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) err
 		genErr := generic_error.NewFromOriginal(pool.ErrorCodeInvalidServiceConfiguration, "Invalid configuration of service for tenancy database", err)
 		genErr.SetDetails(dbService.ServiceName)
 		ctx.SetGenericError(genErr)
-		err = genErr
+		return nil, nil, genErr
+	}
+
+	return dbService, dbConfig, nil
+}
+
+func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyBase.ConnectDatabase", logger.Fields{"customer": t.CUSTOMER_ID, "role": t.ROLE})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check if models can be used in shared database
+	if t.IsShared() {
+		models := t.TenancyManager.tenancyDbModels
+		err = multitenancy.CheckTenancyScopedModels(utils.ConcatSlices(models.DbModels, models.PartitionedDbModels))
+		if err != nil {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyDbInitializationFailed)
+			c.SetMessage("invalid models for shared tenancy database")
+			return err
+		}
+	}
+
+	// find config of database service
+	dbService, dbConfig, err := t.dbService(ctx)
+	if err != nil {
 		return err
 	}
 
@@ -171,10 +198,49 @@ func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) err
 		}
 	}
 
+	// create schema in tenancy database
+	if utils.OptionalArg(false, newDb...) && t.IsSchema() {
+
+		// connect to tenancy database without schema
+		schemaConfig := *dbConfig
+		schemaConfig.DB_NAME = t.DBNAME
+		database := ctx.App().Db().Clone()
+		err = database.InitWithConfig(ctx, ctx.App().Validator(), &schemaConfig)
+		if err != nil {
+			genErr := generic_error.NewFromOriginal(pool.ErrorCodeServiceInitializationFailed, "Failed to connect to tenancy database", err)
+			genErr.SetDetails(dbService.ServiceName)
+			ctx.SetGenericError(genErr)
+			err = genErr
+			return err
+		}
+
+		// create new schema
+		err = database.CreateSchema(ctx, t.SCHEMA)
+		database.Close()
+		if err != nil {
+			genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeCreateTenancyDatabaseFailed, "Failed to create tenancy schema", err)
+			genErr.SetDetails(t.SCHEMA)
+			ctx.SetGenericError(genErr)
+			err = genErr
+			return err
+		}
+	}
+
 	// create and init database connection
 	dbConfig.DB_NAME = t.DBNAME
-	database := ctx.App().Db().Clone()
-	err = database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+	dbConfig.DB_MAX_OPEN_CONNS = t.TenancyManager.DB_MAX_OPEN_CONNS
+	dbConfig.DB_MAX_IDLE_CONNS = t.TenancyManager.DB_MAX_IDLE_CONNS
+	dbConfig.DB_CONN_MAX_IDLE_TIME = t.TenancyManager.DB_CONN_MAX_IDLE_TIME
+	if t.IsSchema() {
+		dbConfig.DB_SCHEMA = t.SCHEMA
+	}
+	var database db.DB
+	if t.IsShared() {
+		database, err = t.TenancyManager.SharedDatabase(ctx, t.PoolId(), dbConfig)
+	} else {
+		database = ctx.App().Db().Clone()
+		err = database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+	}
 	if err != nil {
 		genErr := generic_error.NewFromOriginal(pool.ErrorCodeServiceInitializationFailed, "Failed to connect to tenancy database", err)
 		genErr.SetDetails(dbService.ServiceName)
@@ -182,12 +248,93 @@ func (t *TenancyBase) ConnectDatabase(ctx op_context.Context, newDb ...bool) err
 		err = genErr
 		return err
 	}
+	if t.IsShared() {
+		database = multitenancy.NewTenancyScopedDB(database, t.GetID())
+	}
 	t.WithDBBase.Init(database)
 
 	// done
 	return nil
 }
 
+// Drop tenancy data: database of dedicated tenancy, schema of schema tenancy or rows of shared tenancy.
+func (t *TenancyBase) DropDatabase(ctx op_context.Context) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyBase.DropDatabase", logger.Fields{"dbname": t.DBNAME, "isolation": t.Isolation()})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// delete rows of shared tenancy
+	if t.IsShared() {
+		err = t.ConnectDatabase(ctx)
+		if err != nil {
+			return err
+		}
+		models := append([]interface{}{}, multitenancy.DbInternalModels()...)
+		models = append(models, t.TenancyManager.tenancyDbModels.DbModels...)
+		models = append(models, t.TenancyManager.tenancyDbModels.PartitionedDbModels...)
+		for _, model := range models {
+			if !multitenancy.IsTenancyScoped(model) {
+				continue
+			}
+			err = t.Db().DeleteByFields(ctx, db.Fields{multitenancy.TenancyScopeField: t.GetID()}, model)
+			if err != nil {
+				c.SetMessage("failed to delete rows of shared tenancy")
+				return err
+			}
+		}
+		return nil
+	}
+
+	// find config of database service
+	_, dbConfig, err := t.dbService(ctx)
+	if err != nil {
+		return err
+	}
+
+	// drop schema in tenancy database
+	if t.IsSchema() {
+		dbConfig.DB_NAME = t.DBNAME
+		database := ctx.App().Db().Clone()
+		err = database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+		if err != nil {
+			c.SetMessage("failed to connect to tenancy database")
+			return err
+		}
+		err = database.DropSchema(ctx, t.SCHEMA)
+		database.Close()
+		if err != nil {
+			c.SetMessage("failed to drop tenancy schema")
+			return err
+		}
+		return nil
+	}
+
+	// drop database using master database
+	database := ctx.App().Db().Clone()
+	err = database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+	if err != nil {
+		c.SetMessage("failed to connect to master database")
+		return err
+	}
+	err = database.DropDatabase(ctx, t.DBNAME)
+	database.Close()
+	if err != nil {
+		c.SetMessage("failed to drop tenancy database")
+		return err
+	}
+
+	// done
+	return nil
+}
+
 func (t *TenancyBase) CustomerDisplay() string {
 	return t.Customer.Display()
 }
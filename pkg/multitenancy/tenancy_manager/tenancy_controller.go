@@ -130,20 +130,46 @@ func (t *TenancyController) PublishOp(tenancy *multitenancy.TenancyItem, op stri
 }
 
 func (t *TenancyController) Add(ctx op_context.Context, data *multitenancy.TenancyData) (*multitenancy.TenancyItem, error) {
+	return t.add(ctx, data, "", true)
+}
+
+// Create tenancy and save it in database. If withTemplate is set then template of tenancy is applied in the same transaction.
+func (t *TenancyController) add(ctx op_context.Context, data *multitenancy.TenancyData, id string, withTemplate bool) (*multitenancy.TenancyItem, error) {
 
 	// setup
 	c := ctx.TraceInMethod("TenancyController.Add", logger.Fields{"customer": data.CUSTOMER_ID, "role": data.ROLE})
 	defer ctx.TraceOutMethod()
 
+	// find template
+	var template *multitenancy.TenancyTemplate
+	var err error
+	if withTemplate && data.TEMPLATE != "" {
+		template, err = t.Manager.FindTemplate(ctx, c, data.TEMPLATE)
+		if err != nil {
+			return nil, c.SetError(err)
+		}
+		_, err = t.checkTemplate(ctx, template)
+		if err != nil {
+			return nil, c.SetError(err)
+		}
+	}
+
 	// create tenancy
-	tenancy, err := t.Manager.CreateTenancy(ctx, data)
+	tenancy, err := t.Manager.createTenancy(ctx, data, id)
 	if err != nil {
 		c.SetMessage("failed to create tenancy")
 		return nil, c.SetError(err)
 	}
 
 	// save tenancy in database
-	err = t.CRUD.Create(ctx, &tenancy.TenancyDb)
+	save := func() error {
+		return t.CRUD.Create(ctx, &tenancy.TenancyDb)
+	}
+	if template != nil {
+		err = t.applyTemplate(ctx, tenancy, template, save)
+	} else {
+		err = save()
+	}
 	if err != nil {
 		c.SetMessage("failed to save tenancy in database")
 		return nil, c.SetError(err)
@@ -279,6 +305,10 @@ func (t *TenancyController) Activate(ctx op_context.Context, id string, idIsDisp
 	if err != nil {
 		return c.SetError(err)
 	}
+	if tenancy.IsDeleted() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyDeleted)
+		return c.SetError(errors.New("tenancy is deleted"))
+	}
 
 	// update field
 	err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"active": true})
@@ -443,38 +473,3 @@ func (t *TenancyController) ChangePoolOrDb(ctx op_context.Context, id string, po
 	// done
 	return nil
 }
-
-func (t *TenancyController) Delete(ctx op_context.Context, id string, withDatabase bool, idIsDisplay ...bool) error {
-
-	// setup
-	c := ctx.TraceInMethod("TenancyController.Delete")
-	defer ctx.TraceOutMethod()
-
-	if withDatabase {
-		ctx.SetGenericError(generic_error.New(generic_error.ErrorCodeUnsupported, "Database can not be droped through administrator. Use raw database tools for database dropping."))
-		return c.SetError(errors.New("database can not be dropped"))
-	}
-
-	// find tenancy
-	tenancy, err := t.Find(ctx, id, idIsDisplay...)
-	if err != nil {
-		return c.SetError(err)
-	}
-
-	// delete tenancy
-	err = t.CRUD.Delete(ctx, &tenancy.TenancyDb)
-	if err != nil {
-		c.SetMessage("failed to delete tenancy")
-		return c.SetError(err)
-	}
-
-	// save oplog
-	t.OpLog(ctx, multitenancy.OpDelete, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
-		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay()})
-
-	// publish notification
-	t.PublishOp(tenancy, multitenancy.OpDelete)
-
-	// done
-	return nil
-}
@@ -0,0 +1,248 @@
+package tenancy_manager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+)
+
+func (t *TenancyController) Delete(ctx op_context.Context, id string, withDatabase bool, idIsDisplay ...bool) error {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.Delete")
+	defer ctx.TraceOutMethod()
+
+	if withDatabase && !t.Manager.DROP_DATABASES {
+		ctx.SetGenericError(generic_error.New(generic_error.ErrorCodeUnsupported, "Dropping of tenancy databases is disabled in configuration. Use raw database tools for database dropping."))
+		return c.SetError(errors.New("database can not be dropped"))
+	}
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return c.SetError(err)
+	}
+	if tenancy.IsDeleted() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyDeleted)
+		return c.SetError(errors.New("tenancy already deleted"))
+	}
+
+	// mark tenancy as deleted, tenancy domains are kept until purge
+	err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"deleted_at": time.Now(), "active": false, "purge_database": withDatabase})
+	if err != nil {
+		c.SetMessage("failed to update tenancy")
+		return c.SetError(err)
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpDelete, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), DbName: tenancy.DbName(), WithDatabase: withDatabase})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpDelete)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) Undelete(ctx op_context.Context, id string, idIsDisplay ...bool) error {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.Undelete")
+	defer ctx.TraceOutMethod()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return c.SetError(err)
+	}
+	if !tenancy.IsDeleted() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyNotDeleted)
+		return c.SetError(errors.New("tenancy is not deleted"))
+	}
+
+	// restore tenancy, it stays inactive until explicit activation
+	err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"deleted_at": time.Time{}, "purge_database": false})
+	if err != nil {
+		c.SetMessage("failed to update tenancy")
+		return c.SetError(err)
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpUndelete, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay()})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpUndelete)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) Purge(ctx op_context.Context, id string, idIsDisplay ...bool) error {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.Purge")
+	defer ctx.TraceOutMethod()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return c.SetError(err)
+	}
+	if !tenancy.IsDeleted() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyNotDeleted)
+		return c.SetError(errors.New("tenancy is not deleted"))
+	}
+
+	// purge tenancy
+	err = t.purgeTenancy(ctx, tenancy)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) purgeTenancy(ctx op_context.Context, tenancy *multitenancy.TenancyItem) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.purgeTenancy", logger.Fields{"tenancy": tenancy.GetID(), "dbname": tenancy.DbName()})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find pool
+	p, err := t.Manager.FindPool(ctx, c, tenancy.PoolId())
+	if err != nil {
+		return err
+	}
+
+	// drop tenancy database
+	if tenancy.IsPurgeDatabase() {
+		base := NewTenancy(t.Manager)
+		base.TenancyDb = tenancy.TenancyDb
+		base.TenancyBaseData.Pool = p
+		err = base.DropDatabase(ctx)
+		if base.Db() != nil && !base.IsShared() {
+			base.Db().Close()
+		}
+		if err != nil {
+			genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancyPurgeFailed, "Failed to drop tenancy database", err)
+			genErr.SetDetails(tenancy.DbName())
+			ctx.SetGenericError(genErr)
+			err = genErr
+			return err
+		}
+	}
+
+	// delete tenancy with its domains and settings in one transaction
+	err = ctx.ExecDbTransaction(func() error {
+
+		// delete tenancy
+		err := t.CRUD.Delete(ctx, &tenancy.TenancyDb)
+		if err != nil {
+			c.SetMessage("failed to delete tenancy")
+			return err
+		}
+
+		// delete tenancy domains
+		err = t.CRUD.DeleteByFields(ctx, db.Fields{"tenancy_id": tenancy.GetID()}, &multitenancy.TenancyDomain{})
+		if err != nil {
+			c.SetMessage("failed to delete tenancy domains")
+			return err
+		}
+
+		// delete tenancy settings
+		err = t.CRUD.DeleteByFields(ctx, db.Fields{"tenancy_id": tenancy.GetID()}, &multitenancy.TenancySetting{})
+		if err != nil {
+			c.SetMessage("failed to delete tenancy settings")
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpPurge, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Pool: p.Name(), DbName: tenancy.DbName(), WithDatabase: tenancy.IsPurgeDatabase()})
+
+	// done
+	return nil
+}
+
+// Purge tenancies whose grace period after deletion expired, returns number of purged tenancies.
+func (t *TenancyManager) PurgeDeletedTenancies(ctx op_context.Context) (int, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyManager.PurgeDeletedTenancies")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	controller, ok := t.Controller.(*TenancyController)
+	if !ok {
+		err = errors.New("purging is not supported by tenancy controller")
+		return 0, err
+	}
+
+	// find expired tenancies
+	expired := time.Now().Add(-time.Duration(t.DELETION_GRACE_PERIOD) * time.Second)
+	filter := db.NewFilter()
+	filter.Intervals = map[string]*db.Interval{"deleted_at": {From: time.Time{}, FromOpen: true, To: expired}}
+	tenancies, _, err := controller.List(ctx, filter)
+	if err != nil {
+		c.SetMessage("failed to find deleted tenancies")
+		return 0, err
+	}
+
+	// purge each tenancy, failed tenancies are retried next time
+	count := 0
+	for _, tenancy := range tenancies {
+		if !tenancy.IsDeleted() || tenancy.DELETED_AT.After(expired) {
+			continue
+		}
+		pErr := controller.purgeTenancy(ctx, tenancy)
+		if pErr != nil {
+			c.Logger().Error("failed to purge tenancy", pErr, logger.Fields{"tenancy": tenancy.GetID()})
+			ctx.ClearError()
+			continue
+		}
+		count++
+	}
+
+	// done
+	return count, nil
+}
+
+type tenancyPurger struct {
+	background_worker.JobRunnerBase
+	manager *TenancyManager
+}
+
+func (p *tenancyPurger) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(p.manager.app, "TenancyManager.Purge")
+	defer ctx.Close()
+	p.manager.PurgeDeletedTenancies(ctx)
+}
@@ -0,0 +1,153 @@
+package tenancy_manager
+
+import (
+	"errors"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyController) findDomain(ctx op_context.Context, domain string) (*multitenancy.TenancyDomain, error) {
+	tenancyDomain := &multitenancy.TenancyDomain{}
+	found, err := t.CRUD.Read(ctx, db.Fields{"domain": domain}, tenancyDomain)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return tenancyDomain, nil
+}
+
+func (t *TenancyController) AddDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.AddDomain", logger.Fields{"domain": domain})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return err
+	}
+
+	// check if domain is already bound to some tenancy
+	domain = multitenancy.NormalizeDomain(domain)
+	existing, err := t.findDomain(ctx, domain)
+	if err != nil {
+		c.SetMessage("failed to find domain")
+		return err
+	}
+	if existing != nil {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyConflictDomain)
+		err = errors.New("domain is already bound to tenancy")
+		return err
+	}
+
+	// save domain
+	tenancyDomain := &multitenancy.TenancyDomain{}
+	tenancyDomain.InitObject()
+	tenancyDomain.TENANCY_ID = tenancy.GetID()
+	tenancyDomain.DOMAIN = domain
+	err = t.CRUD.Create(ctx, tenancyDomain)
+	if err != nil {
+		c.SetMessage("failed to save tenancy domain")
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpAddDomain, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Domain: domain})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpAddDomain)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) DeleteDomain(ctx op_context.Context, id string, domain string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.DeleteDomain", logger.Fields{"domain": domain})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return err
+	}
+
+	// find domain
+	domain = multitenancy.NormalizeDomain(domain)
+	tenancyDomain, err := t.findDomain(ctx, domain)
+	if err != nil {
+		c.SetMessage("failed to find domain")
+		return err
+	}
+	if tenancyDomain == nil || tenancyDomain.TENANCY_ID != tenancy.GetID() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyDomainNotFound)
+		err = errors.New("domain is not bound to tenancy")
+		return err
+	}
+
+	// delete domain
+	err = t.CRUD.Delete(ctx, tenancyDomain)
+	if err != nil {
+		c.SetMessage("failed to delete tenancy domain")
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpDeleteDomain, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Domain: domain})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpDeleteDomain)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) ListDomains(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*multitenancy.TenancyDomain, error) {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.ListDomains")
+	defer ctx.TraceOutMethod()
+
+	// adjust ID
+	id, _, err := TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, c.SetError(err)
+	}
+
+	// list domains
+	filter := db.NewFilter()
+	filter.AddField("tenancy_id", id)
+	filter.SetSorting("domain")
+	var domains []*multitenancy.TenancyDomain
+	_, err = t.CRUD.List(ctx, filter, &domains)
+	if err != nil {
+		c.SetMessage("failed to list tenancy domains")
+		return nil, c.SetError(err)
+	}
+
+	// done
+	return domains, nil
+}
@@ -1,9 +1,12 @@
 package tenancy_manager
 
 import (
+	"container/list"
 	"errors"
 	"sync"
 
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
 	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
 	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/customer"
@@ -12,6 +15,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/pool"
 	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pool_pubsub"
 	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_subscriber"
@@ -28,12 +32,24 @@ func (t *TenancyNotificationHandler) Handle(ctx op_context.Context, msg *multite
 	c := ctx.TraceInMethod("TenancyNotificationHandler.Handle")
 	defer ctx.TraceOutMethod()
 
-	t.manager.UnloadTenancy(msg.Tenancy)
-	if msg.Operation != multitenancy.OpDelete {
-		_, err := t.manager.LoadTenancy(ctx, msg.Tenancy)
+	loaded := t.manager.UnloadTenancy(msg.Tenancy)
+	if msg.Operation == multitenancy.OpDelete {
+		t.manager.forgetTenancy(msg.Tenancy)
+		return nil
+	}
+
+	// in lazy mode only refresh index of tenancies, tenancy is reloaded only if it was loaded
+	if t.manager.LAZY_LOADING {
+		err := t.manager.refreshTenancyRecord(ctx, msg.Tenancy, loaded)
 		if err != nil {
 			return c.SetError(err)
 		}
+		return nil
+	}
+
+	_, err := t.manager.LoadTenancy(ctx, msg.Tenancy)
+	if err != nil {
+		return c.SetError(err)
 	}
 
 	return nil
@@ -42,6 +58,37 @@ func (t *TenancyNotificationHandler) Handle(ctx op_context.Context, msg *multite
 type TenancyManagerConfig struct {
 	MULTITENANCY bool
 	DB_PREFIX    string `validate:"required,alphanum" vmessage:"Invalid prefix for names of databases" default:"tenancy"`
+
+	MOVE_BATCH_SIZE int `validate:"gt=0" vmessage:"Invalid size of batch for moving tenancy data" default:"100"`
+
+	// Directory where tenancy archives are created and restored from.
+	BACKUP_PATH string `default:"backups"`
+
+	// Directory where tenancy templates are looked up as <name>.yaml, <name>.yml or <name>.json files.
+	TEMPLATES_PATH string `default:"templates"`
+
+	// Deleted tenancies can be restored during grace period in seconds, after that they are purged in background.
+	// Purging is disabled if PURGE_PERIOD is zero. Tenancy databases are dropped on purge only if DROP_DATABASES is set.
+	DELETION_GRACE_PERIOD int `validate:"gte=0" vmessage:"Invalid grace period of tenancy deletion" default:"2592000"`
+	PURGE_PERIOD          int `validate:"gte=0" vmessage:"Invalid period of purging deleted tenancies"`
+	DROP_DATABASES        bool
+
+	// In lazy mode tenancies are loaded on first use and evicted when not used.
+	LAZY_LOADING         bool
+	MAX_LOADED_TENANCIES int `validate:"gte=0" vmessage:"Invalid max number of loaded tenancies"`
+	IDLE_TIMEOUT         int `validate:"gte=0" vmessage:"Invalid idle timeout of tenancies"`
+	EVICTION_PERIOD      int `validate:"gt=0" vmessage:"Invalid period of tenancies eviction" default:"60"`
+
+	// Limits of connection pool of each tenancy database.
+	DB_MAX_OPEN_CONNS     int `validate:"gte=0" vmessage:"Invalid max number of open connections to tenancy database"`
+	DB_MAX_IDLE_CONNS     int `validate:"gte=0" vmessage:"Invalid max number of idle connections to tenancy database"`
+	DB_CONN_MAX_IDLE_TIME int `validate:"gte=0" vmessage:"Invalid max idle time of connections to tenancy database"`
+
+	// Usage of tenancies is metered if METERING is set. Counted usage is flushed to database every METERING_FLUSH_PERIOD seconds.
+	// Storage size of tenancy databases is measured every STORAGE_METERING_PERIOD seconds, zero disables measuring.
+	METERING                bool
+	METERING_FLUSH_PERIOD   int `validate:"gt=0" vmessage:"Invalid period of flushing tenancies usage" default:"60"`
+	STORAGE_METERING_PERIOD int `validate:"gte=0" vmessage:"Invalid period of measuring tenancies storage" default:"3600"`
 }
 
 func (t *TenancyManagerConfig) IsMultiTenancy() bool {
@@ -50,9 +97,11 @@ func (t *TenancyManagerConfig) IsMultiTenancy() bool {
 
 type TenancyManager struct {
 	TenancyManagerConfig
+	background_worker.JobRunnerBase
 	mutex                      sync.Mutex
 	tenanciesById              map[string]multitenancy.Tenancy
 	tenanciesByPath            map[string]multitenancy.Tenancy
+	tenanciesByDomain          map[string]multitenancy.Tenancy
 	Controller                 multitenancy.TenancyController
 	Pools                      pool.PoolStore
 	Customers                  customer.CustomerController
@@ -64,6 +113,37 @@ type TenancyManager struct {
 	poolTopicsSubscriptions map[string]string
 
 	tenancyDbModels *multitenancy.TenancyDbModels
+
+	sharedDbMutex sync.Mutex
+	sharedDbs     map[string]db.DB
+
+	app         app_context.Context
+	loadMutex   sync.Mutex
+	records     map[string]*tenancyRecord
+	idsByPath   map[string]string
+	idsByDomain map[string]string
+	lru         *list.List
+	lruItems    map[string]*list.Element
+	usage       map[multitenancy.Tenancy]*tenancyUsage
+	stats       TenancyManagerStats
+	worker      *background_worker.BackgroundWorker
+	purger      *background_worker.BackgroundWorker
+
+	metering        *TenancyMetering
+	meteringFlusher *background_worker.BackgroundWorker
+	storageMeter    *background_worker.BackgroundWorker
+
+	backupJobs sync.WaitGroup
+
+	movesMutex sync.Mutex
+	moves      map[string]bool
+	moveJobs   sync.WaitGroup
+
+	templatesMutex sync.Mutex
+	templates      map[string]*multitenancy.TenancyTemplate
+	templateUsers  multitenancy.TemplateUsers
+
+	settingsSchema *multitenancy.TenancySettingsSchema
 }
 
 func NewTenancyManager(pools pool.PoolStore, poolPubsub pool_pubsub.PoolPubsub, tenancyDbModels *multitenancy.TenancyDbModels) *TenancyManager {
@@ -71,6 +151,15 @@ func NewTenancyManager(pools pool.PoolStore, poolPubsub pool_pubsub.PoolPubsub,
 	m.Pools = pools
 	m.tenanciesById = make(map[string]multitenancy.Tenancy)
 	m.tenanciesByPath = make(map[string]multitenancy.Tenancy)
+	m.tenanciesByDomain = make(map[string]multitenancy.Tenancy)
+	m.sharedDbs = make(map[string]db.DB)
+	m.templates = make(map[string]*multitenancy.TenancyTemplate)
+	m.settingsSchema = multitenancy.NewTenancySettingsSchema()
+	m.resetRecords()
+	m.lru = list.New()
+	m.lruItems = make(map[string]*list.Element)
+	m.usage = make(map[multitenancy.Tenancy]*tenancyUsage)
+	m.moves = make(map[string]bool)
 	m.tenancyDbModels = tenancyDbModels
 	m.PoolPubsub = poolPubsub
 	m.PubsubTopic = &multitenancy.PubsubTopic{}
@@ -100,6 +189,7 @@ func (t *TenancyManager) Init(ctx op_context.Context, configPath ...string) erro
 
 	// init manager
 	app := ctx.App()
+	t.app = app
 	cfg := app.Cfg()
 	log := app.Logger()
 	vld := app.Validator()
@@ -112,6 +202,14 @@ func (t *TenancyManager) Init(ctx op_context.Context, configPath ...string) erro
 	// get self pool
 	selfPool, selfPoolErr := t.Pools.SelfPool()
 
+	// register quotas before loading tenancies
+	if t.METERING {
+		t.metering = NewTenancyMetering(t)
+		for _, metric := range []string{multitenancy.MetricRequests, multitenancy.MetricSms, multitenancy.MetricStorage, multitenancy.MetricActiveUsers} {
+			t.settingsSchema.Register(&multitenancy.TenancySettingSpec{Key: multitenancy.QuotaKey(metric), Type: multitenancy.SettingInt, Rules: "gte=0"})
+		}
+	}
+
 	// subscribe to pubsub notifications
 	t.PubsubTopic.TopicBase = pubsub_subscriber.New(multitenancy.PubsubTopicName, multitenancy.NewPubsubNotification)
 	if selfPoolErr == nil && selfPool != nil {
@@ -140,22 +238,85 @@ func (t *TenancyManager) Init(ctx op_context.Context, configPath ...string) erro
 		return ctx.Logger().PushFatalStack("failed to load tenancies", err)
 	}
 
+	// run eviction of idle tenancies
+	if t.LAZY_LOADING && t.IDLE_TIMEOUT > 0 {
+		t.worker = background_worker.New(log, t, t.EVICTION_PERIOD)
+		t.worker.RunInBackground()
+	}
+
+	// run purging of deleted tenancies
+	if t.PURGE_PERIOD > 0 {
+		t.purger = background_worker.New(log, &tenancyPurger{manager: t}, t.PURGE_PERIOD)
+		t.purger.RunInBackground()
+	}
+
+	// run metering of tenancies usage
+	if t.metering != nil {
+		t.meteringFlusher = background_worker.New(log, &meteringFlusher{metering: t.metering}, t.METERING_FLUSH_PERIOD)
+		t.meteringFlusher.RunInBackground()
+		if t.STORAGE_METERING_PERIOD > 0 {
+			t.storageMeter = background_worker.New(log, &storageMeter{metering: t.metering}, t.STORAGE_METERING_PERIOD)
+			t.storageMeter.RunInBackground()
+		}
+	}
+
 	// done
 	return nil
 }
 
 func (t *TenancyManager) Close() {
 
+	if t.worker != nil {
+		t.worker.Stop()
+		t.worker = nil
+	}
+	if t.purger != nil {
+		t.purger.Stop()
+		t.purger = nil
+	}
+	if t.storageMeter != nil {
+		t.storageMeter.Stop()
+		t.storageMeter = nil
+	}
+	if t.meteringFlusher != nil {
+		t.meteringFlusher.Stop()
+		t.meteringFlusher = nil
+		ctx := default_op_context.NewBackgroundContext(t.app, "TenancyMetering.Flush")
+		t.metering.Flush(ctx)
+		ctx.Close()
+	}
+
+	t.backupJobs.Wait()
+	t.moveJobs.Wait()
+
 	t.mutex.Lock()
 
 	for _, tenancy := range t.tenanciesById {
 		tenancy.Db().Close()
 	}
+	for tenancy, usage := range t.usage {
+		if usage.unloaded {
+			tenancy.Db().Close()
+		}
+	}
+	t.usage = make(map[multitenancy.Tenancy]*tenancyUsage)
 	t.tenanciesById = make(map[string]multitenancy.Tenancy)
 	t.tenanciesByPath = make(map[string]multitenancy.Tenancy)
+	t.tenanciesByDomain = make(map[string]multitenancy.Tenancy)
+	t.resetRecords()
+	t.lru.Init()
+	t.lruItems = make(map[string]*list.Element)
+	t.stats.Loaded = 0
 
 	t.mutex.Unlock()
 
+	t.sharedDbMutex.Lock()
+	for _, database := range t.sharedDbs {
+		database.Close()
+	}
+	t.sharedDbs = make(map[string]db.DB)
+	t.sharedDbMutex.Unlock()
+
 	if t.PubsubTopic != nil {
 		t.PoolPubsub.UnsubscribePools(t.PubsubTopic.Name())
 		t.PoolPubsub.UnsubscribeSelfPool(t.PubsubTopic.Name())
@@ -164,9 +325,15 @@ func (t *TenancyManager) Close() {
 
 func (t *TenancyManager) Tenancy(id string) (multitenancy.Tenancy, error) {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
 	tenancy, ok := t.tenanciesById[id]
+	if ok {
+		t.touchTenancy(id)
+	}
+	t.mutex.Unlock()
 	if !ok {
+		if t.LAZY_LOADING {
+			return t.lazyLoadTenancy(id)
+		}
 		return nil, errors.New("unknown tenancy")
 	}
 	return tenancy, nil
@@ -174,14 +341,40 @@ func (t *TenancyManager) Tenancy(id string) (multitenancy.Tenancy, error) {
 
 func (t *TenancyManager) TenancyByPath(path string) (multitenancy.Tenancy, error) {
 	t.mutex.Lock()
-	defer t.mutex.Unlock()
 	tenancy, ok := t.tenanciesByPath[path]
+	if ok {
+		t.touchTenancy(tenancy.GetID())
+	}
+	id, known := t.idsByPath[path]
+	t.mutex.Unlock()
+	if !ok {
+		if t.LAZY_LOADING && known {
+			return t.lazyLoadTenancy(id)
+		}
+		return nil, errors.New("tenancy not found")
+	}
+	return tenancy, nil
+}
+
+func (t *TenancyManager) TenancyByDomain(domain string) (multitenancy.Tenancy, error) {
+	domain = multitenancy.NormalizeDomain(domain)
+	t.mutex.Lock()
+	tenancy, ok := t.tenanciesByDomain[domain]
+	if ok {
+		t.touchTenancy(tenancy.GetID())
+	}
+	id, known := t.idsByDomain[domain]
+	t.mutex.Unlock()
 	if !ok {
+		if t.LAZY_LOADING && known {
+			return t.lazyLoadTenancy(id)
+		}
 		return nil, errors.New("tenancy not found")
 	}
 	return tenancy, nil
 }
 
+// Get loaded tenancies. In lazy mode only tenancies that are currently loaded are returned, use TenancyIds() to get all tenancies.
 func (t *TenancyManager) Tenancies() []multitenancy.Tenancy {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -189,14 +382,66 @@ func (t *TenancyManager) Tenancies() []multitenancy.Tenancy {
 	return tenancies
 }
 
-func (t *TenancyManager) UnloadTenancy(id string) {
+func (t *TenancyManager) TenancyIds() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.LAZY_LOADING {
+		return utils.AllMapKeys(t.records)
+	}
+	return utils.AllMapKeys(t.tenanciesById)
+}
+
+// Unload tenancy and close its database, returns true if tenancy was loaded.
+// If tenancy is acquired then its database is closed when the last user releases it.
+func (t *TenancyManager) UnloadTenancy(id string) bool {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 	tenancy, ok := t.tenanciesById[id]
 	if ok {
-		tenancy.Db().Close()
+		usage, acquired := t.usage[tenancy]
+		if acquired {
+			usage.unloaded = true
+		} else {
+			tenancy.Db().Close()
+		}
 		delete(t.tenanciesById, id)
 		delete(t.tenanciesByPath, tenancy.Path())
+		for _, domain := range tenancy.Domains() {
+			delete(t.tenanciesByDomain, domain)
+		}
+		t.removeFromLru(id)
+	}
+	return ok
+}
+
+func (t *TenancyManager) AcquireTenancy(tenancy multitenancy.Tenancy) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	usage, acquired := t.usage[tenancy]
+	if !acquired {
+		if t.tenanciesById[tenancy.GetID()] != tenancy {
+			return false
+		}
+		usage = &tenancyUsage{}
+		t.usage[tenancy] = usage
+	}
+	usage.refs++
+	return true
+}
+
+func (t *TenancyManager) ReleaseTenancy(tenancy multitenancy.Tenancy) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	usage, acquired := t.usage[tenancy]
+	if !acquired {
+		return
+	}
+	usage.refs--
+	if usage.refs == 0 {
+		delete(t.usage, tenancy)
+		if usage.unloaded {
+			tenancy.Db().Close()
+		}
 	}
 }
 
@@ -213,6 +458,11 @@ func (t *TenancyManager) LoadTenancyFromData(ctx op_context.Context, tenancyDb *
 	}
 	defer onExit()
 
+	// deleted tenancies are not loaded
+	if tenancyDb.IsDeleted() {
+		return nil, nil
+	}
+
 	// TODO use tenancy builder to support derived tenancy types
 	// init tenancy
 	tenancy := NewTenancy(t)
@@ -224,12 +474,41 @@ func (t *TenancyManager) LoadTenancyFromData(ctx op_context.Context, tenancyDb *
 		return nil, nil
 	}
 
+	// load domains
+	domains, err := t.Controller.ListDomains(ctx, tenancy.GetID())
+	if err != nil {
+		c.SetMessage("failed to load tenancy domains")
+		tenancy.Db().Close()
+		return nil, err
+	}
+	for _, domain := range domains {
+		tenancy.DomainNames = append(tenancy.DomainNames, domain.Domain())
+	}
+
+	// load tenancy settings
+	settings, err := t.Controller.ListSettings(ctx, tenancy.GetID())
+	if err != nil {
+		c.SetMessage("failed to load tenancy settings")
+		tenancy.Db().Close()
+		return nil, err
+	}
+	tenancy.SetCfg(multitenancy.NewTenancyConfig(t.settingsSchema, settings, ctx.App().Cfg()))
+
 	// keep it
 	t.mutex.Lock()
 	t.tenanciesById[tenancy.GetID()] = tenancy
 	t.tenanciesByPath[tenancy.Path()] = tenancy
+	for _, domain := range tenancy.DomainNames {
+		t.tenanciesByDomain[domain] = tenancy
+	}
+	t.addToLru(tenancy.GetID())
 	t.mutex.Unlock()
 
+	// evict least recently used tenancies if limit is reached
+	if t.LAZY_LOADING {
+		t.evictExcessTenancies(ctx)
+	}
+
 	// done
 	return tenancy, nil
 }
@@ -269,6 +548,27 @@ func (t *TenancyManager) LoadTenancy(ctx op_context.Context, id string) (multite
 	return tenancy, nil
 }
 
+// Get connection to database shared by tenancies, the connection is created on first use and kept until manager is closed.
+func (t *TenancyManager) SharedDatabase(ctx op_context.Context, poolId string, dbConfig *db.DBConfig) (db.DB, error) {
+
+	t.sharedDbMutex.Lock()
+	defer t.sharedDbMutex.Unlock()
+
+	key := utils.ConcatStrings(poolId, "/", dbConfig.DB_NAME)
+	database, ok := t.sharedDbs[key]
+	if ok {
+		return database, nil
+	}
+
+	database = ctx.App().Db().Clone()
+	err := database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	t.sharedDbs[key] = database
+	return database, nil
+}
+
 func (t *TenancyManager) FindCustomer(ctx op_context.Context, c op_context.CallContext, id string) (*customer.Customer, error) {
 	owner, err := t.Customers.Find(ctx, id)
 	if err != nil {
@@ -338,6 +638,11 @@ func (t *TenancyManager) CheckDuplicatePath(ctx op_context.Context, c op_context
 }
 
 func (t *TenancyManager) CreateTenancy(ctx op_context.Context, data *multitenancy.TenancyData) (*multitenancy.TenancyItem, error) {
+	return t.createTenancy(ctx, data, "")
+}
+
+// Create tenancy with given ID, if ID is empty then it is generated.
+func (t *TenancyManager) createTenancy(ctx op_context.Context, data *multitenancy.TenancyData, id string) (*multitenancy.TenancyItem, error) {
 
 	// setup
 	var err error
@@ -376,6 +681,9 @@ func (t *TenancyManager) CreateTenancy(ctx op_context.Context, data *multitenanc
 	// create
 	tenancy := NewTenancy(t)
 	tenancy.InitObject()
+	if id != "" {
+		tenancy.SetID(id)
+	}
 	tenancy.TenancyData = *data
 	tenancy.CUSTOMER_ID = customer.GetID()
 	tenancy.POOL_ID = p.GetID()
@@ -383,8 +691,22 @@ func (t *TenancyManager) CreateTenancy(ctx op_context.Context, data *multitenanc
 	if tenancy.PATH == "" {
 		tenancy.PATH = crypt_utils.GenerateString()
 	}
+	if tenancy.IsSchema() {
+		if tenancy.SCHEMA == "" {
+			tenancy.SCHEMA = utils.ConcatStrings(t.DB_PREFIX, "_", customer.Login(), "_", data.ROLE)
+		}
+	} else {
+		tenancy.SCHEMA = ""
+	}
 	if tenancy.DBNAME == "" {
-		tenancy.DBNAME = utils.ConcatStrings(t.DB_PREFIX, "_", customer.Login(), "_", data.ROLE)
+		switch tenancy.Isolation() {
+		case multitenancy.IsolationShared:
+			tenancy.DBNAME = utils.ConcatStrings(t.DB_PREFIX, "_shared")
+		case multitenancy.IsolationSchema:
+			tenancy.DBNAME = utils.ConcatStrings(t.DB_PREFIX, "_schemas")
+		default:
+			tenancy.DBNAME = utils.ConcatStrings(t.DB_PREFIX, "_", customer.Login(), "_", data.ROLE)
+		}
 	}
 
 	// check if tenancy with such path in that pool
@@ -409,23 +731,29 @@ func (t *TenancyManager) CreateTenancy(ctx op_context.Context, data *multitenanc
 	}
 
 	// check if there are any tenancy metas in database
-	var metas []multitenancy.TenancyMeta
-	_, err = tenancy.Db().FindWithFilter(ctx, nil, &metas)
+	var metas []*multitenancy.TenancyMeta
+	_, err = multitenancy.UnscopedDb(tenancy.Db()).FindWithFilter(ctx, nil, &metas)
 	if err != nil {
 		c.SetMessage("failed to check tenancy metas in created database")
 		return nil, err
 	}
-	if len(metas) != 0 {
+	if !multitenancy.CheckTenancyMetas(metas, tenancy.GetID(), tenancy.IsShared()) {
 		// database already belongs to some tenancy
-		if metas[0].GetID() != tenancy.GetID() {
-			ctx.SetGenericErrorCode(multitenancy.ErrorCodeForeignDatabase)
-			err = errors.New("created database already belongs to other tenancy")
-			return nil, err
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeForeignDatabase)
+		err = errors.New("created database already belongs to other tenancy")
+		return nil, err
+	}
+	ownMeta := false
+	for _, meta := range metas {
+		if meta.GetID() == tenancy.GetID() {
+			ownMeta = true
 		}
-	} else {
+	}
+	if !ownMeta {
 		// set tenancy meta in the database
 		meta := &multitenancy.TenancyMeta{}
 		meta.ObjectBase = tenancy.ObjectBase
+		meta.ISOLATION = tenancy.Isolation()
 		err = tenancy.Db().Create(ctx, meta)
 		if err != nil {
 			c.SetMessage("failed to save tenancy meta in created database")
@@ -468,6 +796,17 @@ func (t *TenancyManager) LoadTenancies(ctx op_context.Context, selfPool pool.Poo
 		return err
 	}
 
+	// in lazy mode only keep index of tenancies
+	if t.LAZY_LOADING {
+		for _, tenancy := range tenancies {
+			err = t.indexTenancy(ctx, &tenancy.TenancyDb)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// load each tenancy
 	for _, tenancy := range tenancies {
 		_, err = t.LoadTenancyFromData(ctx, &tenancy.TenancyDb)
@@ -480,6 +819,17 @@ func (t *TenancyManager) LoadTenancies(ctx op_context.Context, selfPool pool.Poo
 	return nil
 }
 
+func (t *TenancyManager) SettingsSchema() *multitenancy.TenancySettingsSchema {
+	return t.settingsSchema
+}
+
+func (t *TenancyManager) Metering() multitenancy.Metering {
+	if t.metering == nil {
+		return nil
+	}
+	return t.metering
+}
+
 func (t *TenancyManager) TenancyController() multitenancy.TenancyController {
 	return t.Controller
 }
@@ -0,0 +1,414 @@
+package tenancy_manager
+
+import (
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+type usageKey struct {
+	tenancy string
+	metric  string
+	month   utils.Month
+}
+
+type usageCounter struct {
+	// usage loaded from database plus usage counted after loading
+	total  int64
+	loaded bool
+	// usage counted but not flushed to database yet
+	pending int64
+}
+
+// TenancyMetering counts usage of tenancies in memory and periodically flushes it to database.
+// Counters are reloaded from database after flushing, so usage counted by other application instances is taken into account.
+type TenancyMetering struct {
+	manager     *TenancyManager
+	mutex       sync.Mutex
+	counters    map[usageKey]*usageCounter
+	activeUsers map[usageKey]bool
+	partitions  map[utils.Month]bool
+}
+
+func NewTenancyMetering(manager *TenancyManager) *TenancyMetering {
+	m := &TenancyMetering{manager: manager}
+	m.counters = make(map[usageKey]*usageCounter)
+	m.activeUsers = make(map[usageKey]bool)
+	m.partitions = make(map[utils.Month]bool)
+	return m
+}
+
+// Create month partitions of metering tables if they were not created yet by this instance.
+func (m *TenancyMetering) createPartitions(ctx op_context.Context, month utils.Month) error {
+
+	m.mutex.Lock()
+	created := m.partitions[month]
+	m.mutex.Unlock()
+	if created {
+		return nil
+	}
+
+	err := ctx.MainDB().CreateMonthPartitions(ctx, []utils.Month{month}, multitenancy.PartitionedDbModels())
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.partitions[month] = true
+	m.mutex.Unlock()
+	return nil
+}
+
+func (m *TenancyMetering) counter(key usageKey) *usageCounter {
+	counter, ok := m.counters[key]
+	if !ok {
+		counter = &usageCounter{}
+		m.counters[key] = counter
+	}
+	return counter
+}
+
+func (m *TenancyMetering) Count(tenancyId string, metric string, delta int64) {
+	m.mutex.Lock()
+	counter := m.counter(usageKey{tenancy: tenancyId, metric: metric, month: utils.CurrentMonth()})
+	counter.total += delta
+	counter.pending += delta
+	m.mutex.Unlock()
+}
+
+func (m *TenancyMetering) CountActiveUser(ctx op_context.Context, tenancyId string, userId string) error {
+
+	// check if user was already counted
+	key := usageKey{tenancy: tenancyId, metric: userId, month: utils.CurrentMonth()}
+	m.mutex.Lock()
+	counted := m.activeUsers[key]
+	m.mutex.Unlock()
+	if counted {
+		return nil
+	}
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyMetering.CountActiveUser", logger.Fields{"tenancy": tenancyId, "user": userId})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// keep active user in database
+	database := op_context.DB(ctx, true)
+	activeUser := &multitenancy.TenancyActiveUser{}
+	found, err := database.FindByFields(ctx, db.Fields{"tenancy_id": tenancyId, "user_id": userId, "month": key.month}, activeUser)
+	if err != nil {
+		c.SetMessage("failed to find active user")
+		return err
+	}
+	if !found {
+		err = m.createPartitions(ctx, key.month)
+		if err != nil {
+			c.SetMessage("failed to create partitions of metering tables")
+			return err
+		}
+		activeUser.InitObject()
+		activeUser.SetMonth(key.month)
+		activeUser.TENANCY_ID = tenancyId
+		activeUser.USER_ID = userId
+		err = database.Create(ctx, activeUser)
+		if err != nil {
+			c.SetMessage("failed to save active user")
+			return err
+		}
+		m.Count(tenancyId, multitenancy.MetricActiveUsers, 1)
+	}
+
+	// done
+	m.mutex.Lock()
+	m.activeUsers[key] = true
+	m.mutex.Unlock()
+	return nil
+}
+
+func (m *TenancyMetering) Usage(ctx op_context.Context, tenancyId string, metric string, month utils.Month) (int64, error) {
+
+	// use loaded counter
+	key := usageKey{tenancy: tenancyId, metric: metric, month: month}
+	m.mutex.Lock()
+	counter, ok := m.counters[key]
+	if ok && counter.loaded {
+		total := counter.total
+		m.mutex.Unlock()
+		return total, nil
+	}
+	m.mutex.Unlock()
+
+	// load usage from database
+	c := ctx.TraceInMethod("TenancyMetering.Usage", logger.Fields{"tenancy": tenancyId, "metric": metric, "month": month})
+	defer ctx.TraceOutMethod()
+	value, err := m.loadUsage(ctx, key)
+	if err != nil {
+		return 0, c.SetError(err)
+	}
+
+	// add pending usage
+	m.mutex.Lock()
+	counter = m.counter(key)
+	if !counter.loaded {
+		counter.total = value + counter.pending
+		counter.loaded = true
+	}
+	total := counter.total
+	m.mutex.Unlock()
+
+	// done
+	return total, nil
+}
+
+func (m *TenancyMetering) QuotaExceeded(ctx op_context.Context, tenancy multitenancy.Tenancy, metric string) (bool, error) {
+	quota := multitenancy.Quota(tenancy, metric)
+	if quota <= 0 {
+		return false, nil
+	}
+	usage, err := m.Usage(ctx, tenancy.GetID(), metric, utils.CurrentMonth())
+	if err != nil {
+		return false, err
+	}
+	return usage >= quota, nil
+}
+
+func (m *TenancyMetering) loadUsage(ctx op_context.Context, key usageKey) (int64, error) {
+	filter := db.NewFilter()
+	filter.AddField("tenancy_id", key.tenancy)
+	filter.AddField("metric", key.metric)
+	filter.AddField("month", key.month)
+	var usages []*multitenancy.TenancyUsage
+	_, err := op_context.DB(ctx, true).FindWithFilter(ctx, filter, &usages)
+	if err != nil {
+		return 0, err
+	}
+	var value int64
+	for _, usage := range usages {
+		value += usage.VALUE
+	}
+	return value, nil
+}
+
+func (m *TenancyMetering) findUsage(ctx op_context.Context, key usageKey) (*multitenancy.TenancyUsage, bool, error) {
+	usage := &multitenancy.TenancyUsage{}
+	found, err := op_context.DB(ctx, true).FindByFields(ctx, db.Fields{"tenancy_id": key.tenancy, "metric": key.metric, "month": key.month}, usage)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		usage.InitObject()
+		usage.SetMonth(key.month)
+		usage.TENANCY_ID = key.tenancy
+		usage.METRIC = key.metric
+	}
+	return usage, found, nil
+}
+
+func (m *TenancyMetering) addUsage(ctx op_context.Context, key usageKey, delta int64) error {
+	usage, found, err := m.findUsage(ctx, key)
+	if err != nil {
+		return err
+	}
+	database := op_context.DB(ctx, true)
+	if found {
+		return db.Update(database, ctx, usage, db.Fields{"value": database.MakeExpression("value + ?", delta)})
+	}
+	err = m.createPartitions(ctx, key.month)
+	if err != nil {
+		return err
+	}
+	usage.VALUE = delta
+	return database.Create(ctx, usage)
+}
+
+func (m *TenancyMetering) setUsage(ctx op_context.Context, key usageKey, value int64) error {
+	usage, found, err := m.findUsage(ctx, key)
+	if err != nil {
+		return err
+	}
+	database := op_context.DB(ctx, true)
+	if found {
+		return db.Update(database, ctx, usage, db.Fields{"value": value})
+	}
+	err = m.createPartitions(ctx, key.month)
+	if err != nil {
+		return err
+	}
+	usage.VALUE = value
+	return database.Create(ctx, usage)
+}
+
+// Flush counted usage to database.
+func (m *TenancyMetering) Flush(ctx op_context.Context) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyMetering.Flush")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// take pending usage
+	pending := make(map[usageKey]int64)
+	m.mutex.Lock()
+	for key, counter := range m.counters {
+		if counter.pending != 0 {
+			pending[key] = counter.pending
+			counter.pending = 0
+		}
+	}
+	m.mutex.Unlock()
+
+	// write usage to database, failed usage is returned back to counters
+	for key, delta := range pending {
+		err1 := m.addUsage(ctx, key, delta)
+		if err1 != nil {
+			err = err1
+			m.mutex.Lock()
+			m.counter(key).pending += delta
+			m.mutex.Unlock()
+		}
+	}
+
+	// forget flushed counters to reload them from database and forget active users of previous months
+	currentMonth := utils.CurrentMonth()
+	m.mutex.Lock()
+	for key, counter := range m.counters {
+		if counter.pending == 0 {
+			delete(m.counters, key)
+		}
+	}
+	for key := range m.activeUsers {
+		if key.month != currentMonth {
+			delete(m.activeUsers, key)
+		}
+	}
+	m.mutex.Unlock()
+
+	if err != nil {
+		c.SetMessage("failed to flush usage")
+		return err
+	}
+
+	// done
+	return nil
+}
+
+// Measure storage size of databases of tenancies. Databases of tenancies that are not loaded are connected only for measuring.
+// Storage of tenancies in shared databases is not measured because tables of shared database keep data of all its tenancies.
+func (m *TenancyMetering) MeasureStorage(ctx op_context.Context) error {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyMetering.MeasureStorage")
+	defer ctx.TraceOutMethod()
+	month := utils.CurrentMonth()
+
+	// measure loaded tenancies
+	for _, tenancy := range m.manager.Tenancies() {
+		if tenancy.IsShared() || !m.manager.AcquireTenancy(tenancy) {
+			continue
+		}
+		m.measureTenancy(ctx, tenancy, month)
+		m.manager.ReleaseTenancy(tenancy)
+	}
+
+	// measure tenancies that are not loaded
+	for _, data := range m.manager.unloadedTenancies() {
+		if data.IsShared() {
+			continue
+		}
+		tenancy := NewTenancy(m.manager)
+		skip, err := tenancy.Init(ctx, data)
+		if err != nil {
+			c.Logger().Error("failed to connect to database of tenancy", err, logger.Fields{"tenancy": data.GetID()})
+			continue
+		}
+		if skip {
+			continue
+		}
+		m.measureTenancy(ctx, tenancy, month)
+		tenancy.Db().Close()
+	}
+
+	// done
+	return nil
+}
+
+func (m *TenancyMetering) measureTenancy(ctx op_context.Context, tenancy multitenancy.Tenancy, month utils.Month) {
+
+	var size int64
+	var err error
+	if tenancy.IsSchema() {
+		size, err = tenancy.Db().SchemaSize(ctx, tenancy.Schema())
+	} else {
+		size, err = tenancy.Db().DatabaseSize(ctx, tenancy.DbName())
+	}
+	if err == nil {
+		key := usageKey{tenancy: tenancy.GetID(), metric: multitenancy.MetricStorage, month: month}
+		err = m.setUsage(ctx, key, size)
+		if err == nil {
+			m.mutex.Lock()
+			delete(m.counters, key)
+			m.mutex.Unlock()
+		}
+	}
+	if err != nil {
+		ctx.Logger().Error("failed to measure storage of tenancy", err, logger.Fields{"tenancy": tenancy.GetID()})
+	}
+}
+
+type meteringFlusher struct {
+	background_worker.JobRunnerBase
+	metering *TenancyMetering
+}
+
+func (f *meteringFlusher) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(f.metering.manager.app, "TenancyMetering.Flush")
+	defer ctx.Close()
+	f.metering.Flush(ctx)
+}
+
+type storageMeter struct {
+	background_worker.JobRunnerBase
+	metering *TenancyMetering
+}
+
+func (s *storageMeter) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(s.metering.manager.app, "TenancyMetering.MeasureStorage")
+	defer ctx.Close()
+	s.metering.MeasureStorage(ctx)
+}
+
+func (t *TenancyController) ListUsage(ctx op_context.Context, filter *db.Filter) ([]*multitenancy.TenancyUsage, int64, error) {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.ListUsage")
+	defer ctx.TraceOutMethod()
+
+	// list usage
+	var usages []*multitenancy.TenancyUsage
+	count, err := t.CRUD.List(ctx, filter, &usages)
+	if err != nil {
+		c.SetMessage("failed to list usage of tenancies")
+		return nil, 0, c.SetError(err)
+	}
+
+	// done
+	return usages, count, nil
+}
@@ -0,0 +1,680 @@
+package tenancy_manager
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+func (t *TenancyController) FindMove(ctx op_context.Context, id string, idIsDisplay ...bool) (*multitenancy.TenancyMove, error) {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.FindMove")
+	defer ctx.TraceOutMethod()
+
+	// adjust ID
+	id, _, err := TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, c.SetError(err)
+	}
+
+	// find the last move
+	move, err := t.lastMove(ctx, id)
+	if err != nil {
+		return nil, c.SetError(err)
+	}
+
+	// done
+	return move, nil
+}
+
+func (t *TenancyController) lastMove(ctx op_context.Context, tenancyId string) (*multitenancy.TenancyMove, error) {
+	filter := db.NewFilter()
+	filter.AddField("tenancy_id", tenancyId)
+	filter.SetSorting("created_at", db.SORT_DESC)
+	filter.Limit = 1
+	var moves []*multitenancy.TenancyMove
+	_, err := t.CRUD.List(ctx, filter, &moves)
+	if err != nil {
+		return nil, err
+	}
+	if len(moves) == 0 {
+		return nil, nil
+	}
+	return moves[0], nil
+}
+
+func (t *TenancyController) updateMove(ctx op_context.Context, move *multitenancy.TenancyMove, fields db.Fields) error {
+	return t.CRUD.Update(ctx, move, fields)
+}
+
+// Mark tenancy as being moved by this instance, false is returned if tenancy is already being moved.
+func (t *TenancyManager) startMove(tenancyId string) bool {
+	t.movesMutex.Lock()
+	defer t.movesMutex.Unlock()
+	if t.moves[tenancyId] {
+		return false
+	}
+	t.moves[tenancyId] = true
+	return true
+}
+
+func (t *TenancyManager) finishMove(tenancyId string) {
+	t.movesMutex.Lock()
+	delete(t.moves, tenancyId)
+	t.movesMutex.Unlock()
+}
+
+func (t *TenancyController) Move(ctx op_context.Context, id string, poolId string, dbName string, idIsDisplay ...bool) (*multitenancy.TenancyMove, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.Move", logger.Fields{"tenancy": id, "pool": poolId, "dbname": dbName})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, err
+	}
+
+	// only one move of tenancy can run in this instance
+	if !t.Manager.startMove(tenancy.GetID()) {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMoveInProgress)
+		err = errors.New("tenancy is being moved")
+		return nil, err
+	}
+	started := false
+	defer func() {
+		if !started {
+			t.Manager.finishMove(tenancy.GetID())
+		}
+	}()
+
+	// find target pool
+	var targetPool pool.Pool
+	if poolId != "" {
+		targetPool, err = t.Manager.FindPool(ctx, c, poolId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// find unfinished move
+	move, err := t.lastMove(ctx, tenancy.GetID())
+	if err != nil {
+		c.SetMessage("failed to find tenancy move")
+		return nil, err
+	}
+	if move != nil && move.IsDone() {
+		move = nil
+	}
+
+	// unfinished move can be resumed only with the same target, failed move can be replaced with move to other target
+	if move != nil {
+		sameTarget := (targetPool == nil || targetPool.GetID() == move.TARGET_POOL_ID) && (dbName == "" || dbName == move.TARGET_DBNAME)
+		if !sameTarget {
+			if move.Status() != multitenancy.MoveStatusFailed {
+				ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMoveInProgress)
+				err = errors.New("tenancy is being moved to other database")
+				return nil, err
+			}
+			move = nil
+		}
+	}
+
+	if move != nil {
+
+		// resume move
+		targetPool, err = t.Manager.FindPool(ctx, c, move.TARGET_POOL_ID)
+		if err != nil {
+			return nil, err
+		}
+		c.Logger().Info("resuming tenancy move", logger.Fields{"move": move.GetID(), "status": move.Status()})
+	} else {
+
+		// fill target
+		if targetPool == nil {
+			targetPool, err = t.Manager.FindPool(ctx, c, tenancy.PoolId())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if dbName == "" {
+			dbName = tenancy.DbName()
+		}
+		if targetPool.GetID() == tenancy.PoolId() && dbName == tenancy.DbName() {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMoveSameDatabase)
+			err = errors.New("tenancy already uses that database")
+			return nil, err
+		}
+
+		// check if tenancy with such path in new pool exists
+		if targetPool.GetID() != tenancy.PoolId() {
+			err = t.Manager.CheckDuplicatePath(ctx, c, targetPool.GetID(), tenancy.Path())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// create move
+		move = &multitenancy.TenancyMove{}
+		move.InitObject()
+		move.TENANCY_ID = tenancy.GetID()
+		move.SOURCE_POOL_ID = tenancy.PoolId()
+		move.SOURCE_DBNAME = tenancy.DbName()
+		move.TARGET_POOL_ID = targetPool.GetID()
+		move.TARGET_DBNAME = dbName
+		move.STATUS = multitenancy.MoveStatusCopying
+		err = t.CRUD.Create(ctx, move)
+		if err != nil {
+			c.SetMessage("failed to save tenancy move in database")
+			return nil, err
+		}
+	}
+	if !targetPool.IsActive() {
+		ctx.SetGenericErrorCode(pool.ErrorCodePoolNotActive)
+		err = errors.New("target pool is not active")
+		return nil, err
+	}
+
+	// put tenancy in maintenance mode
+	if !tenancy.IsMaintenance() {
+		err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"maintenance": true})
+		if err != nil {
+			c.SetMessage("failed to set tenancy maintenance mode")
+			return nil, err
+		}
+		t.OpLog(ctx, multitenancy.OpSetMaintenance, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+			Role: tenancy.Role(), Customer: tenancy.CustomerDisplay()})
+		t.PublishOp(tenancy, multitenancy.OpSetMaintenance)
+	}
+
+	// mark move as running
+	if move.Status() == multitenancy.MoveStatusFailed {
+		move.STATUS = multitenancy.MoveStatusCopying
+		err = t.updateMove(ctx, move, db.Fields{"status": move.STATUS})
+		if err != nil {
+			c.SetMessage("failed to save status of tenancy move")
+			return nil, err
+		}
+	}
+
+	// run move in background
+	started = true
+	result := *move
+	t.runMove(tenancy, move, targetPool)
+
+	// done
+	return &result, nil
+}
+
+// Copy and verify tenancy data in background, then switch tenancy to new database.
+// If move fails then its status is set to failed and tenancy is left in maintenance mode.
+func (t *TenancyController) runMove(tenancy *multitenancy.TenancyItem, move *multitenancy.TenancyMove, targetPool pool.Pool) {
+
+	t.Manager.moveJobs.Add(1)
+	go func() {
+		defer t.Manager.moveJobs.Done()
+		defer t.Manager.finishMove(tenancy.GetID())
+
+		ctx := default_op_context.NewBackgroundContext(t.Manager.app, "TenancyController.Move")
+		defer ctx.Close()
+
+		err := t.finishMove(ctx, tenancy, move, targetPool)
+		if err != nil {
+			move.STATUS = multitenancy.MoveStatusFailed
+			move.LAST_ERROR = err.Error()
+			uErr := t.updateMove(ctx, move, db.Fields{"status": move.STATUS, "last_error": move.LAST_ERROR})
+			if uErr != nil {
+				ctx.Logger().Error("failed to save status of tenancy move", uErr, logger.Fields{"move": move.GetID()})
+			}
+		}
+	}()
+}
+
+func (t *TenancyController) finishMove(ctx op_context.Context, tenancy *multitenancy.TenancyItem, move *multitenancy.TenancyMove, targetPool pool.Pool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.finishMove", logger.Fields{"tenancy": tenancy.GetID(), "move": move.GetID()})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// copy and verify data
+	err = t.MoveTenancyData(ctx, tenancy, move, targetPool)
+	if err != nil {
+		return err
+	}
+
+	// switch tenancy to new database and leave maintenance mode
+	oldPoolId := tenancy.PoolId()
+	err = t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"pool_id": move.TARGET_POOL_ID, "dbname": move.TARGET_DBNAME, "maintenance": false})
+	if err != nil {
+		c.SetMessage("failed to update tenancy")
+		return err
+	}
+	tenancy.POOL_ID = move.TARGET_POOL_ID
+	tenancy.DBNAME = move.TARGET_DBNAME
+	tenancy.MAINTENANCE = false
+
+	// finish move
+	move.STATUS = multitenancy.MoveStatusDone
+	move.CURRENT_TABLE = ""
+	move.LAST_ERROR = ""
+	move.FINISHED_AT = time.Now()
+	err = t.updateMove(ctx, move, db.Fields{"status": move.STATUS, "current_table": move.CURRENT_TABLE, "last_error": move.LAST_ERROR, "finished_at": move.FINISHED_AT})
+	if err != nil {
+		c.SetMessage("failed to save status of tenancy move")
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpMove, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Pool: targetPool.Name(), DbName: move.TARGET_DBNAME})
+
+	// publish notification
+	if oldPoolId != move.TARGET_POOL_ID {
+		t.PublishOp(tenancy, multitenancy.OpDelete, oldPoolId)
+	}
+	t.PublishOp(tenancy, multitenancy.OpChangePoolOrDb)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) tenancyModels() []interface{} {
+	models := append([]interface{}{}, multitenancy.DbInternalModels()...)
+	models = append(models, t.Manager.tenancyDbModels.DbModels...)
+	return models
+}
+
+// Copy tenancy data to target database and verify it. Copying continues from the last row found in target database.
+func (t *TenancyController) MoveTenancyData(ctx op_context.Context, tenancy *multitenancy.TenancyItem, move *multitenancy.TenancyMove, targetPool pool.Pool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.MoveTenancyData", logger.Fields{"tenancy": tenancy.GetID(), "target_pool": targetPool.Name(), "target_db": move.TARGET_DBNAME})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// connect to source database
+	sourceDb := tenancy.TenancyDb
+	sourceDb.POOL_ID = move.SOURCE_POOL_ID
+	sourceDb.DBNAME = move.SOURCE_DBNAME
+	source := NewTenancy(t.Manager)
+	skip, err := source.Init(ctx, &sourceDb)
+	if err != nil {
+		c.SetMessage("failed to connect to source database")
+		return err
+	}
+	if skip {
+		err = errors.New("pool of source database is not active")
+		return err
+	}
+	defer source.Db().Close()
+
+	// connect to target database
+	target := NewTenancy(t.Manager)
+	target.TenancyDb = tenancy.TenancyDb
+	target.POOL_ID = move.TARGET_POOL_ID
+	target.DBNAME = move.TARGET_DBNAME
+	target.TenancyBaseData.Pool = targetPool
+	err = target.ConnectDatabase(ctx, true)
+	if err != nil {
+		c.SetMessage("failed to connect to target database")
+		return err
+	}
+	defer target.Db().Close()
+	err = multitenancy.UpgradeTenancyDatabase(ctx, target, t.Manager.tenancyDbModels)
+	if err != nil {
+		c.SetMessage("failed to initialize database models in target database")
+		return err
+	}
+
+	// check if target database belongs to other tenancy
+	var metas []*multitenancy.TenancyMeta
+	_, err = multitenancy.UnscopedDb(target.Db()).FindWithFilter(ctx, nil, &metas)
+	if err != nil {
+		c.SetMessage("failed to check tenancy metas in target database")
+		return err
+	}
+	if !multitenancy.CheckTenancyMetas(metas, tenancy.GetID(), tenancy.IsShared()) {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeForeignDatabase)
+		err = errors.New("target database already belongs to other tenancy")
+		return err
+	}
+
+	// copy tables
+	models := t.tenancyModels()
+	partitioned := t.Manager.tenancyDbModels.PartitionedDbModels
+	move.TABLES_TOTAL = len(models) + len(partitioned)
+	move.STATUS = multitenancy.MoveStatusCopying
+	move.LAST_ERROR = ""
+	err = t.updateMove(ctx, move, db.Fields{"status": move.STATUS, "tables_total": move.TABLES_TOTAL, "last_error": move.LAST_ERROR})
+	if err != nil {
+		c.SetMessage("failed to save progress of tenancy move")
+		return err
+	}
+	for i, model := range append(models, partitioned...) {
+		err = t.copyTable(ctx, source, target, model, i >= len(models), move)
+		if err != nil {
+			return err
+		}
+		move.TABLES_DONE = i + 1
+		err = t.updateMove(ctx, move, db.Fields{"tables_done": move.TABLES_DONE})
+		if err != nil {
+			c.SetMessage("failed to save progress of tenancy move")
+			return err
+		}
+	}
+
+	// verify tables
+	move.STATUS = multitenancy.MoveStatusVerifying
+	move.CURRENT_TABLE = ""
+	err = t.updateMove(ctx, move, db.Fields{"status": move.STATUS, "current_table": move.CURRENT_TABLE})
+	if err != nil {
+		c.SetMessage("failed to save progress of tenancy move")
+		return err
+	}
+	for _, model := range append(models, partitioned...) {
+		err = t.verifyTable(ctx, source, target, model)
+		if err != nil {
+			return err
+		}
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) copyTable(ctx op_context.Context, source *TenancyBase, target *TenancyBase, model interface{}, partitioned bool, move *multitenancy.TenancyMove) error {
+
+	// setup
+	var err error
+	table := tableName(model)
+	c := ctx.TraceInMethod("TenancyController.copyTable", logger.Fields{"table": table})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	move.CURRENT_TABLE = table
+	err = t.updateMove(ctx, move, db.Fields{"current_table": move.CURRENT_TABLE})
+	if err != nil {
+		c.SetMessage("failed to save progress of tenancy move")
+		return err
+	}
+
+	// find the last row copied to target database
+	last := newModelSlice(model)
+	filter := db.NewFilter()
+	filter.SetSorting("id", db.SORT_DESC)
+	filter.Limit = 1
+	_, err = target.Db().FindWithFilter(ctx, filter, last.Interface())
+	if err != nil {
+		c.SetMessage("failed to find last copied row")
+		return err
+	}
+	lastId := ""
+	if last.Elem().Len() != 0 {
+		lastId = rowId(last.Elem().Index(0))
+	}
+
+	// copy rows in batches
+	for {
+
+		// read batch from source database
+		batch := newModelSlice(model)
+		filter := db.NewFilter()
+		filter.SetSorting("id", db.SORT_ASC)
+		filter.Limit = t.Manager.MOVE_BATCH_SIZE
+		if lastId != "" {
+			filter.Intervals = map[string]*db.Interval{"id": {From: lastId, FromOpen: true}}
+		}
+		_, err = source.Db().FindWithFilter(ctx, filter, batch.Interface())
+		if err != nil {
+			c.SetMessage("failed to read rows from source database")
+			return err
+		}
+		rows := batch.Elem()
+		if rows.Len() == 0 {
+			break
+		}
+
+		// create partitions for rows
+		if partitioned {
+			months := make(map[utils.Month]bool)
+			for i := 0; i < rows.Len(); i++ {
+				monthData, ok := rows.Index(i).Interface().(utils.MonthData)
+				if ok {
+					months[monthData.GetMonth()] = true
+				}
+			}
+			err = target.Db().CreateMonthPartitions(ctx, utils.AllMapKeys(months), []interface{}{model})
+			if err != nil {
+				c.SetMessage("failed to create partitions in target database")
+				return err
+			}
+		}
+
+		// write batch to target database
+		err = target.Db().Create(ctx, batch.Interface())
+		if err != nil {
+			c.SetMessage("failed to write rows to target database")
+			return err
+		}
+		lastId = rowId(rows.Index(rows.Len() - 1))
+
+		// save progress
+		move.ROWS_COPIED += int64(rows.Len())
+		err = t.updateMove(ctx, move, db.Fields{"rows_copied": move.ROWS_COPIED})
+		if err != nil {
+			c.SetMessage("failed to save progress of tenancy move")
+			return err
+		}
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) verifyTable(ctx op_context.Context, source *TenancyBase, target *TenancyBase, model interface{}) error {
+
+	// setup
+	var err error
+	table := tableName(model)
+	c := ctx.TraceInMethod("TenancyController.verifyTable", logger.Fields{"table": table})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// calculate checksums
+	sourceCount, sourceSum, err := t.tableChecksum(ctx, source.Db(), model)
+	if err != nil {
+		c.SetMessage("failed to calculate checksum in source database")
+		return err
+	}
+	targetCount, targetSum, err := t.tableChecksum(ctx, target.Db(), model)
+	if err != nil {
+		c.SetMessage("failed to calculate checksum in target database")
+		return err
+	}
+
+	// compare
+	if sourceCount != targetCount {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMoveVerificationFailed)
+		err = fmt.Errorf("number of rows in table %s mismatch: source %d, target %d", table, sourceCount, targetCount)
+		return err
+	}
+	if sourceSum != targetSum {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyMoveVerificationFailed)
+		err = fmt.Errorf("checksum of table %s mismatch", table)
+		return err
+	}
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) tableChecksum(ctx op_context.Context, database db.DB, model interface{}) (int64, string, error) {
+
+	var count int64
+	hash := sha256.New()
+	lastId := ""
+	for {
+		batch := newModelSlice(model)
+		filter := db.NewFilter()
+		filter.SetSorting("id", db.SORT_ASC)
+		filter.Limit = t.Manager.MOVE_BATCH_SIZE
+		if lastId != "" {
+			filter.Intervals = map[string]*db.Interval{"id": {From: lastId, FromOpen: true}}
+		}
+		_, err := database.FindWithFilter(ctx, filter, batch.Interface())
+		if err != nil {
+			return 0, "", err
+		}
+		rows := batch.Elem()
+		if rows.Len() == 0 {
+			break
+		}
+		for i := 0; i < rows.Len(); i++ {
+			err = writeNormalizedValue(hash, rows.Index(i))
+			if err != nil {
+				return 0, "", err
+			}
+		}
+		count += int64(rows.Len())
+		lastId = rowId(rows.Index(rows.Len() - 1))
+	}
+
+	return count, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Write value of column or row in the form that does not depend on database backend.
+// Times are written in UTC with precision of microseconds and numbers are written in canonical decimal form.
+func writeNormalizedValue(w io.Writer, value reflect.Value) error {
+
+	if value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			_, err := io.WriteString(w, "null;")
+			return err
+		}
+		return writeNormalizedValue(w, value.Elem())
+	}
+
+	var str string
+	switch v := value.Interface().(type) {
+	case time.Time:
+		if !v.IsZero() {
+			str = v.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+		}
+	case []byte:
+		str = hex.EncodeToString(v)
+	case driver.Valuer:
+		dbValue, err := v.Value()
+		if err != nil {
+			return err
+		}
+		if dbValue == nil {
+			str = "null"
+		} else {
+			return writeNormalizedValue(w, reflect.ValueOf(dbValue))
+		}
+	default:
+		switch value.Kind() {
+		case reflect.Bool:
+			str = strconv.FormatBool(value.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			str = strconv.FormatInt(value.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			str = strconv.FormatUint(value.Uint(), 10)
+		case reflect.Float32:
+			str = strconv.FormatFloat(value.Float(), 'g', -1, 32)
+		case reflect.Float64:
+			str = strconv.FormatFloat(value.Float(), 'g', -1, 64)
+		case reflect.String:
+			str = strconv.Quote(value.String())
+		case reflect.Struct:
+			typ := value.Type()
+			for i := 0; i < typ.NumField(); i++ {
+				field := typ.Field(i)
+				if !field.IsExported() || field.Tag.Get("gorm") == "-" {
+					continue
+				}
+				err := writeNormalizedValue(w, value.Field(i))
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			b, err := json.Marshal(value.Interface())
+			if err != nil {
+				return err
+			}
+			str = string(b)
+		}
+	}
+
+	_, err := io.WriteString(w, utils.ConcatStrings(str, ";"))
+	return err
+}
+
+func newModelSlice(model interface{}) reflect.Value {
+	return reflect.New(reflect.SliceOf(reflect.TypeOf(model)))
+}
+
+func rowId(row reflect.Value) string {
+	obj, ok := row.Interface().(interface{ GetID() string })
+	if !ok {
+		return ""
+	}
+	return obj.GetID()
+}
+
+func tableName(model interface{}) string {
+	tabler, ok := model.(interface{ TableName() string })
+	if ok {
+		return tabler.TableName()
+	}
+	return utils.ObjectTypeName(model)
+}
@@ -0,0 +1,176 @@
+package tenancy_manager
+
+import (
+	"errors"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func (t *TenancyController) findSetting(ctx op_context.Context, tenancyId string, key string) (*multitenancy.TenancySetting, error) {
+	setting := &multitenancy.TenancySetting{}
+	found, err := t.CRUD.Read(ctx, db.Fields{"tenancy_id": tenancyId, "key": key}, setting)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	return setting, nil
+}
+
+// Check that setting is registered in settings schema and has valid value, returns normalized key.
+func (t *TenancyController) checkSetting(ctx op_context.Context, key string, value string) (string, error) {
+	key = multitenancy.NormalizeSettingKey(key)
+	schema := t.Manager.SettingsSchema()
+	_, ok := schema.Spec(key)
+	if !ok {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancySettingUnknown)
+		return key, errors.New("unknown setting")
+	}
+	_, err := schema.Parse(ctx.App().Validator(), key, value)
+	if err != nil {
+		genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancySettingInvalid, "Invalid value of tenancy setting.", err)
+		genErr.SetDetails(key)
+		ctx.SetGenericError(genErr)
+		return key, err
+	}
+	return key, nil
+}
+
+func (t *TenancyController) SetSetting(ctx op_context.Context, id string, key string, value string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.SetSetting", logger.Fields{"key": key})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check setting
+	key, err = t.checkSetting(ctx, key, value)
+	if err != nil {
+		return err
+	}
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return err
+	}
+
+	// save setting
+	setting, err := t.findSetting(ctx, tenancy.GetID(), key)
+	if err != nil {
+		c.SetMessage("failed to find setting")
+		return err
+	}
+	if setting != nil {
+		err = t.CRUD.Update(ctx, setting, db.Fields{"value": value})
+	} else {
+		setting = &multitenancy.TenancySetting{}
+		setting.InitObject()
+		setting.TENANCY_ID = tenancy.GetID()
+		setting.KEY = key
+		setting.VALUE = value
+		err = t.CRUD.Create(ctx, setting)
+	}
+	if err != nil {
+		c.SetMessage("failed to save tenancy setting")
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpSetSetting, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Setting: key})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpSetSetting)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) UnsetSetting(ctx op_context.Context, id string, key string, idIsDisplay ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.UnsetSetting", logger.Fields{"key": key})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return err
+	}
+
+	// find setting
+	key = multitenancy.NormalizeSettingKey(key)
+	setting, err := t.findSetting(ctx, tenancy.GetID(), key)
+	if err != nil {
+		c.SetMessage("failed to find setting")
+		return err
+	}
+	if setting == nil {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancySettingNotFound)
+		err = errors.New("setting is not overridden in tenancy")
+		return err
+	}
+
+	// delete setting
+	err = t.CRUD.Delete(ctx, setting)
+	if err != nil {
+		c.SetMessage("failed to delete tenancy setting")
+		return err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpUnsetSetting, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Setting: key})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpUnsetSetting)
+
+	// done
+	return nil
+}
+
+func (t *TenancyController) ListSettings(ctx op_context.Context, id string, idIsDisplay ...bool) ([]*multitenancy.TenancySetting, error) {
+
+	// setup
+	c := ctx.TraceInMethod("TenancyController.ListSettings")
+	defer ctx.TraceOutMethod()
+
+	// adjust ID
+	id, _, err := TenancyId(t, ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, c.SetError(err)
+	}
+
+	// list settings
+	filter := db.NewFilter()
+	filter.AddField("tenancy_id", id)
+	filter.SetSorting("key")
+	var settings []*multitenancy.TenancySetting
+	_, err = t.CRUD.List(ctx, filter, &settings)
+	if err != nil {
+		c.SetMessage("failed to list tenancy settings")
+		return nil, c.SetError(err)
+	}
+
+	// done
+	return settings, nil
+}
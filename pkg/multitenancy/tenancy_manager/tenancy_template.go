@@ -0,0 +1,340 @@
+package tenancy_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Register template, registered templates take precedence over templates in templates directory.
+func (t *TenancyManager) AddTemplate(template *multitenancy.TenancyTemplate) error {
+	err := template.Validate()
+	if err != nil {
+		return err
+	}
+	t.templatesMutex.Lock()
+	t.templates[template.Name] = template
+	t.templatesMutex.Unlock()
+	return nil
+}
+
+// Set handler creating default users of templates. Templates with users can not be applied if handler is not set.
+func (t *TenancyManager) SetTemplateUsers(users multitenancy.TemplateUsers) {
+	t.templateUsers = users
+}
+
+// Find template by name in registered templates or in templates directory.
+// Template files are read on each lookup, so that new versions of templates are used without restart.
+func (t *TenancyManager) FindTemplate(ctx op_context.Context, c op_context.CallContext, name string) (*multitenancy.TenancyTemplate, error) {
+
+	c.SetLoggerField("template", name)
+
+	// find registered template
+	t.templatesMutex.Lock()
+	template, ok := t.templates[name]
+	t.templatesMutex.Unlock()
+	if ok {
+		return template, nil
+	}
+
+	// load template from file
+	if name != "" && filepath.Base(name) == name {
+		for _, ext := range multitenancy.TemplateExtensions {
+			fileName := filepath.Join(t.TEMPLATES_PATH, utils.ConcatStrings(name, ext))
+			if !utils.FileExists(fileName) {
+				continue
+			}
+			template, err := multitenancy.LoadTenancyTemplate(fileName)
+			if err == nil && template.Name != name {
+				err = fmt.Errorf("template file contains template %s", template.Name)
+			}
+			if err != nil {
+				genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancyTemplateInvalid, "Invalid tenancy template.", err)
+				genErr.SetDetails(name)
+				ctx.SetGenericError(genErr)
+				c.SetMessage("failed to load template")
+				return nil, err
+			}
+			return template, nil
+		}
+	}
+
+	// not found
+	ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyTemplateNotFound)
+	return nil, errors.New("template not found")
+}
+
+// Check that template can be applied to tenancies, returns models of fixtures mapped by names.
+func (t *TenancyController) checkTemplate(ctx op_context.Context, template *multitenancy.TenancyTemplate) (map[string]interface{}, error) {
+
+	invalid := func(err error) (map[string]interface{}, error) {
+		genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancyTemplateInvalid, "Invalid tenancy template.", err)
+		genErr.SetDetails(template.Name)
+		ctx.SetGenericError(genErr)
+		return nil, err
+	}
+
+	// check fixtures, partitioned models can not be used in fixtures
+	models := make(map[string]interface{})
+	for _, model := range t.Manager.tenancyDbModels.DbModels {
+		models[tableName(model)] = model
+	}
+	for _, fixture := range template.Fixtures {
+		if _, ok := models[fixture.Model]; !ok {
+			return invalid(fmt.Errorf("unknown model %s in fixtures", fixture.Model))
+		}
+	}
+
+	// check users
+	if len(template.Users) != 0 && t.Manager.templateUsers == nil {
+		return invalid(errors.New("template users are not supported"))
+	}
+
+	// check settings
+	for key, value := range template.Settings {
+		_, err := t.checkSetting(ctx, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// done
+	return models, nil
+}
+
+// Apply template to tenancy. Fixtures and users are applied in transaction of tenancy database.
+// Settings are saved in transaction of main database together with tenancy record that is saved with save handler.
+func (t *TenancyController) applyTemplate(ctx op_context.Context, tenancy *multitenancy.TenancyItem, template *multitenancy.TenancyTemplate, save func() error) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.applyTemplate", logger.Fields{"tenancy": tenancy.GetID(), "template": template.Name, "version": template.Version})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check template
+	models, err := t.checkTemplate(ctx, template)
+	if err != nil {
+		return err
+	}
+
+	// connect to tenancy database
+	target := NewTenancy(t.Manager)
+	skip, err := target.Init(ctx, &tenancy.TenancyDb)
+	if err != nil {
+		c.SetMessage("failed to connect to tenancy database")
+		return err
+	}
+	if skip {
+		err = errors.New("pool of tenancy database is not active")
+		return err
+	}
+	defer target.Db().Close()
+
+	// apply template
+	tx := ctx.DbTransaction()
+	err = target.Db().Transaction(func(tenancyTx db.Transaction) error {
+
+		// seed tenancy database
+		ctx.SetDbTransaction(tenancyTx)
+		err := t.applyFixtures(ctx, template, models)
+		if err == nil {
+			err = t.applyUsers(ctx, template)
+		}
+		ctx.SetDbTransaction(tx)
+		if err != nil {
+			return err
+		}
+
+		// save settings and tenancy in main database
+		return ctx.ExecDbTransaction(func() error {
+			err := t.applySettings(ctx, tenancy, template)
+			if err != nil {
+				return err
+			}
+			tenancy.TEMPLATE = template.Name
+			tenancy.TEMPLATE_VERSION = template.Version
+			return save()
+		})
+	})
+	if err != nil {
+		if ctx.GenericError() == nil {
+			genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancyTemplateFailed, "Failed to apply tenancy template.", err)
+			genErr.SetDetails(template.Name)
+			ctx.SetGenericError(genErr)
+		}
+		return err
+	}
+
+	// done
+	return nil
+}
+
+// Create rows of fixtures that do not exist in tenancy database.
+func (t *TenancyController) applyFixtures(ctx op_context.Context, template *multitenancy.TenancyTemplate, models map[string]interface{}) error {
+
+	invalid := func(err error) error {
+		genErr := generic_error.NewFromOriginal(multitenancy.ErrorCodeTenancyTemplateInvalid, "Invalid tenancy template.", err)
+		genErr.SetDetails(template.Name)
+		ctx.SetGenericError(genErr)
+		return err
+	}
+
+	rowsCrud := &crud.DbCRUD{}
+	for _, fixture := range template.Fixtures {
+		model := models[fixture.Model]
+		for i, data := range fixture.Rows {
+
+			// decode row
+			row := newModel(model)
+			err := json.Unmarshal(data, row)
+			if err != nil {
+				return invalid(fmt.Errorf("failed to decode row %d of model %s: %s", i, fixture.Model, err))
+			}
+			withId, ok := row.(common.ID)
+			if !ok || withId.GetID() == "" {
+				return invalid(fmt.Errorf("row %d of model %s must have ID", i, fixture.Model))
+			}
+
+			// skip existing row
+			found, err := rowsCrud.Read(ctx, db.Fields{"id": withId.GetID()}, newModel(model))
+			if err != nil {
+				return fmt.Errorf("failed to find row %s of model %s: %s", withId.GetID(), fixture.Model, err)
+			}
+			if found {
+				continue
+			}
+
+			// create row
+			obj, ok := row.(common.Object)
+			if ok {
+				if obj.GetCreatedAt().IsZero() {
+					obj.InitCreatedAt()
+					obj.SetUpDatedAt(obj.GetCreatedAt())
+				}
+				err = rowsCrud.Create(ctx, obj)
+			} else {
+				err = op_context.DB(ctx).Create(ctx, row)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create row %s of model %s: %s", withId.GetID(), fixture.Model, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create users of template that do not exist in tenancy.
+func (t *TenancyController) applyUsers(ctx op_context.Context, template *multitenancy.TenancyTemplate) error {
+	for _, user := range template.Users {
+		err := t.Manager.templateUsers.AddTemplateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save settings of template that are not overridden in tenancy yet.
+func (t *TenancyController) applySettings(ctx op_context.Context, tenancy *multitenancy.TenancyItem, template *multitenancy.TenancyTemplate) error {
+
+	keys := utils.AllMapKeys(template.Settings)
+	sort.Strings(keys)
+	for _, key := range keys {
+		normalizedKey := multitenancy.NormalizeSettingKey(key)
+		setting, err := t.findSetting(ctx, tenancy.GetID(), normalizedKey)
+		if err != nil {
+			return err
+		}
+		if setting != nil {
+			continue
+		}
+		setting = &multitenancy.TenancySetting{}
+		setting.InitObject()
+		setting.TENANCY_ID = tenancy.GetID()
+		setting.KEY = normalizedKey
+		setting.VALUE = template.Settings[key]
+		err = t.CRUD.Create(ctx, setting)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *TenancyController) ApplyTemplate(ctx op_context.Context, id string, templateName string, idIsDisplay ...bool) (*multitenancy.TenancyItem, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TenancyController.ApplyTemplate")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find tenancy
+	tenancy, err := t.Find(ctx, id, idIsDisplay...)
+	if err != nil {
+		return nil, err
+	}
+	if tenancy.IsDeleted() {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyDeleted)
+		err = errors.New("tenancy is deleted")
+		return nil, err
+	}
+
+	// find template
+	if templateName == "" {
+		templateName = tenancy.TEMPLATE
+	}
+	if templateName == "" {
+		ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyTemplateNotFound)
+		err = errors.New("tenancy has no template")
+		return nil, err
+	}
+	template, err := t.Manager.FindTemplate(ctx, c, templateName)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply template
+	save := func() error {
+		return t.CRUD.Update(ctx, &tenancy.TenancyDb, db.Fields{"template": template.Name, "template_version": template.Version})
+	}
+	err = t.applyTemplate(ctx, tenancy, template, save)
+	if err != nil {
+		c.SetMessage("failed to apply template")
+		return nil, err
+	}
+
+	// save oplog
+	t.OpLog(ctx, multitenancy.OpApplyTemplate, &multitenancy.OpLogTenancy{TenancyId: tenancy.GetID(),
+		Role: tenancy.Role(), Customer: tenancy.CustomerDisplay(), Template: template.Name, Version: template.Version})
+
+	// publish notification
+	t.PublishOp(tenancy, multitenancy.OpApplyTemplate)
+
+	// done
+	return tenancy, nil
+}
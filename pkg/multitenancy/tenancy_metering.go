@@ -0,0 +1,78 @@
+package multitenancy
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const (
+	MetricRequests string = "requests"
+	MetricSms      string = "sms"
+	// Size of tenancy database or schema. Storage of tenancies in shared databases is not measured.
+	MetricStorage     string = "storage"
+	MetricActiveUsers string = "active_users"
+)
+
+// Metrics that are accumulated during month. Other metrics keep the last measured value.
+var CountedMetrics = []string{MetricRequests, MetricSms, MetricActiveUsers}
+
+// Configuration path of tenancy quotas. Quotas are set per tenancy with tenancy settings, zero quota means no limit.
+const QuotasPath string = "multitenancy.quotas"
+
+func QuotaKey(metric string) string {
+	return object_config.Key(QuotasPath, metric)
+}
+
+// TenancyUsage is a value of usage metric of tenancy in a month.
+type TenancyUsage struct {
+	common.ObjectWithMonth
+	TENANCY_ID string `gorm:"index" json:"tenancy_id"`
+	METRIC     string `gorm:"index" json:"metric"`
+	VALUE      int64  `json:"value"`
+}
+
+func (TenancyUsage) TableName() string {
+	return "tenancy_usage"
+}
+
+// TenancyActiveUser is a user that was active in tenancy in a month.
+type TenancyActiveUser struct {
+	common.ObjectWithMonth
+	TENANCY_ID string `gorm:"index" json:"tenancy_id"`
+	USER_ID    string `gorm:"index" json:"user_id"`
+}
+
+func (TenancyActiveUser) TableName() string {
+	return "tenancy_active_users"
+}
+
+// Models of tenancy metering that must be migrated with PartitionedMonthAutoMigrate in main database, see MigrateDbModels().
+func PartitionedDbModels() []interface{} {
+	return []interface{}{&TenancyUsage{}, &TenancyActiveUser{}}
+}
+
+type Metering interface {
+
+	// Add delta to usage of metric in tenancy in current month.
+	Count(tenancyId string, metric string, delta int64)
+
+	// Register activity of user in tenancy in current month.
+	CountActiveUser(ctx op_context.Context, tenancyId string, userId string) error
+
+	// Get usage of metric in tenancy in given month.
+	Usage(ctx op_context.Context, tenancyId string, metric string, month utils.Month) (int64, error)
+
+	// Check if usage of metric in tenancy in current month reached quota of tenancy.
+	QuotaExceeded(ctx op_context.Context, tenancy Tenancy, metric string) (bool, error)
+}
+
+// Get quota of metric in tenancy, zero means no limit.
+func Quota(tenancy Tenancy, metric string) int64 {
+	cfg := tenancy.Cfg()
+	if cfg == nil || !cfg.IsSet(QuotaKey(metric)) {
+		return 0
+	}
+	return cfg.GetInt64(QuotaKey(metric))
+}
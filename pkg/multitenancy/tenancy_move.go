@@ -0,0 +1,51 @@
+package multitenancy
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+)
+
+const (
+	MoveStatusCopying   string = "copying"
+	MoveStatusVerifying string = "verifying"
+	MoveStatusDone      string = "done"
+	MoveStatusFailed    string = "failed"
+)
+
+type TenancyMoveData struct {
+	TENANCY_ID     string    `gorm:"index" json:"tenancy_id"`
+	SOURCE_POOL_ID string    `json:"source_pool_id"`
+	SOURCE_DBNAME  string    `json:"source_dbname"`
+	TARGET_POOL_ID string    `json:"target_pool_id"`
+	TARGET_DBNAME  string    `json:"target_dbname"`
+	STATUS         string    `gorm:"index" json:"status"`
+	TABLES_TOTAL   int       `json:"tables_total"`
+	TABLES_DONE    int       `json:"tables_done"`
+	CURRENT_TABLE  string    `json:"current_table"`
+	ROWS_COPIED    int64     `json:"rows_copied"`
+	LAST_ERROR     string    `json:"last_error"`
+	FINISHED_AT    time.Time `json:"finished_at"`
+}
+
+// TenancyMove keeps state and progress of moving tenancy data to other database.
+type TenancyMove struct {
+	common.ObjectBase
+	TenancyMoveData
+}
+
+func (TenancyMove) TableName() string {
+	return "tenancy_moves"
+}
+
+func (m *TenancyMove) Status() string {
+	return m.STATUS
+}
+
+func (m *TenancyMove) IsDone() bool {
+	return m.STATUS == MoveStatusDone
+}
+
+func (m *TenancyMove) IsRunning() bool {
+	return m.STATUS == MoveStatusCopying || m.STATUS == MoveStatusVerifying
+}
@@ -4,10 +4,16 @@ import "github.com/evgeniums/go-backend-helpers/pkg/oplog"
 
 type OpLogTenancy struct {
 	oplog.OplogBase
-	Customer  string `gorm:"index" json:"customer"`
-	TenancyId string `gorm:"index" json:"tenancy_id"`
-	Role      string `gorm:"index" json:"role"`
-	Path      string `gorm:"index" json:"path"`
-	DbName    string `gorm:"index" json:"db_name"`
-	Pool      string `gorm:"index" json:"pool"`
+	Customer     string `gorm:"index" json:"customer"`
+	TenancyId    string `gorm:"index" json:"tenancy_id"`
+	Role         string `gorm:"index" json:"role"`
+	Path         string `gorm:"index" json:"path"`
+	DbName       string `gorm:"index" json:"db_name"`
+	Pool         string `gorm:"index" json:"pool"`
+	Domain       string `gorm:"index" json:"domain"`
+	Archive      string `gorm:"index" json:"archive"`
+	WithDatabase bool   `json:"with_database"`
+	Setting      string `gorm:"index" json:"setting"`
+	Template     string `gorm:"index" json:"template"`
+	Version      int    `json:"version"`
 }
@@ -0,0 +1,369 @@
+package multitenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+const TenancyScopeField string = "tenancy_id"
+
+// Interface of models that can be stored in database shared by multiple tenancies.
+type TenancyScoped interface {
+	TenancyScope() string
+	SetTenancyScope(tenancyId string)
+}
+
+// Embed WithTenancyScopeBase in models of tenancy database to make them usable in shared tenancy databases.
+type WithTenancyScopeBase struct {
+	TENANCY_ID string `gorm:"index" json:"-"`
+}
+
+func (w *WithTenancyScopeBase) TenancyScope() string {
+	return w.TENANCY_ID
+}
+
+func (w *WithTenancyScopeBase) SetTenancyScope(tenancyId string) {
+	w.TENANCY_ID = tenancyId
+}
+
+var tenancyScopedType = reflect.TypeOf((*TenancyScoped)(nil)).Elem()
+
+// Check if model or slice of models is tenancy scoped.
+func IsTenancyScoped(model interface{}) bool {
+	if model == nil {
+		return false
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		if t.Implements(tenancyScopedType) {
+			return true
+		}
+		t = t.Elem()
+	}
+	return reflect.PtrTo(t).Implements(tenancyScopedType)
+}
+
+// Check that all models can be stored in shared tenancy database.
+func CheckTenancyScopedModels(models []interface{}) error {
+	for _, model := range models {
+		if !IsTenancyScoped(model) {
+			return fmt.Errorf("model %s can not be used in shared tenancy database because it does not implement TenancyScoped", utils.ObjectTypeName(model))
+		}
+	}
+	return nil
+}
+
+func setTenancyScope(obj interface{}, tenancyId string) {
+	scoped, ok := obj.(TenancyScoped)
+	if ok {
+		scoped.SetTenancyScope(tenancyId)
+		return
+	}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			if item.Kind() != reflect.Ptr {
+				item = item.Addr()
+			}
+			setTenancyScope(item.Interface(), tenancyId)
+		}
+	}
+}
+
+// Database handlers scoping all queries of tenancy scoped models with tenancy ID.
+type TenancyScopedHandlers struct {
+	handlers  db.DBHandlers
+	tenancyId string
+}
+
+func (s *TenancyScopedHandlers) TenancyId() string {
+	return s.tenancyId
+}
+
+func (s *TenancyScopedHandlers) scopeFields(fields db.Fields, model interface{}) db.Fields {
+	if !IsTenancyScoped(model) {
+		return fields
+	}
+	f := utils.CopyMapOneLevel(fields)
+	f[TenancyScopeField] = s.tenancyId
+	return f
+}
+
+func (s *TenancyScopedHandlers) pushScope(filter *db.Filter, model interface{}) (*db.Filter, bool) {
+	if !IsTenancyScoped(model) {
+		return filter, false
+	}
+	if filter == nil {
+		filter = db.NewFilter()
+	}
+	filter.PushPresetFields(db.Fields{TenancyScopeField: s.tenancyId})
+	return filter, true
+}
+
+func popScope(filter *db.Filter, pushed bool) {
+	if pushed {
+		filter.PopPresetFields()
+	}
+}
+
+func (s *TenancyScopedHandlers) checkNewFields(fields db.Fields, model interface{}) error {
+	if IsTenancyScoped(model) && db.IsFieldSet(fields, TenancyScopeField) {
+		return errors.New("tenancy scope of object can not be changed")
+	}
+	return nil
+}
+
+func (s *TenancyScopedHandlers) FindByField(ctx logger.WithLogger, field string, value interface{}, obj interface{}, dest ...interface{}) (bool, error) {
+	return s.handlers.FindByFields(ctx, s.scopeFields(db.Fields{field: value}, obj), obj, dest...)
+}
+
+func (s *TenancyScopedHandlers) FindByFields(ctx logger.WithLogger, fields db.Fields, obj interface{}, dest ...interface{}) (bool, error) {
+	return s.handlers.FindByFields(ctx, s.scopeFields(fields, obj), obj, dest...)
+}
+
+func (s *TenancyScopedHandlers) FindWithFilter(ctx logger.WithLogger, filter *db.Filter, docs interface{}, dest ...interface{}) (int64, error) {
+	f, pushed := s.pushScope(filter, docs)
+	defer popScope(f, pushed)
+	return s.handlers.FindWithFilter(ctx, f, docs, dest...)
+}
+
+func (s *TenancyScopedHandlers) FindForUpdate(ctx logger.WithLogger, fields db.Fields, obj interface{}) (bool, error) {
+	return s.handlers.FindForUpdate(ctx, s.scopeFields(fields, obj), obj)
+}
+
+func (s *TenancyScopedHandlers) FindForShare(ctx logger.WithLogger, fields db.Fields, obj interface{}) (bool, error) {
+	return s.handlers.FindForShare(ctx, s.scopeFields(fields, obj), obj)
+}
+
+func (s *TenancyScopedHandlers) Exists(ctx logger.WithLogger, filter *db.Filter, doc interface{}) (bool, error) {
+	f, pushed := s.pushScope(filter, doc)
+	defer popScope(f, pushed)
+	return s.handlers.Exists(ctx, f, doc)
+}
+
+func (s *TenancyScopedHandlers) Create(ctx logger.WithLogger, obj interface{}) error {
+	if IsTenancyScoped(obj) {
+		setTenancyScope(obj, s.tenancyId)
+	}
+	return s.handlers.Create(ctx, obj)
+}
+
+func (s *TenancyScopedHandlers) CreateDup(ctx logger.WithLogger, obj interface{}) (bool, error) {
+	if IsTenancyScoped(obj) {
+		setTenancyScope(obj, s.tenancyId)
+	}
+	return s.handlers.CreateDup(ctx, obj)
+}
+
+func (s *TenancyScopedHandlers) CreateIfNotExists(ctx logger.WithLogger, obj interface{}) (bool, error) {
+	if IsTenancyScoped(obj) {
+		setTenancyScope(obj, s.tenancyId)
+	}
+	return s.handlers.CreateIfNotExists(ctx, obj)
+}
+
+func (s *TenancyScopedHandlers) Delete(ctx logger.WithLogger, obj common.Object) error {
+	if !IsTenancyScoped(obj) {
+		return s.handlers.Delete(ctx, obj)
+	}
+	return s.handlers.DeleteByFields(ctx, s.scopeFields(db.Fields{"id": obj.GetID()}, obj), obj)
+}
+
+func (s *TenancyScopedHandlers) DeleteByField(ctx logger.WithLogger, field string, value interface{}, model interface{}) error {
+	return s.handlers.DeleteByFields(ctx, s.scopeFields(db.Fields{field: value}, model), model)
+}
+
+func (s *TenancyScopedHandlers) DeleteByFields(ctx logger.WithLogger, fields db.Fields, obj interface{}) error {
+	return s.handlers.DeleteByFields(ctx, s.scopeFields(fields, obj), obj)
+}
+
+func (s *TenancyScopedHandlers) RowsWithFilter(ctx logger.WithLogger, filter *db.Filter, obj interface{}) (db.Cursor, error) {
+	f, pushed := s.pushScope(filter, obj)
+	defer popScope(f, pushed)
+	return s.handlers.RowsWithFilter(ctx, f, obj)
+}
+
+func (s *TenancyScopedHandlers) AllRows(ctx logger.WithLogger, obj interface{}) (db.Cursor, error) {
+	if !IsTenancyScoped(obj) {
+		return s.handlers.AllRows(ctx, obj)
+	}
+	return s.RowsWithFilter(ctx, nil, obj)
+}
+
+func (s *TenancyScopedHandlers) Update(ctx logger.WithLogger, obj interface{}, filter db.Fields, fields db.Fields) error {
+	err := s.checkNewFields(fields, obj)
+	if err != nil {
+		return err
+	}
+	return s.handlers.Update(ctx, obj, s.scopeFields(filter, obj), fields)
+}
+
+func (s *TenancyScopedHandlers) UpdateAll(ctx logger.WithLogger, obj interface{}, newFields db.Fields) error {
+	if !IsTenancyScoped(obj) {
+		return s.handlers.UpdateAll(ctx, obj, newFields)
+	}
+	return s.UpdateWithFilter(ctx, obj, nil, newFields)
+}
+
+func (s *TenancyScopedHandlers) UpdateWithFilter(ctx logger.WithLogger, obj interface{}, filter *db.Filter, newFields db.Fields) error {
+	err := s.checkNewFields(newFields, obj)
+	if err != nil {
+		return err
+	}
+	f, pushed := s.pushScope(filter, obj)
+	defer popScope(f, pushed)
+	return s.handlers.UpdateWithFilter(ctx, obj, f, newFields)
+}
+
+func (s *TenancyScopedHandlers) Join(ctx logger.WithLogger, joinConfig *db.JoinQueryConfig, filter *db.Filter, dest interface{}) (int64, error) {
+	return 0, errors.New("join queries are not supported in shared tenancy database, use unscoped database with explicit tenancy filter")
+}
+
+func (s *TenancyScopedHandlers) Joiner() db.Joiner {
+	return s.handlers.Joiner()
+}
+
+func (s *TenancyScopedHandlers) CreateDatabase(ctx logger.WithLogger, dbName string) error {
+	return s.handlers.CreateDatabase(ctx, dbName)
+}
+
+func (s *TenancyScopedHandlers) CreateSchema(ctx logger.WithLogger, schema string) error {
+	return s.handlers.CreateSchema(ctx, schema)
+}
+
+func (s *TenancyScopedHandlers) DropDatabase(ctx logger.WithLogger, dbName string) error {
+	return errors.New("database can not be dropped in shared tenancy database, use unscoped database")
+}
+
+func (s *TenancyScopedHandlers) DropSchema(ctx logger.WithLogger, schema string) error {
+	return errors.New("schema can not be dropped in shared tenancy database, use unscoped database")
+}
+
+func (s *TenancyScopedHandlers) DatabaseSize(ctx logger.WithLogger, dbName string) (int64, error) {
+	return s.handlers.DatabaseSize(ctx, dbName)
+}
+
+func (s *TenancyScopedHandlers) SchemaSize(ctx logger.WithLogger, schema string) (int64, error) {
+	return s.handlers.SchemaSize(ctx, schema)
+}
+
+func (s *TenancyScopedHandlers) MakeExpression(expr string, args ...interface{}) interface{} {
+	return s.handlers.MakeExpression(expr, args...)
+}
+
+func (s *TenancyScopedHandlers) Sum(ctx logger.WithLogger, groupFields []string, sumFields []string, filter *db.Filter, model interface{}, dest ...interface{}) (int64, error) {
+	f, pushed := s.pushScope(filter, model)
+	defer popScope(f, pushed)
+	return s.handlers.Sum(ctx, groupFields, sumFields, f, model, dest...)
+}
+
+// Database shared by multiple tenancies. Queries of tenancy scoped models are limited to the tenancy.
+// Underlying database connection is owned by tenancy manager, so Close() does nothing.
+type TenancyScopedDB struct {
+	TenancyScopedHandlers
+	database db.DB
+}
+
+func NewTenancyScopedDB(database db.DB, tenancyId string) *TenancyScopedDB {
+	s := &TenancyScopedDB{database: database}
+	s.handlers = database
+	s.tenancyId = tenancyId
+	return s
+}
+
+// Get database without tenancy scoping.
+func (s *TenancyScopedDB) Unscoped() db.DB {
+	return s.database
+}
+
+// Get database without tenancy scoping if database is scoped, otherwise return database as is.
+func UnscopedDb(database db.DB) db.DB {
+	scoped, ok := database.(*TenancyScopedDB)
+	if ok {
+		return scoped.Unscoped()
+	}
+	return database
+}
+
+func (s *TenancyScopedDB) PrepareFilterParser(model interface{}, name string, validator ...*db.FilterValidator) (db.FilterParser, error) {
+	return s.database.PrepareFilterParser(model, name, validator...)
+}
+
+func (s *TenancyScopedDB) ParseFilter(query *db.Query, parserName string) (*db.Filter, error) {
+	return s.database.ParseFilter(query, parserName)
+}
+
+func (s *TenancyScopedDB) ParseFilterDirect(query *db.Query, model interface{}, name string, validator ...*db.FilterValidator) (*db.Filter, error) {
+	return s.database.ParseFilterDirect(query, model, name, validator...)
+}
+
+func (s *TenancyScopedDB) Clone() db.DB {
+	return NewTenancyScopedDB(s.database.Clone(), s.tenancyId)
+}
+
+func (s *TenancyScopedDB) InitWithConfig(ctx logger.WithLogger, vld validator.Validator, cfg *db.DBConfig) error {
+	return s.database.InitWithConfig(ctx, vld, cfg)
+}
+
+func (s *TenancyScopedDB) Transaction(handler db.TransactionHandler) error {
+	return s.database.Transaction(func(tx db.Transaction) error {
+		return handler(&TenancyScopedHandlers{handlers: tx, tenancyId: s.tenancyId})
+	})
+}
+
+func (s *TenancyScopedDB) EnableDebug(value bool) {
+	s.database.EnableDebug(value)
+}
+
+func (s *TenancyScopedDB) EnableVerboseErrors(value bool) {
+	s.database.EnableVerboseErrors(value)
+}
+
+func (s *TenancyScopedDB) AutoMigrate(ctx logger.WithLogger, models []interface{}) error {
+	return s.database.AutoMigrate(ctx, models)
+}
+
+func (s *TenancyScopedDB) PartitionedMonthAutoMigrate(ctx logger.WithLogger, models []interface{}) error {
+	return s.database.PartitionedMonthAutoMigrate(ctx, models)
+}
+
+func (s *TenancyScopedDB) CreateMonthPartitions(ctx logger.WithLogger, months []utils.Month, models []interface{}) error {
+	return s.database.CreateMonthPartitions(ctx, months, models)
+}
+
+// Partitions are shared by all tenancies of database, so they can be listed and dropped only in unscoped database.
+func (s *TenancyScopedDB) ListMonthPartitions(ctx logger.WithLogger, model interface{}) ([]*db.MonthPartitionInfo, error) {
+	return nil, errors.New("partitions can not be listed in shared tenancy database, use unscoped database")
+}
+
+func (s *TenancyScopedDB) DropMonthPartition(ctx logger.WithLogger, model interface{}, month utils.Month) error {
+	return errors.New("partitions can not be dropped in shared tenancy database, use unscoped database")
+}
+
+// Native handler of shared database. Queries made with native handler are not scoped with tenancy ID.
+func (s *TenancyScopedDB) NativeHandler() interface{} {
+	return s.database.NativeHandler()
+}
+
+func (s *TenancyScopedDB) Ping(ctx logger.WithLogger) error {
+	return s.database.Ping(ctx)
+}
+
+func (s *TenancyScopedDB) PingContext(ctx logger.WithLogger, sysCtx context.Context) error {
+	return s.database.PingContext(ctx, sysCtx)
+}
+
+func (s *TenancyScopedDB) Close() {
+}
@@ -0,0 +1,225 @@
+package multitenancy
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_viper"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+type TenancySettingData struct {
+	KEY   string `gorm:"index;index:,unique,composite:u" json:"key" validate:"required" vmessage:"Setting key must be specified" long:"key" description:"Configuration key of setting, e.g. auth.methods.token.access_token_ttl_seconds" required:"true"`
+	VALUE string `json:"value" long:"value" description:"Value of setting"`
+}
+
+func (s *TenancySettingData) Key() string {
+	return s.KEY
+}
+
+func (s *TenancySettingData) Value() string {
+	return s.VALUE
+}
+
+// TenancySetting overrides value of application configuration key for tenancy.
+type TenancySetting struct {
+	common.ObjectBase
+	TENANCY_ID string `gorm:"index;index:,unique,composite:u" json:"tenancy_id"`
+	TenancySettingData
+}
+
+func (TenancySetting) TableName() string {
+	return "tenancy_settings"
+}
+
+const (
+	SettingString  string = "string"
+	SettingInt     string = "int"
+	SettingBool    string = "bool"
+	SettingFloat   string = "float"
+	SettingStrings string = "strings"
+)
+
+// Specification of configuration key that can be overridden in tenancy.
+type TenancySettingSpec struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Rules string `json:"rules,omitempty"`
+}
+
+// Schema of configuration keys that can be overridden in tenancies.
+type TenancySettingsSchema struct {
+	mutex sync.RWMutex
+	specs map[string]*TenancySettingSpec
+}
+
+func NewTenancySettingsSchema() *TenancySettingsSchema {
+	s := &TenancySettingsSchema{}
+	s.specs = make(map[string]*TenancySettingSpec)
+	return s
+}
+
+// Component with configuration keys that can be overridden in tenancies.
+type WithTenancySettings interface {
+	// Register configuration keys of component in schema of tenancy settings.
+	RegisterTenancySettings(schema *TenancySettingsSchema) error
+}
+
+func NormalizeSettingKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func (s *TenancySettingsSchema) Register(specs ...*TenancySettingSpec) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, spec := range specs {
+		spec.Key = NormalizeSettingKey(spec.Key)
+		s.specs[spec.Key] = spec
+	}
+}
+
+func settingType(kind reflect.Kind, elem reflect.Type) (string, error) {
+	switch kind {
+	case reflect.String:
+		return SettingString, nil
+	case reflect.Bool:
+		return SettingBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SettingInt, nil
+	case reflect.Float32, reflect.Float64:
+		return SettingFloat, nil
+	case reflect.Slice:
+		if elem != nil && elem.Kind() == reflect.String {
+			return SettingStrings, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type of setting %s", kind)
+}
+
+// Register fields of configuration object loaded from configuration path. Types and validation rules are taken from the fields.
+func (s *TenancySettingsSchema) RegisterObject(path string, obj interface{}, fields ...string) error {
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return errors.New("configuration object must be a struct")
+	}
+	t := v.Type()
+	for _, name := range fields {
+		field, ok := t.FieldByName(name)
+		if !ok {
+			return fmt.Errorf("unknown field %s of configuration object", name)
+		}
+		var elem reflect.Type
+		if field.Type.Kind() == reflect.Slice {
+			elem = field.Type.Elem()
+		}
+		typ, err := settingType(field.Type.Kind(), elem)
+		if err != nil {
+			return fmt.Errorf("invalid field %s: %s", name, err)
+		}
+		s.Register(&TenancySettingSpec{Key: object_config.Key(path, strings.ToLower(name)), Type: typ, Rules: field.Tag.Get("validate")})
+	}
+	return nil
+}
+
+func (s *TenancySettingsSchema) Spec(key string) (*TenancySettingSpec, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	spec, ok := s.specs[NormalizeSettingKey(key)]
+	return spec, ok
+}
+
+func (s *TenancySettingsSchema) Specs() []*TenancySettingSpec {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	specs := make([]*TenancySettingSpec, 0, len(s.specs))
+	for _, spec := range s.specs {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
+	return specs
+}
+
+func (spec *TenancySettingSpec) Convert(value string) (interface{}, error) {
+	switch spec.Type {
+	case SettingInt:
+		return strconv.Atoi(strings.TrimSpace(value))
+	case SettingBool:
+		return strconv.ParseBool(strings.TrimSpace(value))
+	case SettingFloat:
+		return strconv.ParseFloat(strings.TrimSpace(value), 64)
+	case SettingStrings:
+		if strings.TrimSpace(value) == "" {
+			return []string{}, nil
+		}
+		items := strings.Split(value, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items, nil
+	}
+	return value, nil
+}
+
+// Convert setting value to type of its key and validate it using rules of the key.
+func (s *TenancySettingsSchema) Parse(vld validator.Validator, key string, value string) (interface{}, error) {
+	spec, ok := s.Spec(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %s", key)
+	}
+	v, err := spec.Convert(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of setting %s: %s", key, err)
+	}
+	if spec.Rules != "" && spec.Type != SettingStrings {
+		err = vld.ValidateValue(v, spec.Rules)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Make configuration of tenancy with settings layered over base configuration.
+// Settings unknown to the schema are kept as strings.
+func NewTenancyConfig(schema *TenancySettingsSchema, settings []*TenancySetting, base config.Config) config.Config {
+	top := config_viper.New()
+	for _, setting := range settings {
+		var value interface{} = setting.Value()
+		spec, ok := schema.Spec(setting.Key())
+		if ok {
+			v, err := spec.Convert(setting.Value())
+			if err == nil {
+				value = v
+			}
+		}
+		top.Set(setting.Key(), value)
+	}
+	return config.NewLayeredConfig(top, base)
+}
+
+// Get integer value of configuration key in tenancy context or default value if the key is not set.
+func CfgInt(ctx TenancyContext, key string, defaultValue int) int {
+	cfg := ctx.Cfg()
+	if cfg == nil || !cfg.IsSet(key) {
+		return defaultValue
+	}
+	return cfg.GetInt(key)
+}
+
+// Get boolean value of configuration key in tenancy context or default value if the key is not set.
+func CfgBool(ctx TenancyContext, key string, defaultValue bool) bool {
+	cfg := ctx.Cfg()
+	if cfg == nil || !cfg.IsSet(key) {
+		return defaultValue
+	}
+	return cfg.GetBool(key)
+}
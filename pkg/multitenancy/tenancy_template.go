@@ -0,0 +1,120 @@
+package multitenancy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TemplateFormatYaml string = "yaml"
+	TemplateFormatJson string = "json"
+)
+
+// Extensions of template files looked up in directory of templates.
+var TemplateExtensions = []string{".yaml", ".yml", ".json"}
+
+// Fixture is a set of rows of tenancy database model.
+// Model is a table name if model defines TableName() otherwise a name of model type.
+// Each row must have ID, rows are decoded from JSON into model.
+type TenancyTemplateFixture struct {
+	Model string            `json:"model"`
+	Rows  []json.RawMessage `json:"rows"`
+}
+
+// Default user of tenancy created with TemplateUsers.
+type TenancyTemplateUser struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+}
+
+// TenancyTemplate is a named bundle of seed data applied to tenancy when tenancy is created.
+// Template can be re-applied to bring existing tenancy up to new version of template.
+// Applying is idempotent: only rows, users and settings missing in tenancy are added, existing data is never overwritten.
+type TenancyTemplate struct {
+	Name        string                    `json:"name"`
+	Version     int                       `json:"version"`
+	Description string                    `json:"description,omitempty"`
+	Fixtures    []*TenancyTemplateFixture `json:"fixtures,omitempty"`
+	Users       []*TenancyTemplateUser    `json:"users,omitempty"`
+	Settings    map[string]string         `json:"settings,omitempty"`
+}
+
+func (t *TenancyTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("name of template must not be empty")
+	}
+	if t.Version <= 0 {
+		return errors.New("version of template must be positive")
+	}
+	for _, fixture := range t.Fixtures {
+		if fixture.Model == "" {
+			return errors.New("model of fixture must not be empty")
+		}
+	}
+	for _, user := range t.Users {
+		if user.Login == "" {
+			return errors.New("login of template user must not be empty")
+		}
+	}
+	return nil
+}
+
+// Unmarshal template from YAML or JSON.
+func UnmarshalTenancyTemplate(data []byte, format string) (*TenancyTemplate, error) {
+
+	switch format {
+	case TemplateFormatJson:
+	case TemplateFormatYaml:
+		// convert via generic object so that YAML keys are the same as JSON keys
+		var obj interface{}
+		err := yaml.Unmarshal(data, &obj)
+		if err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported template format %s", format)
+	}
+
+	template := &TenancyTemplate{}
+	err := json.Unmarshal(data, template)
+	if err != nil {
+		return nil, err
+	}
+	err = template.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Load template from file, format is detected by file extension.
+func LoadTenancyTemplate(fileName string) (*TenancyTemplate, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	format := TemplateFormatYaml
+	if strings.ToLower(filepath.Ext(fileName)) == ".json" {
+		format = TemplateFormatJson
+	}
+	return UnmarshalTenancyTemplate(data, format)
+}
+
+// TemplateUsers creates default users of tenancy templates.
+type TemplateUsers interface {
+	// Add user if user with the same login does not exist. Database of context is a database of tenancy.
+	AddTemplateUser(ctx op_context.Context, user *TenancyTemplateUser) error
+}
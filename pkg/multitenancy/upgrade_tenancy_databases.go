@@ -3,12 +3,13 @@ package multitenancy
 import (
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 )
 
 func UpgradeTenancyDatabase(ctx op_context.Context, tenancy Tenancy, dbModels *TenancyDbModels) error {
 
 	// setup
-	loggerFields := logger.Fields{"tenancy": tenancy.GetID(), "tenancy_db": tenancy.DbName()}
+	loggerFields := logger.Fields{"tenancy": tenancy.GetID(), "tenancy_db": tenancy.DbName(), "tenancy_schema": tenancy.Schema()}
 	var err error
 	c := ctx.TraceInMethod("multitenancy.UpgradeTenancyDatabase", loggerFields)
 	onExit := func() {
@@ -19,6 +20,24 @@ func UpgradeTenancyDatabase(ctx op_context.Context, tenancy Tenancy, dbModels *T
 	}
 	defer onExit()
 
+	// check if models can be used in shared database
+	if tenancy.IsShared() {
+		err = CheckTenancyScopedModels(utils.ConcatSlices(dbModels.DbModels, dbModels.PartitionedDbModels))
+		if err != nil {
+			c.SetMessage("invalid models for shared tenancy database")
+			return err
+		}
+	}
+
+	// make sure that schema exists
+	if tenancy.IsSchema() {
+		err = tenancy.Db().CreateSchema(ctx, tenancy.Schema())
+		if err != nil {
+			c.SetMessage("failed to create schema in tenancy database")
+			return err
+		}
+	}
+
 	// migrate internal implicit models
 	err = tenancy.Db().AutoMigrate(ctx, DbInternalModels())
 	if err != nil {
@@ -49,8 +68,14 @@ func UpgradeTenancyDatabases(ctx op_context.Context, multitenancy Multitenancy,
 	c := ctx.TraceInMethod("multitenancy.UpgradeTenancyDatabases")
 	defer ctx.TraceOutMethod()
 
-	for _, tenancy := range multitenancy.Tenancies() {
-		err := UpgradeTenancyDatabase(ctx, tenancy, dbModels)
+	for _, id := range multitenancy.TenancyIds() {
+		tenancy, err := multitenancy.Tenancy(id)
+		if err != nil {
+			c.SetLoggerField("tenancy", id)
+			c.SetMessage("failed to load tenancy")
+			return c.SetError(err)
+		}
+		err = UpgradeTenancyDatabase(ctx, tenancy, dbModels)
 		if err != nil {
 			return c.SetError(err)
 		}
@@ -0,0 +1,27 @@
+package default_op_context
+
+import (
+	"net/http"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+func NewBackgroundContext(app app_context.Context, name string) op_context.Context {
+
+	opCtx := NewContext()
+	opCtx.Init(app, app.Logger(), app.Db())
+	opCtx.SetName(name)
+	errManager := &generic_error.ErrorManagerBase{}
+	errManager.Init(http.StatusInternalServerError)
+	opCtx.SetErrorManager(errManager)
+
+	origin := NewOrigin(app)
+	origin.SetUser(background_worker.ContextUser)
+	origin.SetUserType(op_context.AutoUserType)
+	opCtx.SetOrigin(origin)
+
+	return opCtx
+}
@@ -97,6 +97,7 @@ type ContextBase struct {
 
 	oplogs       []oplog.Oplog
 	oplogHandler op_context.OplogHandler
+	oplogTenancy string
 
 	origin        op_context.Origin
 	writeCloseLog bool
@@ -129,6 +130,9 @@ func (c *ContextBase) Init(app app_context.Context, log logger.Logger, db db.DB,
 	c.stack = make([]op_context.CallContext, 0)
 
 	c.oplogHandler = oplog_db.MakeOplogController
+	if withOplog, ok := app.(op_context.AppWithOplogHandler); ok && withOplog.OplogHandler() != nil {
+		c.oplogHandler = withOplog.OplogHandler()
+	}
 
 	c.Logger().Trace("open")
 }
@@ -145,6 +149,14 @@ func (c *ContextBase) SetOplogHandler(handler op_context.OplogHandler) {
 	c.oplogHandler = handler
 }
 
+func (c *ContextBase) OplogTenancy() string {
+	return c.oplogTenancy
+}
+
+func (c *ContextBase) SetOplogTenancy(tenancyId string) {
+	c.oplogTenancy = tenancyId
+}
+
 func (c *ContextBase) SetErrorManager(manager generic_error.ErrorManager) {
 	c.errorManager = manager
 }
@@ -103,6 +103,31 @@ type CallContextBuilder = func(methodName string, parentLogger logger.Logger, fi
 
 type OplogHandler = func(ctx Context) oplog.OplogController
 
+// Application that holds handler of oplog to be used by its operation contexts.
+type AppWithOplogHandler interface {
+	OplogHandler() OplogHandler
+	SetOplogHandler(handler OplogHandler)
+}
+
+// Context that keeps ID of tenancy which oplog records belong to.
+type WithOplogTenancy interface {
+	OplogTenancy() string
+	SetOplogTenancy(tenancyId string)
+}
+
+// Tag log entries of context with name of component, returned function restores previous component.
+func SetLogComponent(c Context, component string) func() {
+	previous, ok := c.LoggerFields()[logger.ComponentField]
+	c.SetLoggerField(logger.ComponentField, component)
+	return func() {
+		if ok {
+			c.SetLoggerField(logger.ComponentField, previous)
+		} else {
+			c.UnsetLoggerField(logger.ComponentField)
+		}
+	}
+}
+
 func DB(c Context, forceMainDb ...bool) db.DBHandlers {
 	if c.DbTransaction() != nil {
 		return c.DbTransaction()
@@ -0,0 +1,87 @@
+package oplog
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Oplog with explicit type name used in audit records.
+type WithOplogType interface {
+	OplogType() string
+}
+
+// Get type of oplog: explicit type name if oplog implements WithOplogType, otherwise type name of oplog structure without "OpLog" prefix in snake case, e.g. "user" for OpLogUser.
+func OplogType(o Oplog) string {
+	if t, ok := o.(WithOplogType); ok {
+		return t.OplogType()
+	}
+	name := strings.TrimPrefix(utils.ObjectTypeName(o), "OpLog")
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Audit record is a unified record of oplog of any type.
+type AuditRecord struct {
+	common.ObjectBase
+	TYPE             string `gorm:"index" json:"type"`
+	OPLOG_ID         string `gorm:"index" json:"oplog_id"`
+	TENANCY          string `gorm:"index" json:"tenancy"`
+	OPERATION        string `gorm:"index" json:"operation"`
+	CONTEXT          string `gorm:"index" json:"context"`
+	CONTEXT_NAME     string `gorm:"index" json:"context_name"`
+	ORIGIN_APP       string `gorm:"index" json:"origin_app"`
+	ORIGIN_NAME      string `gorm:"index" json:"origin_name"`
+	ORIGIN_USER      string `gorm:"index" json:"origin_user"`
+	ORIGIN_SOURCE    string `gorm:"index" json:"origin_source"`
+	ORIGIN_CLIENT    string `gorm:"index" json:"origin_client"`
+	ORIGIN_USER_TYPE string `gorm:"index" json:"origin_user_type"`
+	DETAILS          string `json:"details"`
+}
+
+// Create audit record from oplog. Tenancy is taken from argument or from tenancy_id field of oplog.
+func NewAuditRecord(o Oplog, tenancy ...string) (*AuditRecord, error) {
+
+	details, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &AuditRecord{}
+	r.InitObject()
+	r.SetCreatedAt(o.GetCreatedAt())
+	r.OPERATION = o.Operation()
+	r.CONTEXT = o.Context()
+	r.CONTEXT_NAME = o.ContextName()
+	r.ORIGIN_APP = o.OriginApp()
+	r.ORIGIN_NAME = o.OriginName()
+	r.ORIGIN_USER = o.User()
+	r.ORIGIN_SOURCE = o.OriginSource()
+	r.ORIGIN_CLIENT = o.OriginClient()
+	r.ORIGIN_USER_TYPE = o.UserType()
+	r.TYPE = OplogType(o)
+	r.OPLOG_ID = o.GetID()
+	r.DETAILS = string(details)
+
+	r.TENANCY = utils.OptionalArg("", tenancy...)
+	if r.TENANCY == "" {
+		fields := struct {
+			TenancyId string `json:"tenancy_id"`
+		}{}
+		json.Unmarshal(details, &fields)
+		r.TENANCY = fields.TenancyId
+	}
+
+	return r, nil
+}
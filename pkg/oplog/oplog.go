@@ -37,6 +37,11 @@ type OplogHolder struct {
 	OriginSource string `gorm:"index" json:"origin_source"`
 	OriginClient string `gorm:"index" json:"origin_client"`
 	UserType     string `gorm:"index" json:"origin_user_type"`
+
+	Chain    string `gorm:"index" json:"chain,omitempty"`
+	ChainSeq int64  `gorm:"index" json:"chain_seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
 }
 
 type OplogBase struct {
@@ -116,6 +121,43 @@ func (o *OplogBase) SetUserType(val string) {
 	o.OplogHolder.UserType = val
 }
 
+func (o *OplogBase) ChainName() string {
+	return o.OplogHolder.Chain
+}
+
+func (o *OplogBase) ChainSeq() int64 {
+	return o.OplogHolder.ChainSeq
+}
+
+func (o *OplogBase) PrevHash() string {
+	return o.OplogHolder.PrevHash
+}
+
+func (o *OplogBase) SetChainLink(chain string, seq int64, prevHash string) {
+	o.OplogHolder.Chain = chain
+	o.OplogHolder.ChainSeq = seq
+	o.OplogHolder.PrevHash = prevHash
+}
+
+func (o *OplogBase) Hash() string {
+	return o.OplogHolder.Hash
+}
+
+func (o *OplogBase) SetHash(val string) {
+	o.OplogHolder.Hash = val
+}
+
+// Oplog that can be linked into tamper-evident hash chain.
+type ChainedOplog interface {
+	Oplog
+	ChainName() string
+	ChainSeq() int64
+	PrevHash() string
+	SetChainLink(chain string, seq int64, prevHash string)
+	Hash() string
+	SetHash(val string)
+}
+
 type OplogController interface {
 	Write(o Oplog) error
 	Read(filter *db.Filter, docs interface{}) (int64, error)
@@ -0,0 +1,15 @@
+package audit_api
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+)
+
+const ServiceName string = "audit"
+const RecordsResource string = "records"
+
+type ListRecordsResponse = api.ResponseList[*oplog.AuditRecord]
+
+var (
+	List = func() api.Operation { return api.List("list_audit_records") }
+)
@@ -0,0 +1,60 @@
+package audit_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit/audit_api"
+)
+
+type AuditClient struct {
+	api_client.ServiceClient
+
+	RecordsResource api.Resource
+
+	list api.Operation
+}
+
+func NewAuditClient(client api_client.Client) *AuditClient {
+
+	c := &AuditClient{}
+
+	c.Init(client, audit_api.ServiceName)
+	c.RecordsResource = api.NewResource(audit_api.RecordsResource)
+	c.AddChild(c.RecordsResource)
+
+	c.list = audit_api.List()
+	c.RecordsResource.AddOperation(c.list)
+
+	return c
+}
+
+func (a *AuditClient) List(ctx op_context.Context, filter *db.Filter) ([]*oplog.AuditRecord, int64, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("AuditClient.List")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// set query
+	cmd := api.NewDbQuery(filter)
+
+	// prepare and exec handler
+	handler := api_client.NewHandler(cmd, &audit_api.ListRecordsResponse{})
+	err = a.list.Exec(ctx, api_client.MakeOperationHandler(a.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, 0, err
+	}
+
+	// done
+	return handler.Result.Items, handler.Result.Count, nil
+}
@@ -0,0 +1,43 @@
+package audit_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit/audit_api"
+)
+
+type AuditEndpoint struct {
+	service *AuditService
+	api_server.EndpointBase
+}
+
+func (e *AuditEndpoint) Construct(service *AuditService, op api.Operation) {
+	e.service = service
+	e.EndpointBase.Construct(op)
+}
+
+type AuditService struct {
+	api_server.ServiceBase
+	Audit oplog_audit.AuditController
+
+	RecordsResource api.Resource
+}
+
+func NewAuditService(auditController oplog_audit.AuditController) *AuditService {
+
+	s := &AuditService{}
+	s.Audit = auditController
+
+	s.Init(audit_api.ServiceName)
+	s.RecordsResource = api.NewResource(audit_api.RecordsResource)
+	s.AddChild(s.RecordsResource)
+
+	listOp := List(s)
+	s.RecordsResource.AddOperation(listOp)
+
+	s.AddDynamicTables(&api_server.DynamicTableConfig{Model: &oplog.AuditRecord{}, Operation: listOp})
+
+	return s
+}
@@ -0,0 +1,44 @@
+package audit_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit/audit_api"
+)
+
+type ListEndpoint struct {
+	AuditEndpoint
+}
+
+func (e *ListEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("audit.List")
+	defer request.TraceOutMethod()
+
+	// parse query
+	queryName := request.Endpoint().Resource().ServicePathPrototype()
+	filter, err := api_server.ParseDbQuery(request, &oplog.AuditRecord{}, queryName)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// get records
+	resp := &audit_api.ListRecordsResponse{}
+	resp.Items, resp.Count, err = e.service.Audit.List(request, filter)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func List(s *AuditService) *ListEndpoint {
+	e := &ListEndpoint{}
+	e.Construct(s, audit_api.List())
+	return e
+}
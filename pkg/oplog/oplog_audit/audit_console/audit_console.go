@@ -0,0 +1,38 @@
+package audit_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit"
+)
+
+type AuditCommands struct {
+	console_tool.Commands[*AuditCommands]
+	GetAuditController func() oplog_audit.AuditController
+}
+
+func NewAuditCommands(auditController func() oplog_audit.AuditController) *AuditCommands {
+	p := &AuditCommands{}
+	p.Construct(p, "audit", "Search audit records of operations")
+	p.GetAuditController = auditController
+	p.LoadHandlers()
+	return p
+}
+
+func (p *AuditCommands) LoadHandlers() {
+	p.AddHandlers(ListRecords)
+}
+
+type Handler = console_tool.Handler[*AuditCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*AuditCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, oplog_audit.AuditController, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, b.Group.GetAuditController(), nil
+}
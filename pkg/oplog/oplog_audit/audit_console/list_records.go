@@ -0,0 +1,58 @@
+package audit_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const ListRecordsCmd string = "list"
+const ListRecordsDescription string = "List audit records"
+
+func ListRecords() Handler {
+	a := &ListRecordsHandler{}
+	a.Init(ListRecordsCmd, ListRecordsDescription)
+	return a
+}
+
+type ListRecordsData struct {
+	console_tool.QueryData
+	oplog_audit.AuditQuery
+}
+
+type ListRecordsHandler struct {
+	HandlerBase
+	ListRecordsData
+}
+
+func (a *ListRecordsHandler) Data() interface{} {
+	return &a.ListRecordsData
+}
+
+func (a *ListRecordsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	filter, err := db.ParseQuery(ctx.Db(), a.Query, &oplog.AuditRecord{}, "")
+	if err != nil {
+		return fmt.Errorf("failed to parse query: %s", err)
+	}
+	filter, err = a.AuditQuery.Filter(filter)
+	if err != nil {
+		return err
+	}
+
+	records, count, err := controller.List(ctx, filter)
+	if err == nil {
+		fmt.Printf("Audit records:\n\n%s\n\nTotal count %d\n\n", utils.DumpPrettyJson(records), count)
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package oplog_audit
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Query of audit records by user, origin, operation, tenancy, type of oplog and time range.
+// Time is in format "2006-01-02 15:04:05" or "2006-01-02" in UTC.
+type AuditQuery struct {
+	User      string `json:"user,omitempty" long:"user" description:"User who performed operation"`
+	Origin    string `json:"origin,omitempty" long:"origin" description:"Name of origin of operation"`
+	Operation string `json:"operation,omitempty" long:"operation" description:"Operation"`
+	Tenancy   string `json:"tenancy,omitempty" long:"tenancy" description:"ID of tenancy"`
+	Type      string `json:"type,omitempty" long:"type" description:"Type of oplog, e.g. user, admin, tenancy, pool, customer, pub_key"`
+	From      string `json:"from,omitempty" long:"from" description:"Beginning of time range"`
+	To        string `json:"to,omitempty" long:"to" description:"End of time range"`
+}
+
+// Add criteria of audit query to filter. If filter is not set then new filter is created.
+func (a *AuditQuery) Filter(filter ...*db.Filter) (*db.Filter, error) {
+
+	f := utils.OptionalArg(nil, filter...)
+	if f == nil {
+		f = db.NewFilter()
+	}
+
+	fields := map[string]string{
+		"origin_user": a.User,
+		"origin_name": a.Origin,
+		"operation":   a.Operation,
+		"tenancy":     a.Tenancy,
+		"type":        a.Type,
+	}
+	for name, value := range fields {
+		if value != "" {
+			f.AddField(name, value)
+		}
+	}
+
+	if a.From != "" || a.To != "" {
+		var from interface{}
+		var to interface{}
+		if a.From != "" {
+			t, err := utils.ParseTime(a.From)
+			if err != nil {
+				return nil, fmt.Errorf("invalid beginning of time range: %s", err)
+			}
+			from = t
+		}
+		if a.To != "" {
+			t, err := utils.ParseTime(a.To)
+			if err != nil {
+				return nil, fmt.Errorf("invalid end of time range: %s", err)
+			}
+			to = t
+		}
+		f.AddInterval("created_at", from, to)
+	}
+
+	return f, nil
+}
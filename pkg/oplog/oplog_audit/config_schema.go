@@ -0,0 +1,11 @@
+package oplog_audit
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+// Register configuration section of oplog audit in configuration schema.
+func RegisterConfigSchema(schema *config_schema.Schema, configPath ...string) {
+	schema.RegisterOptional(utils.OptionalArg("oplog_audit", configPath...), New())
+}
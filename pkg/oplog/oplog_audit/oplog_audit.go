@@ -0,0 +1,202 @@
+package oplog_audit
+
+import (
+	"errors"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_db"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+func DbModels() []interface{} {
+	return []interface{}{&oplog.AuditRecord{}}
+}
+
+// Sink of audit records.
+type Sink interface {
+	Write(ctx op_context.Context, record *oplog.AuditRecord) error
+}
+
+// Oplog controller that writes oplog with primary controller and additionally writes audit records of oplog to sinks.
+// Failures of sinks are logged but not returned.
+type AuditOplogController struct {
+	Ctx     op_context.Context
+	Primary oplog.OplogController
+	Sinks   []Sink
+}
+
+func (o *AuditOplogController) Write(op oplog.Oplog) error {
+
+	var err error
+	if o.Primary != nil {
+		err = o.Primary.Write(op)
+	}
+	if len(o.Sinks) == 0 {
+		return err
+	}
+
+	tenancy := ""
+	if t, ok := o.Ctx.(op_context.WithOplogTenancy); ok {
+		tenancy = t.OplogTenancy()
+	}
+	record, rErr := oplog.NewAuditRecord(op, tenancy)
+	if rErr != nil {
+		o.Ctx.Logger().Error("failed to make audit record", rErr, logger.Fields{"oplog": utils.ObjectTypeName(op)})
+		return err
+	}
+	for _, sink := range o.Sinks {
+		sErr := sink.Write(o.Ctx, record)
+		if sErr != nil {
+			o.Ctx.Logger().Error("failed to write audit record", sErr, logger.Fields{"oplog": utils.ObjectTypeName(op), "sink": utils.ObjectTypeName(sink)})
+		}
+	}
+
+	return err
+}
+
+func (o *AuditOplogController) Read(filter *db.Filter, docs interface{}) (int64, error) {
+	if o.Primary == nil {
+		return 0, errors.New("primary oplog controller not set")
+	}
+	return o.Primary.Read(filter, docs)
+}
+
+// Make handler of oplog that writes oplog with primary handler and to sinks.
+func MakeAuditOplogHandler(primary op_context.OplogHandler, sinks ...Sink) op_context.OplogHandler {
+	return func(ctx op_context.Context) oplog.OplogController {
+		controller := &AuditOplogController{Ctx: ctx, Sinks: sinks}
+		if primary != nil {
+			controller.Primary = primary(ctx)
+		}
+		return controller
+	}
+}
+
+type AuditConfig struct {
+	// Write audit records to central audit store in main database.
+	STORE bool `default:"true"`
+	// Write audit records in JSON lines to file, file is not written if path is empty.
+	FILE                string
+	FILE_ROTATE_SIZE_MB int `validate:"gte=0"`
+	FILE_MAX_BACKUPS    int `validate:"gte=0"`
+	FILE_MAX_AGE_DAYS   int `validate:"gte=0"`
+	// Publish audit records to pubsub topic, records are not published if topic is empty.
+	PUBSUB_TOPIC string
+}
+
+// Audit of oplog records of all types.
+// To enable audit set handler of oplog of contexts, e.g. app.SetOplogHandler(audit.OplogHandler()).
+type Audit struct {
+	AuditConfig
+	sinks    []Sink
+	fileSink *FileSink
+}
+
+func New() *Audit {
+	return &Audit{}
+}
+
+func (a *Audit) Config() interface{} {
+	return &a.AuditConfig
+}
+
+// Init audit. Publisher is required only if pubsub topic is set in configuration.
+func (a *Audit) Init(app app_context.Context, publisher pubsub.Publisher, configPath ...string) error {
+
+	path := utils.OptionalArg("oplog_audit", configPath...)
+	err := object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), a, path)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load configuration of oplog audit", err)
+	}
+
+	if a.STORE {
+		a.AddSink(NewDbSink())
+	}
+
+	if a.FILE != "" {
+		a.fileSink = NewFileSink(&utils.FileWriteReopen{
+			Path:       a.FILE,
+			MaxSize:    int64(a.FILE_ROTATE_SIZE_MB) * 1024 * 1024,
+			MaxBackups: a.FILE_MAX_BACKUPS,
+			MaxAge:     time.Duration(a.FILE_MAX_AGE_DAYS) * 24 * time.Hour,
+		})
+		a.AddSink(a.fileSink)
+	}
+
+	if a.PUBSUB_TOPIC != "" {
+		if publisher == nil {
+			return app.Logger().PushFatalStack("publisher must be set for pubsub topic of oplog audit", nil, logger.Fields{"topic": a.PUBSUB_TOPIC})
+		}
+		a.AddSink(NewPubsubSink(publisher, a.PUBSUB_TOPIC))
+	}
+
+	return nil
+}
+
+// Add custom sink of audit records.
+func (a *Audit) AddSink(sink Sink) {
+	a.sinks = append(a.sinks, sink)
+}
+
+func (a *Audit) Sinks() []Sink {
+	return a.sinks
+}
+
+// Get handler of oplog that writes oplog with primary handler and to sinks of audit. By default oplog is written to database of context.
+func (a *Audit) OplogHandler(primary ...op_context.OplogHandler) op_context.OplogHandler {
+	return MakeAuditOplogHandler(utils.OptionalArg(oplog_db.MakeOplogController, primary...), a.sinks...)
+}
+
+func (a *Audit) Close() error {
+	if a.fileSink != nil {
+		return a.fileSink.Close()
+	}
+	return nil
+}
+
+// Controller of audit records in central audit store.
+type AuditController interface {
+	List(ctx op_context.Context, filter *db.Filter) ([]*oplog.AuditRecord, int64, error)
+}
+
+type AuditControllerBase struct {
+	crud crud.CRUD
+}
+
+func NewAuditController() *AuditControllerBase {
+	return &AuditControllerBase{crud: &crud.DbCRUD{ForceMainDb: true}}
+}
+
+func (a *AuditControllerBase) List(ctx op_context.Context, filter *db.Filter) ([]*oplog.AuditRecord, int64, error) {
+
+	// setup
+	c := ctx.TraceInMethod("AuditController.List")
+	defer ctx.TraceOutMethod()
+
+	// the latest records go first by default
+	if filter == nil {
+		filter = db.NewFilter()
+	}
+	if filter.SortField == "" {
+		filter.SetSorting("created_at", db.SORT_DESC)
+	}
+
+	// list records
+	var records []*oplog.AuditRecord
+	count, err := a.crud.List(ctx, filter, &records)
+	if err != nil {
+		c.SetMessage("failed to list audit records")
+		return nil, 0, c.SetError(err)
+	}
+
+	// done
+	return records, count, nil
+}
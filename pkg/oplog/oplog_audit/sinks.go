@@ -0,0 +1,67 @@
+package oplog_audit
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub"
+)
+
+// Sink writing audit records to central audit store in main database.
+type DbSink struct {
+	crud crud.CRUD
+}
+
+func NewDbSink() *DbSink {
+	return &DbSink{crud: &crud.DbCRUD{ForceMainDb: true}}
+}
+
+func (d *DbSink) Write(ctx op_context.Context, record *oplog.AuditRecord) error {
+	return d.crud.Create(ctx, record)
+}
+
+// Sink writing audit records in JSON lines.
+type FileSink struct {
+	mutex  sync.Mutex
+	writer io.WriteCloser
+}
+
+func NewFileSink(writer io.WriteCloser) *FileSink {
+	return &FileSink{writer: writer}
+}
+
+func (f *FileSink) Write(ctx op_context.Context, record *oplog.AuditRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_, err = f.writer.Write(b)
+	return err
+}
+
+func (f *FileSink) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.writer.Close()
+}
+
+// Sink publishing audit records to pubsub topic.
+type PubsubSink struct {
+	publisher pubsub.Publisher
+	topic     string
+}
+
+func NewPubsubSink(publisher pubsub.Publisher, topic string) *PubsubSink {
+	return &PubsubSink{publisher: publisher, topic: topic}
+}
+
+func (p *PubsubSink) Write(ctx op_context.Context, record *oplog.AuditRecord) error {
+	return p.publisher.Publish(p.topic, record)
+}
@@ -0,0 +1,38 @@
+package chain_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_chain"
+)
+
+type ChainCommands struct {
+	console_tool.Commands[*ChainCommands]
+	GetChain func() *oplog_chain.Chain
+}
+
+func NewChainCommands(chain func() *oplog_chain.Chain) *ChainCommands {
+	p := &ChainCommands{}
+	p.Construct(p, "oplog_chain", "Verify hash chains of oplog records")
+	p.GetChain = chain
+	p.LoadHandlers()
+	return p
+}
+
+func (p *ChainCommands) LoadHandlers() {
+	p.AddHandlers(Verify, Checkpoint)
+}
+
+type Handler = console_tool.Handler[*ChainCommands]
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*ChainCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, *oplog_chain.Chain, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, b.Group.GetChain(), nil
+}
@@ -0,0 +1,48 @@
+package chain_console
+
+import (
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const CheckpointCmd string = "checkpoint"
+const CheckpointDescription string = "Make signed checkpoint of head of oplog chain"
+
+func Checkpoint() Handler {
+	a := &CheckpointHandler{}
+	a.Init(CheckpointCmd, CheckpointDescription)
+	return a
+}
+
+type CheckpointData struct {
+	Chain string `long:"chain" description:"Name of chain" required:"true"`
+}
+
+type CheckpointHandler struct {
+	HandlerBase
+	CheckpointData
+}
+
+func (a *CheckpointHandler) Data() interface{} {
+	return &a.CheckpointData
+}
+
+func (a *CheckpointHandler) Execute(args []string) error {
+
+	ctx, chain, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	checkpoint, err := chain.Checkpoint(ctx, a.Chain)
+	if err == nil {
+		if checkpoint == nil {
+			fmt.Printf("Chain is empty or its head is already checkpointed\n\n")
+		} else {
+			fmt.Printf("Created checkpoint:\n\n%s\n\n", utils.DumpPrettyJson(checkpoint))
+		}
+	}
+	return err
+}
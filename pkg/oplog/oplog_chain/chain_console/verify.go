@@ -0,0 +1,62 @@
+package chain_console
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_chain"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const VerifyCmd string = "verify"
+const VerifyDescription string = "Verify hash chains of oplog records and report gaps or modifications"
+
+func Verify() Handler {
+	a := &VerifyHandler{}
+	a.Init(VerifyCmd, VerifyDescription)
+	return a
+}
+
+type VerifyData struct {
+	Chain string `long:"chain" description:"Name of chain, all chains are verified if not set"`
+}
+
+type VerifyHandler struct {
+	HandlerBase
+	VerifyData
+}
+
+func (a *VerifyHandler) Data() interface{} {
+	return &a.VerifyData
+}
+
+func (a *VerifyHandler) Execute(args []string) error {
+
+	ctx, chain, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	var reports []*oplog_chain.Report
+	if a.Chain != "" {
+		report, err := chain.Verify(ctx, a.Chain)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+	} else {
+		reports, err = chain.VerifyAll(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Verification of oplog chains:\n\n%s\n\n", utils.DumpPrettyJson(reports))
+	for _, report := range reports {
+		if !report.Ok() {
+			return errors.New("oplog chain verification failed")
+		}
+	}
+	return nil
+}
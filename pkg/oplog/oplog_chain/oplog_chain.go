@@ -0,0 +1,425 @@
+package oplog_chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+const (
+	// Each oplog table has its own chain named after type of oplog.
+	ModeTable string = "table"
+	// Each tenancy has its own chain spanning all oplog tables.
+	ModeTenancy string = "tenancy"
+)
+
+// Name of chain of oplog records that do not belong to any tenancy in tenancy mode.
+const MainChain string = "main"
+
+// Head of chain keeps sequence number and hash of the last record in chain. ID of head is a name of chain.
+type ChainHead struct {
+	common.ObjectBase
+	SEQ  int64  `json:"seq"`
+	HASH string `json:"hash"`
+}
+
+func (ChainHead) TableName() string {
+	return "oplog_chain_heads"
+}
+
+// Signed checkpoint of chain.
+type Checkpoint struct {
+	common.ObjectBase
+	CHAIN     string `gorm:"index" json:"chain"`
+	SEQ       int64  `gorm:"index" json:"seq"`
+	HASH      string `json:"hash"`
+	SIGNATURE string `json:"signature"`
+}
+
+func (Checkpoint) TableName() string {
+	return "oplog_chain_checkpoints"
+}
+
+// Data signed in checkpoint.
+func (c *Checkpoint) SignedData() []byte {
+	return []byte(fmt.Sprintf("%s:%d:%s:%d", c.CHAIN, c.SEQ, c.HASH, c.GetCreatedAt().UTC().UnixMicro()))
+}
+
+// Models of chain that must be migrated in the same database where oplog records are written.
+func DbModels() []interface{} {
+	return []interface{}{&ChainHead{}, &Checkpoint{}}
+}
+
+// Calculate hash of oplog record. Hash covers all JSON fields of record including link to previous record except for hash itself and time of update.
+func Hash(o oplog.Oplog) (string, error) {
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	fields := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&fields)
+	if err != nil {
+		return "", err
+	}
+
+	// time is normalized because databases differ in how they return time zones
+	delete(fields, "hash")
+	delete(fields, "updated_at")
+	fields["created_at"] = strconv.FormatInt(o.GetCreatedAt().UTC().UnixMicro(), 10)
+
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return crypt_utils.H256B64(canonical), nil
+}
+
+type ChainConfig struct {
+	// Chain either per oplog table or per tenancy.
+	MODE string `default:"table" validate:"oneof=table tenancy"`
+	// Make signed checkpoint each time given number of records is added to chain, zero disables automatic checkpoints.
+	CHECKPOINT_INTERVAL int `default:"100" validate:"gte=0"`
+	// Private key for signing checkpoints, checkpoints are not made if key is not set.
+	PRIVATE_KEY_FILE     string `validate:"omitempty,file"`
+	PRIVATE_KEY_PASSWORD string `mask:"true"`
+	// Public key for verification of checkpoints, signatures of checkpoints are not checked if key is not set.
+	PUBLIC_KEY_FILE string `validate:"omitempty,file"`
+	// Period in seconds of background job making checkpoints of all chains in main database, zero disables background job.
+	CHECKPOINT_PERIOD int `validate:"gte=0"`
+}
+
+// Tamper-evident hash chain of oplog records.
+// Each record keeps hash of its content and hash of the previous record in the same chain.
+// To enable chaining set handler of oplog of contexts, e.g. app.SetOplogHandler(chain.OplogHandler()).
+type Chain struct {
+	ChainConfig
+	models   []oplog.ChainedOplog
+	signer   *crypt_utils.RsaSigner
+	verifier *crypt_utils.RsaVerifier
+	mutex    sync.Mutex
+	worker   *background_worker.BackgroundWorker
+}
+
+func New() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) Config() interface{} {
+	return &c.ChainConfig
+}
+
+func (c *Chain) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
+
+	path := utils.OptionalArg("oplog_chain", configPath...)
+	err := object_config.LoadLogValidate(cfg, log, vld, c, path)
+	if err != nil {
+		return log.PushFatalStack("failed to load configuration of oplog chain", err)
+	}
+
+	if c.PRIVATE_KEY_FILE != "" {
+		c.signer = crypt_utils.NewRsaSigner()
+		err = c.signer.LoadKeyFromFile(c.PRIVATE_KEY_FILE, c.PRIVATE_KEY_PASSWORD)
+		if err != nil {
+			return log.PushFatalStack("failed to load private key of oplog chain", err)
+		}
+	}
+
+	if c.PUBLIC_KEY_FILE != "" {
+		c.verifier = crypt_utils.NewRsaVerifier()
+		err = c.verifier.LoadKeyFromFile(c.PUBLIC_KEY_FILE)
+		if err != nil {
+			return log.PushFatalStack("failed to load public key of oplog chain", err)
+		}
+	}
+
+	return nil
+}
+
+// Add models of oplog records that must be walked through when chain is verified.
+func (c *Chain) AddModels(models ...oplog.ChainedOplog) {
+	c.models = append(c.models, models...)
+}
+
+// Get name of chain which oplog record belongs to.
+func (c *Chain) ChainName(ctx op_context.Context, o oplog.Oplog) string {
+	if c.MODE == ModeTenancy {
+		if t, ok := ctx.(op_context.WithOplogTenancy); ok && t.OplogTenancy() != "" {
+			return t.OplogTenancy()
+		}
+		return MainChain
+	}
+	return oplog.OplogType(o)
+}
+
+// Write oplog record linking it to the last record of chain.
+func (c *Chain) Write(ctx op_context.Context, o oplog.ChainedOplog) error {
+
+	if o.GetID() == "" {
+		o.InitObject()
+	}
+	name := c.ChainName(ctx, o)
+
+	// records are linked sequentially, database lock of chain head protects chain from concurrent application instances
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return ctx.Db().Transaction(func(tx db.Transaction) error {
+
+		// create empty head of new chain, head created concurrently by other instance is kept as is
+		head := &ChainHead{}
+		head.InitObject()
+		head.SetID(name)
+		_, err := tx.CreateIfNotExists(ctx, head)
+		if err != nil {
+			return fmt.Errorf("failed to create head of chain: %s", err)
+		}
+
+		// lock head of chain
+		head = &ChainHead{}
+		found, err := tx.FindForUpdate(ctx, db.Fields{"id": name}, head)
+		if err != nil {
+			return fmt.Errorf("failed to find head of chain: %s", err)
+		}
+		if !found {
+			return errors.New("head of chain not found")
+		}
+
+		seq := head.SEQ + 1
+		o.SetChainLink(name, seq, head.HASH)
+		hash, err := Hash(o)
+		if err != nil {
+			return fmt.Errorf("failed to calculate hash: %s", err)
+		}
+		o.SetHash(hash)
+
+		err = tx.Create(ctx, o)
+		if err != nil {
+			return err
+		}
+
+		err = db.Update(tx, ctx, head, db.Fields{"seq": seq, "hash": hash})
+		if err != nil {
+			return fmt.Errorf("failed to update head of chain: %s", err)
+		}
+
+		if c.CHECKPOINT_INTERVAL > 0 && seq%int64(c.CHECKPOINT_INTERVAL) == 0 && c.signer != nil {
+			_, err = c.makeCheckpoint(ctx, tx, name, seq, hash)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (c *Chain) makeCheckpoint(ctx op_context.Context, tx db.DBHandlers, name string, seq int64, hash string) (*Checkpoint, error) {
+
+	checkpoint := &Checkpoint{}
+	checkpoint.InitObject()
+	checkpoint.CHAIN = name
+	checkpoint.SEQ = seq
+	checkpoint.HASH = hash
+
+	var err error
+	checkpoint.SIGNATURE, err = crypt_utils.Sign(c.signer, checkpoint.SignedData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign checkpoint: %s", err)
+	}
+
+	err = tx.Create(ctx, checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save checkpoint: %s", err)
+	}
+	return checkpoint, nil
+}
+
+// Make signed checkpoint of the current head of chain, e.g. periodically from scheduled job.
+// Checkpoint is not made if chain is empty or its head is already checkpointed.
+func (c *Chain) Checkpoint(ctx op_context.Context, name string) (*Checkpoint, error) {
+
+	// setup
+	op := ctx.TraceInMethod("OplogChain.Checkpoint", logger.Fields{"chain": name})
+	defer ctx.TraceOutMethod()
+
+	if c.signer == nil {
+		return nil, op.SetError(errors.New("private key of oplog chain not set"))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var checkpoint *Checkpoint
+	err := ctx.Db().Transaction(func(tx db.Transaction) error {
+
+		head := &ChainHead{}
+		found, err := tx.FindForUpdate(ctx, db.Fields{"id": name}, head)
+		if err != nil {
+			return fmt.Errorf("failed to find head of chain: %s", err)
+		}
+		if !found || head.SEQ == 0 {
+			return nil
+		}
+
+		last := &Checkpoint{}
+		found, err = tx.FindByFields(ctx, db.Fields{"chain": name, "seq": head.SEQ}, last)
+		if err != nil {
+			return fmt.Errorf("failed to find checkpoint: %s", err)
+		}
+		if found {
+			return nil
+		}
+
+		checkpoint, err = c.makeCheckpoint(ctx, tx, name, head.SEQ, head.HASH)
+		return err
+	})
+	if err != nil {
+		return nil, op.SetError(err)
+	}
+
+	return checkpoint, nil
+}
+
+// Make signed checkpoints of current heads of all chains, returns made checkpoints.
+func (c *Chain) CheckpointAll(ctx op_context.Context) ([]*Checkpoint, error) {
+
+	// setup
+	var err error
+	op := ctx.TraceInMethod("OplogChain.CheckpointAll")
+	onExit := func() {
+		if err != nil {
+			op.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	names, err := c.Chains(ctx)
+	if err != nil {
+		op.SetMessage("failed to list chains")
+		return nil, err
+	}
+
+	checkpoints := make([]*Checkpoint, 0)
+	for _, name := range names {
+		var checkpoint *Checkpoint
+		checkpoint, err = c.Checkpoint(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+
+	return checkpoints, nil
+}
+
+type checkpointRunner struct {
+	background_worker.JobRunnerBase
+	chain *Chain
+	app   app_context.Context
+}
+
+func (r *checkpointRunner) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(r.app, "OplogChain.CheckpointAll")
+	defer ctx.Close()
+	r.chain.CheckpointAll(ctx)
+}
+
+// Run background job making checkpoints of chains in main database of application.
+// If leader is set then the job runs only when this instance is a leader.
+// The job is not started if checkpoint period is zero or private key is not set.
+func (c *Chain) Start(app app_context.Context, leader ...background_worker.Leadership) {
+	if c.CHECKPOINT_PERIOD == 0 || c.signer == nil || c.worker != nil {
+		return
+	}
+	c.worker = background_worker.New(app.Logger(), &checkpointRunner{chain: c, app: app}, c.CHECKPOINT_PERIOD)
+	if len(leader) != 0 {
+		c.worker.RunOnlyWhenLeader(leader[0])
+	}
+	c.worker.RunInBackground()
+}
+
+// Stop background job.
+func (c *Chain) Close() {
+	if c.worker != nil {
+		c.worker.Stop()
+		c.worker = nil
+	}
+}
+
+// List names of chains.
+func (c *Chain) Chains(ctx op_context.Context) ([]string, error) {
+
+	var heads []*ChainHead
+	filter := db.NewFilter()
+	filter.SetSorting("id")
+	_, err := db.DB(ctx.Db()).FindWithFilter(ctx, filter, &heads)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(heads))
+	for i, head := range heads {
+		names[i] = head.GetID()
+	}
+	return names, nil
+}
+
+// Oplog controller that writes oplog records to database of context linking them into chain.
+type OplogControllerChain struct {
+	Ctx   op_context.Context
+	Chain *Chain
+}
+
+func (o *OplogControllerChain) Write(op oplog.Oplog) error {
+
+	chained, ok := op.(oplog.ChainedOplog)
+	if !ok {
+		err := errors.New("oplog can not be chained")
+		o.Ctx.Logger().Error("failed to write oplog", err, logger.Fields{"oplog": utils.ObjectTypeName(op)})
+		return err
+	}
+
+	err := o.Chain.Write(o.Ctx, chained)
+	if err != nil {
+		o.Ctx.Logger().Error("failed to write oplog", err, logger.Fields{"oplog": utils.ObjectTypeName(op)})
+	}
+	return err
+}
+
+func (o *OplogControllerChain) Read(filter *db.Filter, docs interface{}) (int64, error) {
+	count, err := db.DB(o.Ctx.Db()).FindWithFilter(o.Ctx, filter, docs)
+	if err != nil {
+		o.Ctx.Logger().Error("failed to read oplog", err, logger.Fields{"oplog": utils.ObjectTypeName(docs)})
+	}
+	return count, err
+}
+
+// Get handler of oplog that writes oplog records linked into chain.
+func (c *Chain) OplogHandler() op_context.OplogHandler {
+	return func(ctx op_context.Context) oplog.OplogController {
+		return &OplogControllerChain{Ctx: ctx, Chain: c}
+	}
+}
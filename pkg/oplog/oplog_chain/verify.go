@@ -0,0 +1,227 @@
+package oplog_chain
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+)
+
+const (
+	// Records are missing in chain.
+	ProblemGap string = "gap"
+	// Content of record does not match its hash.
+	ProblemModified string = "modified"
+	// Record is not linked to hash of previous record.
+	ProblemBrokenLink string = "broken_link"
+	// Multiple records have the same sequence number.
+	ProblemDuplicate string = "duplicate"
+	// Last records of chain are missing or head of chain does not match the last record.
+	ProblemHead string = "head"
+	// Checkpoint has invalid signature or does not match record.
+	ProblemCheckpoint string = "checkpoint"
+)
+
+// Problem found in chain.
+type Problem struct {
+	Kind    string `json:"kind"`
+	Seq     int64  `json:"seq"`
+	Type    string `json:"type,omitempty"`
+	OplogId string `json:"oplog_id,omitempty"`
+	Message string `json:"message"`
+}
+
+// Report of chain verification.
+type Report struct {
+	Chain       string     `json:"chain"`
+	Records     int        `json:"records"`
+	Checkpoints int        `json:"checkpoints"`
+	HeadSeq     int64      `json:"head_seq"`
+	Problems    []*Problem `json:"problems,omitempty"`
+}
+
+func (r *Report) Ok() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) addProblem(kind string, seq int64, link *link, message string, args ...interface{}) {
+	p := &Problem{Kind: kind, Seq: seq, Message: fmt.Sprintf(message, args...)}
+	if link != nil {
+		p.Type = link.oplogType
+		p.OplogId = link.oplogId
+	}
+	r.Problems = append(r.Problems, p)
+}
+
+type link struct {
+	seq       int64
+	oplogType string
+	oplogId   string
+	prevHash  string
+	hash      string
+}
+
+const verifyBatchSize = 1000
+
+// Load links of chain from all oplog models added to chain.
+func (c *Chain) loadLinks(ctx op_context.Context, name string, report *Report) ([]*link, error) {
+
+	links := make([]*link, 0)
+	for _, model := range c.models {
+
+		modelType := reflect.TypeOf(model)
+		for offset := 0; ; offset += verifyBatchSize {
+
+			filter := db.NewFilter()
+			filter.AddField("chain", name)
+			filter.SetSorting("chain_seq")
+			filter.Offset = offset
+			filter.Limit = verifyBatchSize
+			docs := reflect.New(reflect.SliceOf(modelType))
+			_, err := db.DB(ctx.Db()).FindWithFilter(ctx, filter, docs.Interface())
+			if err != nil {
+				return nil, fmt.Errorf("failed to read oplog %s: %s", oplog.OplogType(model), err)
+			}
+
+			records := docs.Elem()
+			for i := 0; i < records.Len(); i++ {
+				o := records.Index(i).Interface().(oplog.ChainedOplog)
+				l := &link{seq: o.ChainSeq(), oplogType: oplog.OplogType(o), oplogId: o.GetID(), prevHash: o.PrevHash(), hash: o.Hash()}
+				hash, err := Hash(o)
+				if err != nil {
+					return nil, fmt.Errorf("failed to calculate hash of oplog %s: %s", l.oplogType, err)
+				}
+				if !crypt_utils.HashEqual(hash, l.hash) {
+					report.addProblem(ProblemModified, l.seq, l, "content of record does not match its hash")
+				}
+				links = append(links, l)
+			}
+
+			if records.Len() < verifyBatchSize {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(links, func(i, j int) bool { return links[i].seq < links[j].seq })
+	return links, nil
+}
+
+// Verify chain walking through all its records and checkpoints.
+// Returns error only if verification could not be performed, problems of chain are listed in report.
+func (c *Chain) Verify(ctx op_context.Context, name string) (*Report, error) {
+
+	// setup
+	op := ctx.TraceInMethod("OplogChain.Verify", logger.Fields{"chain": name})
+	defer ctx.TraceOutMethod()
+
+	report := &Report{Chain: name}
+
+	// load records
+	links, err := c.loadLinks(ctx, name, report)
+	if err != nil {
+		return nil, op.SetError(err)
+	}
+	report.Records = len(links)
+
+	// walk through chain
+	bySeq := make(map[int64]*link, len(links))
+	var prev *link
+	for _, l := range links {
+		expected := int64(1)
+		if prev != nil {
+			expected = prev.seq + 1
+		}
+		switch {
+		case prev != nil && l.seq == prev.seq:
+			report.addProblem(ProblemDuplicate, l.seq, l, "duplicate sequence number")
+			continue
+		case l.seq > expected:
+			report.addProblem(ProblemGap, expected, nil, "records %d-%d are missing", expected, l.seq-1)
+		case prev != nil && !crypt_utils.HashEqual(l.prevHash, prev.hash):
+			report.addProblem(ProblemBrokenLink, l.seq, l, "record is not linked to previous record")
+		case prev == nil && l.prevHash != "":
+			report.addProblem(ProblemBrokenLink, l.seq, l, "first record is linked to unknown record")
+		}
+		bySeq[l.seq] = l
+		prev = l
+	}
+
+	// check head
+	head := &ChainHead{}
+	found, err := db.DB(ctx.Db()).FindByField(ctx, "id", name, head)
+	if err != nil {
+		return nil, op.SetError(fmt.Errorf("failed to find head of chain: %s", err))
+	}
+	if found {
+		report.HeadSeq = head.SEQ
+		lastSeq := int64(0)
+		if prev != nil {
+			lastSeq = prev.seq
+		}
+		if head.SEQ > lastSeq {
+			report.addProblem(ProblemHead, lastSeq+1, nil, "records %d-%d at the end of chain are missing", lastSeq+1, head.SEQ)
+		} else if head.SEQ < lastSeq || !crypt_utils.HashEqual(head.HASH, prev.hash) {
+			report.addProblem(ProblemHead, head.SEQ, nil, "head of chain does not match the last record")
+		}
+	} else if prev != nil {
+		report.addProblem(ProblemHead, prev.seq, nil, "head of chain not found")
+	}
+
+	// check checkpoints
+	var checkpoints []*Checkpoint
+	filter := db.NewFilter()
+	filter.AddField("chain", name)
+	filter.SetSorting("seq")
+	_, err = db.DB(ctx.Db()).FindWithFilter(ctx, filter, &checkpoints)
+	if err != nil {
+		return nil, op.SetError(fmt.Errorf("failed to read checkpoints: %s", err))
+	}
+	report.Checkpoints = len(checkpoints)
+	for _, checkpoint := range checkpoints {
+		if c.verifier != nil {
+			err = crypt_utils.VerifySignature(c.verifier, checkpoint.SignedData(), checkpoint.SIGNATURE)
+			if err != nil {
+				report.addProblem(ProblemCheckpoint, checkpoint.SEQ, nil, "invalid signature of checkpoint: %s", err)
+				continue
+			}
+		}
+		l, ok := bySeq[checkpoint.SEQ]
+		if !ok {
+			if checkpoint.SEQ > report.HeadSeq {
+				report.addProblem(ProblemCheckpoint, checkpoint.SEQ, nil, "checkpointed record is beyond head of chain")
+			}
+			continue
+		}
+		if !crypt_utils.HashEqual(l.hash, checkpoint.HASH) {
+			report.addProblem(ProblemCheckpoint, checkpoint.SEQ, l, "record does not match checkpoint")
+		}
+	}
+
+	// done
+	return report, nil
+}
+
+// Verify all chains.
+func (c *Chain) VerifyAll(ctx op_context.Context) ([]*Report, error) {
+
+	names, err := c.Chains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*Report, 0, len(names))
+	for _, name := range names {
+		report, err := c.Verify(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -1,5 +1,5 @@
 package pool
 
 func DbModels() []interface{} {
-	return []interface{}{&PoolBase{}, &PoolServiceBase{}, &PoolServiceAssociationBase{}, &OpLogPool{}}
+	return []interface{}{&PoolBase{}, &PoolServiceBase{}, &PoolServiceAssociationBase{}, &OpLogPool{}, &PoolServiceHealth{}}
 }
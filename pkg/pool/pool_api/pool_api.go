@@ -21,6 +21,13 @@ type ListPoolsResponse = api.ResponseList[*pool.PoolBase]
 
 type ListServicePoolsResponse = api.ResponseList[*pool.PoolServiceBinding]
 
+type ServiceHealthResponse struct {
+	api.ResponseBase
+	*pool.PoolServiceHealth
+}
+
+type ListServicesHealthResponse = api.ResponseList[*pool.PoolServiceHealth]
+
 var (
 	UpdateService             = func() api.Operation { return api.UpdatePartial("update_service") }
 	UpdatePool                = func() api.Operation { return api.UpdatePartial("update_pool") }
@@ -38,4 +45,6 @@ var (
 	AddPool                   = func() api.Operation { return api.Add("add_pool") }
 	ListPools                 = func() api.Operation { return api.List("list_pools") }
 	ListPoolServices          = func() api.Operation { return api.List("list_pool_services") }
+	FindServiceHealth         = func() api.Operation { return api.Find("find_service_health") }
+	ListServicesHealth        = func() api.Operation { return api.List("list_services_health") }
 )
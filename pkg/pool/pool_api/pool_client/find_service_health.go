@@ -0,0 +1,59 @@
+package pool_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_api"
+)
+
+type FindServiceHealth struct {
+	result *pool_api.ServiceHealthResponse
+}
+
+func (a *FindServiceHealth) Exec(client api_client.Client, ctx op_context.Context, operation api.Operation) error {
+
+	c := ctx.TraceInMethod("FindServiceHealth.Exec")
+	defer ctx.TraceOutMethod()
+
+	err := client.Exec(ctx, operation, nil, a.result)
+	c.SetError(err)
+	return err
+}
+
+func (p *PoolClient) FindServiceHealth(ctx op_context.Context, id string, idIsName ...bool) (*pool.PoolServiceHealth, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("PoolClient.FindServiceHealth")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// adjust service ID
+	sId, _, err := p.serviceId(ctx, id, idIsName...)
+	if err != nil {
+		return nil, err
+	}
+
+	// prepare and exec handler
+	handler := &FindServiceHealth{
+		result: &pool_api.ServiceHealthResponse{},
+	}
+	resource := p.resourceForServiceHealth(sId)
+	op := pool_api.FindServiceHealth()
+	resource.AddOperation(op)
+	err = op.Exec(ctx, api_client.MakeOperationHandler(p.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, err
+	}
+
+	// done
+	return handler.result.PoolServiceHealth, nil
+}
@@ -0,0 +1,56 @@
+package pool_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_api"
+)
+
+type ListServicesHealth struct {
+	cmd    api.Query
+	result *pool_api.ListServicesHealthResponse
+}
+
+func (a *ListServicesHealth) Exec(client api_client.Client, ctx op_context.Context, operation api.Operation) error {
+
+	c := ctx.TraceInMethod("ListServicesHealth.Exec")
+	defer ctx.TraceOutMethod()
+
+	err := client.Exec(ctx, operation, a.cmd, a.result)
+	c.SetError(err)
+	return err
+}
+
+func (p *PoolClient) GetServicesHealth(ctx op_context.Context, filter *db.Filter) ([]*pool.PoolServiceHealth, int64, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("PoolClient.GetServicesHealth")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// set query
+	cmd := api.NewDbQuery(filter)
+
+	// prepare and exec handler
+	handler := &ListServicesHealth{
+		cmd:    cmd,
+		result: &pool_api.ListServicesHealthResponse{},
+	}
+	err = p.list_services_health.Exec(ctx, api_client.MakeOperationHandler(p.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, 0, err
+	}
+
+	// done
+	return handler.result.Items, handler.result.Count, nil
+}
@@ -26,8 +26,9 @@ type PoolClient struct {
 	add_pool    api.Operation
 	add_service api.Operation
 
-	list_pools    api.Operation
-	list_services api.Operation
+	list_pools           api.Operation
+	list_services        api.Operation
+	list_services_health api.Operation
 }
 
 func NewPoolClient(client api_client.Client) *PoolClient {
@@ -53,6 +54,11 @@ func NewPoolClient(client api_client.Client) *PoolClient {
 		c.list_services,
 	)
 
+	servicesHealthResource := api.NewResource("health")
+	c.ServicesResource.AddChild(servicesHealthResource)
+	c.list_services_health = pool_api.ListServicesHealth()
+	servicesHealthResource.AddOperation(c.list_services_health)
+
 	return c
 }
 
@@ -75,6 +81,13 @@ func (p *PoolClient) resourceForPoolServices(poolId string) api.Resource {
 	return servicesResource
 }
 
+func (p *PoolClient) resourceForServiceHealth(serviceId string) api.Resource {
+	serviceResource := p.namedServiceResource(serviceId)
+	healthResource := api.NewResource("health")
+	serviceResource.AddChild(healthResource)
+	return healthResource
+}
+
 func (p *PoolClient) resourceForServicePools(serviceId string) api.Resource {
 	serviceResource := p.namedServiceResource(serviceId)
 	poolsResource := api.NewResource(poolIdType)
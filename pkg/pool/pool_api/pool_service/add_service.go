@@ -34,6 +34,7 @@ func (e *AddServiceEndpoint) HandleRequest(request api_server.Request) error {
 	// set response
 	resp := &pool_api.ServiceResponse{}
 	resp.PoolServiceBase = s.(*pool.PoolServiceBase)
+	maskSecrets(e.service, resp.PoolServiceBase)
 	request.Response().SetMessage(resp)
 
 	// done
@@ -0,0 +1,45 @@
+package pool_service
+
+import (
+	"errors"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_api"
+)
+
+type FindServiceHealthEndpoint struct {
+	PoolEndpoint
+}
+
+func (e *FindServiceHealthEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("pool.FindServiceHealth")
+	defer request.TraceOutMethod()
+
+	// find service health
+	h, err := e.service.Pools.FindServiceHealth(request, request.GetResourceId("service"))
+	if err != nil {
+		c.SetMessage("failed to find service health")
+		return c.SetError(err)
+	}
+	if h == nil {
+		request.SetGenericErrorCode(pool.ErrorCodeServiceNotFound)
+		return c.SetError(errors.New("service not found"))
+	}
+
+	// set response
+	resp := &pool_api.ServiceHealthResponse{}
+	resp.PoolServiceHealth = h
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func FindServiceHealth(s *PoolService) *FindServiceHealthEndpoint {
+	e := &FindServiceHealthEndpoint{}
+	e.Construct(s, pool_api.FindServiceHealth())
+	return e
+}
@@ -23,6 +23,7 @@ func (e *ListPoolServicesEndpoint) HandleRequest(request api_server.Request) err
 		c.SetMessage("failed to get service bindings")
 		return c.SetError(err)
 	}
+	maskSecrets(e.service, resp.Items...)
 
 	// set response
 	request.Response().SetMessage(resp)
@@ -29,6 +29,7 @@ func (e *ListServicesEndpoint) HandleRequest(request api_server.Request) error {
 	if err != nil {
 		return c.SetError(err)
 	}
+	maskSecrets(e.service, resp.Items...)
 
 	// set response message
 	api_server.SetResponseList(request, resp)
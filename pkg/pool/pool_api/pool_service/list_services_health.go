@@ -0,0 +1,44 @@
+package pool_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_api"
+)
+
+type ListServicesHealthEndpoint struct {
+	PoolEndpoint
+}
+
+func (e *ListServicesHealthEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("pool.ListServicesHealth")
+	defer request.TraceOutMethod()
+
+	// parse query
+	queryName := request.Endpoint().Resource().ServicePathPrototype()
+	filter, err := api_server.ParseDbQuery(request, &pool.PoolServiceHealth{}, queryName)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// get health of services
+	resp := &pool_api.ListServicesHealthResponse{}
+	resp.Items, resp.Count, err = e.service.Pools.GetServicesHealth(request, filter)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// set response message
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func ListServicesHealth(s *PoolService) *ListServicesHealthEndpoint {
+	e := &ListServicesHealthEndpoint{}
+	e.Construct(s, pool_api.ListServicesHealth())
+	return e
+}
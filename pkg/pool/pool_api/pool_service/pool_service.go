@@ -20,6 +20,9 @@ type PoolService struct {
 	api_server.ServiceBase
 	Pools pool.PoolController
 
+	// Privileged flag to send secrets of services in responses, if not set then secrets are masked.
+	ShowSecrets bool
+
 	PoolsResource    api.Resource
 	PoolResource     api.Resource
 	ServicesResource api.Resource
@@ -59,6 +62,15 @@ func NewPoolService(poolController pool.PoolController) *PoolService {
 	s.ServResource.AddOperation(FindService(s), true)
 	s.ServResource.AddOperations(UpdateService(s), DeleteService(s))
 
+	serviceHealth := api.NewResource("health")
+	serviceHealth.AddOperation(FindServiceHealth(s))
+	s.ServResource.AddChild(serviceHealth)
+
+	servicesHealth := api.NewResource("health")
+	listServicesHealth := ListServicesHealth(s)
+	servicesHealth.AddOperation(listServicesHealth)
+	s.ServicesResource.AddChild(servicesHealth)
+
 	servicePoolAssociations := api.NewResource("pool")
 	listServicePools := ListServicePools(s)
 	servicePoolAssociations.AddOperations(listServicePools, RemoveServiceFromAllPools(s))
@@ -68,7 +80,21 @@ func NewPoolService(poolController pool.PoolController) *PoolService {
 	serviceTableConfig := &api_server.DynamicTableConfig{Model: &pool.PoolServiceBase{}, Operation: listServices}
 	poolServicesTableConfig := &api_server.DynamicTableConfig{Model: &pool.PoolServiceBinding{}, Operation: listPoolServices}
 	servicePoolsTableConfig := &api_server.DynamicTableConfig{Model: &pool.PoolServiceBinding{}, Operation: listServicePools}
-	s.AddDynamicTables(poolTableConfig, serviceTableConfig, poolServicesTableConfig, servicePoolsTableConfig)
+	servicesHealthTableConfig := &api_server.DynamicTableConfig{Model: &pool.PoolServiceHealth{}, Operation: listServicesHealth}
+	s.AddDynamicTables(poolTableConfig, serviceTableConfig, poolServicesTableConfig, servicePoolsTableConfig, servicesHealthTableConfig)
 
 	return s
 }
+
+type withSecrets interface {
+	MaskSecrets()
+}
+
+func maskSecrets[T withSecrets](s *PoolService, items ...T) {
+	if s.ShowSecrets {
+		return
+	}
+	for _, item := range items {
+		item.MaskSecrets()
+	}
+}
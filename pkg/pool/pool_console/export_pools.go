@@ -0,0 +1,60 @@
+package pool_console
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+)
+
+const ExportPoolsCmd string = "export"
+const ExportPoolsDescription string = "Export pools, services and bindings of services to pools"
+
+func ExportPools() Handler {
+	a := &ExportPoolsHandler{}
+	a.Init(ExportPoolsCmd, ExportPoolsDescription)
+	return a
+}
+
+type ExportPoolsData struct {
+	File          string `long:"file" description:"Path to output file, if not set then document is printed to stdout"`
+	Format        string `long:"format" description:"Format of document" default:"yaml" choice:"yaml" choice:"json"`
+	RedactSecrets bool   `long:"redact-secrets" description:"Replace secrets of services with mask"`
+}
+
+type ExportPoolsHandler struct {
+	HandlerBase
+	ExportPoolsData
+}
+
+func (a *ExportPoolsHandler) Data() interface{} {
+	return &a.ExportPoolsData
+}
+
+func (a *ExportPoolsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	doc, err := pool.ExportPools(controller, ctx, a.RedactSecrets)
+	if err != nil {
+		return err
+	}
+	data, err := pool.MarshalPoolsDocument(doc, a.Format)
+	if err != nil {
+		return err
+	}
+
+	if a.File == "" {
+		fmt.Println(string(data))
+		return nil
+	}
+	err = os.WriteFile(a.File, data, 0600)
+	if err == nil {
+		fmt.Printf("Exported %d pools and %d services to %s\n", len(doc.Pools), len(doc.Services), a.File)
+	}
+	return err
+}
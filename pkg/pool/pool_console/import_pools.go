@@ -0,0 +1,78 @@
+package pool_console
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+)
+
+const ImportPoolsCmd string = "import"
+const ImportPoolsDescription string = "Import pools, services and bindings of services to pools"
+
+func ImportPools() Handler {
+	a := &ImportPoolsHandler{}
+	a.Init(ImportPoolsCmd, ImportPoolsDescription)
+	return a
+}
+
+type ImportPoolsData struct {
+	File   string `long:"file" description:"Path to document" required:"true"`
+	Format string `long:"format" description:"Format of document, if not set then it is detected by file extension" choice:"yaml" choice:"json"`
+	Plan   bool   `long:"plan" description:"Only show what would be changed"`
+}
+
+type ImportPoolsHandler struct {
+	HandlerBase
+	ImportPoolsData
+}
+
+func (a *ImportPoolsHandler) Data() interface{} {
+	return &a.ImportPoolsData
+}
+
+func (a *ImportPoolsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	data, err := os.ReadFile(a.File)
+	if err != nil {
+		return err
+	}
+	format := a.Format
+	if format == "" {
+		format = pool.DocumentFormatYaml
+		if strings.ToLower(filepath.Ext(a.File)) == ".json" {
+			format = pool.DocumentFormatJson
+		}
+	}
+	doc, err := pool.UnmarshalPoolsDocument(data, format)
+	if err != nil {
+		return err
+	}
+
+	plan, err := pool.ImportPools(controller, ctx, doc, a.Plan)
+	if err != nil {
+		return err
+	}
+
+	if plan.IsEmpty() {
+		fmt.Println("Nothing to change")
+		return nil
+	}
+	if a.Plan {
+		fmt.Println("Plan:")
+	} else {
+		fmt.Println("Applied:")
+	}
+	for _, item := range plan.Items {
+		fmt.Printf("  %s\n", item.String())
+	}
+	return nil
+}
@@ -39,7 +39,10 @@ func (p *PoolCommands) LoadHandlers() {
 		EnablePool,
 		DisablePool,
 		EnableService,
-		DisableService)
+		DisableService,
+		ReencryptSecrets,
+		ExportPools,
+		ImportPools)
 }
 
 type Handler = console_tool.Handler[*PoolCommands]
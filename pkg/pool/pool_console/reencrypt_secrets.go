@@ -0,0 +1,50 @@
+package pool_console
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+)
+
+const ReencryptSecretsCmd string = "reencrypt_secrets"
+const ReencryptSecretsDescription string = "Re-encrypt secrets of all services with new master key"
+
+func ReencryptSecrets() Handler {
+	a := &ReencryptSecretsHandler{}
+	a.Init(ReencryptSecretsCmd, ReencryptSecretsDescription)
+	return a
+}
+
+type ReencryptSecretsData struct {
+	KeyId string `long:"key-id" description:"ID of master key from configuration, if not set then current key is used"`
+}
+
+type ReencryptSecretsHandler struct {
+	HandlerBase
+	ReencryptSecretsData
+}
+
+func (a *ReencryptSecretsHandler) Data() interface{} {
+	return &a.ReencryptSecretsData
+}
+
+func (a *ReencryptSecretsHandler) Execute(args []string) error {
+
+	ctx, controller, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	secretsManager, ok := controller.(pool.PoolSecretsManager)
+	if !ok {
+		return errors.New("pool controller does not support encryption of secrets")
+	}
+
+	count, err := secretsManager.ReencryptServiceSecrets(ctx, a.KeyId)
+	if err == nil {
+		fmt.Printf("Re-encrypted secrets of %d services\n", count)
+	}
+	return err
+}
@@ -35,13 +35,19 @@ func (a *ShowServiceHandler) Execute(args []string) error {
 		return err
 	}
 	defer ctx.Close()
-	service, err := controller.FindPool(ctx, a.Service, true)
+	service, err := controller.FindService(ctx, a.Service, true)
+	if err != nil {
+		return err
+	}
+	if service == nil {
+		fmt.Println("Service not found")
+		return nil
+	}
+	fmt.Printf("Service:\n\n%s\n\n", utils.DumpPrettyJson(service))
+
+	health, err := controller.FindServiceHealth(ctx, service.GetID())
 	if err == nil {
-		if service != nil {
-			fmt.Printf("Service:\n\n%s\n\n", utils.DumpPrettyJson(service))
-		} else {
-			fmt.Println("Service not found")
-		}
+		fmt.Printf("Health:\n\n%s\n\n", utils.DumpPrettyJson(health))
 	}
 	return err
 }
@@ -23,6 +23,8 @@ const ErrorCodeServiceInitializationFailed = "service_initialization_failed"
 const ErrorCodePoolServiceBoundToPool = "service_bound_to_pool"
 const ErrorCodeServiceNotActive = "service_not_active"
 const ErrorCodePoolNotActive = "pool_not_active"
+const ErrorCodeServiceSecretsFailed = "service_secrets_failed"
+const ErrorCodeInvalidPoolsDocument = "invalid_pools_document"
 
 var ErrorDescriptions = map[string]string{
 	ErrorCodePoolNotFound:                "Pool not found.",
@@ -37,6 +39,8 @@ var ErrorDescriptions = map[string]string{
 	ErrorCodeServiceInitializationFailed: "Failed to connect to service",
 	ErrorCodeServiceNotActive:            "Service not active. First, activate corresponsing service.",
 	ErrorCodePoolNotActive:               "Pool not active. First, activate corresponsing pool.",
+	ErrorCodeServiceSecretsFailed:        "Failed to process secrets of service.",
+	ErrorCodeInvalidPoolsDocument:        "Invalid document with pools and services.",
 }
 
 var ErrorHttpCodes = map[string]int{
@@ -46,6 +50,8 @@ var ErrorHttpCodes = map[string]int{
 	ErrorCodeServiceInitializationFailed: http.StatusInternalServerError,
 	ErrorCodeServiceNotActive:            http.StatusInternalServerError,
 	ErrorCodePoolNotActive:               http.StatusInternalServerError,
+	ErrorCodeServiceSecretsFailed:        http.StatusInternalServerError,
+	ErrorCodeInvalidPoolsDocument:        http.StatusBadRequest,
 }
 
 type PoolController interface {
@@ -68,6 +74,9 @@ type PoolController interface {
 
 	GetPoolBindings(ctx op_context.Context, id string, idIsName ...bool) ([]*PoolServiceBinding, error)
 	GetServiceBindings(ctx op_context.Context, id string, idIsName ...bool) ([]*PoolServiceBinding, error)
+
+	FindServiceHealth(ctx op_context.Context, id string, idIsName ...bool) (*PoolServiceHealth, error)
+	GetServicesHealth(ctx op_context.Context, filter *db.Filter) ([]*PoolServiceHealth, int64, error)
 }
 
 func NewPoolController(crud crud.CRUD) *PoolControllerBase {
@@ -77,7 +86,100 @@ func NewPoolController(crud crud.CRUD) *PoolControllerBase {
 }
 
 type PoolControllerBase struct {
-	CRUD crud.CRUD
+	CRUD               crud.CRUD
+	secretsCipher      SecretsCipher
+	secretsInitialized bool
+}
+
+// Set cipher of service secrets. Nil cipher disables encryption of secrets.
+// Secrets can not be stored or loaded until cipher is set, PoolStore sets it in Init.
+func (m *PoolControllerBase) SetSecretsCipher(cipher SecretsCipher) {
+	m.secretsCipher = cipher
+	m.secretsInitialized = true
+}
+
+func (m *PoolControllerBase) SecretsCipher() SecretsCipher {
+	return m.secretsCipher
+}
+
+func (m *PoolControllerBase) checkSecretsCipher(ctx op_context.Context) error {
+	if !m.secretsInitialized {
+		ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+		return errors.New("encryption of secrets is not initialized")
+	}
+	return nil
+}
+
+func (m *PoolControllerBase) encryptSecrets(ctx op_context.Context, secrets Secrets) error {
+	err := m.checkSecretsCipher(ctx)
+	if err != nil {
+		return err
+	}
+	if m.secretsCipher == nil {
+		return nil
+	}
+	secret1, err := m.secretsCipher.EncryptSecret(secrets.Secret1())
+	if err != nil {
+		ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+		return err
+	}
+	secret2, err := m.secretsCipher.EncryptSecret(secrets.Secret2())
+	if err != nil {
+		ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+		return err
+	}
+	secrets.SetSecret1(secret1)
+	secrets.SetSecret2(secret2)
+	return nil
+}
+
+func (m *PoolControllerBase) decryptSecrets(ctx op_context.Context, secrets Secrets) error {
+	err := m.checkSecretsCipher(ctx)
+	if err != nil {
+		return err
+	}
+	if m.secretsCipher == nil {
+		if IsEncryptedSecret(secrets.Secret1()) || IsEncryptedSecret(secrets.Secret2()) {
+			ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+			return errors.New("secrets are encrypted but encryption of secrets is not configured")
+		}
+		return nil
+	}
+	secret1, err := m.secretsCipher.DecryptSecret(secrets.Secret1())
+	if err != nil {
+		ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+		return err
+	}
+	secret2, err := m.secretsCipher.DecryptSecret(secrets.Secret2())
+	if err != nil {
+		ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+		return err
+	}
+	secrets.SetSecret1(secret1)
+	secrets.SetSecret2(secret2)
+	return nil
+}
+
+func (m *PoolControllerBase) encryptSecretFields(ctx op_context.Context, fields db.Fields) (db.Fields, error) {
+	err := m.checkSecretsCipher(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m.secretsCipher == nil {
+		return fields, nil
+	}
+	result := utils.CopyMapOneLevel(fields)
+	for _, field := range []string{"secret1", "secret2"} {
+		if value, ok := fields[field].(string); ok {
+			encrypted, err := m.secretsCipher.EncryptSecret(value)
+			if err != nil {
+				ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+				return nil, err
+			}
+			result[field] = encrypted
+		}
+	}
+	return result, nil
 }
 
 func fieldName(idIsName ...bool) string {
@@ -245,11 +347,22 @@ func (m *PoolControllerBase) AddService(ctx op_context.Context, service PoolServ
 		return nil, c.SetError(err)
 	}
 
+	// encrypt secrets
+	secret1 := service.Secret1()
+	secret2 := service.Secret2()
+	err = m.encryptSecrets(ctx, service)
+	if err != nil {
+		c.SetMessage("failed to encrypt secrets")
+		return nil, c.SetError(err)
+	}
+
 	service.InitObject()
 	err = m.CRUD.Create(ctx, service)
 	if err != nil {
 		return nil, err
 	}
+	service.SetSecret1(secret1)
+	service.SetSecret2(secret2)
 
 	m.OpLog(ctx, "add_service", &OpLogPool{ServiceId: service.GetID(), ServiceName: service.Name()})
 
@@ -266,6 +379,10 @@ func (m *PoolControllerBase) FindService(ctx op_context.Context, id string, idIs
 		ctx.SetGenericErrorCode(ErrorCodeServiceNotFound)
 		return nil, err
 	}
+	err = m.decryptSecrets(ctx, service)
+	if err != nil {
+		return nil, err
+	}
 	return service, nil
 }
 
@@ -315,9 +432,16 @@ func (m *PoolControllerBase) UpdateService(ctx op_context.Context, id string, fi
 		}
 	}
 
+	// encrypt secrets
+	updateFields, err := m.encryptSecretFields(ctx, fields)
+	if err != nil {
+		c.SetMessage("failed to encrypt secrets")
+		return nil, c.SetError(err)
+	}
+
 	// update
 	idField := fieldName(idIsName...)
-	obj, err := crud.FindUpdate(m.CRUD, ctx, "PoolController.FindUpdateService", idField, id, fields, &PoolServiceBase{}, logger.Fields{idField: id})
+	obj, err := crud.FindUpdate(m.CRUD, ctx, "PoolController.FindUpdateService", idField, id, updateFields, &PoolServiceBase{}, logger.Fields{idField: id})
 	if err != nil {
 		return nil, c.SetError(err)
 	}
@@ -379,6 +503,11 @@ func (m *PoolControllerBase) DeleteService(ctx op_context.Context, id string, id
 	if err != nil {
 		return err
 	}
+	err = m.CRUD.DeleteByFields(ctx, db.Fields{"service_id": serviceId}, &PoolServiceHealth{})
+	if err != nil {
+		c.SetMessage("failed to delete service health")
+		return c.SetError(err)
+	}
 
 	o := &OpLogPool{ServiceId: serviceId}
 	if utils.OptionalArg(false, idIsName...) {
@@ -403,6 +532,12 @@ func (p *PoolControllerBase) GetServices(ctx op_context.Context, filter *db.Filt
 	if err != nil {
 		return nil, 0, err
 	}
+	for _, service := range services {
+		err = p.decryptSecrets(ctx, service)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
 	return services, count, nil
 }
 
@@ -667,6 +802,14 @@ func (p *PoolControllerBase) GetPoolBindings(ctx op_context.Context, id string,
 		return nil, err
 	}
 
+	// decrypt secrets
+	for _, service := range services {
+		err = p.decryptSecrets(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// done
 	return services, nil
 }
@@ -711,10 +854,212 @@ func (p *PoolControllerBase) GetServiceBindings(ctx op_context.Context, id strin
 		return nil, err
 	}
 
+	// decrypt secrets
+	for _, service := range services {
+		err = p.decryptSecrets(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// done
 	return services, nil
 }
 
+func (p *PoolControllerBase) FindServiceHealth(ctx op_context.Context, id string, idIsName ...bool) (*PoolServiceHealth, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PoolController.FindServiceHealth")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find service
+	service, err := p.FindService(ctx, id, idIsName...)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		err = errors.New("service not found")
+		return nil, err
+	}
+
+	// find health
+	health := &PoolServiceHealth{}
+	found, err := p.CRUD.Read(ctx, db.Fields{"service_id": service.GetID()}, health)
+	if err != nil {
+		c.SetMessage("failed to find service health")
+		return nil, err
+	}
+	if !found {
+		// service was not checked yet
+		return NewServiceHealth(service), nil
+	}
+
+	// done
+	return health, nil
+}
+
+func (p *PoolControllerBase) GetServicesHealth(ctx op_context.Context, filter *db.Filter) ([]*PoolServiceHealth, int64, error) {
+	var items []*PoolServiceHealth
+	count, err := crud.List(p.CRUD, ctx, "PoolController.GetServicesHealth", filter, &items)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, count, nil
+}
+
+// Save result of service health check. Returns true if status of the service was changed.
+func (p *PoolControllerBase) SaveServiceHealth(ctx op_context.Context, health *PoolServiceHealth) (bool, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PoolController.SaveServiceHealth", logger.Fields{"service": health.SERVICE_NAME, "status": health.STATUS})
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find previous health
+	prev := &PoolServiceHealth{}
+	found, err := p.CRUD.Read(ctx, db.Fields{"service_id": health.SERVICE_ID}, prev)
+	if err != nil {
+		c.SetMessage("failed to find service health")
+		return false, err
+	}
+
+	// create new record
+	if !found {
+		health.InitObject()
+		health.STATUS_CHANGED_AT = health.CHECKED_AT
+		err = p.CRUD.Create(ctx, health)
+		if err != nil {
+			c.SetMessage("failed to create service health")
+			return false, err
+		}
+		return true, nil
+	}
+
+	// update existing record
+	changed := prev.STATUS != health.STATUS
+	health.ObjectBase = prev.ObjectBase
+	health.STATUS_CHANGED_AT = prev.STATUS_CHANGED_AT
+	if changed {
+		health.STATUS_CHANGED_AT = health.CHECKED_AT
+	}
+	fields := db.Fields{
+		"service_name":      health.SERVICE_NAME,
+		"status":            health.STATUS,
+		"latency_ms":        health.LATENCY_MS,
+		"last_error":        health.LAST_ERROR,
+		"checked_at":        health.CHECKED_AT,
+		"status_changed_at": health.STATUS_CHANGED_AT,
+	}
+	err = p.CRUD.Update(ctx, prev, fields)
+	if err != nil {
+		c.SetMessage("failed to update service health")
+		return false, err
+	}
+
+	// done
+	return changed, nil
+}
+
+// Re-encrypt secrets of all services with current master key or with the key with given ID.
+// Returns number of updated services.
+func (p *PoolControllerBase) ReencryptServiceSecrets(ctx op_context.Context, keyId ...string) (int, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PoolController.ReencryptServiceSecrets")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// check cipher
+	if p.secretsCipher == nil {
+		err = errors.New("encryption of secrets is not configured")
+		return 0, err
+	}
+	cipher := p.secretsCipher
+	if len(keyId) != 0 && keyId[0] != "" {
+		base, ok := p.secretsCipher.(*SecretsCipherBase)
+		if !ok {
+			err = errors.New("selection of master key is not supported")
+			return 0, err
+		}
+		selected := *base
+		err = selected.SetKeyId(keyId[0])
+		if err != nil {
+			return 0, err
+		}
+		cipher = &selected
+	}
+	c.SetLoggerField("key_id", cipher.KeyId())
+
+	// load raw services
+	var services []*PoolServiceBase
+	_, err = crud.List(p.CRUD, ctx, "PoolController.ListServicesForReencryption", nil, &services)
+	if err != nil {
+		return 0, err
+	}
+
+	// re-encrypt secrets
+	count := 0
+	for _, service := range services {
+		fields := db.Fields{}
+		secret1, changed, err1 := cipher.ReencryptSecret(service.Secret1())
+		if err1 != nil {
+			err = err1
+			c.SetLoggerField("service", service.Name())
+			c.SetMessage("failed to re-encrypt secret1")
+			ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+			return count, err
+		}
+		if changed {
+			fields["secret1"] = secret1
+		}
+		secret2, changed, err1 := cipher.ReencryptSecret(service.Secret2())
+		if err1 != nil {
+			err = err1
+			c.SetLoggerField("service", service.Name())
+			c.SetMessage("failed to re-encrypt secret2")
+			ctx.SetGenericErrorCode(ErrorCodeServiceSecretsFailed)
+			return count, err
+		}
+		if changed {
+			fields["secret2"] = secret2
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		err = p.CRUD.Update(ctx, service, fields)
+		if err != nil {
+			c.SetLoggerField("service", service.Name())
+			c.SetMessage("failed to update service")
+			return count, err
+		}
+		p.OpLog(ctx, "reencrypt_service_secrets", &OpLogPool{ServiceId: service.GetID(), ServiceName: service.Name()})
+		count++
+	}
+
+	// done
+	return count, nil
+}
+
 func LoadPool(ctrl PoolController, ctx op_context.Context, id string, idIsName ...bool) (Pool, error) {
 
 	c := ctx.TraceInMethod("LoadPool")
@@ -0,0 +1,508 @@
+package pool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DocumentFormatYaml string = "yaml"
+	DocumentFormatJson string = "json"
+)
+
+const (
+	PlanActionCreate string = "create"
+	PlanActionUpdate string = "update"
+	PlanActionBind   string = "bind"
+	PlanActionUnbind string = "unbind"
+)
+
+const (
+	PlanObjectPool    string = "pool"
+	PlanObjectService string = "service"
+	PlanObjectBinding string = "binding"
+)
+
+type ServiceDocument struct {
+	PoolServiceBaseEssentials
+}
+
+type PoolDocument struct {
+	PoolBaseData
+	// Services bound to the pool, map of role to service name.
+	Services map[string]string `json:"services,omitempty"`
+}
+
+// PoolsDocument describes pools, services and their bindings in declarative form.
+type PoolsDocument struct {
+	Services []*ServiceDocument `json:"services"`
+	Pools    []*PoolDocument    `json:"pools"`
+}
+
+// Marshal document to YAML or JSON.
+func MarshalPoolsDocument(doc *PoolsDocument, format string) ([]byte, error) {
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case DocumentFormatJson:
+		return data, nil
+	case DocumentFormatYaml:
+		// convert via generic object so that YAML keys are the same as JSON keys
+		var obj interface{}
+		err = json.Unmarshal(data, &obj)
+		if err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(obj)
+	}
+
+	return nil, fmt.Errorf("unsupported document format %s", format)
+}
+
+// Unmarshal document from YAML or JSON.
+func UnmarshalPoolsDocument(data []byte, format string) (*PoolsDocument, error) {
+
+	switch format {
+	case DocumentFormatJson:
+	case DocumentFormatYaml:
+		var obj interface{}
+		err := yaml.Unmarshal(data, &obj)
+		if err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported document format %s", format)
+	}
+
+	doc := &PoolsDocument{}
+	err := json.Unmarshal(data, doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Export all pools, services and bindings of services to pools.
+// If redactSecrets is true then secrets of services are replaced with mask.
+func ExportPools(ctrl PoolController, ctx op_context.Context, redactSecrets bool) (*PoolsDocument, error) {
+
+	// setup
+	c := ctx.TraceInMethod("ExportPools")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+	doc := &PoolsDocument{}
+
+	// export services
+	services, _, err := ctrl.GetServices(ctx, nil)
+	if err != nil {
+		c.SetMessage("failed to load services")
+		return nil, err
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name() < services[j].Name() })
+	doc.Services = make([]*ServiceDocument, len(services))
+	for i, service := range services {
+		item := &ServiceDocument{PoolServiceBaseEssentials: service.PoolServiceBaseEssentials}
+		if redactSecrets {
+			item.MaskSecrets()
+		}
+		doc.Services[i] = item
+	}
+
+	// export pools
+	pools, _, err := ctrl.GetPools(ctx, nil)
+	if err != nil {
+		c.SetMessage("failed to load pools")
+		return nil, err
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name() < pools[j].Name() })
+	doc.Pools = make([]*PoolDocument, len(pools))
+	for i, pool := range pools {
+		item := &PoolDocument{PoolBaseData: pool.PoolBaseData}
+		bindings, err1 := ctrl.GetPoolBindings(ctx, pool.GetID())
+		if err1 != nil {
+			err = err1
+			c.SetMessage("failed to load bindings of pool")
+			c.SetLoggerField("pool", pool.Name())
+			return nil, err
+		}
+		if len(bindings) != 0 {
+			item.Services = make(map[string]string)
+			for _, binding := range bindings {
+				item.Services[binding.Role()] = binding.ServiceName
+			}
+		}
+		doc.Pools[i] = item
+	}
+
+	// done
+	return doc, nil
+}
+
+// PlanItem describes single change of import plan.
+type PlanItem struct {
+	Action  string   `json:"action"`
+	Object  string   `json:"object"`
+	Name    string   `json:"name"`
+	Role    string   `json:"role,omitempty"`
+	Service string   `json:"service,omitempty"`
+	Fields  []string `json:"fields,omitempty"`
+
+	fields  db.Fields
+	service *ServiceDocument
+	pool    *PoolDocument
+}
+
+func (p *PlanItem) String() string {
+	s := utils.ConcatStrings(p.Action, " ", p.Object, " ", p.Name)
+	if p.Role != "" {
+		s = utils.ConcatStrings(s, " role=", p.Role, " service=", p.Service)
+	}
+	if len(p.Fields) != 0 {
+		s = utils.ConcatStrings(s, " fields=", strings.Join(p.Fields, ","))
+	}
+	return s
+}
+
+// ImportPlan is a list of changes that must be applied to make pools and services match the document.
+type ImportPlan struct {
+	Items []*PlanItem `json:"items"`
+}
+
+func (p *ImportPlan) IsEmpty() bool {
+	return len(p.Items) == 0
+}
+
+func (p *ImportPlan) add(item *PlanItem) {
+	p.Items = append(p.Items, item)
+}
+
+// Convert object to map of database fields. Names of database columns of pools and services are the same as JSON keys.
+func documentFields(obj interface{}) (db.Fields, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	fields := db.Fields{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Find fields that differ in current and desired objects.
+func diffFields(current interface{}, desired interface{}) (db.Fields, error) {
+
+	currentFields, err := documentFields(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := documentFields(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := db.Fields{}
+	for key, value := range desiredFields {
+		if !reflect.DeepEqual(currentFields[key], value) {
+			diff[key] = value
+		}
+	}
+	return diff, nil
+}
+
+func sortedKeys(fields db.Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedRoles(services map[string]string) []string {
+	roles := make([]string, 0, len(services))
+	for role := range services {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func (d *PoolsDocument) validate() error {
+
+	services := make(map[string]bool)
+	for _, service := range d.Services {
+		if service.Name() == "" {
+			return errors.New("service name can not be empty")
+		}
+		if services[service.Name()] {
+			return fmt.Errorf("duplicate service %s", service.Name())
+		}
+		services[service.Name()] = true
+	}
+
+	pools := make(map[string]bool)
+	for _, pool := range d.Pools {
+		if pool.Name() == "" {
+			return errors.New("pool name can not be empty")
+		}
+		if pools[pool.Name()] {
+			return fmt.Errorf("duplicate pool %s", pool.Name())
+		}
+		pools[pool.Name()] = true
+		for role, service := range pool.Services {
+			if role == "" || service == "" {
+				return fmt.Errorf("invalid service binding in pool %s", pool.Name())
+			}
+		}
+	}
+
+	return nil
+}
+
+// Make plan of changes for import of the document. Pools and services are matched by names.
+// Pools and services missing in the document are left intact, services bound to pools from the document
+// but missing in the document are unbound. Masked secrets in the document do not overwrite existing secrets.
+func PlanImportPools(ctrl PoolController, ctx op_context.Context, doc *PoolsDocument) (*ImportPlan, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PlanImportPools")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+	plan := &ImportPlan{}
+
+	// validate document
+	err = doc.validate()
+	if err != nil {
+		ctx.SetGenericErrorCode(ErrorCodeInvalidPoolsDocument)
+		return nil, err
+	}
+
+	// plan services
+	services, _, err := ctrl.GetServices(ctx, nil)
+	if err != nil {
+		c.SetMessage("failed to load services")
+		return nil, err
+	}
+	existingServices := make(map[string]*PoolServiceBase)
+	for _, service := range services {
+		existingServices[service.Name()] = service
+	}
+	for _, service := range doc.Services {
+		current, ok := existingServices[service.Name()]
+		if !ok {
+			plan.add(&PlanItem{Action: PlanActionCreate, Object: PlanObjectService, Name: service.Name(), service: service})
+			continue
+		}
+		fields, err1 := diffFields(&current.PoolServiceBaseEssentials, &service.PoolServiceBaseEssentials)
+		if err1 != nil {
+			err = err1
+			return nil, err
+		}
+		if service.Secret1() == MaskedSecret {
+			delete(fields, "secret1")
+		}
+		if service.Secret2() == MaskedSecret {
+			delete(fields, "secret2")
+		}
+		if len(fields) != 0 {
+			plan.add(&PlanItem{Action: PlanActionUpdate, Object: PlanObjectService, Name: service.Name(), Fields: sortedKeys(fields), fields: fields})
+		}
+	}
+
+	// plan pools
+	pools, _, err := ctrl.GetPools(ctx, nil)
+	if err != nil {
+		c.SetMessage("failed to load pools")
+		return nil, err
+	}
+	existingPools := make(map[string]*PoolBase)
+	for _, pool := range pools {
+		existingPools[pool.Name()] = pool
+	}
+	var bindings []*PlanItem
+	for _, pool := range doc.Pools {
+
+		// pool itself
+		current, ok := existingPools[pool.Name()]
+		if !ok {
+			plan.add(&PlanItem{Action: PlanActionCreate, Object: PlanObjectPool, Name: pool.Name(), pool: pool})
+		} else {
+			fields, err1 := diffFields(&current.PoolBaseData, &pool.PoolBaseData)
+			if err1 != nil {
+				err = err1
+				return nil, err
+			}
+			if len(fields) != 0 {
+				plan.add(&PlanItem{Action: PlanActionUpdate, Object: PlanObjectPool, Name: pool.Name(), Fields: sortedKeys(fields), fields: fields})
+			}
+		}
+
+		// current bindings
+		currentBindings := make(map[string]string)
+		if ok {
+			poolBindings, err1 := ctrl.GetPoolBindings(ctx, current.GetID())
+			if err1 != nil {
+				err = err1
+				c.SetMessage("failed to load bindings of pool")
+				c.SetLoggerField("pool", pool.Name())
+				return nil, err
+			}
+			for _, binding := range poolBindings {
+				currentBindings[binding.Role()] = binding.ServiceName
+			}
+		}
+
+		// unbind services that are not in the document or are bound with other roles
+		for _, role := range sortedRoles(currentBindings) {
+			service := currentBindings[role]
+			if pool.Services[role] != service {
+				plan.add(&PlanItem{Action: PlanActionUnbind, Object: PlanObjectBinding, Name: pool.Name(), Role: role, Service: service})
+			}
+		}
+
+		// bind new services
+		for _, role := range sortedRoles(pool.Services) {
+			service := pool.Services[role]
+			if currentBindings[role] == service {
+				continue
+			}
+			_, inDb := existingServices[service]
+			if !inDb && !doc.hasService(service) {
+				ctx.SetGenericErrorCode(ErrorCodeInvalidPoolsDocument)
+				err = fmt.Errorf("unknown service %s in pool %s", service, pool.Name())
+				return nil, err
+			}
+			bindings = append(bindings, &PlanItem{Action: PlanActionBind, Object: PlanObjectBinding, Name: pool.Name(), Role: role, Service: service})
+		}
+	}
+
+	// bind services after all unbindings
+	plan.Items = append(plan.Items, bindings...)
+
+	// done
+	return plan, nil
+}
+
+func (d *PoolsDocument) hasService(name string) bool {
+	for _, service := range d.Services {
+		if service.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply import plan in single database transaction.
+func ApplyImportPlan(ctrl PoolController, ctx op_context.Context, plan *ImportPlan) error {
+
+	// setup
+	c := ctx.TraceInMethod("ApplyImportPlan")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// apply changes
+	apply := func() error {
+		for _, item := range plan.Items {
+			var err error
+			switch item.Object {
+			case PlanObjectService:
+				if item.Action == PlanActionCreate {
+					service := NewService()
+					service.PoolServiceBaseEssentials = item.service.PoolServiceBaseEssentials
+					if service.Secret1() == MaskedSecret {
+						service.SetSecret1("")
+					}
+					if service.Secret2() == MaskedSecret {
+						service.SetSecret2("")
+					}
+					_, err = ctrl.AddService(ctx, service)
+				} else {
+					_, err = ctrl.UpdateService(ctx, item.Name, item.fields, true)
+				}
+			case PlanObjectPool:
+				if item.Action == PlanActionCreate {
+					pool := NewPool()
+					pool.PoolBaseData = item.pool.PoolBaseData
+					_, err = ctrl.AddPool(ctx, pool)
+				} else {
+					_, err = ctrl.UpdatePool(ctx, item.Name, item.fields, true)
+				}
+			case PlanObjectBinding:
+				if item.Action == PlanActionBind {
+					err = ctrl.AddServiceToPool(ctx, item.Name, item.Service, item.Role, true)
+				} else {
+					err = ctrl.RemoveServiceFromPool(ctx, item.Name, item.Role, true)
+				}
+			}
+			if err != nil {
+				c.Logger().Error("failed to apply import plan", err, logger.Fields{"item": item.String()})
+				return err
+			}
+		}
+		return nil
+	}
+	err = ctx.ExecDbTransaction(apply)
+	if err != nil {
+		return err
+	}
+
+	// done
+	return nil
+}
+
+// Import document of pools and services. If planOnly is true then changes are not applied.
+// Returns plan of changes.
+func ImportPools(ctrl PoolController, ctx op_context.Context, doc *PoolsDocument, planOnly ...bool) (*ImportPlan, error) {
+
+	plan, err := PlanImportPools(ctrl, ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	if utils.OptionalArg(false, planOnly...) || plan.IsEmpty() {
+		return plan, nil
+	}
+
+	err = ApplyImportPlan(ctrl, ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_subscriber"
+)
+
+const (
+	HealthStatusUnknown string = "unknown"
+	HealthStatusUp      string = "up"
+	HealthStatusDown    string = "down"
+)
+
+type PoolServiceHealthData struct {
+	STATUS            string    `gorm:"index" json:"status"`
+	LATENCY_MS        int64     `json:"latency_ms" display:"Latency, ms"`
+	LAST_ERROR        string    `json:"last_error"`
+	CHECKED_AT        time.Time `gorm:"index" json:"checked_at"`
+	STATUS_CHANGED_AT time.Time `gorm:"index" json:"status_changed_at"`
+}
+
+func (h *PoolServiceHealthData) Status() string {
+	return h.STATUS
+}
+
+func (h *PoolServiceHealthData) Latency() time.Duration {
+	return time.Duration(h.LATENCY_MS) * time.Millisecond
+}
+
+func (h *PoolServiceHealthData) LastError() string {
+	return h.LAST_ERROR
+}
+
+func (h *PoolServiceHealthData) CheckedAt() time.Time {
+	return h.CHECKED_AT
+}
+
+func (h *PoolServiceHealthData) StatusChangedAt() time.Time {
+	return h.STATUS_CHANGED_AT
+}
+
+func (h *PoolServiceHealthData) IsUp() bool {
+	return h.STATUS == HealthStatusUp
+}
+
+type PoolServiceHealth struct {
+	common.ObjectBase
+	SERVICE_ID   string `gorm:"uniqueIndex" json:"service_id"`
+	SERVICE_NAME string `gorm:"index" json:"service_name"`
+	PoolServiceHealthData
+}
+
+func (PoolServiceHealth) TableName() string {
+	return "pool_service_health"
+}
+
+func (h *PoolServiceHealth) ServiceId() string {
+	return h.SERVICE_ID
+}
+
+func (h *PoolServiceHealth) ServiceName() string {
+	return h.SERVICE_NAME
+}
+
+func NewServiceHealth(service PoolService) *PoolServiceHealth {
+	h := &PoolServiceHealth{}
+	h.SERVICE_ID = service.GetID()
+	h.SERVICE_NAME = service.Name()
+	h.STATUS = HealthStatusUnknown
+	return h
+}
+
+type HealthNotification struct {
+	ServiceId   string `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	Status      string `json:"status"`
+	LastError   string `json:"last_error,omitempty"`
+}
+
+const HealthTopicName = "pool_service_health"
+
+type HealthTopic struct {
+	*pubsub_subscriber.TopicBase[*HealthNotification]
+}
+
+func NewHealthNotification() *HealthNotification {
+	return &HealthNotification{}
+}
+
+func NewHealthTopic() *HealthTopic {
+	t := &HealthTopic{}
+	t.TopicBase = pubsub_subscriber.New(HealthTopicName, NewHealthNotification)
+	return t
+}
+
+// PoolHealthRecorder is implemented by pool controllers that can save results of service health checks.
+type PoolHealthRecorder interface {
+	SaveServiceHealth(ctx op_context.Context, health *PoolServiceHealth) (bool, error)
+}
@@ -0,0 +1,105 @@
+package pool_health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// HealthChecker checks if pool service is reachable.
+type HealthChecker interface {
+	Check(ctx op_context.Context, service *pool.PoolServiceBase, timeout time.Duration) error
+}
+
+// DatabaseChecker connects to database of the service and pings it.
+type DatabaseChecker struct{}
+
+func (d *DatabaseChecker) Check(ctx op_context.Context, service *pool.PoolServiceBase, timeout time.Duration) error {
+
+	dbConfig, err := pool.ParseDbService(&service.PoolServiceBaseData)
+	if err != nil {
+		return err
+	}
+
+	// database is connected lazily so that connection is established by ping aborted on timeout
+	database := ctx.App().Db().Clone()
+	lazy, ok := database.(db.WithLazyConnect)
+	if ok {
+		lazy.SetLazyConnect(true)
+	}
+	err = database.InitWithConfig(ctx, ctx.App().Validator(), dbConfig)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	sysCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = database.PingContext(ctx, sysCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("database did not respond in %v", timeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// RedisChecker pings redis server of the service.
+// Secret1 is used as a password and DbName as a number of database.
+type RedisChecker struct{}
+
+func (r *RedisChecker) Check(ctx op_context.Context, service *pool.PoolServiceBase, timeout time.Duration) error {
+
+	db := 0
+	if service.DbName() != "" {
+		dbU, err := utils.StrToUint32(service.DbName())
+		if err != nil {
+			return errors.New("invalid number of redis database")
+		}
+		db = int(dbU)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:        fmt.Sprintf("%s:%d", service.PrivateHost(), service.PrivatePort()),
+		Password:    service.Secret1(),
+		DB:          db,
+		DialTimeout: timeout,
+		ReadTimeout: timeout,
+	})
+	defer client.Close()
+
+	sysCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.Ping(sysCtx).Err()
+}
+
+// HttpChecker sends GET request to private URL of the service. Service is up if response status is less than 500.
+type HttpChecker struct{}
+
+func (h *HttpChecker) Check(ctx op_context.Context, service *pool.PoolServiceBase, timeout time.Duration) error {
+
+	if service.PrivateUrl() == "" {
+		return errors.New("private URL of service is not set")
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(service.PrivateUrl())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("service responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package pool_health
+
+import (
+	"errors"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context/default_op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+type HealthMonitorConfig struct {
+	PERIOD         int `default:"60" validate:"gt=0" vmessage:"Period of health checks must be positive"`
+	TIMEOUT        int `default:"5" validate:"gt=0" vmessage:"Timeout of health check must be positive"`
+	CHECK_INACTIVE bool
+}
+
+// Publisher of notifications about health of services, e.g. pool_pubsub.PoolPubsub.
+type Publisher interface {
+	PublishPools(topicName string, msg interface{}, poolIds ...string) error
+}
+
+// HealthMonitor periodically checks services of pools and saves results using pool controller.
+type HealthMonitor struct {
+	background_worker.JobRunnerBase
+	HealthMonitorConfig
+
+	app        app_context.Context
+	controller pool.PoolController
+	pubsub     Publisher
+	checkers   map[string]HealthChecker
+	worker     *background_worker.BackgroundWorker
+}
+
+func NewHealthMonitor(controller pool.PoolController, pubsub ...Publisher) *HealthMonitor {
+	m := &HealthMonitor{}
+	m.controller = controller
+	m.pubsub = utils.OptionalArg(nil, pubsub...)
+
+	m.checkers = make(map[string]HealthChecker)
+	dbChecker := &DatabaseChecker{}
+	m.AddChecker("postgres", dbChecker)
+	m.AddChecker("sqlite", dbChecker)
+	m.AddChecker("redis", &RedisChecker{})
+	httpChecker := &HttpChecker{}
+	m.AddChecker("http", httpChecker)
+	m.AddChecker("https", httpChecker)
+
+	return m
+}
+
+func (m *HealthMonitor) Config() interface{} {
+	return &m.HealthMonitorConfig
+}
+
+// Add or replace checker for services of given provider.
+func (m *HealthMonitor) AddChecker(provider string, checker HealthChecker) {
+	m.checkers[provider] = checker
+}
+
+// Find checker for service. If there is no checker for provider of the service but service has private URL then HTTP checker is used.
+func (m *HealthMonitor) Checker(service *pool.PoolServiceBase) HealthChecker {
+	checker, ok := m.checkers[service.Provider()]
+	if ok {
+		return checker
+	}
+	if service.PrivateUrl() != "" {
+		return m.checkers["http"]
+	}
+	return nil
+}
+
+func (m *HealthMonitor) Init(app app_context.Context, configPath ...string) error {
+
+	m.app = app
+
+	err := object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), m, "pools.health", configPath...)
+	if err != nil {
+		return app.Logger().PushFatalStack("failed to load configuration of pool health monitor", err)
+	}
+
+	m.worker = background_worker.New(app.Logger(), m, m.PERIOD)
+	return nil
+}
+
+func (m *HealthMonitor) Worker() *background_worker.BackgroundWorker {
+	return m.worker
+}
+
+// Run health checks in background. If leader is set then checks run only when this instance is a leader.
+func (m *HealthMonitor) Start(leader ...background_worker.Leadership) {
+	if len(leader) != 0 {
+		m.worker.RunOnlyWhenLeader(leader[0])
+	}
+	m.worker.RunInBackground()
+}
+
+func (m *HealthMonitor) Close() {
+	if m.worker != nil {
+		m.worker.Stop()
+	}
+}
+
+func (m *HealthMonitor) RunJob() {
+	ctx := default_op_context.NewBackgroundContext(m.app, "PoolHealthMonitor")
+	defer ctx.Close()
+	m.CheckServices(ctx)
+}
+
+func (m *HealthMonitor) stopped() bool {
+	return m.Stopper() != nil && m.Stopper().IsStopped()
+}
+
+// Check all services.
+func (m *HealthMonitor) CheckServices(ctx op_context.Context) error {
+
+	// setup
+	c := ctx.TraceInMethod("HealthMonitor.CheckServices")
+	defer ctx.TraceOutMethod()
+
+	// load services
+	var filter *db.Filter
+	if !m.CHECK_INACTIVE {
+		filter = db.NewFilter()
+		filter.AddField("active", true)
+	}
+	services, _, err := m.controller.GetServices(ctx, filter)
+	if err != nil {
+		c.SetMessage("failed to load services")
+		return c.SetError(err)
+	}
+
+	// check services
+	for _, service := range services {
+		if m.stopped() {
+			break
+		}
+		_, err = m.CheckService(ctx, service)
+		if err != nil {
+			c.Logger().Error("failed to check service", err, logger.Fields{"service": service.Name()})
+		}
+	}
+
+	// done
+	return nil
+}
+
+// Check service, save result and publish notification if status of the service changed.
+func (m *HealthMonitor) CheckService(ctx op_context.Context, service *pool.PoolServiceBase) (*pool.PoolServiceHealth, error) {
+
+	// setup
+	c := ctx.TraceInMethod("HealthMonitor.CheckService", logger.Fields{"service": service.Name(), "provider": service.Provider()})
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find checker
+	health := pool.NewServiceHealth(service)
+	checker := m.Checker(service)
+	if checker == nil {
+		c.Logger().Debug("no health checker for service")
+		return health, nil
+	}
+
+	// check service
+	started := time.Now()
+	checkErr := checker.Check(ctx, service, time.Duration(m.TIMEOUT)*time.Second)
+	health.CHECKED_AT = time.Now()
+	health.LATENCY_MS = health.CHECKED_AT.Sub(started).Milliseconds()
+	if checkErr != nil {
+		health.STATUS = pool.HealthStatusDown
+		health.LAST_ERROR = checkErr.Error()
+	} else {
+		health.STATUS = pool.HealthStatusUp
+	}
+
+	// save result
+	recorder, ok := m.controller.(pool.PoolHealthRecorder)
+	if !ok {
+		err = errors.New("pool controller can not save health of services")
+		return nil, err
+	}
+	changed, err := recorder.SaveServiceHealth(ctx, health)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish notification
+	if changed {
+		fields := logger.Fields{"service": service.Name(), "status": health.STATUS, "last_error": health.LAST_ERROR}
+		if health.IsUp() {
+			c.Logger().Info("service is up", fields)
+		} else {
+			c.Logger().Warn("service is down", fields)
+		}
+		if m.pubsub != nil {
+			notification := &pool.HealthNotification{ServiceId: service.GetID(), ServiceName: service.Name(), Status: health.STATUS, LastError: health.LAST_ERROR}
+			pubErr := m.pubsub.PublishPools(pool.HealthTopicName, notification)
+			if pubErr != nil {
+				c.Logger().Error("failed to publish notification about service health", pubErr)
+			}
+		}
+	}
+
+	// done
+	return health, nil
+}
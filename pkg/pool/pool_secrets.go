@@ -0,0 +1,336 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Version of envelope of encrypted secrets.
+const EncryptedSecretVersion = "v1"
+
+// Prefix of encrypted secrets. Format of encrypted secret is $psec$v1$<key_id>$<wrapped_data_key>$<ciphertext>.
+// Prefix can not be confused with env:, file: and enc: references to secrets in configuration.
+const EncryptedSecretPrefix = "$psec$" + EncryptedSecretVersion + "$"
+
+const encryptedSecretSeparator = "$"
+
+// Value used instead of secrets in responses to non-privileged clients.
+const MaskedSecret = "********"
+
+// SecretsCipher encrypts and decrypts secrets of pool services with envelope encryption.
+// Each secret is encrypted with random data key which is in turn encrypted with master key.
+type SecretsCipher interface {
+	KeyId() string
+	EncryptSecret(secret string) (string, error)
+	DecryptSecret(value string) (string, error)
+	ReencryptSecret(value string) (string, bool, error)
+}
+
+// PoolSecretsManager is implemented by pool controllers that can encrypt secrets of services.
+type PoolSecretsManager interface {
+	SetSecretsCipher(cipher SecretsCipher)
+	SecretsCipher() SecretsCipher
+	ReencryptServiceSecrets(ctx op_context.Context, keyId ...string) (int, error)
+}
+
+type SecretsKeyConfig struct {
+	ID       string `validate:"required,alphanum_"`
+	KEY      string `mask:"true"`
+	KEY_FILE string `validate:"omitempty,file"`
+}
+
+type secretsKey struct {
+	SecretsKeyConfig
+}
+
+func (s *secretsKey) Config() interface{} {
+	return &s.SecretsKeyConfig
+}
+
+type SecretsCipherConfig struct {
+	KEY_ID string `validate:"required,alphanum_"`
+	SALT   string `default:"pool_secrets" mask:"true"`
+}
+
+type SecretsCipherBase struct {
+	SecretsCipherConfig
+	keys map[string]*crypt_utils.AEAD
+}
+
+func NewSecretsCipher() *SecretsCipherBase {
+	s := &SecretsCipherBase{}
+	s.keys = make(map[string]*crypt_utils.AEAD)
+	s.SALT = "pool_secrets"
+	return s
+}
+
+func (s *SecretsCipherBase) Config() interface{} {
+	return &s.SecretsCipherConfig
+}
+
+func (s *SecretsCipherBase) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
+
+	path := utils.OptionalArg("pools.secrets", configPath...)
+	fields := logger.Fields{"config_path": path}
+
+	// load configuration
+	err := object_config.LoadLogValidate(cfg, log, vld, s, path)
+	if err != nil {
+		return log.PushFatalStack("failed to load configuration of secrets cipher", err, fields)
+	}
+
+	// load master keys
+	keysPath := object_config.Key(path, "keys")
+	keysSection, ok := cfg.Get(keysPath).([]interface{})
+	if !ok {
+		return log.PushFatalStack("failed to load master keys of secrets cipher", errors.New("invalid keys section"), fields)
+	}
+	for i := range keysSection {
+		keyPath := object_config.KeyInt(keysPath, i)
+		key := &secretsKey{}
+		err = object_config.LoadLogValidate(cfg, log, vld, key, keyPath)
+		if err != nil {
+			return log.PushFatalStack("failed to load master key", err, fields)
+		}
+		secret := key.KEY
+		if key.KEY_FILE != "" {
+			data, err := os.ReadFile(key.KEY_FILE)
+			if err != nil {
+				return log.PushFatalStack("failed to read file of master key", err, fields, logger.Fields{"key_id": key.ID})
+			}
+			secret = strings.TrimSpace(string(data))
+		}
+		err = s.AddKey(key.ID, secret)
+		if err != nil {
+			return log.PushFatalStack("failed to add master key", err, fields, logger.Fields{"key_id": key.ID})
+		}
+	}
+
+	// check if current key is configured
+	if _, ok := s.keys[s.KEY_ID]; !ok {
+		return log.PushFatalStack("invalid configuration of secrets cipher", fmt.Errorf("master key %s not found", s.KEY_ID), fields)
+	}
+
+	// done
+	return nil
+}
+
+// Add master key to key ring.
+func (s *SecretsCipherBase) AddKey(id string, secret string) error {
+	if id == "" || strings.Contains(id, encryptedSecretSeparator) {
+		return errors.New("invalid key ID")
+	}
+	if secret == "" {
+		return errors.New("master key can not be empty")
+	}
+	aead, err := crypt_utils.NewAEAD(secret, []byte(s.SALT))
+	if err != nil {
+		return err
+	}
+	s.keys[id] = aead
+	return nil
+}
+
+// Set ID of master key used for encryption.
+func (s *SecretsCipherBase) SetKeyId(id string) error {
+	if _, ok := s.keys[id]; !ok {
+		return fmt.Errorf("master key %s not found", id)
+	}
+	s.KEY_ID = id
+	return nil
+}
+
+func (s *SecretsCipherBase) KeyId() string {
+	return s.KEY_ID
+}
+
+// Check if value is a valid envelope of encrypted secret.
+func IsEncryptedSecret(value string) bool {
+	_, err := parseEncryptedSecret(value)
+	return err == nil
+}
+
+func (s *SecretsCipherBase) masterKey(id string) (*crypt_utils.AEAD, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("master key %s not found", id)
+	}
+	return key, nil
+}
+
+func (s *SecretsCipherBase) wrapDataKey(keyId string, dataKey []byte) (string, error) {
+	key, err := s.masterKey(keyId)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := key.Encrypt(dataKey, []byte(keyId))
+	if err != nil {
+		return "", err
+	}
+	b64 := utils.Base64StringCoding{}
+	return b64.Encode(wrapped), nil
+}
+
+func (s *SecretsCipherBase) EncryptSecret(secret string) (string, error) {
+
+	if secret == "" {
+		return "", nil
+	}
+
+	// generate data key
+	dataKey, err := crypt_utils.GenerateCryptoRand(chacha20poly1305.KeySize)
+	if err != nil {
+		return "", err
+	}
+	dataCipher, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return "", err
+	}
+	aead := &crypt_utils.AEAD{Cipher: dataCipher}
+
+	// encrypt secret with data key
+	ciphertext, err := aead.Encrypt([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+
+	// encrypt data key with master key
+	wrappedKey, err := s.wrapDataKey(s.KEY_ID, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// done
+	b64 := utils.Base64StringCoding{}
+	return formatEncryptedSecret(s.KEY_ID, wrappedKey, b64.Encode(ciphertext)), nil
+}
+
+type encryptedSecret struct {
+	keyId      string
+	wrappedKey []byte
+	ciphertext string
+}
+
+func formatEncryptedSecret(keyId string, wrappedKey string, ciphertext string) string {
+	return utils.ConcatStrings(EncryptedSecretPrefix, keyId, encryptedSecretSeparator, wrappedKey, encryptedSecretSeparator, ciphertext)
+}
+
+func parseEncryptedSecret(value string) (*encryptedSecret, error) {
+
+	invalid := errors.New("invalid format of encrypted secret")
+
+	if !strings.HasPrefix(value, EncryptedSecretPrefix) {
+		return nil, invalid
+	}
+	parts := strings.Split(strings.TrimPrefix(value, EncryptedSecretPrefix), encryptedSecretSeparator)
+	if len(parts) != 3 || parts[0] == "" {
+		return nil, invalid
+	}
+
+	// data key is wrapped with nonce and tag of master key
+	b64 := utils.Base64StringCoding{}
+	wrappedKey, err := b64.Decode(parts[1])
+	if err != nil || len(wrappedKey) <= chacha20poly1305.KeySize+chacha20poly1305.Overhead {
+		return nil, invalid
+	}
+
+	// ciphertext contains nonce and tag of data key
+	ciphertext, err := b64.Decode(parts[2])
+	if err != nil || len(ciphertext) < chacha20poly1305.NonceSizeX+chacha20poly1305.Overhead {
+		return nil, invalid
+	}
+
+	return &encryptedSecret{keyId: parts[0], wrappedKey: wrappedKey, ciphertext: parts[2]}, nil
+}
+
+func (s *SecretsCipherBase) unwrapDataKey(secret *encryptedSecret) ([]byte, error) {
+	key, err := s.masterKey(secret.keyId)
+	if err != nil {
+		return nil, err
+	}
+	return key.Decrypt(secret.wrappedKey, []byte(secret.keyId))
+}
+
+func (s *SecretsCipherBase) DecryptSecret(value string) (string, error) {
+
+	// plaintext secrets are returned as is
+	if !IsEncryptedSecret(value) {
+		return value, nil
+	}
+
+	// decrypt data key
+	secret, err := parseEncryptedSecret(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := s.unwrapDataKey(secret)
+	if err != nil {
+		return "", err
+	}
+	dataCipher, err := chacha20poly1305.NewX(dataKey)
+	if err != nil {
+		return "", err
+	}
+	aead := &crypt_utils.AEAD{Cipher: dataCipher}
+
+	// decrypt secret with data key
+	b64 := utils.Base64StringCoding{}
+	ciphertext, err := b64.Decode(secret.ciphertext)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := aead.Decrypt(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	// done
+	return string(plaintext), nil
+}
+
+// Re-encrypt secret with current master key. Only data key is re-encrypted for secrets that are already encrypted.
+// Returns true if value was changed.
+func (s *SecretsCipherBase) ReencryptSecret(value string) (string, bool, error) {
+
+	if value == "" {
+		return value, false, nil
+	}
+
+	if !IsEncryptedSecret(value) {
+		encrypted, err := s.EncryptSecret(value)
+		if err != nil {
+			return "", false, err
+		}
+		return encrypted, true, nil
+	}
+
+	secret, err := parseEncryptedSecret(value)
+	if err != nil {
+		return "", false, err
+	}
+	if secret.keyId == s.KEY_ID {
+		return value, false, nil
+	}
+
+	dataKey, err := s.unwrapDataKey(secret)
+	if err != nil {
+		return "", false, err
+	}
+	wrappedKey, err := s.wrapDataKey(s.KEY_ID, dataKey)
+	if err != nil {
+		return "", false, err
+	}
+
+	return formatEncryptedSecret(s.KEY_ID, wrappedKey, secret.ciphertext), true, nil
+}
@@ -7,6 +7,8 @@ import (
 type Secrets interface {
 	Secret1() string
 	Secret2() string
+	SetSecret1(secret string)
+	SetSecret2(secret string)
 }
 
 type ServiceConfig interface {
@@ -57,6 +59,24 @@ func (s *SecretsBase) Secret2() string {
 	return s.SECRET2
 }
 
+func (s *SecretsBase) SetSecret1(secret string) {
+	s.SECRET1 = secret
+}
+
+func (s *SecretsBase) SetSecret2(secret string) {
+	s.SECRET2 = secret
+}
+
+// Replace non-empty secrets with mask.
+func (s *SecretsBase) MaskSecrets() {
+	if s.SECRET1 != "" {
+		s.SECRET1 = MaskedSecret
+	}
+	if s.SECRET2 != "" {
+		s.SECRET2 = MaskedSecret
+	}
+}
+
 type ServiceConfigBase struct {
 	PROVIDER        string `gorm:"index;column:provider" json:"provider" long:"provider" description:"Service provider" required:"true"`
 	PUBLIC_HOST     string `gorm:"index" json:"public_host" long:"public-host" description:"Public host of the service (optional)"`
@@ -73,6 +73,23 @@ func (p *PoolStoreBase) Init(ctx op_context.Context, configPath ...string) error
 		return ctx.Logger().PushFatalStack(msg, c.SetError(err))
 	}
 
+	// init encryption of service secrets
+	secretsManager, canEncrypt := p.poolController.(PoolSecretsManager)
+	secretsPath := object_config.Key(utils.OptionalArg("pools", configPath...), "secrets")
+	if ctx.App().Cfg().IsSet(secretsPath) {
+		if !canEncrypt {
+			return ctx.Logger().PushFatalStack("failed to init encryption of service secrets", c.SetErrorStr("pool controller does not support encryption of secrets"))
+		}
+		cipher := NewSecretsCipher()
+		err = cipher.Init(ctx.App().Cfg(), ctx.Logger(), ctx.App().Validator(), secretsPath)
+		if err != nil {
+			return c.SetError(err)
+		}
+		secretsManager.SetSecretsCipher(cipher)
+	} else if canEncrypt {
+		secretsManager.SetSecretsCipher(nil)
+	}
+
 	loadServices := func(pool Pool) error {
 		services, err := p.poolController.GetPoolBindings(ctx, pool.GetID())
 		if err != nil {
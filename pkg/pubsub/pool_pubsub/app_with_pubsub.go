@@ -4,6 +4,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/pool/app_with_pools"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_health"
 	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
 )
 
@@ -14,13 +15,19 @@ type AppWithPubsub interface {
 
 type AppWithPubsubBase struct {
 	*app_with_pools.AppWithPoolsBase
-	pubsub *PoolPubsubBase
+	pubsub        *PoolPubsubBase
+	healthMonitor *pool_health.HealthMonitor
 }
 
 func (a *AppWithPubsubBase) Pubsub() PoolPubsub {
 	return a.pubsub
 }
 
+// Monitor of health of pool services, nil if health monitoring is not configured in pools.health section.
+func (a *AppWithPubsubBase) HealthMonitor() *pool_health.HealthMonitor {
+	return a.healthMonitor
+}
+
 type PoolPubsubConfigI interface {
 	GetPubsubFactory() pubsub_factory.PubsubFactory
 }
@@ -81,6 +88,18 @@ func (a *AppWithPubsubBase) InitWithArgs(configFile string, args []string, confi
 		return opCtx, opCtx.Logger().PushFatalStack(msg, c.SetError(err))
 	}
 
+	if a.Cfg().IsSet("pools.health") {
+		monitor := pool_health.NewHealthMonitor(a.Pools().PoolController(), a.pubsub)
+		err = monitor.Init(a)
+		if err != nil {
+			msg := "failed to init health monitor of pool services"
+			c.SetMessage(msg)
+			return opCtx, opCtx.Logger().PushFatalStack(msg, c.SetError(err))
+		}
+		monitor.Start()
+		a.healthMonitor = monitor
+	}
+
 	return opCtx, nil
 }
 
@@ -89,5 +108,9 @@ func (a *AppWithPubsubBase) Init(configFile string, configType ...string) (op_co
 }
 
 func (a *AppWithPubsubBase) Close() {
+	if a.healthMonitor != nil {
+		a.healthMonitor.Close()
+		a.healthMonitor = nil
+	}
 	a.AppWithPoolsBase.Close()
 }
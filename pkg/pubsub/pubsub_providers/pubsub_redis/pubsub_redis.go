@@ -68,6 +68,14 @@ func (r *RedisClient) InitWithConfig(log logger.Logger, cfg *RedisConfig) error
 	return nil
 }
 
+func (r *RedisClient) Client() *redis.Client {
+	return r.redisClient
+}
+
+func (r *RedisClient) Context() context.Context {
+	return r.context
+}
+
 func (p *RedisClient) Shutdown(ctx context.Context) error {
 	return p.redisClient.Close()
 }
@@ -15,6 +15,7 @@ type MessageSignature struct {
 	Message    string
 	Signature  string
 	ExtraData  string `gorm:"index"`
+	PubKeyId   string `gorm:"index"`
 	PubKeyHash string `gorm:"index"`
 }
 
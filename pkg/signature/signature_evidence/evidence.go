@@ -0,0 +1,125 @@
+package signature_evidence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+const EvidenceVersion int = 1
+
+type Query struct {
+	Context string    `json:"context,omitempty"`
+	UserId  string    `json:"user_id,omitempty"`
+	From    time.Time `json:"from,omitempty"`
+	To      time.Time `json:"to,omitempty"`
+}
+
+type SignedMessage struct {
+	Id         string                   `json:"id"`
+	CreatedAt  time.Time                `json:"created_at"`
+	Context    string                   `json:"context"`
+	Operation  string                   `json:"operation"`
+	UserId     string                   `json:"user_id"`
+	UserLogin  string                   `json:"user_login"`
+	Algorithm  string                   `json:"algorithm"`
+	Message    string                   `json:"message"`
+	Signature  string                   `json:"signature"`
+	ExtraData  string                   `json:"extra_data"`
+	PubKeyId   string                   `json:"pub_key_id"`
+	PubKeyHash string                   `json:"pub_key_hash"`
+	PubKey     string                   `json:"pub_key"`
+	KeyHistory []*signature.OpLogPubKey `json:"key_history"`
+}
+
+// Decoded original message. Message is kept in evidence in base64 format.
+func (s *SignedMessage) RawMessage() ([]byte, error) {
+	return utils.Base64Decode(s.Message)
+}
+
+type Evidence struct {
+	Version   int              `json:"version"`
+	Issuer    string           `json:"issuer"`
+	CreatedAt time.Time        `json:"created_at"`
+	Query     Query            `json:"query"`
+	Messages  []*SignedMessage `json:"messages"`
+}
+
+// Evidence package consists of evidence document in JSON format and detached signature of that document.
+type Package struct {
+	Evidence  []byte
+	Signature string
+}
+
+// Verify signature of a single message using public key included into the message.
+func VerifyMessage(msg *SignedMessage) error {
+
+	if msg.Algorithm != crypt_utils.RSA_H256_SIGNATURE {
+		return fmt.Errorf("unsupported algorithm %s", msg.Algorithm)
+	}
+
+	if crypt_utils.H256B64([]byte(msg.PubKey)) != msg.PubKeyHash {
+		return errors.New("hash of public key mismatch")
+	}
+
+	message, err := msg.RawMessage()
+	if err != nil {
+		return fmt.Errorf("invalid message encoding: %s", err)
+	}
+
+	verifier := crypt_utils.NewRsaVerifier()
+	err = verifier.LoadKey([]byte(msg.PubKey))
+	if err != nil {
+		return fmt.Errorf("invalid public key: %s", err)
+	}
+
+	err = crypt_utils.VerifySignature(verifier, message, msg.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+
+	return nil
+}
+
+// Verify evidence package offline.
+// First, the detached signature of the evidence document is checked with public key of the issuer.
+// Then, signatures of all messages are checked with public keys included into the evidence.
+func VerifyPackage(evidence []byte, evidenceSignature string, issuerPubKey []byte) (*Evidence, error) {
+
+	// check signature of the package
+	verifier := crypt_utils.NewRsaVerifier()
+	err := verifier.LoadKey(issuerPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of issuer: %s", err)
+	}
+	err = crypt_utils.VerifySignature(verifier, evidence, evidenceSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of evidence: %s", err)
+	}
+
+	// parse evidence
+	e := &Evidence{}
+	err = json.Unmarshal(evidence, e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse evidence: %s", err)
+	}
+	if e.Version != EvidenceVersion {
+		return nil, fmt.Errorf("unsupported evidence version %d", e.Version)
+	}
+
+	// check messages
+	for _, msg := range e.Messages {
+		err = VerifyMessage(msg)
+		if err != nil {
+			return nil, fmt.Errorf("message %s: %s", msg.Id, err)
+		}
+	}
+
+	// done
+	return e, nil
+}
@@ -0,0 +1,198 @@
+package signature_evidence
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator"
+)
+
+type PubKeyFinder interface {
+	FindPubKeyByHash(ctx op_context.Context, ownerId string, hash string) (signature.PubKey, error)
+}
+
+type ExporterConfig struct {
+	PRIVATE_KEY_FILE     string `validate:"required,file"`
+	PRIVATE_KEY_PASSWORD string `mask:"true"`
+	ISSUER               string
+}
+
+type Exporter struct {
+	ExporterConfig
+	manager *signature.SignatureManagerBase
+	keys    PubKeyFinder
+	signer  *crypt_utils.RsaSigner
+}
+
+func NewExporter(manager *signature.SignatureManagerBase, keys PubKeyFinder) *Exporter {
+	return &Exporter{manager: manager, keys: keys}
+}
+
+func (e *Exporter) Config() interface{} {
+	return &e.ExporterConfig
+}
+
+func (e *Exporter) Init(cfg config.Config, log logger.Logger, vld validator.Validator, configPath ...string) error {
+
+	// load configuration
+	path := utils.OptionalArg("signature.evidence", configPath...)
+	err := object_config.LoadLogValidate(cfg, log, vld, e, path)
+	if err != nil {
+		return log.PushFatalStack("failed to init signature evidence exporter", err)
+	}
+
+	// load key of issuer
+	e.signer = crypt_utils.NewRsaSigner()
+	err = e.signer.LoadKeyFromFile(e.PRIVATE_KEY_FILE, e.PRIVATE_KEY_PASSWORD)
+	if err != nil {
+		return log.PushFatalStack("failed to load private key of signature evidence exporter", err)
+	}
+
+	// done
+	return nil
+}
+
+// Export signed messages either for context ID or for user and time range.
+func (e *Exporter) Export(ctx op_context.Context, query *Query) (*Package, error) {
+
+	// setup
+	c := ctx.TraceInMethod("signature_evidence.Export", logger.Fields{"context": query.Context, "user_id": query.UserId})
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find signatures
+	var signatures []*signature.MessageSignature
+	if query.Context != "" {
+		var obj *signature.MessageSignature
+		obj, err = e.manager.Find(ctx, query.Context)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, obj)
+	} else {
+		if query.UserId == "" {
+			err = errors.New("either context or user must be specified")
+			return nil, err
+		}
+		filter := db.NewFilter()
+		filter.AddField("user_id", query.UserId)
+		if !query.From.IsZero() || !query.To.IsZero() {
+			var from, to interface{}
+			if !query.From.IsZero() {
+				from = query.From
+			}
+			if !query.To.IsZero() {
+				to = query.To
+			}
+			filter.AddInterval("created_at", from, to)
+		}
+		filter.SetSorting("created_at")
+		signatures, err = e.manager.FindSignatures(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// fill evidence
+	evidence := &Evidence{}
+	evidence.Version = EvidenceVersion
+	evidence.Issuer = e.ISSUER
+	if evidence.Issuer == "" {
+		evidence.Issuer = ctx.App().Application()
+	}
+	evidence.CreatedAt = time.Now().UTC()
+	evidence.Query = *query
+	evidence.Messages = make([]*SignedMessage, 0, len(signatures))
+	for _, obj := range signatures {
+		var msg *SignedMessage
+		msg, err = e.signedMessage(ctx, obj)
+		if err != nil {
+			c.SetLoggerField("signature_id", obj.GetID())
+			return nil, err
+		}
+		evidence.Messages = append(evidence.Messages, msg)
+	}
+
+	// sign evidence
+	pkg := &Package{}
+	pkg.Evidence, err = json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		c.SetMessage("failed to serialize evidence")
+		return nil, err
+	}
+	pkg.Signature, err = crypt_utils.Sign(e.signer, pkg.Evidence)
+	if err != nil {
+		c.SetMessage("failed to sign evidence")
+		return nil, err
+	}
+
+	// done
+	return pkg, nil
+}
+
+func (e *Exporter) signedMessage(ctx op_context.Context, obj *signature.MessageSignature) (*SignedMessage, error) {
+
+	c := ctx.TraceInMethod("signature_evidence.signedMessage")
+	defer ctx.TraceOutMethod()
+
+	msg := &SignedMessage{}
+	msg.Id = obj.GetID()
+	msg.CreatedAt = obj.GetCreatedAt()
+	msg.Context = obj.Context
+	msg.Operation = obj.Operation
+	msg.UserId = obj.UserId
+	msg.UserLogin = obj.UserLogin
+	msg.Algorithm = obj.Algorithm
+	msg.Signature = obj.Signature
+	msg.ExtraData = obj.ExtraData
+	msg.PubKeyId = obj.PubKeyId
+	msg.PubKeyHash = obj.PubKeyHash
+
+	// decrypt message
+	message, err := e.manager.DecryptMessage(obj)
+	if err != nil {
+		c.SetMessage("failed to decrypt message")
+		return nil, c.SetError(err)
+	}
+	msg.Message = utils.Base64Encode(message)
+
+	// find public key
+	key, err := e.keys.FindPubKeyByHash(ctx, obj.UserId, obj.PubKeyHash)
+	if err != nil {
+		c.SetMessage("failed to find public key")
+		return nil, c.SetError(err)
+	}
+	if key == nil {
+		return nil, c.SetError(errors.New("public key not found"))
+	}
+	msg.PubKey = key.PubKey()
+
+	// load history of the key
+	filter := db.NewFilter()
+	filter.AddField("user_id", obj.UserId)
+	filter.AddField("key_hash", obj.PubKeyHash)
+	filter.SetSorting("created_at")
+	_, err = op_context.DB(ctx).FindWithFilter(ctx, filter, &msg.KeyHistory)
+	if err != nil {
+		c.SetMessage("failed to load history of public key")
+		return nil, c.SetError(err)
+	}
+
+	// done
+	return msg, nil
+}
@@ -0,0 +1,76 @@
+package signature_evidence_console
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/signature_evidence"
+)
+
+const ExportCmd string = "export"
+const ExportDescription string = "Export signed messages either for operation context or for user and time range"
+
+func Export() console_tool.Handler[*EvidenceCommands] {
+	a := &ExportHandler{}
+	a.Init(ExportCmd, ExportDescription)
+	return a
+}
+
+type ExportData struct {
+	ContextId string `long:"context" description:"Context ID of signed operation"`
+	User      string `long:"user" description:"User ID"`
+	From      string `long:"from" description:"Start of time range in RFC3339 format"`
+	To        string `long:"to" description:"End of time range in RFC3339 format"`
+	Out       string `long:"out" description:"Path of output files without extension, evidence is written to <out>.json and signature is written to <out>.sig" required:"true"`
+}
+
+type ExportHandler struct {
+	HandlerBase
+	ExportData
+}
+
+func (a *ExportHandler) Data() interface{} {
+	return &a.ExportData
+}
+
+func (a *ExportHandler) Execute(args []string) error {
+
+	ctx, exporter, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	query := &signature_evidence.Query{Context: a.ContextId, UserId: a.User}
+	if a.From != "" {
+		query.From, err = time.Parse(time.RFC3339, a.From)
+		if err != nil {
+			return fmt.Errorf("invalid start of time range: %s", err)
+		}
+	}
+	if a.To != "" {
+		query.To, err = time.Parse(time.RFC3339, a.To)
+		if err != nil {
+			return fmt.Errorf("invalid end of time range: %s", err)
+		}
+	}
+
+	pkg, err := exporter.Export(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(a.Out+".json", pkg.Evidence, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write evidence: %s", err)
+	}
+	err = os.WriteFile(a.Out+".sig", []byte(pkg.Signature), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write signature: %s", err)
+	}
+
+	fmt.Printf("Evidence written to %s.json, signature written to %s.sig\n", a.Out, a.Out)
+	return nil
+}
@@ -0,0 +1,34 @@
+package signature_evidence_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/signature_evidence"
+)
+
+type EvidenceCommands struct {
+	console_tool.Commands[*EvidenceCommands]
+	MakeExporter func(app app_context.Context) *signature_evidence.Exporter
+}
+
+func NewEvidenceCommands(exporterBuilder func(app app_context.Context) *signature_evidence.Exporter) *EvidenceCommands {
+	p := &EvidenceCommands{}
+	p.Construct(p, "evidence", "Export and verify evidences of signed messages")
+	p.MakeExporter = exporterBuilder
+	p.AddHandlers(Export, Verify)
+	return p
+}
+
+type HandlerBase struct {
+	console_tool.HandlerBase[*EvidenceCommands]
+}
+
+func (b *HandlerBase) Context(data interface{}) (op_context.Context, *signature_evidence.Exporter, error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	exporter := b.Group.MakeExporter(ctx.App())
+	return ctx, exporter, nil
+}
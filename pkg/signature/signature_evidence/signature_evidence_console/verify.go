@@ -0,0 +1,58 @@
+package signature_evidence_console
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/signature_evidence"
+)
+
+const VerifyCmd string = "verify"
+const VerifyDescription string = "Verify evidence package offline"
+
+func Verify() console_tool.Handler[*EvidenceCommands] {
+	a := &VerifyHandler{}
+	a.Init(VerifyCmd, VerifyDescription)
+	return a
+}
+
+type VerifyData struct {
+	Evidence  string `long:"evidence" description:"Path to evidence file" required:"true"`
+	Signature string `long:"signature" description:"Path to file with detached signature of evidence" required:"true"`
+	PubKey    string `long:"pubkey" description:"Path to public key of evidence issuer in PEM format" required:"true"`
+}
+
+type VerifyHandler struct {
+	HandlerBase
+	VerifyData
+}
+
+func (a *VerifyHandler) Data() interface{} {
+	return &a.VerifyData
+}
+
+func (a *VerifyHandler) Execute(args []string) error {
+
+	evidence, err := os.ReadFile(a.Evidence)
+	if err != nil {
+		return fmt.Errorf("failed to read evidence: %s", err)
+	}
+	sig, err := os.ReadFile(a.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %s", err)
+	}
+	pubKey, err := os.ReadFile(a.PubKey)
+	if err != nil {
+		return fmt.Errorf("failed to read public key: %s", err)
+	}
+
+	e, err := signature_evidence.VerifyPackage(evidence, strings.TrimSpace(string(sig)), pubKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Evidence is valid: issuer %s, created at %s, %d message(s)\n", e.Issuer, e.CreatedAt, len(e.Messages))
+	return nil
+}
@@ -22,10 +22,26 @@ type UserWithPubkey interface {
 	PubKeyHash() string
 }
 
+type PubKey interface {
+	GetID() string
+	PubKey() string
+	PubKeyHash() string
+	IsPubKeyExpired() bool
+}
+
+// Store of public keys for users that can have multiple active keys.
+type PubKeyStore interface {
+	// Find active key of the user by key ID or key hash. If selector is empty then the most recent active key is returned.
+	// If key not found then nil is returned.
+	FindPubKey(ctx op_context.Context, userId string, selector string) (PubKey, error)
+	MarkPubKeyUsed(ctx op_context.Context, keyId string) error
+}
+
 type SignatureManager interface {
 	generic_error.ErrorDefinitions
 
 	Verify(ctx auth.UserContext, signature string, message []byte, extraData ...string) error
+	VerifyWithKey(ctx auth.UserContext, keySelector string, signature string, message []byte, extraData ...string) error
 	CheckPubKey(ctx op_context.Context, key string) error
 }
 
@@ -36,19 +52,25 @@ type WithSignatureManager interface {
 const (
 	ErrorCodeInvalidKey       string = "invalid_key"
 	ErrorCodeInvalidSignature string = "invalid_signature"
+	ErrorCodeUnknownKey       string = "unknown_key"
+	ErrorCodeKeyExpired       string = "key_expired"
 )
 
 var ErrorDescriptions = map[string]string{
 	ErrorCodeInvalidSignature: "Invalid signature.",
 	ErrorCodeInvalidKey:       "Invalid key.",
+	ErrorCodeUnknownKey:       "Unknown public key.",
+	ErrorCodeKeyExpired:       "Public key expired.",
 }
 
 var ErrorHttpCodes = map[string]int{
 	ErrorCodeInvalidSignature: http.StatusUnauthorized,
+	ErrorCodeUnknownKey:       http.StatusUnauthorized,
+	ErrorCodeKeyExpired:       http.StatusUnauthorized,
 }
 
 type SignatureManagerBaseConfig struct {
-	ALGORITHM             string `validate:"required,oneof:rsa_h256_signature" default:"rsa_h256_signature"`
+	ALGORITHM             string `validate:"required,oneof=rsa_h256_signature" default:"rsa_h256_signature"`
 	ENCRYPT_MESSAGE_STORE bool
 	SECRET                string `mask:"true"`
 	SALT                  string `mask:"true"`
@@ -56,13 +78,23 @@ type SignatureManagerBaseConfig struct {
 
 type SignatureManagerBase struct {
 	SignatureManagerBaseConfig
-	cipher *crypt_utils.AEAD
+	cipher      *crypt_utils.AEAD
+	pubKeyStore PubKeyStore
 }
 
 func NewSignatureManager() *SignatureManagerBase {
 	return &SignatureManagerBase{}
 }
 
+// Set store of public keys. If store is not set then public key is taken from auth user that must be of UserWithPubkey interface.
+func (s *SignatureManagerBase) SetPubKeyStore(store PubKeyStore) {
+	s.pubKeyStore = store
+}
+
+func (s *SignatureManagerBase) PubKeyStore() PubKeyStore {
+	return s.pubKeyStore
+}
+
 func (s *SignatureManagerBase) Config() interface{} {
 	return &s.SignatureManagerBaseConfig
 }
@@ -152,9 +184,50 @@ func (s *SignatureManagerBase) MakeVerifier(ctx op_context.Context, key string)
 }
 
 func (s *SignatureManagerBase) Verify(ctx auth.UserContext, signature string, message []byte, extraData ...string) error {
+	return s.VerifyWithKey(ctx, "", signature, message, extraData...)
+}
+
+func (s *SignatureManagerBase) findKey(ctx auth.UserContext, c op_context.CallContext, keySelector string) (PubKey, error) {
+
+	// use legacy key of auth user if key store is not set
+	if s.pubKeyStore == nil {
+		user, ok := ctx.AuthUser().(UserWithPubkey)
+		if !ok {
+			c.SetMessage("user must be of UserWithPubkey interface")
+			ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
+			return nil, errors.New("invalid user type")
+		}
+		if keySelector != "" && keySelector != user.PubKeyHash() {
+			ctx.SetGenericErrorCode(ErrorCodeUnknownKey)
+			return nil, errors.New("key hash mismatch")
+		}
+		return &userKey{user: user}, nil
+	}
+
+	// find key in store
+	key, err := s.pubKeyStore.FindPubKey(ctx, ctx.AuthUser().GetID(), keySelector)
+	if err != nil {
+		c.SetMessage("failed to find public key")
+		ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
+		return nil, err
+	}
+	if key == nil {
+		ctx.SetGenericErrorCode(ErrorCodeUnknownKey)
+		return nil, errors.New("public key not found")
+	}
+	if key.IsPubKeyExpired() {
+		ctx.SetGenericErrorCode(ErrorCodeKeyExpired)
+		return nil, errors.New("public key expired")
+	}
+
+	// done
+	return key, nil
+}
+
+func (s *SignatureManagerBase) VerifyWithKey(ctx auth.UserContext, keySelector string, signature string, message []byte, extraData ...string) error {
 
 	// setup
-	c := ctx.TraceInMethod("SignatureManagerBase.Verify", logger.Fields{"user": ctx.AuthUser().Display(), "extra_data": extraData})
+	c := ctx.TraceInMethod("SignatureManagerBase.VerifyWithKey", logger.Fields{"user": ctx.AuthUser().Display(), "key": keySelector, "extra_data": extraData})
 	var err error
 	onExit := func() {
 		if err != nil {
@@ -164,16 +237,14 @@ func (s *SignatureManagerBase) Verify(ctx auth.UserContext, signature string, me
 	}
 	defer onExit()
 
-	// extract auth user from context
-	user, ok := ctx.AuthUser().(UserWithPubkey)
-	if !ok {
-		c.SetMessage("user must be of UserWithPubkey interface")
-		ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
+	// find public key
+	key, err := s.findKey(ctx, c, keySelector)
+	if err != nil {
 		return err
 	}
 
 	// make verifier
-	verifier, err := s.MakeVerifier(ctx, user.PubKey())
+	verifier, err := s.MakeVerifier(ctx, key.PubKey())
 	if err != nil {
 		return err
 	}
@@ -197,7 +268,8 @@ func (s *SignatureManagerBase) Verify(ctx auth.UserContext, signature string, me
 	obj.Algorithm = s.ALGORITHM
 	obj.Signature = signature
 	obj.ExtraData = strings.Join(extraData, "+")
-	obj.PubKeyHash = user.PubKeyHash()
+	obj.PubKeyId = key.GetID()
+	obj.PubKeyHash = key.PubKeyHash()
 	if s.ENCRYPT_MESSAGE_STORE {
 		ciphertext, err := s.cipher.Encrypt([]byte(message))
 		if err != nil {
@@ -217,10 +289,38 @@ func (s *SignatureManagerBase) Verify(ctx auth.UserContext, signature string, me
 		return err
 	}
 
+	// update usage time of the key
+	if s.pubKeyStore != nil {
+		err1 := s.pubKeyStore.MarkPubKeyUsed(ctx, key.GetID())
+		if err1 != nil {
+			c.Logger().Error("failed to update usage time of public key", err1)
+		}
+	}
+
 	// done
 	return err
 }
 
+type userKey struct {
+	user UserWithPubkey
+}
+
+func (u *userKey) GetID() string {
+	return ""
+}
+
+func (u *userKey) PubKey() string {
+	return u.user.PubKey()
+}
+
+func (u *userKey) PubKeyHash() string {
+	return u.user.PubKeyHash()
+}
+
+func (u *userKey) IsPubKeyExpired() bool {
+	return false
+}
+
 func (s *SignatureManagerBase) AttachToErrorManager(errManager generic_error.ErrorManager) {
 	errManager.AddErrorDescriptions(ErrorDescriptions)
 	errManager.AddErrorProtocolCodes(ErrorHttpCodes)
@@ -228,7 +328,7 @@ func (s *SignatureManagerBase) AttachToErrorManager(errManager generic_error.Err
 
 func (s *SignatureManagerBase) Find(ctx op_context.Context, contextId string) (*MessageSignature, error) {
 
-	c := ctx.TraceInMethod("SmsManagerBase.FindSms", logger.Fields{"signature_context_id": contextId})
+	c := ctx.TraceInMethod("SignatureManagerBase.Find", logger.Fields{"signature_context_id": contextId})
 	var err error
 	onExit := func() {
 		if err != nil {
@@ -260,3 +360,33 @@ func (s *SignatureManagerBase) Find(ctx op_context.Context, contextId string) (*
 
 	return obj, nil
 }
+
+func (s *SignatureManagerBase) FindSignatures(ctx op_context.Context, filter *db.Filter) ([]*MessageSignature, error) {
+
+	c := ctx.TraceInMethod("SignatureManagerBase.FindSignatures")
+	defer ctx.TraceOutMethod()
+
+	var signatures []*MessageSignature
+	_, err := op_context.DB(ctx).FindWithFilter(ctx, filter, &signatures)
+	if err != nil {
+		c.SetMessage("failed to find signatures in database")
+		return nil, c.SetError(err)
+	}
+
+	return signatures, nil
+}
+
+// Get original message from stored signature, decrypt it if message store is encrypted.
+func (s *SignatureManagerBase) DecryptMessage(obj *MessageSignature) ([]byte, error) {
+
+	if !s.ENCRYPT_MESSAGE_STORE {
+		return []byte(obj.Message), nil
+	}
+
+	enc := utils.Base64StringCoding{}
+	ciphertext, err := enc.Decode(obj.Message)
+	if err != nil {
+		return nil, err
+	}
+	return s.cipher.Decrypt(ciphertext)
+}
@@ -1,6 +1,8 @@
 package user_pubkey
 
 import (
+	"time"
+
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
 	"github.com/evgeniums/go-backend-helpers/pkg/signature"
 )
@@ -18,6 +20,13 @@ type UserPubkeyI interface {
 	PubkeyI
 	PubKeyOwner() string
 	SetPubKeyOwner(hash string)
+	PubKeyLabel() string
+	SetPubKeyLabel(label string)
+	PubKeyExpiresAt() time.Time
+	SetPubKeyExpiresAt(expiresAt time.Time)
+	PubKeyLastUsedAt() time.Time
+	SetPubKeyLastUsedAt(lastUsedAt time.Time)
+	IsPubKeyExpired() bool
 }
 
 type PubkeyData struct {
@@ -30,10 +39,17 @@ type PubkeyEssentials struct {
 	PublicKeyOwner string `json:"public_key_owner" gorm:"index;index:,unique,composite:u" display:"Owner ID"`
 }
 
+type PubkeyMeta struct {
+	Label      string    `json:"label" gorm:"index" display:"Label"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"index" display:"Expires at"`
+	LastUsedAt time.Time `json:"last_used_at" display:"Last used at"`
+}
+
 type UserPubkey struct {
 	common.ObjectBase
 	common.WithActiveBase
 	PubkeyEssentials
+	PubkeyMeta
 }
 
 func (u *UserPubkey) PubKey() string {
@@ -60,6 +76,35 @@ func (u *UserPubkey) SetPubKeyOwner(owner string) {
 	u.PublicKeyOwner = owner
 }
 
+func (u *UserPubkey) PubKeyLabel() string {
+	return u.Label
+}
+
+func (u *UserPubkey) SetPubKeyLabel(label string) {
+	u.Label = label
+}
+
+func (u *UserPubkey) PubKeyExpiresAt() time.Time {
+	return u.ExpiresAt
+}
+
+func (u *UserPubkey) SetPubKeyExpiresAt(expiresAt time.Time) {
+	u.ExpiresAt = expiresAt
+}
+
+func (u *UserPubkey) PubKeyLastUsedAt() time.Time {
+	return u.LastUsedAt
+}
+
+func (u *UserPubkey) SetPubKeyLastUsedAt(lastUsedAt time.Time) {
+	u.LastUsedAt = lastUsedAt
+}
+
+// Key without expiration time never expires.
+func (u *UserPubkey) IsPubKeyExpired() bool {
+	return !u.ExpiresAt.IsZero() && time.Now().After(u.ExpiresAt)
+}
+
 func NewOplog() *signature.OpLogPubKey {
 	return &signature.OpLogPubKey{}
 }
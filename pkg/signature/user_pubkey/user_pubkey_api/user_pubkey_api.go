@@ -0,0 +1,23 @@
+package user_pubkey_api
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+)
+
+type PubkeyLabel struct {
+	Label string `json:"label" validate:"omitempty,max=128" vmessage:"Label must be shorter than 128 characters."`
+}
+
+var (
+	ListPubkeys  = func() api.Operation { return api.List("list_pubkeys") }
+	RenamePubkey = func() api.Operation { return api.UpdatePartial("rename_pubkey") }
+	RevokePubkey = func() api.Operation { return api.Delete("revoke_pubkey") }
+)
+
+// Add resources of public keys to user resource: pubkey collection for listing and named pubkey for rename/revoke.
+func PrepareResources(userResource api.Resource) (pubkeysResource api.Resource, pubkeyResource api.Resource) {
+	pubkeyResource = api.NamedResource("pubkey")
+	pubkeysResource = pubkeyResource.Parent()
+	userResource.AddChild(pubkeysResource)
+	return
+}
@@ -0,0 +1,69 @@
+package user_pubkey_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+)
+
+type ListPubkeys[T user_pubkey.UserPubkeyI] struct {
+	cmd    api.Query
+	result *api.ResponseList[T]
+}
+
+func (a *ListPubkeys[T]) Exec(client api_client.Client, ctx op_context.Context, operation api.Operation) error {
+
+	c := ctx.TraceInMethod("ListPubkeys.Exec")
+	defer ctx.TraceOutMethod()
+
+	err := client.Exec(ctx, operation, a.cmd, a.result)
+	c.SetError(err)
+	return err
+}
+
+func (p *Client[T, U]) ListUserPubKeys(ctx op_context.Context, userId string, filter *db.Filter, idIsLogin ...bool) ([]T, int64, error) {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("PubkeyClient.ListUserPubKeys")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// adjust user ID
+	uId, err := p.Users.GetUserId(ctx, userId, idIsLogin...)
+	if err != nil {
+		c.SetMessage("failed to get user ID")
+		return nil, 0, err
+	}
+
+	// set query
+	cmd := &api.DbQuery{}
+	if filter != nil {
+		cmd.SetQuery(filter.ToQueryString())
+	}
+
+	// prepare and exec handler
+	handler := &ListPubkeys[T]{
+		cmd:    cmd,
+		result: &api.ResponseList[T]{},
+	}
+	pubkeysResource, _ := p.pubkeyResources(uId)
+	op := user_pubkey_api.ListPubkeys()
+	pubkeysResource.AddOperation(op)
+	err = op.Exec(ctx, api_client.MakeOperationHandler(p.Users.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return nil, 0, err
+	}
+
+	// done
+	return handler.result.Items, handler.result.Count, nil
+}
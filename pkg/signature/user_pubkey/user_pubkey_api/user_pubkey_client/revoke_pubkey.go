@@ -0,0 +1,56 @@
+package user_pubkey_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+)
+
+type RevokePubkey struct{}
+
+func (a *RevokePubkey) Exec(client api_client.Client, ctx op_context.Context, operation api.Operation) error {
+
+	c := ctx.TraceInMethod("RevokePubkey.Exec")
+	defer ctx.TraceOutMethod()
+
+	err := client.Exec(ctx, operation, nil, nil)
+	c.SetError(err)
+	return err
+}
+
+func (p *Client[T, U]) DeactivatePubKey(ctx op_context.Context, userId string, keyId string, idIsLogin ...bool) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("PubkeyClient.DeactivatePubKey")
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// adjust user ID
+	uId, err := p.Users.GetUserId(ctx, userId, idIsLogin...)
+	if err != nil {
+		c.SetMessage("failed to get user ID")
+		return err
+	}
+
+	// prepare and exec handler
+	handler := &RevokePubkey{}
+	_, pubkeyResource := p.pubkeyResources(uId)
+	pubkeyResource.SetId(keyId)
+	op := user_pubkey_api.RevokePubkey()
+	pubkeyResource.AddOperation(op)
+	err = op.Exec(ctx, api_client.MakeOperationHandler(p.Users.Client(), handler))
+	if err != nil {
+		c.SetMessage("failed to exec operation")
+		return err
+	}
+
+	// done
+	return nil
+}
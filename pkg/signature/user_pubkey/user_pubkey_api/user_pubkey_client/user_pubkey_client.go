@@ -0,0 +1,24 @@
+package user_pubkey_client
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/user"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_api/user_client"
+)
+
+type Client[T user_pubkey.UserPubkeyI, U user.User] struct {
+	Users *user_client.UserClient[U]
+}
+
+func NewClient[T user_pubkey.UserPubkeyI, U user.User](users *user_client.UserClient[U]) *Client[T, U] {
+	c := &Client[T, U]{Users: users}
+	return c
+}
+
+func (p *Client[T, U]) pubkeyResources(userId string) (pubkeysResource api.Resource, pubkeyResource api.Resource) {
+	userResource := p.Users.UserResource.CloneChain(false)
+	userResource.SetId(userId)
+	return user_pubkey_api.PrepareResources(userResource)
+}
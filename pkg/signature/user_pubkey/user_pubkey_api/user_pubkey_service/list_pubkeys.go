@@ -0,0 +1,47 @@
+package user_pubkey_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+)
+
+type ListPubkeysEndpoint[T user_pubkey.UserPubkeyI] struct {
+	Endpoint[T]
+}
+
+func (e *ListPubkeysEndpoint[T]) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("pubkey.ListPubkeys")
+	defer request.TraceOutMethod()
+
+	// parse query
+	keys := Keys(e.service, request)
+	queryName := request.Endpoint().Resource().ServicePathPrototype()
+	filter, err := api_server.ParseDbQuery(request, keys.MakeKey(), queryName)
+	if err != nil {
+		return c.SetError(err)
+	}
+
+	// get keys
+	resp := &api.ResponseList[T]{}
+	resp.Items, resp.Count, err = keys.ListUserPubKeys(request, request.GetResourceId(e.service.UserTypeName), filter)
+	if err != nil {
+		c.SetMessage("failed to list public keys")
+		return c.SetError(err)
+	}
+
+	// set response
+	api_server.SetResponseList(request, resp)
+
+	// done
+	return nil
+}
+
+func ListPubkeys[T user_pubkey.UserPubkeyI](s *Service[T]) *ListPubkeysEndpoint[T] {
+	e := &ListPubkeysEndpoint[T]{}
+	e.Construct(s, user_pubkey_api.ListPubkeys())
+	return e
+}
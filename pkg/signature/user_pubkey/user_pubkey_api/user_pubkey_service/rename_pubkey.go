@@ -0,0 +1,44 @@
+package user_pubkey_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+)
+
+type RenamePubkeyEndpoint[T user_pubkey.UserPubkeyI] struct {
+	Endpoint[T]
+}
+
+func (e *RenamePubkeyEndpoint[T]) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("pubkey.RenamePubkey")
+	defer request.TraceOutMethod()
+
+	// parse command
+	cmd := &user_pubkey_api.PubkeyLabel{}
+	err := request.ParseValidate(cmd)
+	if err != nil {
+		c.SetMessage("failed to parse/validate command")
+		return c.SetError(err)
+	}
+
+	// rename key
+	userId := request.GetResourceId(e.service.UserTypeName)
+	keyId := request.GetResourceId("pubkey")
+	err = Keys(e.service, request).RenamePubKey(request, userId, keyId, cmd.Label)
+	if err != nil {
+		c.SetMessage("failed to rename public key")
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func RenamePubkey[T user_pubkey.UserPubkeyI](s *Service[T]) *RenamePubkeyEndpoint[T] {
+	e := &RenamePubkeyEndpoint[T]{}
+	e.Construct(s, user_pubkey_api.RenamePubkey())
+	return e
+}
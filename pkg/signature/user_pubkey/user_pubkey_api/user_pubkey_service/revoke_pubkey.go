@@ -0,0 +1,36 @@
+package user_pubkey_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+)
+
+type RevokePubkeyEndpoint[T user_pubkey.UserPubkeyI] struct {
+	Endpoint[T]
+}
+
+func (e *RevokePubkeyEndpoint[T]) HandleRequest(request api_server.Request) error {
+
+	// setup
+	c := request.TraceInMethod("pubkey.RevokePubkey")
+	defer request.TraceOutMethod()
+
+	// revoke key
+	userId := request.GetResourceId(e.service.UserTypeName)
+	keyId := request.GetResourceId("pubkey")
+	err := Keys(e.service, request).DeactivatePubKey(request, userId, keyId)
+	if err != nil {
+		c.SetMessage("failed to revoke public key")
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
+func RevokePubkey[T user_pubkey.UserPubkeyI](s *Service[T]) *RevokePubkeyEndpoint[T] {
+	e := &RevokePubkeyEndpoint[T]{}
+	e.Construct(s, user_pubkey_api.RevokePubkey())
+	return e
+}
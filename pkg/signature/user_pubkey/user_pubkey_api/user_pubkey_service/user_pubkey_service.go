@@ -0,0 +1,61 @@
+package user_pubkey_service
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey/user_pubkey_api"
+	"github.com/evgeniums/go-backend-helpers/pkg/user"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_api/user_service"
+)
+
+type Endpoint[T user_pubkey.UserPubkeyI] struct {
+	service *Service[T]
+	api_server.EndpointBase
+}
+
+func (e *Endpoint[T]) Construct(service *Service[T], op api.Operation) {
+	e.service = service
+	e.EndpointBase.Construct(op)
+}
+
+type Service[T user_pubkey.UserPubkeyI] struct {
+	Keys         user_pubkey.PubkeyController[T]
+	UserTypeName string
+
+	PubkeysResource api.Resource
+	PubkeyResource  api.Resource
+}
+
+// Add endpoints for managing public keys to user service.
+func AddToUserService[T user_pubkey.UserPubkeyI, U user.User](userService *user_service.UserService[U], keys user_pubkey.PubkeyController[T]) *Service[T] {
+
+	s := &Service[T]{}
+	s.Keys = keys
+	s.UserTypeName = userService.UserTypeName
+
+	userService.AddErrors(user_pubkey.ErrorDescriptions, user_pubkey.ErrorHttpCodes)
+
+	s.PubkeysResource, s.PubkeyResource = user_pubkey_api.PrepareResources(userService.UserResource())
+	s.PubkeysResource.AddOperation(ListPubkeys(s))
+	s.PubkeyResource.AddOperations(RenamePubkey(s), RevokePubkey(s))
+
+	return s
+}
+
+type TenancyWithPubkeys[T user_pubkey.UserPubkeyI] interface {
+	PubkeyController() user_pubkey.PubkeyController[T]
+}
+
+func Keys[T user_pubkey.UserPubkeyI](service *Service[T], request api_server.Request) user_pubkey.PubkeyController[T] {
+
+	t := request.GetTenancy()
+	if t != nil {
+		ts, ok := t.(TenancyWithPubkeys[T])
+		if ok {
+			return ts.PubkeyController()
+		}
+	}
+
+	return service.Keys
+}
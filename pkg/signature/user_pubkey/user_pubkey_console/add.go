@@ -0,0 +1,66 @@
+package user_pubkey_console
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_console"
+)
+
+const AddCmd string = "add"
+const AddDescription string = "Add public key"
+
+func Add[T user_pubkey.UserPubkeyI]() console_tool.Handler[*PubkeyCommands[T]] {
+	a := &AddHandler[T]{}
+	a.Init(AddCmd, AddDescription)
+	return a
+}
+
+type AddData struct {
+	user_console.LoginData
+	File    string `long:"file" description:"Path to file with public key in PEM format" required:"true"`
+	Label   string `long:"label" description:"Label of the key, e.g. name of the device"`
+	Expires string `long:"expires" description:"Expiration time of the key in RFC3339 format"`
+}
+
+type AddHandler[T user_pubkey.UserPubkeyI] struct {
+	HandlerBase[T]
+	AddData
+}
+
+func (a *AddHandler[T]) Data() interface{} {
+	return &a.AddData
+}
+
+func (a *AddHandler[T]) Execute(args []string) error {
+
+	ctx, ctrl, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	key, err := os.ReadFile(a.File)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %s", err)
+	}
+
+	var expiresAt time.Time
+	if a.Expires != "" {
+		expiresAt, err = time.Parse(time.RFC3339, a.Expires)
+		if err != nil {
+			return fmt.Errorf("invalid expiration time: %s", err)
+		}
+	}
+
+	keyId, err := ctrl.AddLabeledPubKey(ctx, a.Login, string(key), a.Label, expiresAt, true)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added key with ID: %s\n", keyId)
+	return nil
+}
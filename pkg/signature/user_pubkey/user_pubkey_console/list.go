@@ -0,0 +1,59 @@
+package user_pubkey_console
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_console"
+)
+
+const ListCmd string = "list"
+const ListDescription string = "List public keys of user"
+
+func List[T user_pubkey.UserPubkeyI]() console_tool.Handler[*PubkeyCommands[T]] {
+	a := &ListHandler[T]{}
+	a.Init(ListCmd, ListDescription)
+	return a
+}
+
+type ListData struct {
+	user_console.LoginData
+	console_tool.QueryData
+}
+
+type ListHandler[T user_pubkey.UserPubkeyI] struct {
+	HandlerBase[T]
+	ListData
+}
+
+func (a *ListHandler[T]) Data() interface{} {
+	return &a.ListData
+}
+
+func (a *ListHandler[T]) Execute(args []string) error {
+
+	ctx, ctrl, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	filter, err := db.ParseQuery(ctx.Db(), a.Query, ctrl.MakeKey(), "")
+	if err != nil {
+		return fmt.Errorf("failed to parse query: %s", err)
+	}
+
+	keys, count, err := ctrl.ListUserPubKeys(ctx, a.Login, filter, true)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(keys, "", "   ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize result: %s", err)
+	}
+	fmt.Printf("********************\n\n%s\n\nCount %d\n\n********************\n\n", string(b), count)
+	return nil
+}
@@ -0,0 +1,40 @@
+package user_pubkey_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+)
+
+const RenameCmd string = "rename"
+const RenameDescription string = "Set label of public key"
+
+func Rename[T user_pubkey.UserPubkeyI]() console_tool.Handler[*PubkeyCommands[T]] {
+	a := &RenameHandler[T]{}
+	a.Init(RenameCmd, RenameDescription)
+	return a
+}
+
+type RenameData struct {
+	KeyIdData
+	Label string `long:"label" description:"New label of the key" required:"true"`
+}
+
+type RenameHandler[T user_pubkey.UserPubkeyI] struct {
+	HandlerBase[T]
+	RenameData
+}
+
+func (a *RenameHandler[T]) Data() interface{} {
+	return &a.RenameData
+}
+
+func (a *RenameHandler[T]) Execute(args []string) error {
+
+	ctx, ctrl, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	return ctrl.RenamePubKey(ctx, a.Login, a.Id, a.Label, true)
+}
@@ -0,0 +1,35 @@
+package user_pubkey_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+)
+
+const RevokeCmd string = "revoke"
+const RevokeDescription string = "Revoke public key"
+
+func Revoke[T user_pubkey.UserPubkeyI]() console_tool.Handler[*PubkeyCommands[T]] {
+	a := &RevokeHandler[T]{}
+	a.Init(RevokeCmd, RevokeDescription)
+	return a
+}
+
+type RevokeHandler[T user_pubkey.UserPubkeyI] struct {
+	HandlerBase[T]
+	KeyIdData
+}
+
+func (a *RevokeHandler[T]) Data() interface{} {
+	return &a.KeyIdData
+}
+
+func (a *RevokeHandler[T]) Execute(args []string) error {
+
+	ctx, ctrl, err := a.Context(a.Data())
+	if err != nil {
+		return err
+	}
+	defer ctx.Close()
+
+	return ctrl.DeactivatePubKey(ctx, a.Login, a.Id, true)
+}
@@ -0,0 +1,51 @@
+package user_pubkey_console
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/console_tool"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_console"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+)
+
+type PubkeyCommands[T user_pubkey.UserPubkeyI] struct {
+	console_tool.Commands[*PubkeyCommands[T]]
+	MakeController func(app app_context.Context) user_pubkey.PubkeyController[T]
+}
+
+func NewPubkeyCommands[T user_pubkey.UserPubkeyI](groupName string, groupDescription string, controllerBuilder func(app app_context.Context) user_pubkey.PubkeyController[T], loadDefaultHandlers ...bool) *PubkeyCommands[T] {
+	p := &PubkeyCommands[T]{}
+	p.Construct(p, groupName, groupDescription)
+	p.MakeController = controllerBuilder
+	if utils.OptionalArg(true, loadDefaultHandlers...) {
+		p.LoadDefaultHandlers()
+	}
+	return p
+}
+
+func (p *PubkeyCommands[T]) LoadDefaultHandlers() {
+	p.AddHandlers(Add[T],
+		List[T],
+		Rename[T],
+		Revoke[T],
+	)
+}
+
+type HandlerBase[T user_pubkey.UserPubkeyI] struct {
+	console_tool.HandlerBase[*PubkeyCommands[T]]
+}
+
+func (b *HandlerBase[T]) Context(data interface{}) (op_context.Context, user_pubkey.PubkeyController[T], error) {
+	ctx, err := b.HandlerBase.Context(data)
+	if err != nil {
+		return ctx, nil, err
+	}
+	ctrl := b.Group.MakeController(ctx.App())
+	return ctx, ctrl, nil
+}
+
+type KeyIdData struct {
+	user_console.LoginData
+	Id string `long:"id" description:"Key ID" required:"true"`
+}
@@ -3,6 +3,7 @@ package user_pubkey
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/crud"
 	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
@@ -17,21 +18,31 @@ import (
 const (
 	ErrorCodeDuplicateKey      string = "duplicate_pubkey"
 	ErrorCodeActiveKeyNotFound string = "active_pubkey_not_found"
+	ErrorCodeKeyNotFound       string = "pubkey_not_found"
+	ErrorCodeKeyExpired        string = "pubkey_expired"
 )
 
 var ErrorDescriptions = map[string]string{
 	ErrorCodeDuplicateKey:      "Duplicate key already loaded.",
 	ErrorCodeActiveKeyNotFound: "Active public key not found.",
+	ErrorCodeKeyNotFound:       "Public key not found.",
+	ErrorCodeKeyExpired:        "Public key expired.",
 }
 
 var ErrorHttpCodes = map[string]int{
 	ErrorCodeActiveKeyNotFound: http.StatusNotFound,
+	ErrorCodeKeyNotFound:       http.StatusNotFound,
 }
 
 type PubkeyController[T UserPubkeyI] interface {
+	MakeKey() T
 	AddPubKey(ctx op_context.Context, userId string, key string, idIsLogin ...bool) (string, error)
+	AddLabeledPubKey(ctx op_context.Context, userId string, key string, label string, expiresAt time.Time, idIsLogin ...bool) (string, error)
 	DeactivatePubKey(ctx op_context.Context, userId string, keyId string, idIsLogin ...bool) error
+	RenamePubKey(ctx op_context.Context, userId string, keyId string, label string, idIsLogin ...bool) error
 	FindActivePubKey(ctx op_context.Context, userId string, idIsLogin ...bool) (T, error)
+	FindUserPubKey(ctx op_context.Context, userId string, selector string, idIsLogin ...bool) (T, error)
+	ListUserPubKeys(ctx op_context.Context, userId string, filter *db.Filter, idIsLogin ...bool) ([]T, int64, error)
 }
 
 type PubkeyControllerBase[T UserPubkeyI, U user.User] struct {
@@ -49,6 +60,10 @@ func (p *PubkeyControllerBase[T, U]) CRUD() crud.CRUD {
 	return p.crud
 }
 
+func (p *PubkeyControllerBase[T, U]) MakeKey() T {
+	return p.objectBuilder()
+}
+
 func (p *PubkeyControllerBase[T, U]) OpLog(ctx op_context.Context, op string, userId string, login string, keyId string, keyHash string) {
 	oplog := NewOplog()
 	oplog.SetOperation(op)
@@ -59,10 +74,17 @@ func (p *PubkeyControllerBase[T, U]) OpLog(ctx op_context.Context, op string, us
 	ctx.Oplog(oplog)
 }
 
+// Add public key without label and expiration.
+// Previously added keys of the user are kept active, use DeactivatePubKey to revoke them.
 func (p *PubkeyControllerBase[T, U]) AddPubKey(ctx op_context.Context, userId string, key string, idIsLogin ...bool) (string, error) {
+	return p.AddLabeledPubKey(ctx, userId, key, "", time.Time{}, idIsLogin...)
+}
+
+// Add public key with label and expiration time, zero expiration time means that key never expires.
+func (p *PubkeyControllerBase[T, U]) AddLabeledPubKey(ctx op_context.Context, userId string, key string, label string, expiresAt time.Time, idIsLogin ...bool) (string, error) {
 
 	// setup
-	c := ctx.TraceInMethod("PubkeyController.AddPubKey")
+	c := ctx.TraceInMethod("PubkeyController.AddLabeledPubKey", logger.Fields{"label": label})
 	var err error
 	onExit := func() {
 		if err != nil {
@@ -112,18 +134,14 @@ func (p *PubkeyControllerBase[T, U]) AddPubKey(ctx op_context.Context, userId st
 			return err
 		}
 
-		// deactivate old key
-		err = p.deactivateKey(ctx, c, user)
-		if err != nil {
-			return err
-		}
-
 		// create new key document
 		doc.InitObject()
 		doc.SetActive(true)
 		doc.SetPubKey(key)
 		doc.SetPubKeyHash(hash)
 		doc.SetPubKeyOwner(user.GetID())
+		doc.SetPubKeyLabel(label)
+		doc.SetPubKeyExpiresAt(expiresAt)
 		err = p.crud.Create(ctx, doc)
 		if err != nil {
 			c.SetMessage("failed to create pubkey in database")
@@ -144,20 +162,30 @@ func (p *PubkeyControllerBase[T, U]) AddPubKey(ctx op_context.Context, userId st
 
 func (p *PubkeyControllerBase[T, U]) deactivateKey(ctx op_context.Context, c op_context.CallContext, user U, keyId ...string) error {
 
-	doc := p.objectBuilder()
-	fields := db.Fields{"public_key_owner": user.GetID(), "active": true}
-	if len(keyId) != 0 && keyId[0] != "" {
-		fields["id"] = keyId
+	// find keys to deactivate
+	var docs []T
+	filter := db.NewFilter()
+	filter.AddField("public_key_owner", user.GetID())
+	filter.AddField("active", true)
+	byId := len(keyId) != 0 && keyId[0] != ""
+	if byId {
+		filter.AddField("id", keyId[0])
 	}
-	found, err := p.crud.Read(ctx, fields, doc)
+	_, err := p.crud.List(ctx, filter, &docs)
 	if err != nil {
-		c.SetMessage("failed to find previous key")
+		c.SetMessage("failed to find active keys")
 		return c.SetError(err)
 	}
-	if found {
+	if byId && len(docs) == 0 {
+		ctx.SetGenericErrorCode(ErrorCodeActiveKeyNotFound)
+		return c.SetError(errors.New("active key not found"))
+	}
+
+	// deactivate keys
+	for _, doc := range docs {
 		err = p.crud.Update(ctx, doc, db.Fields{"active": false})
 		if err != nil {
-			c.SetMessage("failed to deactivate previous key")
+			c.SetMessage("failed to deactivate key")
 			return c.SetError(err)
 		}
 		p.OpLog(ctx, "deactivate_pubkey", user.GetID(), user.Login(), doc.GetID(), doc.PubKeyHash())
@@ -166,6 +194,7 @@ func (p *PubkeyControllerBase[T, U]) deactivateKey(ctx op_context.Context, c op_
 	return nil
 }
 
+// Deactivate (revoke) public key of user. If keyId is empty then all active keys of the user are deactivated.
 func (p *PubkeyControllerBase[T, U]) DeactivatePubKey(ctx op_context.Context, userId string, keyId string, idIsLogin ...bool) error {
 
 	// setup
@@ -186,7 +215,7 @@ func (p *PubkeyControllerBase[T, U]) DeactivatePubKey(ctx op_context.Context, us
 	}
 	c.SetLoggerField("user", user.Display())
 
-	// deactivate old key document
+	// deactivate key documents
 	err = p.deactivateKey(ctx, c, user, keyId)
 	if err != nil {
 		return err
@@ -196,10 +225,61 @@ func (p *PubkeyControllerBase[T, U]) DeactivatePubKey(ctx op_context.Context, us
 	return nil
 }
 
+func (p *PubkeyControllerBase[T, U]) RenamePubKey(ctx op_context.Context, userId string, keyId string, label string, idIsLogin ...bool) error {
+
+	// setup
+	c := ctx.TraceInMethod("PubkeyController.RenamePubKey", logger.Fields{"key_id": keyId, "label": label})
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find user
+	user, err := user.FindUser(p.userFinder, ctx, userId, idIsLogin...)
+	if err != nil {
+		return err
+	}
+	c.SetLoggerField("user", user.Display())
+
+	// find key
+	doc := p.objectBuilder()
+	found, err := p.crud.Read(ctx, db.Fields{"public_key_owner": user.GetID(), "id": keyId}, doc)
+	if err != nil {
+		c.SetMessage("failed to find public key")
+		return err
+	}
+	if !found {
+		ctx.SetGenericErrorCode(ErrorCodeKeyNotFound)
+		err = errors.New("key not found")
+		return err
+	}
+
+	// update label
+	err = p.crud.Update(ctx, doc, db.Fields{"label": label})
+	if err != nil {
+		c.SetMessage("failed to update label of public key")
+		return err
+	}
+
+	// done
+	p.OpLog(ctx, "rename_pubkey", user.GetID(), user.Login(), doc.GetID(), doc.PubKeyHash())
+	return nil
+}
+
+// Find the most recent active key of user.
 func (p *PubkeyControllerBase[T, U]) FindActivePubKey(ctx op_context.Context, userId string, idIsLogin ...bool) (T, error) {
+	return p.FindUserPubKey(ctx, userId, "", idIsLogin...)
+}
+
+// Find active key of user by key ID or key hash. If selector is empty then the most recent active key is returned.
+func (p *PubkeyControllerBase[T, U]) FindUserPubKey(ctx op_context.Context, userId string, selector string, idIsLogin ...bool) (T, error) {
 
 	// setup
-	c := ctx.TraceInMethod("PubkeyController.FindActivePubKey")
+	c := ctx.TraceInMethod("PubkeyController.FindUserPubKey", logger.Fields{"key": selector})
 	var err error
 	onExit := func() {
 		if err != nil {
@@ -217,9 +297,7 @@ func (p *PubkeyControllerBase[T, U]) FindActivePubKey(ctx op_context.Context, us
 	c.SetLoggerField("user", user.Display())
 
 	// find key
-	doc := p.objectBuilder()
-	fields := db.Fields{"public_key_owner": user.GetID(), "active": true}
-	found, err := p.crud.Read(ctx, fields, doc)
+	doc, found, err := p.findKey(ctx, user.GetID(), selector)
 	if err != nil {
 		c.SetMessage("failed to find active public key")
 		return *new(T), err
@@ -229,11 +307,120 @@ func (p *PubkeyControllerBase[T, U]) FindActivePubKey(ctx op_context.Context, us
 		err = errors.New("key not found")
 		return *new(T), err
 	}
+	if doc.IsPubKeyExpired() {
+		ctx.SetGenericErrorCode(ErrorCodeKeyExpired)
+		err = errors.New("key expired")
+		return *new(T), err
+	}
 
 	// done
 	return doc, nil
 }
 
+func (p *PubkeyControllerBase[T, U]) findKey(ctx op_context.Context, ownerId string, selector string) (T, bool, error) {
+
+	// selector can be either key ID or key hash
+	if selector != "" {
+		doc, found, err := p.findKeyByField(ctx, ownerId, "id", selector)
+		if err != nil || found {
+			return doc, found, err
+		}
+		return p.findKeyByField(ctx, ownerId, "public_key_hash", selector)
+	}
+
+	return p.findKeyByField(ctx, ownerId, "", "")
+}
+
+func (p *PubkeyControllerBase[T, U]) findKeyByField(ctx op_context.Context, ownerId string, field string, value string) (T, bool, error) {
+
+	filter := db.NewFilter()
+	filter.AddField("public_key_owner", ownerId)
+	filter.AddField("active", true)
+	if field != "" {
+		filter.AddField(field, value)
+	}
+	filter.SetSorting("created_at", db.SORT_DESC)
+
+	var docs []T
+	_, err := p.crud.List(ctx, filter, &docs)
+	if err != nil {
+		return *new(T), false, err
+	}
+	if len(docs) == 0 {
+		return *new(T), false, nil
+	}
+
+	// prefer not expired keys
+	for _, doc := range docs {
+		if !doc.IsPubKeyExpired() {
+			return doc, true, nil
+		}
+	}
+	return docs[0], true, nil
+}
+
+// Implementation of signature.PubKeyStore.
+func (p *PubkeyControllerBase[T, U]) FindPubKey(ctx op_context.Context, userId string, selector string) (signature.PubKey, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PubkeyController.FindPubKey", logger.Fields{"key": selector})
+	defer ctx.TraceOutMethod()
+
+	// find key
+	doc, found, err := p.findKey(ctx, userId, selector)
+	if err != nil {
+		c.SetMessage("failed to find public key")
+		return nil, c.SetError(err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	// done
+	return doc, nil
+}
+
+// Find key of user by hash including deactivated and expired keys.
+// If key not found then nil is returned.
+func (p *PubkeyControllerBase[T, U]) FindPubKeyByHash(ctx op_context.Context, ownerId string, hash string) (signature.PubKey, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PubkeyController.FindPubKeyByHash", logger.Fields{"key_hash": hash})
+	defer ctx.TraceOutMethod()
+
+	// find key
+	doc := p.objectBuilder()
+	found, err := p.crud.Read(ctx, db.Fields{"public_key_owner": ownerId, "public_key_hash": hash}, doc)
+	if err != nil {
+		c.SetMessage("failed to find public key")
+		return nil, c.SetError(err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	// done
+	return doc, nil
+}
+
+// Implementation of signature.PubKeyStore.
+func (p *PubkeyControllerBase[T, U]) MarkPubKeyUsed(ctx op_context.Context, keyId string) error {
+
+	// setup
+	c := ctx.TraceInMethod("PubkeyController.MarkPubKeyUsed", logger.Fields{"key_id": keyId})
+	defer ctx.TraceOutMethod()
+
+	// update usage time
+	err := p.crud.UpdateMulti(ctx, p.objectBuilder(), db.Fields{"id": keyId}, db.Fields{"last_used_at": time.Now()})
+	if err != nil {
+		c.SetMessage("failed to update usage time of public key")
+		return c.SetError(err)
+	}
+
+	// done
+	return nil
+}
+
 func (p *PubkeyControllerBase[T, U]) ListPubKeys(ctx op_context.Context, filter *db.Filter) ([]T, int64, error) {
 
 	// setup
@@ -259,6 +446,42 @@ func (p *PubkeyControllerBase[T, U]) ListPubKeys(ctx op_context.Context, filter
 	return docs, count, nil
 }
 
+// List all keys of user including deactivated and expired keys.
+func (p *PubkeyControllerBase[T, U]) ListUserPubKeys(ctx op_context.Context, userId string, filter *db.Filter, idIsLogin ...bool) ([]T, int64, error) {
+
+	// setup
+	c := ctx.TraceInMethod("PubkeyController.ListUserPubKeys")
+	var err error
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// find user
+	user, err := user.FindUser(p.userFinder, ctx, userId, idIsLogin...)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.SetLoggerField("user", user.Display())
+
+	// read docs
+	if filter == nil {
+		filter = db.NewFilter()
+	}
+	filter.PushPresetFields(db.Fields{"public_key_owner": user.GetID()})
+	defer filter.PopPresetFields()
+	docs, count, err := p.ListPubKeys(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// done
+	return docs, count, nil
+}
+
 func (p *PubkeyControllerBase[T, U]) AttachToErrorManager(errManager generic_error.ErrorManager) {
 	errManager.AddErrorDescriptions(ErrorDescriptions)
 	errManager.AddErrorProtocolCodes(ErrorHttpCodes)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/evgeniums/go-backend-helpers/pkg/auth"
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
@@ -14,6 +15,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
 	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
 	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
 	"github.com/evgeniums/go-backend-helpers/pkg/utils"
 	"github.com/evgeniums/go-backend-helpers/pkg/validator"
@@ -26,6 +28,9 @@ type SmsManager interface {
 	FindSms(ctx op_context.Context, smsId string) (*SmsMessage, error)
 }
 
+// Name of SMS component in log entries.
+const LogComponent = "sms"
+
 const (
 	ErrorCodeSmsSendingFailed string = "sms_sending_failed"
 )
@@ -88,9 +93,9 @@ func (s *SmsDestination) Init(cfg config.Config, log logger.Logger, vld validato
 
 type SmsManagerBase struct {
 	SmsManagerBaseConfig
-	destinations    []*SmsDestination
-	cipher          *crypt_utils.AEAD
-	defaultProvider Provider
+	routing  atomic.Pointer[smsRouting]
+	cipher   *crypt_utils.AEAD
+	metering multitenancy.Metering
 }
 
 func NewSmsManager() *SmsManagerBase {
@@ -105,6 +110,7 @@ func (s *SmsManagerBase) Init(cfg config.Config, log logger.Logger, vld validato
 
 	// load configuration
 	path := utils.OptionalArg("sms", configPath...)
+	log = logger.NewComponentLogger(log, LogComponent)
 	err := object_config.LoadLogValidate(cfg, log, vld, s, path)
 	if err != nil {
 		return log.PushFatalStack("failed to init SMS manager", err)
@@ -124,6 +130,38 @@ func (s *SmsManagerBase) Init(cfg config.Config, log logger.Logger, vld validato
 		}
 	}
 
+	// load providers and destinations
+	routing, err := loadRouting(cfg, log, vld, factory, path, s.DEFAULT_PROVIDER)
+	if err != nil {
+		return err
+	}
+	s.routing.Store(routing)
+
+	// reload providers and destinations when configuration is reloaded, other parameters can not be changed at runtime
+	config.SubscribeReload(cfg, path, func(newCfg config.Config) (func(), error) {
+		reloaded := &SmsManagerBase{}
+		err := object_config.LoadLogValidate(newCfg, log, vld, reloaded, path)
+		if err != nil {
+			return nil, err
+		}
+		routing, err := loadRouting(newCfg, log, vld, factory, path, reloaded.DEFAULT_PROVIDER)
+		if err != nil {
+			return nil, err
+		}
+		return func() { s.routing.Store(routing) }, nil
+	})
+
+	// done
+	return nil
+}
+
+type smsRouting struct {
+	destinations    []*SmsDestination
+	defaultProvider Provider
+}
+
+func loadRouting(cfg config.Config, log logger.Logger, vld validator.Validator, factory ProviderFactory, path string, defaultProvider string) (*smsRouting, error) {
+
 	// load providers
 	createProvider := func(protocol string) (Provider, error) {
 		return factory.Create(protocol)
@@ -131,7 +169,7 @@ func (s *SmsManagerBase) Init(cfg config.Config, log logger.Logger, vld validato
 	providersPath := object_config.Key(path, "providers")
 	providers, err := object_config.LoadLogValidateSubobjectsMap(cfg, log, vld, providersPath, createProvider)
 	if err != nil {
-		return log.PushFatalStack("failed to load SMS providers", err)
+		return nil, log.PushFatalStack("failed to load SMS providers", err)
 	}
 
 	// load destinations
@@ -141,38 +179,45 @@ func (s *SmsManagerBase) Init(cfg config.Config, log logger.Logger, vld validato
 	destinationsPath := object_config.Key(path, "destinations")
 	destinations, err := object_config.LoadLogValidateSubobjectsList(cfg, log, vld, destinationsPath, createDestination)
 	if err != nil {
-		return log.PushFatalStack("failed to load SMS destinations", err)
+		return nil, log.PushFatalStack("failed to load SMS destinations", err)
 	}
 
 	// set default provider
+	r := &smsRouting{}
 	ok := false
-	s.defaultProvider, ok = providers[s.DEFAULT_PROVIDER]
+	r.defaultProvider, ok = providers[defaultProvider]
 	if !ok {
-		return log.PushFatalStack("unknown default provider", nil, logger.Fields{"default_provider": s.DEFAULT_PROVIDER})
+		return nil, log.PushFatalStack("unknown default provider", nil, logger.Fields{"default_provider": defaultProvider})
 	}
 
 	// set destinations
-	s.destinations = make([]*SmsDestination, 0)
+	r.destinations = make([]*SmsDestination, 0)
 	for _, destination := range destinations {
 		destination.provider, ok = providers[destination.PROVIDER]
 		if !ok {
-			return log.PushFatalStack("unknown provider for destination", nil, logger.Fields{"provider": destination.PROVIDER, "destination": destination.PREFIX})
+			return nil, log.PushFatalStack("unknown provider for destination", nil, logger.Fields{"provider": destination.PROVIDER, "destination": destination.PREFIX})
 		}
-		s.destinations = append(s.destinations, destination)
+		r.destinations = append(r.destinations, destination)
 	}
 
 	// sort destinations
-	sort.SliceStable(s.destinations, func(i int, j int) bool {
-		return len(s.destinations[i].PREFIX) > len(s.destinations[j].PREFIX)
+	sort.SliceStable(r.destinations, func(i int, j int) bool {
+		return len(r.destinations[i].PREFIX) > len(r.destinations[j].PREFIX)
 	})
 
 	// done
-	return nil
+	return r, nil
+}
+
+// Set metering of tenancies, SMS are counted in tenancy usage and checked against SMS quota of tenancy.
+func (s *SmsManagerBase) SetMetering(metering multitenancy.Metering) {
+	s.metering = metering
 }
 
 func (s *SmsManagerBase) Send(ctx auth.UserContext, message string, recipient string) (string, error) {
 
 	// setup
+	restoreComponent := op_context.SetLogComponent(ctx, LogComponent)
 	c := ctx.TraceInMethod("SmsManagerBase.Send", logger.Fields{"recipient": recipient})
 	var err error
 	onExit := func() {
@@ -182,12 +227,14 @@ func (s *SmsManagerBase) Send(ctx auth.UserContext, message string, recipient st
 			c.Logger().Info("sms sent")
 		}
 		ctx.TraceOutMethod()
+		restoreComponent()
 	}
 	defer onExit()
 
 	// find provider for destination
-	provider := s.defaultProvider
-	for _, destination := range s.destinations {
+	routing := s.routing.Load()
+	provider := routing.defaultProvider
+	for _, destination := range routing.destinations {
 		if strings.HasPrefix(recipient, destination.PREFIX) {
 			provider = destination.provider
 			break
@@ -196,6 +243,23 @@ func (s *SmsManagerBase) Send(ctx auth.UserContext, message string, recipient st
 	c.SetLoggerField("provider", provider.Name())
 	c.SetLoggerField("user", ctx.AuthUser().Display())
 
+	// check SMS quota of tenancy
+	tenancy := ctx.GetTenancy()
+	if s.metering != nil && tenancy != nil {
+		var exceeded bool
+		exceeded, err = s.metering.QuotaExceeded(ctx, tenancy, multitenancy.MetricSms)
+		if err != nil {
+			c.SetMessage("failed to check quota of tenancy")
+			ctx.SetGenericErrorCode(generic_error.ErrorCodeInternalServerError)
+			return "", err
+		}
+		if exceeded {
+			ctx.SetGenericErrorCode(multitenancy.ErrorCodeTenancyQuotaExceeded)
+			err = errors.New("quota of tenancy SMS exceeded")
+			return "", err
+		}
+	}
+
 	// keep sms
 	sms := &SmsMessage{}
 	sms.InitObject()
@@ -236,6 +300,9 @@ func (s *SmsManagerBase) Send(ctx auth.UserContext, message string, recipient st
 		sms.Status = StatusFail
 	} else {
 		sms.Status = StatusSuccess
+		if s.metering != nil && tenancy != nil {
+			s.metering.Count(tenancy.GetID(), multitenancy.MetricSms, 1)
+		}
 	}
 
 	// update status in database
@@ -257,6 +324,7 @@ func (s *SmsManagerBase) AttachToErrorManager(errManager generic_error.ErrorMana
 
 func (s *SmsManagerBase) FindSms(ctx op_context.Context, smsId string) (*SmsMessage, error) {
 
+	restoreComponent := op_context.SetLogComponent(ctx, LogComponent)
 	c := ctx.TraceInMethod("SmsManagerBase.FindSms", logger.Fields{"sms_id": smsId})
 	var err error
 	onExit := func() {
@@ -264,6 +332,7 @@ func (s *SmsManagerBase) FindSms(ctx op_context.Context, smsId string) (*SmsMess
 			c.SetError(err)
 		}
 		ctx.TraceOutMethod()
+		restoreComponent()
 	}
 	defer onExit()
 
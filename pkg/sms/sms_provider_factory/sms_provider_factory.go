@@ -23,6 +23,10 @@ func (f *DefaultFactory) Create(protocol string) (sms.Provider, error) {
 	return nil, errors.New("unknown SMS provider")
 }
 
+func (f *DefaultFactory) Protocols() []string {
+	return []string{gatewayapi.Protocol, smsru.Protocol}
+}
+
 type MockFactory struct{}
 
 func (f *MockFactory) Create(protocol string) (sms.Provider, error) {
@@ -34,3 +38,7 @@ func (f *MockFactory) Create(protocol string) (sms.Provider, error) {
 
 	return nil, errors.New("unknown SMS provider")
 }
+
+func (f *MockFactory) Protocols() []string {
+	return []string{sms_mock.Protocol}
+}
@@ -27,6 +27,10 @@ func HttpHeadersSet(req *http.Request, headers ...map[string]string) {
 	req.Header.Set("User-Agent", "go-backend-helpers")
 	if len(headers) > 0 {
 		for k, v := range headers[0] {
+			if http.CanonicalHeaderKey(k) == "Host" {
+				req.Host = v
+				continue
+			}
 			req.Header.Set(k, v)
 		}
 	}
@@ -2,6 +2,7 @@ package test_utils
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -87,7 +88,7 @@ func CheckDuplicateKeyError(provider string, result *gorm.DB) (bool, error) {
 		return db_gorm.PostgresCheckDuplicateKeyError(provider, result)
 	case "sqlite":
 		if err, ok := result.Error.(sqlite3.Error); ok {
-			if err.ExtendedCode == sqlite3.ErrConstraintUnique {
+			if err.ExtendedCode == sqlite3.ErrConstraintUnique || err.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
 				return true, errors.New("record already exists")
 			}
 		}
@@ -108,6 +109,126 @@ func PartitionedMonthMigrator(provider string, ctx logger.WithLogger, db *gorm.D
 	return errors.New("unknown database provider")
 }
 
+func MonthPartitionsCreator(provider string, ctx logger.WithLogger, db *gorm.DB, months []utils.Month, models ...interface{}) error {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresCreateMonthPartitions(ctx, db, months, models...)
+	case "sqlite":
+		return nil
+	}
+
+	return errors.New("unknown database provider")
+}
+
+func MonthPartitionsLister(provider string, g *gorm.DB, model interface{}) ([]*db.MonthPartitionInfo, error) {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresListMonthPartitions(g, model)
+	case "sqlite":
+		return db_gorm.RowsMonthPartitions(g, model)
+	}
+
+	return nil, errors.New("unknown database provider")
+}
+
+func MonthPartitionDropper(provider string, ctx logger.WithLogger, g *gorm.DB, model interface{}, month utils.Month) error {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresDropMonthPartition(ctx, g, model, month)
+	case "sqlite":
+		return db_gorm.RowsDropMonthPartition(g, model, month)
+	}
+
+	return errors.New("unknown database provider")
+}
+
+func SchemaCreator(provider string, db *gorm.DB, schema string) error {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresSchemaCreator(provider, db, schema)
+	case "sqlite":
+		return nil
+	}
+
+	return errors.New("unknown database provider")
+}
+
+func DbDropper(provider string, db *gorm.DB, dbName string) error {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresDbDropper(provider, db, dbName)
+	case "sqlite":
+		err := os.Remove(SqliteDbPath(dbName))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return errors.New("unknown database provider")
+}
+
+func SchemaDropper(provider string, db *gorm.DB, schema string) error {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresSchemaDropper(provider, db, schema)
+	case "sqlite":
+		var tables []string
+		rs := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE ?", utils.ConcatStrings(SchemaTablePrefix(provider, schema), "%")).Scan(&tables)
+		if rs.Error != nil {
+			return rs.Error
+		}
+		for _, table := range tables {
+			rs = db.Exec(fmt.Sprintf("DROP TABLE %s", table))
+			if rs.Error != nil {
+				return rs.Error
+			}
+		}
+		return nil
+	}
+
+	return errors.New("unknown database provider")
+}
+
+func DbSizer(provider string, db *gorm.DB, dbName string) (int64, error) {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresDbSizer(provider, db, dbName)
+	case "sqlite":
+		info, err := os.Stat(SqliteDbPath(dbName))
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	}
+
+	return 0, errors.New("unknown database provider")
+}
+
+func SchemaSizer(provider string, db *gorm.DB, schema string) (int64, error) {
+
+	switch provider {
+	case "postgres":
+		return db_gorm.PostgresSchemaSizer(provider, db, schema)
+	}
+
+	return 0, errors.New("unknown database provider")
+}
+
+func SchemaTablePrefix(provider string, schema string) string {
+	if provider == "sqlite" {
+		return utils.ConcatStrings(schema, "_")
+	}
+	return ""
+}
+
 func SetupGormDB(t *testing.T) {
 	db_gorm.NewModelStore(true)
 	db_gorm.DefaultDbConnector = func() *db_gorm.DbConnector {
@@ -118,6 +239,16 @@ func SetupGormDB(t *testing.T) {
 		}
 		c.DbCreator = DbCreator
 		c.PartitionedMonthMigrator = PartitionedMonthMigrator
+		c.MonthPartitionsCreator = MonthPartitionsCreator
+		c.MonthPartitionsLister = MonthPartitionsLister
+		c.MonthPartitionDropper = MonthPartitionDropper
+		c.SchemaCreator = SchemaCreator
+		c.DbDropper = DbDropper
+		c.SchemaDropper = SchemaDropper
+		c.DbSizer = DbSizer
+		c.SchemaSizer = SchemaSizer
+		c.CheckDuplicateKeyError = CheckDuplicateKeyError
+		c.SchemaTablePrefix = SchemaTablePrefix
 		return c
 	}
 }
@@ -133,3 +264,10 @@ func CreateDbModels(t *testing.T, app app_context.Context, models []interface{})
 		db_gorm.GlobalModelStore.RegisterModels(models)
 	}
 }
+
+func CreatePartitionedDbModels(t *testing.T, app app_context.Context, models []interface{}) {
+	if models != nil {
+		require.NoErrorf(t, app.Db().PartitionedMonthAutoMigrate(app, models), "failed to create partitioned tables")
+		db_gorm.GlobalModelStore.RegisterModels(models)
+	}
+}
@@ -0,0 +1,54 @@
+package user
+
+import (
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+)
+
+// TemplateUsers creates default users of tenancy templates with user controller.
+type TemplateUsers[UserType User] struct {
+	Users UserController[UserType]
+}
+
+func NewTemplateUsers[UserType User](users UserController[UserType]) *TemplateUsers[UserType] {
+	return &TemplateUsers[UserType]{Users: users}
+}
+
+func (t *TemplateUsers[UserType]) AddTemplateUser(ctx op_context.Context, templateUser *multitenancy.TenancyTemplateUser) error {
+
+	// setup
+	var err error
+	c := ctx.TraceInMethod("TemplateUsers.AddTemplateUser", logger.Fields{"login": templateUser.Login})
+	onExit := func() {
+		if err != nil {
+			c.SetError(err)
+		}
+		ctx.TraceOutMethod()
+	}
+	defer onExit()
+
+	// skip existing user
+	filter := db.NewFilter()
+	filter.AddField("login", templateUser.Login)
+	filter.Limit = 1
+	users, _, err := t.Users.FindUsers(ctx, filter)
+	if err != nil {
+		c.SetMessage("failed to find user")
+		return err
+	}
+	if len(users) != 0 {
+		return nil
+	}
+
+	// add user
+	_, err = t.Users.Add(ctx, templateUser.Login, templateUser.Password, Phone[UserType](templateUser.Phone), Email[UserType](templateUser.Email))
+	if err != nil {
+		c.SetMessage("failed to add user")
+		return err
+	}
+
+	// done
+	return nil
+}
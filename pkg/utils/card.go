@@ -12,3 +12,28 @@ func MaskCard(pan string) string {
 	}
 	return res
 }
+
+// Check number with Luhn algorithm used for checksums of card numbers.
+func CheckLuhn(number string) bool {
+	if number == "" {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -1,12 +1,35 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
 
+// File writer that reopens file if it was deleted and optionally rotates it by size and/or time.
+// Rotated files are renamed to <path>.<timestamp> and retained according to MaxBackups and MaxAge.
 type FileWriteReopen struct {
 	Path string
 	File *os.File
+
+	// Rotate file when its size exceeds MaxSize bytes, zero disables rotation by size.
+	MaxSize int64
+	// Rotate file when Interval elapsed since it was opened, zero disables rotation by time.
+	Interval time.Duration
+	// Keep at most MaxBackups rotated files, zero means no limit.
+	MaxBackups int
+	// Delete rotated files older than MaxAge, zero means no limit.
+	MaxAge time.Duration
+
+	mutex    sync.Mutex
+	size     int64
+	openedAt time.Time
 }
 
+const RotatedFileTimeFormat = "20060102T150405.000"
+
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
@@ -15,17 +38,149 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
+func (f *FileWriteReopen) open() error {
+	var err error
+	f.File, err = os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	f.size = 0
+	info, err := f.File.Stat()
+	if err == nil {
+		f.size = info.Size()
+	}
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *FileWriteReopen) rotationEnabled() bool {
+	return f.MaxSize > 0 || f.Interval > 0
+}
+
+func (f *FileWriteReopen) needRotation(size int) bool {
+	if f.MaxSize > 0 && f.size > 0 && f.size+int64(size) > f.MaxSize {
+		return true
+	}
+	if f.Interval > 0 && time.Since(f.openedAt) >= f.Interval {
+		return true
+	}
+	return false
+}
+
+// Rotate file: rename current file, open new file and delete outdated rotated files.
+func (f *FileWriteReopen) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.rotate()
+}
+
+func (f *FileWriteReopen) rotate() error {
+
+	if f.File != nil {
+		f.File.Close()
+		f.File = nil
+	}
+
+	if fileExists(f.Path) {
+		rotated := f.Path + "." + time.Now().Format(RotatedFileTimeFormat)
+		err := os.Rename(f.Path, rotated)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := f.open()
+	if err != nil {
+		return err
+	}
+
+	f.cleanup()
+	return nil
+}
+
+// Get rotated files sorted from the oldest to the newest.
+func (f *FileWriteReopen) RotatedFiles() ([]string, error) {
+	files, err := filepath.Glob(f.Path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	rotated := make([]string, 0, len(files))
+	prefixLen := len(f.Path) + 1
+	for _, file := range files {
+		_, err := time.Parse(RotatedFileTimeFormat, file[prefixLen:])
+		if err == nil {
+			rotated = append(rotated, file)
+		}
+	}
+	sort.Strings(rotated)
+	return rotated, nil
+}
+
+func (f *FileWriteReopen) cleanup() {
+
+	if f.MaxBackups <= 0 && f.MaxAge <= 0 {
+		return
+	}
+
+	rotated, err := f.RotatedFiles()
+	if err != nil {
+		return
+	}
+
+	for i, file := range rotated {
+		remove := f.MaxBackups > 0 && len(rotated)-i > f.MaxBackups
+		if !remove && f.MaxAge > 0 {
+			info, err := os.Stat(file)
+			remove = err == nil && time.Since(info.ModTime()) > f.MaxAge
+		}
+		if remove {
+			os.Remove(file)
+		}
+	}
+}
+
 func (f *FileWriteReopen) Write(p []byte) (n int, err error) {
 
-	if !fileExists(f.Path) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.File == nil || !fileExists(f.Path) {
 		if f.File != nil {
 			f.File.Close()
 		}
-		f.File, err = os.OpenFile(f.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+		err = f.open()
 		if err != nil {
 			return 0, err
 		}
+	} else if f.rotationEnabled() {
+		// file could be opened by caller
+		if f.openedAt.IsZero() {
+			f.openedAt = time.Now()
+			info, err := f.File.Stat()
+			if err == nil {
+				f.size = info.Size()
+			}
+		}
+		if f.needRotation(len(p)) {
+			err = f.rotate()
+			if err != nil {
+				return 0, err
+			}
+		}
 	}
 
-	return f.File.Write(p)
+	n, err = f.File.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *FileWriteReopen) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.File == nil {
+		return nil
+	}
+	err := f.File.Close()
+	f.File = nil
+	return err
 }
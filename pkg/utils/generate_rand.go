@@ -15,7 +15,7 @@ func GenerateID() string {
 
 	count := idCount.Add(1) % 0x10000
 
-	id := fmt.Sprintf("%08x%04x%04x", t, count, r1)
+	id := fmt.Sprintf("%08x%04x%08x", t, count, r1)
 	return id
 }
 
@@ -0,0 +1,236 @@
+package config_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context/app_default"
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadConfig struct {
+	VALUE string `validate:"required"`
+	COUNT int    `validate:"gt=0" default:"1"`
+}
+
+type reloadSample struct {
+	reloadConfig
+	mutex sync.Mutex
+}
+
+func (s *reloadSample) Config() interface{} {
+	return &s.reloadConfig
+}
+
+func (s *reloadSample) Get() reloadConfig {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.reloadConfig
+}
+
+func writeReloadConfig(t *testing.T, file string, level string, watch bool, sighup bool, value string, count int) {
+	content := fmt.Sprintf(`{
+		"logger": {"level": "%s"},
+		"config_reload": {"watch": %v, "sighup": %v},
+		"section": {"value": "%s", "count": %d}
+	}`, level, watch, sighup, value, count)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+}
+
+func initReloadApp(t *testing.T, watch bool, sighup bool) (*app_default.Context, string, *reloadSample) {
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeReloadConfig(t, configFile, "info", watch, sighup, "one", 1)
+	app := app_default.New(nil)
+	require.NoError(t, app.Init(configFile))
+
+	sample := &reloadSample{}
+	require.NoError(t, object_config.LoadLogValidate(app.Cfg(), app.Logger(), app.Validator(), sample, "section"))
+	subscribed := config.SubscribeReload(app.Cfg(), "section", func(cfg config.Config) (func(), error) {
+		reloaded := &reloadSample{}
+		err := object_config.LoadLogValidate(cfg, app.Logger(), app.Validator(), reloaded, "section")
+		if err != nil {
+			return nil, err
+		}
+		apply := func() {
+			sample.mutex.Lock()
+			sample.reloadConfig = reloaded.reloadConfig
+			sample.mutex.Unlock()
+		}
+		return apply, nil
+	})
+	require.True(t, subscribed)
+
+	return app, configFile, sample
+}
+
+func logLevel(app *app_default.Context) logrus.Level {
+	return app.Logger().Native().(*logrus.Logger).GetLevel()
+}
+
+func TestConfigReload(t *testing.T) {
+
+	app, configFile, sample := initReloadApp(t, false, false)
+	defer app.Close()
+	assert.Equal(t, "one", sample.Get().VALUE)
+	assert.Equal(t, logrus.InfoLevel, logLevel(app))
+	app.Cfg().SetDefault("runtime.default", "default value")
+
+	// reload not changed configuration
+	require.NoError(t, app.ReloadConfig())
+
+	// reload valid configuration
+	writeReloadConfig(t, configFile, "debug", false, false, "two", 2)
+	require.NoError(t, app.ReloadConfig())
+	assert.Equal(t, reloadConfig{VALUE: "two", COUNT: 2}, sample.Get())
+	assert.Equal(t, "two", app.Cfg().GetString("section.value"))
+	assert.Equal(t, logrus.DebugLevel, logLevel(app))
+	assert.Equal(t, "default value", app.Cfg().GetString("runtime.default"))
+
+	// invalid configuration is rejected and not applied to any subscriber
+	writeReloadConfig(t, configFile, "warn", false, false, "three", 0)
+	assert.Error(t, app.ReloadConfig())
+	assert.Equal(t, reloadConfig{VALUE: "two", COUNT: 2}, sample.Get())
+	assert.Equal(t, "two", app.Cfg().GetString("section.value"))
+	assert.Equal(t, logrus.DebugLevel, logLevel(app))
+
+	// subscriber rejects reload
+	app.Cfg().(config.Reloadable).SubscribeReload("reject", func(cfg config.Config) (func(), error) {
+		return nil, errors.New("rejected")
+	})
+	writeReloadConfig(t, configFile, "warn", false, false, "four", 4)
+	assert.Error(t, app.ReloadConfig())
+	assert.Equal(t, "two", sample.Get().VALUE)
+	assert.Equal(t, logrus.DebugLevel, logLevel(app))
+
+	// reload after unsubscribing
+	app.Cfg().(config.Reloadable).UnsubscribeReload("reject")
+	require.NoError(t, app.ReloadConfig())
+	assert.Equal(t, "four", sample.Get().VALUE)
+	assert.Equal(t, logrus.WarnLevel, logLevel(app))
+}
+
+func TestConfigDiff(t *testing.T) {
+
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.json")
+	newFile := filepath.Join(dir, "new.json")
+	require.NoError(t, os.WriteFile(oldFile, []byte(`{"section": {"value": "one", "count": 1, "removed": true}}`), 0600))
+	require.NoError(t, os.WriteFile(newFile, []byte(`{"section": {"value": "two", "count": 1, "added": true}}`), 0600))
+
+	oldApp := app_default.New(nil)
+	require.NoError(t, oldApp.Init(oldFile))
+	defer oldApp.Close()
+	newApp := app_default.New(nil)
+	require.NoError(t, newApp.Init(newFile))
+	defer newApp.Close()
+
+	diff := config.Diff(oldApp.Cfg(), newApp.Cfg())
+	assert.Equal(t, []string{"section.value"}, diff.Changed)
+	assert.Equal(t, []string{"section.added"}, diff.Added)
+	assert.Equal(t, []string{"section.removed"}, diff.Removed)
+	assert.True(t, config.Diff(oldApp.Cfg(), oldApp.Cfg()).Empty())
+}
+
+func waitValue(sample *reloadSample, value string) bool {
+	for i := 0; i < 50; i++ {
+		if sample.Get().VALUE == value {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
+
+func TestConfigWatch(t *testing.T) {
+
+	app, configFile, sample := initReloadApp(t, true, false)
+	defer app.Close()
+
+	writeReloadConfig(t, configFile, "info", true, false, "two", 2)
+	assert.True(t, waitValue(sample, "two"))
+}
+
+func TestConfigSighup(t *testing.T) {
+
+	app, configFile, sample := initReloadApp(t, false, true)
+	defer app.Close()
+
+	writeReloadConfig(t, configFile, "info", false, true, "two", 2)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	assert.True(t, waitValue(sample, "two"))
+}
+
+func TestConfigReadWhileReload(t *testing.T) {
+
+	app, configFile, _ := initReloadApp(t, false, false)
+	defer app.Close()
+
+	stop := make(chan bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					value := app.Cfg().GetString("section.value")
+					assert.Contains(t, []string{"one", "two", "three"}, value)
+					app.Cfg().IsSet("section.count")
+					app.Cfg().AllKeys()
+				}
+			}
+		}()
+	}
+
+	values := []string{"two", "three", "one"}
+	for i := 0; i < 9; i++ {
+		writeReloadConfig(t, configFile, "info", false, false, values[i%len(values)], i+1)
+		require.NoError(t, app.ReloadConfig())
+	}
+	close(stop)
+	wg.Wait()
+	assert.Equal(t, "one", app.Cfg().GetString("section.value"))
+}
+
+func TestConfigWatchSymlinkSwap(t *testing.T) {
+
+	// layout of config map mounted by Kubernetes: config.json -> ..data/config.json, ..data -> ..v1
+	dir := t.TempDir()
+	writeVersion := func(version string, value string) {
+		versionDir := filepath.Join(dir, version)
+		require.NoError(t, os.Mkdir(versionDir, 0700))
+		writeReloadConfig(t, filepath.Join(versionDir, "config.json"), "info", true, false, value, 1)
+		require.NoError(t, os.Symlink(version, filepath.Join(dir, "..data_tmp")))
+		require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	}
+	writeVersion("..v1", "one")
+	configFile := filepath.Join(dir, "config.json")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.json"), configFile))
+
+	app := app_default.New(nil)
+	require.NoError(t, app.Init(configFile))
+	defer app.Close()
+	assert.Equal(t, "one", app.Cfg().GetString("section.value"))
+
+	writeVersion("..v2", "two")
+	reloaded := false
+	for i := 0; i < 50 && !reloaded; i++ {
+		time.Sleep(100 * time.Millisecond)
+		reloaded = app.Cfg().GetString("section.value") == "two"
+	}
+	assert.True(t, reloaded)
+}
@@ -0,0 +1,146 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/api/bare_bones_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context/app_default"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_schema"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_viper"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/sms/sms_provider_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/validator/validator_playground"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaSampleConfig struct {
+	NAME     string   `validate:"required" vmessage:"Name must be set"`
+	MODE     string   `default:"fast" validate:"oneof=fast slow"`
+	COUNT    int      `default:"10" validate:"gt=0"`
+	ENABLED  bool     `default:"true"`
+	PASSWORD string   `mask:"true"`
+	HOSTS    []string `validate:"dive,hostname"`
+}
+
+type schemaSample struct {
+	schemaSampleConfig
+}
+
+func (s *schemaSample) Config() interface{} {
+	return &s.schemaSampleConfig
+}
+
+func testSchema() *config_schema.Schema {
+	schema := config_schema.New()
+	app_default.RegisterConfigSchema(schema)
+	bare_bones_server.RegisterConfigSchema(schema, &sms_provider_factory.MockFactory{})
+	return schema
+}
+
+func TestSchemaFields(t *testing.T) {
+
+	fields := config_schema.ObjectsFields(&schemaSample{})
+	require.Len(t, fields, 6)
+
+	assert.Equal(t, "name", fields[0].Key)
+	assert.Equal(t, config_schema.FieldString, fields[0].Type)
+	assert.True(t, fields[0].Required)
+	assert.Equal(t, "Name must be set", fields[0].Message)
+
+	assert.Equal(t, "mode", fields[1].Key)
+	assert.Equal(t, "fast", fields[1].Default)
+	assert.Equal(t, []string{"fast", "slow"}, fields[1].Enum())
+
+	assert.Equal(t, config_schema.FieldInteger, fields[2].Type)
+	assert.Equal(t, config_schema.FieldBoolean, fields[3].Type)
+	assert.True(t, fields[4].Masked)
+	assert.Equal(t, config_schema.FieldArray, fields[5].Type)
+	assert.Equal(t, config_schema.FieldString, fields[5].Items)
+}
+
+func TestJsonSchema(t *testing.T) {
+
+	schema := config_schema.New()
+	schema.Register("sample", &schemaSample{})
+	schema.RegisterMap("samples", "", func(string) ([]object_config.Object, error) {
+		return []object_config.Object{&schemaSample{}}, nil
+	}, "first", "second")
+
+	text, err := schema.JsonSchemaText()
+	require.NoError(t, err)
+
+	doc := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(text), &doc))
+	assert.Equal(t, config_schema.JsonSchemaDraft, doc["$schema"])
+
+	properties := doc["properties"].(map[string]interface{})
+	sample := properties["sample"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"name"}, sample["required"])
+	sampleProperties := sample["properties"].(map[string]interface{})
+	mode := sampleProperties["mode"].(map[string]interface{})
+	assert.Equal(t, "fast", mode["default"])
+	assert.Equal(t, []interface{}{"fast", "slow"}, mode["enum"])
+	count := sampleProperties["count"].(map[string]interface{})
+	assert.Equal(t, float64(10), count["default"])
+	password := sampleProperties["password"].(map[string]interface{})
+	assert.Equal(t, true, password["writeOnly"])
+
+	samples := properties["samples"].(map[string]interface{})
+	assert.Equal(t, false, samples["additionalProperties"])
+	samplesProperties := samples["properties"].(map[string]interface{})
+	assert.Contains(t, samplesProperties, "first")
+	assert.Contains(t, samplesProperties, "second")
+
+	// schema of application
+	_, err = testSchema().JsonSchemaText()
+	require.NoError(t, err)
+}
+
+func TestMarkdown(t *testing.T) {
+
+	text, err := testSchema().Markdown()
+	require.NoError(t, err)
+
+	assert.Contains(t, text, "# Configuration reference")
+	assert.Contains(t, text, "## `logger`")
+	assert.Contains(t, text, "| `destination` | string | `stdout` |")
+	assert.Contains(t, text, "## `server.auth.manager.methods`")
+	assert.Contains(t, text, "### `check_token`")
+	assert.Contains(t, text, "## `sms.providers`")
+	assert.Contains(t, text, "### `sms_mock`")
+}
+
+func TestConfigCheck(t *testing.T) {
+
+	cfg := config_viper.New()
+	require.NoError(t, cfg.LoadFile(test_utils.AssetsFilePath(testDir, "schema_check.jsonc")))
+
+	result := testSchema().Check(cfg, validator_playground.New())
+	assert.False(t, result.Ok())
+
+	assert.Equal(t, []string{
+		"logger.colour",
+		"server.rest_api_server.unknown_server_key",
+		"sms.destinations.0.extra",
+		"unknown_top",
+	}, result.UnknownKeys)
+
+	assert.Contains(t, result.Missing, "server.rest_api_server.port")
+	assert.Contains(t, result.Missing, "server.rest_api_server.api_version")
+	assert.NotContains(t, result.Missing, "server.auth.manager.methods.check_token.secret")
+
+	invalid := make(map[string]string)
+	for _, issue := range result.Invalid {
+		invalid[issue.Key] = issue.Message
+	}
+	assert.Equal(t, "logger destination must be one of: stdout | file", invalid["logger.destination"])
+	assert.Contains(t, invalid, "server.auth.manager.methods.bogus")
+	assert.Contains(t, invalid, "server.auth.manager.methods.check_token.access_token_ttl_seconds")
+	assert.Contains(t, invalid, "sms.providers.bad")
+	assert.Contains(t, invalid, "sms.destinations.1.prefix")
+	assert.NotContains(t, invalid, "sms.destinations.0.prefix")
+	assert.NotContains(t, invalid, "server.rest_api_server.port")
+}
@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/config_viper"
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretConfig struct {
+	PLAIN    string
+	PASSWORD string `mask:"true"`
+	API_ID   string `mask:"true"`
+	SECRET   string `mask:"true"`
+	KEYS     []string
+}
+
+type secretSample struct {
+	secretConfig
+}
+
+func (s *secretSample) Config() interface{} {
+	return &s.secretConfig
+}
+
+func TestSecretEncryption(t *testing.T) {
+
+	encrypted, err := config.EncryptSecret("master key", "secret value")
+	require.NoError(t, err)
+	assert.True(t, config.IsSecretRef(encrypted))
+
+	decrypted, err := config.DecryptSecret("master key", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret value", decrypted)
+
+	_, err = config.DecryptSecret("other key", encrypted)
+	assert.Error(t, err)
+
+	encrypted2, err := config.EncryptSecret("master key", "secret value")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, encrypted2)
+}
+
+func TestResolveSecrets(t *testing.T) {
+
+	// prepare secrets
+	t.Setenv("TEST_SECRET_PASSWORD", "password from env")
+	secretFile := filepath.Join(t.TempDir(), "api_id")
+	require.NoError(t, os.WriteFile(secretFile, []byte("api id from file\n"), 0600))
+	config.SetMasterKey("master key")
+	defer config.SetMasterKey("")
+	encrypted, err := config.EncryptSecret("master key", "encrypted secret")
+	require.NoError(t, err)
+
+	// resolve single values
+	value, err := config.ResolveSecret("plain value")
+	require.NoError(t, err)
+	assert.Equal(t, "plain value", value)
+	value, err = config.ResolveSecret("env:TEST_SECRET_PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "password from env", value)
+	value, err = config.ResolveSecret("file:" + secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "api id from file", value)
+	value, err = config.ResolveSecret(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted secret", value)
+	_, err = config.ResolveSecret("env:TEST_SECRET_UNKNOWN")
+	assert.Error(t, err)
+	_, err = config.ResolveSecret("file:" + secretFile + ".unknown")
+	assert.Error(t, err)
+	_, err = config.ResolveSecret("enc:invalid")
+	assert.Error(t, err)
+
+	// escaped values are unescaped
+	value, err = config.ResolveSecret(`\env:TEST_SECRET_PASSWORD`)
+	require.NoError(t, err)
+	assert.Equal(t, "env:TEST_SECRET_PASSWORD", value)
+	value, err = config.ResolveSecret(`\\file:name`)
+	require.NoError(t, err)
+	assert.Equal(t, `\file:name`, value)
+	value, err = config.ResolveSecret(`\plain`)
+	require.NoError(t, err)
+	assert.Equal(t, `\plain`, value)
+	assert.Equal(t, `\env:NAME`, config.EscapeSecret("env:NAME"))
+	assert.Equal(t, "plain", config.EscapeSecret("plain"))
+
+	// resolve secrets in object configuration
+	t.Setenv("TEST_SECRET_REF", "env:TEST_SECRET_PASSWORD")
+	configStr := fmt.Sprintf(`{"section":{"plain":"plain value","password":"env:TEST_SECRET_PASSWORD","api_id":"file:%s","secret":"%s","keys":["key1","%s","\\env:KEY","env:TEST_SECRET_REF"]}}`,
+		secretFile, encrypted, encrypted)
+	cfg := config_viper.New()
+	require.NoError(t, cfg.LoadString(configStr))
+	assert.Equal(t, "env:TEST_SECRET_PASSWORD", cfg.GetString("section.password"))
+	s := &secretSample{}
+	require.NoError(t, object_config.Load(cfg, "section", s))
+	expected := secretConfig{
+		PLAIN:    "plain value",
+		PASSWORD: "password from env",
+		API_ID:   "api id from file",
+		SECRET:   "encrypted secret",
+		KEYS:     []string{"key1", "encrypted secret", "env:KEY", "env:TEST_SECRET_PASSWORD"},
+	}
+	assert.Equal(t, expected, s.secretConfig)
+
+	// resolve secrets when loading configuration file, values looking like references are kept escaped
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configFile, []byte(configStr), 0600))
+	cfg = config_viper.New()
+	require.NoError(t, cfg.LoadFile(configFile))
+	assert.Equal(t, "plain value", cfg.GetString("section.plain"))
+	assert.Equal(t, "password from env", cfg.GetString("section.password"))
+	assert.Equal(t, "api id from file", cfg.GetString("section.api_id"))
+	assert.Equal(t, "encrypted secret", cfg.GetString("section.secret"))
+	assert.Equal(t, []string{"key1", "encrypted secret", `\env:KEY`, `\env:TEST_SECRET_PASSWORD`}, cfg.GetStringSlice("section.keys"))
+
+	// values resolved by configuration file are not resolved again when configuration of object is loaded
+	s = &secretSample{}
+	require.NoError(t, object_config.Load(cfg, "section", s))
+	assert.Equal(t, expected, s.secretConfig)
+
+	// unresolved secret fails loading
+	require.NoError(t, os.WriteFile(configFile, []byte(`{"section":{"password":"env:TEST_SECRET_UNKNOWN"}}`), 0600))
+	cfg = config_viper.New()
+	assert.Error(t, cfg.LoadFile(configFile))
+	cfg = config_viper.New()
+	require.NoError(t, cfg.LoadString(`{"section":{"password":"env:TEST_SECRET_UNKNOWN"}}`))
+	assert.Error(t, object_config.Load(cfg, "section", &secretSample{}))
+}
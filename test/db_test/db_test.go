@@ -163,6 +163,38 @@ type ChildWithParent struct {
 	ChildName  string `json:"children.name" gorm:"->;column:children_name"`
 }
 
+func TestCreateIfNotExists(t *testing.T) {
+	app := test_utils.InitAppContext(t, testDir, dbModels(), "maindb.json")
+	defer app.Close()
+
+	doc1 := &SampleModel1{}
+	doc1.InitObject()
+	doc1.Field1 = "value1"
+	created, err := app.Db().CreateIfNotExists(app, doc1)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// conflicting object is ignored inside transaction and transaction can go on
+	err = app.Db().Transaction(func(tx db.Transaction) error {
+		doc2 := &SampleModel1{}
+		doc2.InitObject()
+		doc2.Field1 = "value1"
+		doc2.Field2 = "value2"
+		created, err := tx.CreateIfNotExists(app, doc2)
+		require.NoError(t, err)
+		assert.False(t, created)
+		return tx.Update(app, &SampleModel1{}, db.Fields{"id": doc1.GetID()}, db.Fields{"field2": "updated"})
+	})
+	require.NoError(t, err)
+
+	docDb1 := &SampleModel1{}
+	found, err := app.Db().FindByFields(app, db.Fields{"field1": "value1"}, docDb1)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, doc1.GetID(), docDb1.GetID())
+	assert.Equal(t, "updated", docDb1.Field2)
+}
+
 func TestDottedJsonFields(t *testing.T) {
 
 	obj := &ChildWithParent{}
@@ -0,0 +1,100 @@
+package db_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/db/partition_retention"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MonthRecord struct {
+	common.ObjectWithMonth
+	Value string `gorm:"index" json:"value"`
+}
+
+func TestPartitionRetention(t *testing.T) {
+
+	app := test_utils.InitAppContext(t, testDir, []interface{}{&MonthRecord{}}, "partition_retention.json")
+	defer app.Close()
+	ctx := test_utils.SimpleOpContext(app, "retention")
+	defer ctx.Close()
+
+	// fill records for current month and two previous months
+	current := utils.CurrentMonth()
+	months := []utils.Month{current.Prev(), current}
+	months = append([]utils.Month{months[0].Prev()}, months...)
+	for i, month := range months {
+		for j := 0; j <= i; j++ {
+			record := &MonthRecord{}
+			record.InitObject()
+			record.SetMonth(month)
+			record.Value = month.String()
+			require.NoError(t, app.Db().Create(ctx, record))
+		}
+	}
+
+	// list partitions
+	partitions, err := app.Db().ListMonthPartitions(ctx, &MonthRecord{})
+	require.NoError(t, err)
+	require.Len(t, partitions, 3)
+	for i, partition := range partitions {
+		assert.Equal(t, "month_records", partition.Table)
+		assert.Equal(t, months[i], partition.Month)
+		assert.Equal(t, int64(i+1), partition.Rows)
+	}
+
+	// setup retention
+	retention := partition_retention.New()
+	require.NoError(t, retention.Init(app))
+	retention.AddModels(&MonthRecord{})
+	require.NotNil(t, retention.Rule("month_records"))
+	archiveDir := t.TempDir()
+	retention.Rule("month_records").ARCHIVE_DIR = archiveDir
+	assert.True(t, retention.Rule("month_records").Expired(months[0], current))
+	assert.False(t, retention.Rule("month_records").Expired(months[1], current))
+
+	// apply retention
+	dropped, err := retention.Apply(ctx)
+	require.NoError(t, err)
+	require.Len(t, dropped, 1)
+	assert.Equal(t, months[0], dropped[0].Month)
+
+	partitions, err = retention.Partitions(ctx)
+	require.NoError(t, err)
+	require.Len(t, partitions, 2)
+	assert.Equal(t, months[1], partitions[0].Month)
+	assert.Equal(t, current, partitions[1].Month)
+	count, err := app.Db().FindWithFilter(ctx, &db.Filter{Fields: db.Fields{"month": months[0]}, FilterConfig: db.FilterConfig{Count: true}}, &[]*MonthRecord{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// check archive
+	file, err := os.Open(partition_retention.ArchivePath(archiveDir, "month_records", months[0]))
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	lines := 0
+	for scanner.Scan() {
+		record := &MonthRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		assert.Equal(t, months[0].String(), record.Value)
+		lines++
+	}
+	assert.Equal(t, 1, lines)
+	_, err = os.Stat(filepath.Join(archiveDir, "month_records_"+months[0].String()+".jsonl.tmp"))
+	assert.True(t, os.IsNotExist(err))
+
+	// nothing to drop next time
+	dropped, err = retention.Apply(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, dropped)
+}
@@ -0,0 +1,332 @@
+package job_queue_test
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/admin"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api/job_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue/job_api/job_service"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/test/api_test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _, testBasePath, _, _ = runtime.Caller(0)
+var testDir = filepath.Dir(testBasePath)
+
+func dbModels() []interface{} {
+	return utils.ConcatSlices([]interface{}{}, admin.DbModels(), job_queue.DbModels())
+}
+
+type JobTestContext struct {
+	*api_test.TestContext
+	Manager    *job_queue.JobManager
+	RemoteJobs *job_client.JobClient
+}
+
+func initTest(t *testing.T) *JobTestContext {
+	ctx := &JobTestContext{}
+	ctx.TestContext = api_test.InitTest(t, "job_queue", testDir, dbModels())
+	ctx.Manager = job_queue.NewJobManager()
+	require.NoError(t, ctx.Manager.Init(ctx.ServerApp))
+	ctx.Manager.RETRY_DELAY = 0
+	api_server.AddServiceToServer(ctx.Server.ApiServer(), job_service.NewJobService(ctx.Manager))
+	ctx.RemoteJobs = job_client.NewJobClient(ctx.RestApiClient)
+	return ctx
+}
+
+type samplePayload struct {
+	Value    string `json:"value"`
+	Failures int    `json:"failures"`
+}
+
+type sampleHandler struct {
+	mutex     sync.Mutex
+	values    []string
+	running   atomic.Int32
+	maxActive atomic.Int32
+	block     chan bool
+}
+
+func (s *sampleHandler) Handle(ctx op_context.Context, job *job_queue.Job) error {
+
+	active := s.running.Add(1)
+	defer s.running.Add(-1)
+	if active > s.maxActive.Load() {
+		s.maxActive.Store(active)
+	}
+	if s.block != nil {
+		<-s.block
+	}
+
+	payload := &samplePayload{}
+	err := job.Payload(payload)
+	if err != nil {
+		return err
+	}
+	if job.Attempts() <= payload.Failures {
+		return errors.New("sample failure")
+	}
+
+	s.mutex.Lock()
+	s.values = append(s.values, payload.Value)
+	s.mutex.Unlock()
+	return nil
+}
+
+func (s *sampleHandler) Values() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.values...)
+}
+
+func findJob(t *testing.T, ctx *JobTestContext, id string, status string) *job_queue.Job {
+	job, err := ctx.RemoteJobs.Find(ctx.ClientOp, id)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, status, job.Status())
+	return job
+}
+
+func processJobs(t *testing.T, ctx *JobTestContext, expected int, queue ...string) {
+	count, err := ctx.Manager.ProcessJobs(ctx.AdminOp, queue...)
+	require.NoError(t, err)
+	assert.Equal(t, expected, count)
+}
+
+func TestJobs(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+	handler := &sampleHandler{}
+	ctx.Manager.RegisterHandler("sample", handler)
+
+	// run job
+	job1, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "job1"})
+	require.NoError(t, err)
+	findJob(t, ctx, job1.GetID(), job_queue.JobPending)
+	processJobs(t, ctx, 1)
+	job := findJob(t, ctx, job1.GetID(), job_queue.JobDone)
+	assert.Equal(t, 1, job.Attempts())
+	assert.False(t, job.FINISHED_AT.IsZero())
+	assert.Equal(t, []string{"job1"}, handler.Values())
+	processJobs(t, ctx, 0)
+
+	// delayed job is not run before time
+	delayed, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "delayed"}, &job_queue.JobOptions{Delay: time.Hour})
+	require.NoError(t, err)
+	processJobs(t, ctx, 0)
+	findJob(t, ctx, delayed.GetID(), job_queue.JobPending)
+
+	// failed job is retried
+	retried, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "retried", Failures: 1})
+	require.NoError(t, err)
+	processJobs(t, ctx, 1)
+	job = findJob(t, ctx, retried.GetID(), job_queue.JobPending)
+	assert.Equal(t, 1, job.Attempts())
+	assert.Equal(t, "sample failure", job.LastError())
+	processJobs(t, ctx, 1)
+	job = findJob(t, ctx, retried.GetID(), job_queue.JobDone)
+	assert.Equal(t, 2, job.Attempts())
+	assert.Empty(t, job.LastError())
+
+	// job fails after max attempts
+	failed, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "failed", Failures: 10}, &job_queue.JobOptions{MaxAttempts: 2})
+	require.NoError(t, err)
+	processJobs(t, ctx, 1)
+	processJobs(t, ctx, 1)
+	job = findJob(t, ctx, failed.GetID(), job_queue.JobFailed)
+	assert.Equal(t, 2, job.Attempts())
+	processJobs(t, ctx, 0)
+
+	// job of unknown type fails
+	unknown, err := ctx.Manager.Enqueue(ctx.AdminOp, "unknown", nil, &job_queue.JobOptions{MaxAttempts: 1})
+	require.NoError(t, err)
+	processJobs(t, ctx, 1)
+	job = findJob(t, ctx, unknown.GetID(), job_queue.JobFailed)
+	assert.Contains(t, job.LastError(), "no handler")
+
+	// cancel pending job
+	job, err = ctx.RemoteJobs.Cancel(ctx.ClientOp, delayed.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, job_queue.JobCancelled, job.Status())
+	_, err = ctx.RemoteJobs.Cancel(ctx.ClientOp, delayed.GetID())
+	test_utils.CheckGenericError(t, err, job_queue.ErrorCodeJobFinished)
+	ctx.ClientOp.Reset()
+	_, err = ctx.RemoteJobs.Cancel(ctx.ClientOp, "unknown")
+	test_utils.CheckGenericError(t, err, job_queue.ErrorCodeJobNotFound)
+	ctx.ClientOp.Reset()
+
+	// list jobs
+	filter := db.NewFilter()
+	filter.AddField("status", job_queue.JobDone)
+	jobs, count, err := ctx.RemoteJobs.List(ctx.ClientOp, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, jobs, 2)
+	_, count, err = ctx.RemoteJobs.List(ctx.ClientOp, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestScheduledJobs(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+	handler := &sampleHandler{}
+	ctx.Manager.RegisterHandler("sample", handler)
+
+	// invalid cron expression
+	_, err := ctx.Manager.Schedule(ctx.AdminOp, "report", "* * *", "sample", nil)
+	assert.Error(t, err)
+	require.NotNil(t, ctx.AdminOp.GenericError())
+	assert.Equal(t, job_queue.ErrorCodeJobInvalidCron, ctx.AdminOp.GenericError().Code())
+	ctx.AdminOp.Reset()
+
+	// schedule job
+	scheduled, err := ctx.Manager.Schedule(ctx.AdminOp, "report", "*/5 * * * *", "sample", &samplePayload{Value: "v1"})
+	require.NoError(t, err)
+	assert.True(t, scheduled.RunAt().After(time.Now()))
+	assert.Equal(t, 0, scheduled.RunAt().Minute()%5)
+	processJobs(t, ctx, 0)
+
+	// reschedule job with the same name
+	rescheduled, err := ctx.Manager.Schedule(ctx.AdminOp, "report", "0 3 * * *", "sample", &samplePayload{Value: "v2"})
+	require.NoError(t, err)
+	assert.Equal(t, scheduled.GetID(), rescheduled.GetID())
+	_, count, err := ctx.Manager.List(ctx.AdminOp, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// run due job, job is scheduled for the next time
+	dbCrud := &crud.DbCRUD{}
+	require.NoError(t, dbCrud.UpdateMulti(ctx.AdminOp, &job_queue.Job{}, db.Fields{"id": scheduled.GetID()}, db.Fields{"run_at": time.Now().Add(-time.Minute)}))
+	processJobs(t, ctx, 1)
+	assert.Equal(t, []string{"v2"}, handler.Values())
+	job := findJob(t, ctx, scheduled.GetID(), job_queue.JobPending)
+	assert.Equal(t, 3, job.RunAt().Hour())
+	assert.Equal(t, 0, job.Attempts())
+	processJobs(t, ctx, 0)
+}
+
+func TestJobLeases(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+	handler := &sampleHandler{}
+	ctx.Manager.RegisterHandler("sample", handler)
+
+	// job abandoned by crashed instance is picked again after lease expired
+	job1, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "abandoned"})
+	require.NoError(t, err)
+	dbCrud := &crud.DbCRUD{}
+	abandoned := db.Fields{"status": job_queue.JobRunning, "attempts": 1, "lease_token": "crashed", "lease_expires_at": time.Now().Add(time.Minute)}
+	require.NoError(t, dbCrud.UpdateMulti(ctx.AdminOp, &job_queue.Job{}, db.Fields{"id": job1.GetID()}, abandoned))
+	processJobs(t, ctx, 0)
+	require.NoError(t, dbCrud.UpdateMulti(ctx.AdminOp, &job_queue.Job{}, db.Fields{"id": job1.GetID()}, db.Fields{"lease_expires_at": time.Now().Add(-time.Second)}))
+	processJobs(t, ctx, 1)
+	job := findJob(t, ctx, job1.GetID(), job_queue.JobDone)
+	assert.Equal(t, 2, job.Attempts())
+
+	// lease of running job is extended with heartbeats
+	handler.block = make(chan bool)
+	job2, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "running"})
+	require.NoError(t, err)
+	done := make(chan bool)
+	go func() {
+		ctx.Manager.ProcessJobs(test_utils.SimpleOpContext(ctx.ServerApp, "process"))
+		done <- true
+	}()
+	require.Eventually(t, func() bool { return handler.running.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	running := findJob(t, ctx, job2.GetID(), job_queue.JobRunning)
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, ctx.Manager.ExtendLeases(ctx.AdminOp))
+	extended := findJob(t, ctx, job2.GetID(), job_queue.JobRunning)
+	assert.True(t, extended.LEASE_EXPIRES_AT.After(running.LEASE_EXPIRES_AT))
+
+	// result of job cancelled while running is discarded
+	_, err = ctx.RemoteJobs.Cancel(ctx.ClientOp, job2.GetID())
+	require.NoError(t, err)
+	handler.block <- true
+	<-done
+	findJob(t, ctx, job2.GetID(), job_queue.JobCancelled)
+	assert.Equal(t, []string{"abandoned", "running"}, handler.Values())
+}
+
+func TestJobWorkers(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+	handler := &sampleHandler{}
+	ctx.Manager.RegisterHandler("sample", handler)
+
+	// concurrency of queue is limited
+	handler.block = make(chan bool)
+	for i := 0; i < 3; i++ {
+		_, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "report"}, &job_queue.JobOptions{Queue: "reports"})
+		require.NoError(t, err)
+	}
+	_, err := ctx.Manager.ProcessJobs(ctx.AdminOp, "unknown")
+	assert.Error(t, err)
+
+	// run workers in background
+	ctx.Manager.Run()
+	require.Eventually(t, func() bool { return handler.running.Load() == 2 }, 5*time.Second, 10*time.Millisecond)
+	handler.block <- true
+	handler.block <- true
+	require.Eventually(t, func() bool { return handler.running.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	handler.block <- true
+	require.Eventually(t, func() bool { return len(handler.Values()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), handler.maxActive.Load())
+
+	// enqueued job is run by worker
+	handler.block = nil
+	job1, err := ctx.Manager.Enqueue(ctx.AdminOp, "sample", &samplePayload{Value: "background"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err := ctx.Manager.Find(ctx.AdminOp, job1.GetID())
+		return err == nil && job.Status() == job_queue.JobDone
+	}, 5*time.Second, 50*time.Millisecond)
+	ctx.Manager.Close()
+}
+
+func TestCron(t *testing.T) {
+
+	base := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC)
+
+	check := func(expr string, expected time.Time) {
+		next, err := job_queue.NextCronTime(expr, base)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, next, expr)
+	}
+	check("* * * * *", time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC))
+	check("*/15 * * * *", time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC))
+	check("0 9-17 * * *", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC))
+	check("30 2 * * *", time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC))
+	check("0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	check("0 0 * * 0", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC))
+	check("0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC))
+	check("0 0 1,15 * 1", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	check("@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	check("@hourly", time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC))
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := job_queue.ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+	_, err := job_queue.NextCronTime("0 0 30 2 *", base)
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package leader_election_test
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/background_worker"
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/leader_election"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pool_pubsub"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _, testBasePath, _, _ = runtime.Caller(0)
+var testDir = filepath.Dir(testBasePath)
+
+type pubsubMock struct {
+	pool_pubsub.PoolPubsub
+	mutex         sync.Mutex
+	notifications []*leader_election.LeadershipNotification
+}
+
+func (p *pubsubMock) PublishSelfPool(topicName string, msg interface{}) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if topicName == leader_election.LeadershipTopicName {
+		p.notifications = append(p.notifications, msg.(*leader_election.LeadershipNotification))
+	}
+	return nil
+}
+
+func initTest(t *testing.T) app_context.Context {
+	return test_utils.InitAppContext(t, testDir, leader_election.DbModels(), "leader_election.json")
+}
+
+func newElector(t *testing.T, app app_context.Context) *leader_election.Elector {
+	e := leader_election.NewElector()
+	require.NoError(t, e.Init(app))
+	return e
+}
+
+func TestElection(t *testing.T) {
+
+	app := initTest(t)
+	defer app.Close()
+	ctx := test_utils.SimpleOpContext(app, t.Name())
+
+	e1 := newElector(t, app)
+	e2 := newElector(t, app)
+	pubsub := &pubsubMock{}
+	e1.SetPubsub(pubsub)
+	var changes []bool
+	e1.OnChange(func(leader bool) { changes = append(changes, leader) })
+
+	// first elector becomes leader
+	leader, err := e1.Elect(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+	assert.True(t, e1.IsLeader())
+	leader, err = e2.Elect(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader)
+	assert.False(t, e2.IsLeader())
+
+	// leader renews lease
+	leader, err = e1.Elect(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+	lease := &leader_election.Lease{}
+	found, err := ctx.Db().FindByField(ctx, "id", "test", lease)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, e1.Holder(), lease.Holder())
+
+	// leader resigns and other elector takes over
+	require.NoError(t, e1.Resign(ctx))
+	assert.False(t, e1.IsLeader())
+	leader, err = e2.Elect(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+	leader, err = e1.Elect(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader)
+
+	// lease of crashed leader expires and other elector takes over
+	dbCrud := &crud.DbCRUD{}
+	require.NoError(t, dbCrud.UpdateMulti(ctx, &leader_election.Lease{}, db.Fields{"id": "test"}, db.Fields{"expires_at": time.Now().Add(-time.Second)}))
+	leader, err = e1.Elect(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+	leader, err = e2.Elect(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader)
+
+	// check notifications
+	assert.Equal(t, []bool{true, false, true}, changes)
+	require.Len(t, pubsub.notifications, 3)
+	for i, notification := range pubsub.notifications {
+		assert.Equal(t, "test", notification.Name)
+		assert.Equal(t, e1.Holder(), notification.Holder)
+		assert.Equal(t, changes[i], notification.Leader)
+	}
+}
+
+type sampleRunner struct {
+	background_worker.JobRunnerBase
+	count atomic.Int32
+}
+
+func (s *sampleRunner) RunJob() {
+	s.count.Add(1)
+}
+
+func TestRunOnlyWhenLeader(t *testing.T) {
+
+	app := initTest(t)
+	defer app.Close()
+
+	e1 := newElector(t, app)
+	e2 := newElector(t, app)
+
+	// run electors in background
+	e1.Run(nil)
+	time.Sleep(200 * time.Millisecond)
+	e2.Run(nil)
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, e1.IsLeader())
+	assert.False(t, e2.IsLeader())
+
+	// only worker of leader runs job
+	runner1 := &sampleRunner{}
+	worker1 := background_worker.New(app.Logger(), runner1, 1)
+	worker1.RunOnlyWhenLeader(e1)
+	runner2 := &sampleRunner{}
+	worker2 := background_worker.New(app.Logger(), runner2, 1)
+	worker2.RunOnlyWhenLeader(e2)
+	worker1.RunInBackground()
+	worker2.RunInBackground()
+	time.Sleep(200 * time.Millisecond)
+	worker1.Stop()
+	worker2.Stop()
+	assert.Equal(t, int32(1), runner1.count.Load())
+	assert.Equal(t, int32(0), runner2.count.Load())
+
+	// stopped leader releases leadership and other elector takes over on next renewal
+	e1.Stop()
+	assert.False(t, e1.IsLeader())
+	time.Sleep(2500 * time.Millisecond)
+	assert.True(t, e2.IsLeader())
+	e2.Stop()
+}
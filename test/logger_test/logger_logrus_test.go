@@ -0,0 +1,215 @@
+package logger_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context/app_default"
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger"
+	"github.com/evgeniums/go-backend-helpers/pkg/logger/log_level"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initApp(t *testing.T, loggerConfig string) (*app_default.Context, string) {
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.log")
+	configFile := filepath.Join(dir, "config.json")
+	content := fmt.Sprintf(`{"logger":{"destination":"file","file":"%s",%s}}`, file, loggerConfig)
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+	app := app_default.New(nil)
+	require.NoError(t, app.Init(configFile))
+	t.Cleanup(app.Close)
+	return app, file
+}
+
+func initLogger(t *testing.T, loggerConfig string) (logger.Logger, string) {
+	app, file := initApp(t, loggerConfig)
+	return app.Logger(), file
+}
+
+func readLines(t *testing.T, file string) []string {
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestJsonFormat(t *testing.T) {
+
+	log, file := initLogger(t, `"format":"json","level":"info"`)
+	log.Info("json message", logger.Fields{"key": "value"})
+	log.Debug("skipped message")
+
+	lines := readLines(t, file)
+	require.NotEmpty(t, lines)
+	entry := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+	assert.Equal(t, "json message", entry["msg"])
+	assert.Equal(t, "value", entry["key"])
+	assert.Equal(t, "info", entry["level"])
+}
+
+func TestLogfmtFormat(t *testing.T) {
+
+	log, file := initLogger(t, `"format":"logfmt"`)
+	log.Info("logfmt message", logger.Fields{"key": "value"})
+
+	lines := readLines(t, file)
+	require.NotEmpty(t, lines)
+	last := lines[len(lines)-1]
+	assert.Contains(t, last, `level=info`)
+	assert.Contains(t, last, `msg="logfmt message"`)
+	assert.Contains(t, last, `key=value`)
+}
+
+func TestComponentLevels(t *testing.T) {
+
+	log, file := initLogger(t, `"level":"info","components":{"db":"debug","sms":"error"}`)
+
+	dbLog := logger.NewComponentLogger(log, "db")
+	smsLog := logger.NewComponentLogger(log, "sms")
+
+	log.Debug("global debug")
+	log.Info("global info")
+	dbLog.Debug("db debug")
+	dbLog.Trace("db trace")
+	smsLog.Warn("sms warn")
+	smsLog.ErrorMessage("sms error")
+
+	text := strings.Join(readLines(t, file), "\n")
+	assert.NotContains(t, text, "global debug")
+	assert.Contains(t, text, "global info")
+	assert.Contains(t, text, "db debug")
+	assert.NotContains(t, text, "db trace")
+	assert.NotContains(t, text, "sms warn")
+	assert.Contains(t, text, "sms error")
+}
+
+func TestSampling(t *testing.T) {
+
+	log, file := initLogger(t, `"sampling_period_seconds":60,"sampling_first":3,"sampling_thereafter":5`)
+
+	for i := 0; i < 13; i++ {
+		log.Info("repeated message")
+	}
+	log.Info("other message")
+
+	lines := readLines(t, file)
+	// 3 first entries, then 8-th and 13-th entries
+	repeated := 0
+	for _, line := range lines {
+		if strings.Contains(line, "repeated message") {
+			repeated++
+		}
+	}
+	assert.Equal(t, 5, repeated)
+	assert.Contains(t, lines[len(lines)-1], "other message")
+}
+
+func TestRuntimeLevels(t *testing.T) {
+
+	app, file := initApp(t, `"level":"info","components":{"db":"debug"}`)
+	log := app.Logger()
+	authLog := logger.NewComponentLogger(log, "auth")
+
+	ctx := test_utils.SimpleOpContext(app, "TestRuntimeLevels")
+	defer ctx.Close()
+	controller := log_level.NewLevelController(log)
+
+	levels, err := controller.Levels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "info", levels.LEVEL)
+	assert.Equal(t, map[string]string{"db": "debug"}, levels.COMPONENTS)
+
+	authLog.Debug("auth debug before")
+	levels, err = controller.SetLevel(ctx, &log_level.LogLevelCmd{LEVEL: "debug", COMPONENT: "auth"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "debug", "auth": "debug"}, levels.COMPONENTS)
+	authLog.Debug("auth debug after")
+	log.Debug("global debug")
+
+	levels, err = controller.SetLevel(ctx, &log_level.LogLevelCmd{LEVEL: "warn"})
+	require.NoError(t, err)
+	assert.Equal(t, "warn", levels.LEVEL)
+	log.Info("global info")
+
+	// reset level of component
+	levels, err = controller.SetLevel(ctx, &log_level.LogLevelCmd{COMPONENT: "db"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"auth": "debug"}, levels.COMPONENTS)
+
+	text := strings.Join(readLines(t, file), "\n")
+	assert.NotContains(t, text, "auth debug before")
+	assert.Contains(t, text, "auth debug after")
+	assert.NotContains(t, text, "global debug")
+	assert.NotContains(t, text, "global info")
+}
+
+type componentSample struct {
+	common.ObjectBase
+	Field1 string
+}
+
+func TestDatabaseComponent(t *testing.T) {
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.log")
+	configFile := filepath.Join(dir, "config.json")
+	content := fmt.Sprintf(`{"logger":{"destination":"file","file":"%s","format":"json","level":"info","components":{"db":"fatal"}},"db":{"db_provider":"sqlite","db_name":"component","verbose_errors":true}}`, file)
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0600))
+
+	test_utils.SetupGormDB(t)
+	sqliteFolder := test_utils.SqliteFolder
+	test_utils.SqliteFolder = dir
+	t.Cleanup(func() { test_utils.SqliteFolder = sqliteFolder })
+
+	app := app_default.New(nil)
+	require.NoError(t, app.Init(configFile))
+	t.Cleanup(app.Close)
+	require.NoError(t, app.InitDB("db"))
+	ctx := test_utils.SimpleOpContext(app, "TestDatabaseComponent")
+	defer ctx.Close()
+
+	dbEntries := func() []map[string]interface{} {
+		entries := make([]map[string]interface{}, 0)
+		for _, line := range readLines(t, file) {
+			entry := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			if entry["msg"] == "GormDB" {
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+
+	// errors of database are not logged at level of db component
+	_, err := ctx.Db().FindByField(ctx, "id", "id1", &componentSample{})
+	require.Error(t, err)
+	assert.Empty(t, dbEntries())
+
+	// errors of database are logged with db component
+	_, err = log_level.NewLevelController(app.Logger()).SetLevel(ctx, &log_level.LogLevelCmd{LEVEL: "error", COMPONENT: db.LogComponent})
+	require.NoError(t, err)
+	_, err = ctx.Db().FindByField(ctx, "id", "id1", &componentSample{})
+	require.Error(t, err)
+	entries := dbEntries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, db.LogComponent, entries[0][logger.ComponentField])
+	assert.Equal(t, "TestDatabaseComponent", entries[0]["op"])
+}
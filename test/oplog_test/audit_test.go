@@ -0,0 +1,174 @@
+package oplog_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/admin"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit/audit_api/audit_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_audit/audit_api/audit_service"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_chain"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/user"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/evgeniums/go-backend-helpers/test/api_test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _, testBasePath, _, _ = runtime.Caller(0)
+var testDir = filepath.Dir(testBasePath)
+
+func dbModels() []interface{} {
+	return utils.ConcatSlices([]interface{}{}, admin.DbModels(), oplog_audit.DbModels(), oplog_chain.DbModels())
+}
+
+type memorySink struct {
+	mutex   sync.Mutex
+	records []*oplog.AuditRecord
+}
+
+func (m *memorySink) Write(ctx op_context.Context, record *oplog.AuditRecord) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.records = append(m.records, record)
+	return nil
+}
+
+type memoryPublisher struct {
+	mutex    sync.Mutex
+	messages map[string][]interface{}
+}
+
+func (m *memoryPublisher) Publish(topicName string, obj interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.messages == nil {
+		m.messages = make(map[string][]interface{})
+	}
+	m.messages[topicName] = append(m.messages[topicName], obj)
+	return nil
+}
+
+func (m *memoryPublisher) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestOplogType(t *testing.T) {
+	assert.Equal(t, "admin", oplog.OplogType(&admin.OpLogAdmin{}))
+	assert.Equal(t, "user", oplog.OplogType(&user.OpLogUser{}))
+	assert.Equal(t, "pub_key", oplog.OplogType(&signature.OpLogPubKey{}))
+}
+
+func TestAudit(t *testing.T) {
+
+	ctx := api_test.InitTest(t, "oplog", testDir, dbModels())
+	defer ctx.Close()
+
+	// setup audit
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx.ServerApp.Cfg().Set("oplog_audit.file", file)
+	ctx.ServerApp.Cfg().Set("oplog_audit.pubsub_topic", "audit")
+	publisher := &memoryPublisher{}
+	audit := oplog_audit.New()
+	require.NoError(t, audit.Init(ctx.ServerApp, publisher))
+	defer audit.Close()
+	sink := &memorySink{}
+	audit.AddSink(sink)
+	assert.Len(t, audit.Sinks(), 4)
+
+	app, ok := ctx.ServerApp.(op_context.AppWithOplogHandler)
+	require.True(t, ok)
+	app.SetOplogHandler(audit.OplogHandler())
+	defer app.SetOplogHandler(nil)
+
+	// write oplogs
+	opCtx := test_utils.SimpleOpContext(ctx.ServerApp, "audit")
+	_, err := ctx.LocalAdminManager.Add(opCtx, "admin1", "password1")
+	require.NoError(t, err)
+	require.NoError(t, ctx.LocalAdminManager.SetBlocked(opCtx, "admin1", true, true))
+	opCtx.Close()
+
+	// oplogs must be written to per type table
+	readCtx := test_utils.SimpleOpContext(ctx.ServerApp, "read")
+	defer readCtx.Close()
+	var adminOplogs []*admin.OpLogAdmin
+	_, err = db.DB(readCtx.Db()).FindWithFilter(readCtx, &db.Filter{Fields: db.Fields{"login": "admin1"}}, &adminOplogs)
+	require.NoError(t, err)
+	assert.Len(t, adminOplogs, 2)
+
+	// check sinks
+	require.Len(t, sink.records, 2)
+	assert.Equal(t, "add", sink.records[0].OPERATION)
+	assert.Equal(t, "set_blocked", sink.records[1].OPERATION)
+	assert.Equal(t, "admin", sink.records[0].TYPE)
+	assert.Equal(t, "audit", sink.records[0].CONTEXT_NAME)
+	assert.Contains(t, sink.records[0].DETAILS, `"login":"admin1"`)
+	assert.Len(t, publisher.messages["audit"], 2)
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	lines := 0
+	for scanner.Scan() {
+		record := &oplog.AuditRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		assert.Equal(t, sink.records[lines].GetID(), record.GetID())
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+
+	// search audit store
+	controller := oplog_audit.NewAuditController()
+	query := &oplog_audit.AuditQuery{Type: "admin", Operation: "set_blocked"}
+	filter, err := query.Filter()
+	require.NoError(t, err)
+	records, _, err := controller.List(readCtx, filter)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, sink.records[1].GetID(), records[0].GetID())
+
+	query = &oplog_audit.AuditQuery{From: time.Now().Add(time.Hour).UTC().Format("2006-01-02 15:04:05")}
+	filter, err = query.Filter()
+	require.NoError(t, err)
+	records, _, err = controller.List(readCtx, filter)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// search with API
+	api_server.AddServiceToServer(ctx.Server.ApiServer(), audit_service.NewAuditService(controller))
+	client := audit_client.NewAuditClient(ctx.RestApiClient)
+
+	query = &oplog_audit.AuditQuery{Type: "admin", From: time.Now().Add(-time.Hour).UTC().Format("2006-01-02 15:04:05")}
+	filter, err = query.Filter()
+	require.NoError(t, err)
+	filter.Count = true
+	records, count, err := client.List(ctx.ClientOp, filter)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	require.Len(t, records, 2)
+	// the latest records go first
+	assert.Equal(t, "set_blocked", records[0].OPERATION)
+	assert.Equal(t, "add", records[1].OPERATION)
+
+	query = &oplog_audit.AuditQuery{Operation: "add", User: "unknown"}
+	filter, err = query.Filter()
+	require.NoError(t, err)
+	records, _, err = client.List(ctx.ClientOp, filter)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
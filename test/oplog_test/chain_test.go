@@ -0,0 +1,221 @@
+package oplog_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/admin"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/oplog/oplog_chain"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/test/api_test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func problemKinds(report *oplog_chain.Report) []string {
+	kinds := make([]string, len(report.Problems))
+	for i, problem := range report.Problems {
+		kinds[i] = problem.Kind
+	}
+	return kinds
+}
+
+func prepareChainKeys(t *testing.T) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pubKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	privateKeyFile := filepath.Join(t.TempDir(), "private.pem")
+	publicKeyFile := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600))
+	require.NoError(t, os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey}), 0600))
+	return privateKeyFile, publicKeyFile
+}
+
+type testLeader struct {
+	leader atomic.Bool
+}
+
+func (l *testLeader) IsLeader() bool {
+	return l.leader.Load()
+}
+
+func TestChain(t *testing.T) {
+
+	ctx := api_test.InitTest(t, "oplog", testDir, dbModels())
+	defer ctx.Close()
+
+	// prepare keys
+	privateKeyFile, publicKeyFile := prepareChainKeys(t)
+
+	// setup chain
+	cfg := ctx.ServerApp.Cfg()
+	cfg.Set("oplog_chain.private_key_file", privateKeyFile)
+	cfg.Set("oplog_chain.public_key_file", publicKeyFile)
+	cfg.Set("oplog_chain.checkpoint_interval", 2)
+	chain := oplog_chain.New()
+	require.NoError(t, chain.Init(cfg, ctx.ServerApp.Logger(), ctx.ServerApp.Validator()))
+	chain.AddModels(&admin.OpLogAdmin{})
+
+	app, ok := ctx.ServerApp.(op_context.AppWithOplogHandler)
+	require.True(t, ok)
+	app.SetOplogHandler(chain.OplogHandler())
+	defer app.SetOplogHandler(nil)
+
+	// write oplogs
+	opCtx := test_utils.SimpleOpContext(ctx.ServerApp, "chain")
+	_, err := ctx.LocalAdminManager.Add(opCtx, "admin1", "password1")
+	require.NoError(t, err)
+	_, err = ctx.LocalAdminManager.Add(opCtx, "admin2", "password2")
+	require.NoError(t, err)
+	require.NoError(t, ctx.LocalAdminManager.SetBlocked(opCtx, "admin1", true, true))
+	opCtx.Close()
+
+	// verify intact chain
+	readCtx := test_utils.SimpleOpContext(ctx.ServerApp, "verify")
+	defer readCtx.Close()
+	chains, err := chain.Chains(readCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, chains)
+	report, err := chain.Verify(readCtx, "admin")
+	require.NoError(t, err)
+	assert.True(t, report.Ok(), "problems: %v", problemKinds(report))
+	assert.Equal(t, 3, report.Records)
+	assert.Equal(t, 1, report.Checkpoints)
+	assert.Equal(t, int64(3), report.HeadSeq)
+
+	// make checkpoint explicitly
+	checkpoint, err := chain.Checkpoint(readCtx, "admin")
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, int64(3), checkpoint.SEQ)
+	checkpoint, err = chain.Checkpoint(readCtx, "admin")
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	// modify record
+	database := db.DB(readCtx.Db())
+	require.NoError(t, database.Update(readCtx, &admin.OpLogAdmin{}, db.Fields{"chain_seq": 2}, db.Fields{"login": "intruder"}))
+	report, err = chain.Verify(readCtx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{oplog_chain.ProblemModified}, problemKinds(report))
+	assert.Equal(t, int64(2), report.Problems[0].Seq)
+
+	// rewrite hash of modified record
+	modified := &admin.OpLogAdmin{}
+	found, err := database.FindByField(readCtx, "chain_seq", 2, modified)
+	require.NoError(t, err)
+	require.True(t, found)
+	hash, err := oplog_chain.Hash(modified)
+	require.NoError(t, err)
+	require.NoError(t, database.Update(readCtx, &admin.OpLogAdmin{}, db.Fields{"chain_seq": 2}, db.Fields{"hash": hash}))
+	report, err = chain.Verify(readCtx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{oplog_chain.ProblemBrokenLink, oplog_chain.ProblemCheckpoint}, problemKinds(report))
+	assert.Equal(t, int64(3), report.Problems[0].Seq)
+	assert.Equal(t, int64(2), report.Problems[1].Seq)
+
+	// delete first and last records
+	require.NoError(t, database.DeleteByFields(readCtx, db.Fields{"chain_seq": 1}, &admin.OpLogAdmin{}))
+	require.NoError(t, database.DeleteByFields(readCtx, db.Fields{"chain_seq": 3}, &admin.OpLogAdmin{}))
+	report, err = chain.Verify(readCtx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{oplog_chain.ProblemGap, oplog_chain.ProblemHead, oplog_chain.ProblemCheckpoint}, problemKinds(report))
+	assert.Equal(t, 1, report.Records)
+
+	// forged checkpoint
+	forged := &oplog_chain.Checkpoint{}
+	forged.InitObject()
+	forged.CHAIN = "admin"
+	forged.SEQ = 2
+	forged.HASH = modified.Hash()
+	forged.SIGNATURE = "forged"
+	require.NoError(t, database.Create(readCtx, forged))
+	reports, err := chain.VerifyAll(readCtx)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, []string{oplog_chain.ProblemGap, oplog_chain.ProblemHead, oplog_chain.ProblemCheckpoint, oplog_chain.ProblemCheckpoint}, problemKinds(reports[0]))
+	forgedFound := false
+	for _, problem := range reports[0].Problems {
+		if strings.Contains(problem.Message, "invalid signature") {
+			forgedFound = true
+		}
+	}
+	assert.True(t, forgedFound)
+}
+
+func TestChainCheckpointJob(t *testing.T) {
+
+	ctx := api_test.InitTest(t, "oplog", testDir, dbModels())
+	defer ctx.Close()
+
+	// setup chain without automatic checkpoints
+	privateKeyFile, publicKeyFile := prepareChainKeys(t)
+	cfg := ctx.ServerApp.Cfg()
+	cfg.Set("oplog_chain.private_key_file", privateKeyFile)
+	cfg.Set("oplog_chain.public_key_file", publicKeyFile)
+	cfg.Set("oplog_chain.checkpoint_interval", 0)
+	cfg.Set("oplog_chain.checkpoint_period", 1)
+	chain := oplog_chain.New()
+	require.NoError(t, chain.Init(cfg, ctx.ServerApp.Logger(), ctx.ServerApp.Validator()))
+	chain.AddModels(&admin.OpLogAdmin{})
+
+	app, ok := ctx.ServerApp.(op_context.AppWithOplogHandler)
+	require.True(t, ok)
+	app.SetOplogHandler(chain.OplogHandler())
+	defer app.SetOplogHandler(nil)
+
+	// write oplogs
+	opCtx := test_utils.SimpleOpContext(ctx.ServerApp, "chain")
+	_, err := ctx.LocalAdminManager.Add(opCtx, "admin1", "password1")
+	require.NoError(t, err)
+	require.NoError(t, ctx.LocalAdminManager.SetBlocked(opCtx, "admin1", true, true))
+	opCtx.Close()
+
+	readCtx := test_utils.SimpleOpContext(ctx.ServerApp, "verify")
+	defer readCtx.Close()
+	countCheckpoints := func() int64 {
+		filter := db.NewFilter()
+		filter.AddField("chain", "admin")
+		filter.AddField("seq", 2)
+		filter.Count = true
+		count, err := db.DB(readCtx.Db()).FindWithFilter(readCtx, filter, &[]*oplog_chain.Checkpoint{})
+		require.NoError(t, err)
+		return count
+	}
+
+	// job does not make checkpoints when instance is not a leader
+	leader := &testLeader{}
+	chain.Start(ctx.ServerApp, leader)
+	defer chain.Close()
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int64(0), countCheckpoints())
+
+	// leader makes checkpoint of head of chain once
+	leader.leader.Store(true)
+	assert.Eventually(t, func() bool { return countCheckpoints() == 1 }, 5*time.Second, 100*time.Millisecond)
+	time.Sleep(2500 * time.Millisecond)
+	assert.Equal(t, int64(1), countCheckpoints())
+	chain.Close()
+
+	// chain with checkpoint made by job is intact
+	report, err := chain.Verify(readCtx, "admin")
+	require.NoError(t, err)
+	assert.True(t, report.Ok(), "problems: %v", problemKinds(report))
+	assert.Equal(t, 1, report.Checkpoints)
+
+	// nothing to checkpoint
+	checkpoints, err := chain.CheckpointAll(readCtx)
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
@@ -0,0 +1,169 @@
+package pool_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/test/pool_api_test/pool_test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const poolsDocument = `
+services:
+  - name: service1
+    type_name: database
+    provider: sqlite
+    db_name: db1
+    secret1: password1
+    active: true
+  - name: service2
+    type_name: cache
+    provider: redis
+    public_port: 6379
+    active: true
+pools:
+  - name: pool1
+    long_name: Pool 1
+    active: true
+    services:
+      database: service1
+      cache: service2
+  - name: pool2
+    active: false
+    services:
+      database: service1
+`
+
+func planActions(plan *pool.ImportPlan) []string {
+	actions := make([]string, len(plan.Items))
+	for i, item := range plan.Items {
+		actions[i] = item.String()
+	}
+	return actions
+}
+
+func exportPools(t *testing.T, ctx *pool_test_utils.PoolTestContext, redactSecrets bool) *pool.PoolsDocument {
+	doc, err := pool.ExportPools(ctx.LocalPoolController, ctx.AdminOp, redactSecrets)
+	require.NoError(t, err)
+	return doc
+}
+
+func TestImportExportPools(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+
+	doc, err := pool.UnmarshalPoolsDocument([]byte(poolsDocument), pool.DocumentFormatYaml)
+	require.NoError(t, err)
+
+	// plan import into empty database
+	plan, err := pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, doc, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"create service service1",
+		"create service service2",
+		"create pool pool1",
+		"create pool pool2",
+		"bind binding pool1 role=cache service=service2",
+		"bind binding pool1 role=database service=service1",
+		"bind binding pool2 role=database service=service1",
+	}, planActions(plan))
+	pools, _, err := ctx.LocalPoolController.GetPools(ctx.AdminOp, nil)
+	require.NoError(t, err)
+	assert.Empty(t, pools)
+
+	// import
+	_, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, doc)
+	require.NoError(t, err)
+	p1, err := pool.LoadPool(ctx.LocalPoolController, ctx.AdminOp, "pool1", true)
+	require.NoError(t, err)
+	require.NotNil(t, p1)
+	assert.Equal(t, "Pool 1", p1.LongName())
+	db1, err := p1.Service("database")
+	require.NoError(t, err)
+	assert.Equal(t, "service1", db1.ServiceName)
+	assert.Equal(t, "password1", db1.Secret1())
+	p2, err := ctx.LocalPoolController.FindPool(ctx.AdminOp, "pool2", true)
+	require.NoError(t, err)
+	require.NotNil(t, p2)
+	assert.False(t, p2.IsActive())
+
+	// import is idempotent
+	plan, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, doc)
+	require.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+
+	// export and import exported document
+	exported := exportPools(t, ctx, false)
+	require.Len(t, exported.Services, 2)
+	require.Len(t, exported.Pools, 2)
+	assert.Equal(t, "password1", exported.Services[0].Secret1())
+	assert.Equal(t, map[string]string{"database": "service1", "cache": "service2"}, exported.Pools[0].Services)
+	for _, format := range []string{pool.DocumentFormatYaml, pool.DocumentFormatJson} {
+		data, err := pool.MarshalPoolsDocument(exported, format)
+		require.NoError(t, err)
+		parsed, err := pool.UnmarshalPoolsDocument(data, format)
+		require.NoError(t, err)
+		assert.Equal(t, exported, parsed)
+		plan, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, parsed, true)
+		require.NoError(t, err)
+		assert.True(t, plan.IsEmpty(), format)
+	}
+
+	// redacted secrets do not overwrite existing secrets
+	redacted := exportPools(t, ctx, true)
+	assert.Equal(t, pool.MaskedSecret, redacted.Services[0].Secret1())
+	assert.Equal(t, "", redacted.Services[1].Secret1())
+	redacted.Services[0].SetLongName("Service 1")
+	redacted.Pools[0].Services = map[string]string{"database": "service2"}
+	redacted.Pools[1].SetDescription("Second pool")
+	plan, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, redacted)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"update service service1 fields=long_name",
+		"unbind binding pool1 role=cache service=service2",
+		"unbind binding pool1 role=database service=service1",
+		"update pool pool2 fields=description",
+		"bind binding pool1 role=database service=service2",
+	}, planActions(plan))
+	s1, err := ctx.LocalPoolController.FindService(ctx.AdminOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, s1)
+	assert.Equal(t, "Service 1", s1.LongName())
+	assert.Equal(t, "password1", s1.Secret1())
+	p1, err = pool.LoadPool(ctx.LocalPoolController, ctx.AdminOp, "pool1", true)
+	require.NoError(t, err)
+	require.Len(t, p1.(*pool.PoolBase).Services, 1)
+	db1, err = p1.Service("database")
+	require.NoError(t, err)
+	assert.Equal(t, "service2", db1.ServiceName)
+
+	// invalid documents are rejected
+	invalid := exportPools(t, ctx, true)
+	invalid.Pools[0].Services["cache"] = "unknown"
+	_, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, invalid)
+	assert.Error(t, err)
+	ctx.AdminOp.ClearError()
+	invalid = exportPools(t, ctx, true)
+	invalid.Services = append(invalid.Services, invalid.Services[0])
+	_, err = pool.ImportPools(ctx.LocalPoolController, ctx.AdminOp, invalid)
+	assert.Error(t, err)
+	ctx.AdminOp.ClearError()
+
+	// failed import is rolled back
+	failed := exportPools(t, ctx, true)
+	p3 := &pool.PoolDocument{}
+	p3.SetName("pool3")
+	failed.Pools = append(failed.Pools, p3)
+	plan, err = pool.PlanImportPools(ctx.LocalPoolController, ctx.AdminOp, failed)
+	require.NoError(t, err)
+	require.Len(t, plan.Items, 1)
+	plan.Items = append(plan.Items, &pool.PlanItem{Action: pool.PlanActionBind, Object: pool.PlanObjectBinding, Name: "pool3", Role: "database", Service: "unknown"})
+	err = pool.ApplyImportPlan(ctx.LocalPoolController, ctx.AdminOp, plan)
+	assert.Error(t, err)
+	ctx.AdminOp.ClearError()
+	p3Found, err := ctx.LocalPoolController.FindPool(ctx.AdminOp, "pool3", true)
+	require.NoError(t, err)
+	assert.Nil(t, p3Found)
+}
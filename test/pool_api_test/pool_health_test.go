@@ -0,0 +1,209 @@
+package pool_api_test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool/pool_health"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pool_pubsub"
+	"github.com/evgeniums/go-backend-helpers/test/pool_api_test/pool_test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pubsubMock struct {
+	pool_pubsub.PoolPubsub
+	mutex         sync.Mutex
+	notifications []*pool.HealthNotification
+}
+
+func (p *pubsubMock) PublishPools(topicName string, msg interface{}, poolIds ...string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if topicName == pool.HealthTopicName {
+		p.notifications = append(p.notifications, msg.(*pool.HealthNotification))
+	}
+	return nil
+}
+
+func (p *pubsubMock) Notifications() []*pool.HealthNotification {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]*pool.HealthNotification{}, p.notifications...)
+}
+
+func addHealthService(t *testing.T, ctx *pool_test_utils.PoolTestContext, name string, typeName string, provider string, fill func(s *pool.PoolServiceBase)) pool.PoolService {
+	s := pool.NewService()
+	s.SetName(name)
+	s.SetTypeName(typeName)
+	s.PROVIDER = provider
+	s.SetActive(true)
+	if fill != nil {
+		fill(s)
+	}
+	added, err := ctx.LocalPoolController.AddService(ctx.AdminOp, s)
+	require.NoError(t, err)
+	require.NotNil(t, added)
+	return added
+}
+
+func checkHealth(t *testing.T, ctx *pool_test_utils.PoolTestContext, name string, status string) *pool.PoolServiceHealth {
+	health, err := ctx.RemotePoolController.FindServiceHealth(ctx.ClientOp, name, true)
+	require.NoError(t, err)
+	require.NotNil(t, health)
+	assert.Equal(t, status, health.Status(), name)
+	assert.Equal(t, name, health.ServiceName())
+	return health
+}
+
+func TestServiceHealth(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+
+	// prepare http server
+	var httpFailed atomic.Bool
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if httpFailed.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer httpServer.Close()
+
+	// add services
+	addHealthService(t, ctx, "database_up", pool.TypeDatabase, "sqlite", func(s *pool.PoolServiceBase) { s.DB_NAME = "pool_health_test" })
+	addHealthService(t, ctx, "database_down", pool.TypeDatabase, "postgres", func(s *pool.PoolServiceBase) {
+		s.PRIVATE_HOST = "127.0.0.1"
+		s.PRIVATE_PORT = 1
+		s.USER = "user"
+		s.DB_NAME = "db"
+	})
+	addHealthService(t, ctx, "api_server", pool.TypeApiServer, "api", func(s *pool.PoolServiceBase) { s.PRIVATE_URL = httpServer.URL })
+	addHealthService(t, ctx, "unknown", pool.TypeApiServer, "api", nil)
+	addHealthService(t, ctx, "inactive", pool.TypeApiServer, "api", func(s *pool.PoolServiceBase) {
+		s.PRIVATE_URL = httpServer.URL
+		s.SetActive(false)
+	})
+
+	// health is unknown before checks
+	checkHealth(t, ctx, "database_up", pool.HealthStatusUnknown)
+	_, err := ctx.RemotePoolController.FindServiceHealth(ctx.ClientOp, "some_service", true)
+	assert.Error(t, err)
+	ctx.ClientOp.Reset()
+
+	// check services
+	pubsub := &pubsubMock{}
+	monitor := pool_health.NewHealthMonitor(ctx.LocalPoolController, pubsub)
+	require.NoError(t, monitor.Init(ctx.ServerApp))
+	require.NoError(t, monitor.CheckServices(ctx.AdminOp))
+
+	checkHealth(t, ctx, "database_up", pool.HealthStatusUp)
+	down := checkHealth(t, ctx, "database_down", pool.HealthStatusDown)
+	assert.NotEmpty(t, down.LastError())
+	assert.False(t, down.CheckedAt().IsZero())
+	up := checkHealth(t, ctx, "api_server", pool.HealthStatusUp)
+	assert.Empty(t, up.LastError())
+	checkHealth(t, ctx, "unknown", pool.HealthStatusUnknown)
+	checkHealth(t, ctx, "inactive", pool.HealthStatusUnknown)
+
+	items, count, err := ctx.RemotePoolController.GetServicesHealth(ctx.ClientOp, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Len(t, items, 3)
+
+	filter := db.NewFilter()
+	filter.AddField("status", pool.HealthStatusDown)
+	items, _, err = ctx.RemotePoolController.GetServicesHealth(ctx.ClientOp, filter)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "database_down", items[0].ServiceName())
+
+	notifications := pubsub.Notifications()
+	assert.Len(t, notifications, 3)
+
+	// status not changed
+	require.NoError(t, monitor.CheckServices(ctx.AdminOp))
+	assert.Len(t, pubsub.Notifications(), 3)
+	upAgain := checkHealth(t, ctx, "api_server", pool.HealthStatusUp)
+	assert.Equal(t, up.StatusChangedAt().UnixMilli(), upAgain.StatusChangedAt().UnixMilli())
+
+	// status changed
+	httpFailed.Store(true)
+	require.NoError(t, monitor.CheckServices(ctx.AdminOp))
+	downAgain := checkHealth(t, ctx, "api_server", pool.HealthStatusDown)
+	assert.Contains(t, downAgain.LastError(), "503")
+	notifications = pubsub.Notifications()
+	require.Len(t, notifications, 4)
+	assert.Equal(t, "api_server", notifications[3].ServiceName)
+	assert.Equal(t, pool.HealthStatusDown, notifications[3].Status)
+
+	// run in background worker
+	httpFailed.Store(false)
+	monitor.Worker().RunInBackground()
+	require.Eventually(t, func() bool { return len(pubsub.Notifications()) == 5 }, 5*time.Second, 10*time.Millisecond)
+	monitor.Worker().Stop()
+	checkHealth(t, ctx, "api_server", pool.HealthStatusUp)
+
+	// delete service with health
+	require.NoError(t, ctx.LocalPoolController.DeleteService(ctx.AdminOp, "api_server", true))
+	_, count, err = ctx.RemotePoolController.GetServicesHealth(ctx.ClientOp, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestDatabaseCheckTimeout(t *testing.T) {
+
+	ctx := initTest(t)
+	defer ctx.Close()
+
+	// server accepts connections but never responds
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	conns := make(chan net.Conn, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	service := pool.NewService()
+	service.SetName("database_silent")
+	service.SetTypeName(pool.TypeDatabase)
+	service.PROVIDER = "postgres"
+	service.PRIVATE_HOST = "127.0.0.1"
+	service.PRIVATE_PORT = uint16(listener.Addr().(*net.TCPAddr).Port)
+	service.USER = "user"
+	service.DB_NAME = "db"
+
+	checker := &pool_health.DatabaseChecker{}
+	started := time.Now()
+	err = checker.Check(ctx.AdminOp, service, 200*time.Millisecond)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), 2*time.Second)
+
+	// connection is closed by checker when check is timed out
+	select {
+	case conn := <-conns:
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+		_, err = io.Copy(io.Discard, conn)
+		assert.NoError(t, err, "connection must be closed by checker")
+	case <-time.After(time.Second):
+		require.Fail(t, "checker did not connect to database")
+	}
+}
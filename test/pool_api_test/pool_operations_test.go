@@ -31,6 +31,12 @@ func dbModels() []interface{} {
 }
 
 func initTest(t *testing.T) *pool_test_utils.PoolTestContext {
+	ctx, poolService := initTestWithService(t, "pool")
+	poolService.ShowSecrets = true
+	return ctx
+}
+
+func initTestWithService(t *testing.T, configPrefix string) (*pool_test_utils.PoolTestContext, *pool_service.PoolService) {
 
 	var appWithPools *app_with_pools.AppWithPoolsBase
 
@@ -50,14 +56,14 @@ func initTest(t *testing.T) *pool_test_utils.PoolTestContext {
 	test_utils.SetAppHandlers(buildApp, initApp)
 
 	ctx := &pool_test_utils.PoolTestContext{}
-	ctx.TestContext = api_test.InitTest(t, "pool", testDir, dbModels())
+	ctx.TestContext = api_test.InitTest(t, configPrefix, testDir, dbModels())
 	ctx.RemotePoolController = pool_client.NewPoolClient(ctx.RestApiClient)
 	ctx.LocalPoolController = appWithPools.Pools().PoolController()
 
 	poolService := pool_service.NewPoolService(ctx.LocalPoolController)
 	api_server.AddServiceToServer(ctx.Server.ApiServer(), poolService)
 
-	return ctx
+	return ctx, poolService
 }
 
 func TestInit(t *testing.T) {
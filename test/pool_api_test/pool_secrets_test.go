@@ -0,0 +1,209 @@
+package pool_api_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crud"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/test/pool_api_test/pool_test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsCipher(t *testing.T) {
+
+	cipher := pool.NewSecretsCipher()
+	require.NoError(t, cipher.AddKey("key1", "master secret 1"))
+	require.NoError(t, cipher.AddKey("key2", "master secret 2"))
+	require.NoError(t, cipher.SetKeyId("key1"))
+	assert.Error(t, cipher.SetKeyId("key3"))
+	assert.Error(t, cipher.AddKey("key$3", "master secret 3"))
+
+	// plaintext and empty values are decrypted as is
+	plaintext, err := cipher.DecryptSecret("plain secret")
+	require.NoError(t, err)
+	assert.Equal(t, "plain secret", plaintext)
+	encrypted, err := cipher.EncryptSecret("")
+	require.NoError(t, err)
+	assert.Equal(t, "", encrypted)
+
+	// only valid envelopes are treated as encrypted secrets
+	for _, value := range []string{"enc:c2VjcmV0", "enc:key1:a2V5:c2VjcmV0", pool.EncryptedSecretPrefix + "key1$a2V5$c2VjcmV0", pool.EncryptedSecretPrefix + "key1$not base64$"} {
+		assert.False(t, pool.IsEncryptedSecret(value), value)
+		plaintext, err = cipher.DecryptSecret(value)
+		require.NoError(t, err)
+		assert.Equal(t, value, plaintext)
+	}
+
+	// encrypt and decrypt
+	encrypted, err = cipher.EncryptSecret("secret value")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encrypted, pool.EncryptedSecretPrefix+"key1$"))
+	assert.NotContains(t, encrypted, "secret value")
+	assert.True(t, pool.IsEncryptedSecret(encrypted))
+	decrypted, err := cipher.DecryptSecret(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret value", decrypted)
+
+	// re-encrypt with the same key
+	reencrypted, changed, err := cipher.ReencryptSecret(encrypted)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, encrypted, reencrypted)
+
+	// re-encrypt with new key
+	require.NoError(t, cipher.SetKeyId("key2"))
+	reencrypted, changed, err = cipher.ReencryptSecret(encrypted)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(reencrypted, pool.EncryptedSecretPrefix+"key2$"))
+	decrypted, err = cipher.DecryptSecret(reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret value", decrypted)
+
+	// re-encrypt plaintext
+	reencrypted, changed, err = cipher.ReencryptSecret("plain secret")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(reencrypted, pool.EncryptedSecretPrefix+"key2$"))
+
+	// decrypt with unknown key
+	other := pool.NewSecretsCipher()
+	require.NoError(t, other.AddKey("key1", "other master secret"))
+	_, err = other.DecryptSecret(reencrypted)
+	assert.Error(t, err)
+	_, err = other.DecryptSecret(encrypted)
+	assert.Error(t, err)
+}
+
+func checkRawSecrets(t *testing.T, ctx *pool_test_utils.PoolTestContext, name string, keyId string) {
+	raw := &pool.PoolServiceBase{}
+	found, err := ctx.AdminOp.Db().FindByField(ctx.AdminOp, "name", name, raw)
+	require.NoError(t, err)
+	require.True(t, found)
+	prefix := pool.EncryptedSecretPrefix + keyId + "$"
+	assert.True(t, strings.HasPrefix(raw.Secret1(), prefix))
+	assert.True(t, strings.HasPrefix(raw.Secret2(), prefix))
+}
+
+func TestServiceSecretsEncryption(t *testing.T) {
+
+	ctx, poolService := initTestWithService(t, "pool_secrets")
+	defer ctx.Close()
+
+	// add service
+	s := pool.NewService()
+	s.SetName("service1")
+	s.SetTypeName("type1")
+	s.PROVIDER = "provider1"
+	s.SECRET1 = "secret1"
+	s.SECRET2 = "secret2"
+	added, err := ctx.RemotePoolController.AddService(ctx.ClientOp, s)
+	require.NoError(t, err)
+	require.NotNil(t, added)
+	assert.Equal(t, pool.MaskedSecret, added.Secret1())
+	assert.Equal(t, pool.MaskedSecret, added.Secret2())
+
+	// secrets are encrypted in database
+	checkRawSecrets(t, ctx, "service1", "key1")
+
+	// secrets are decrypted by local controller
+	local, err := ctx.LocalPoolController.FindService(ctx.AdminOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, local)
+	assert.Equal(t, "secret1", local.Secret1())
+	assert.Equal(t, "secret2", local.Secret2())
+	services, _, err := ctx.LocalPoolController.GetServices(ctx.AdminOp, nil)
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "secret1", services[0].Secret1())
+
+	// secrets are masked in responses
+	remote, err := ctx.RemotePoolController.FindService(ctx.ClientOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, remote)
+	assert.Equal(t, pool.MaskedSecret, remote.Secret1())
+	assert.Equal(t, pool.MaskedSecret, remote.Secret2())
+	remoteServices, _, err := ctx.RemotePoolController.GetServices(ctx.ClientOp, nil)
+	require.NoError(t, err)
+	require.Len(t, remoteServices, 1)
+	assert.Equal(t, pool.MaskedSecret, remoteServices[0].Secret1())
+
+	// secrets are sent to privileged clients
+	poolService.ShowSecrets = true
+	remote, err = ctx.RemotePoolController.FindService(ctx.ClientOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, remote)
+	assert.Equal(t, "secret1", remote.Secret1())
+	assert.Equal(t, "secret2", remote.Secret2())
+
+	// update secrets
+	updated, err := ctx.RemotePoolController.UpdateService(ctx.ClientOp, "service1", db.Fields{"secret1": "new secret1"}, true)
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, "new secret1", updated.Secret1())
+	assert.Equal(t, "secret2", updated.Secret2())
+	checkRawSecrets(t, ctx, "service1", "key1")
+
+	// re-encrypt secrets with new key
+	secretsManager, ok := ctx.LocalPoolController.(pool.PoolSecretsManager)
+	require.True(t, ok)
+	count, err := secretsManager.ReencryptServiceSecrets(ctx.AdminOp, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	checkRawSecrets(t, ctx, "service1", "key2")
+	assert.Equal(t, "key1", secretsManager.SecretsCipher().KeyId())
+
+	// nothing to re-encrypt
+	count, err = secretsManager.ReencryptServiceSecrets(ctx.AdminOp, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	local, err = ctx.LocalPoolController.FindService(ctx.AdminOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, local)
+	assert.Equal(t, "new secret1", local.Secret1())
+	assert.Equal(t, "secret2", local.Secret2())
+
+	// unknown key
+	_, err = secretsManager.ReencryptServiceSecrets(ctx.AdminOp, "key3")
+	assert.Error(t, err)
+}
+
+func TestSecretsCipherNotInitialized(t *testing.T) {
+
+	ctx, _ := initTestWithService(t, "pool_secrets")
+	defer ctx.Close()
+
+	// controller can not store secrets before cipher is set
+	controller := pool.NewPoolController(&crud.DbCRUD{})
+	s := pool.NewService()
+	s.SetName("service1")
+	s.SetTypeName("type1")
+	s.PROVIDER = "provider1"
+	s.SECRET1 = "secret1"
+	_, err := controller.AddService(ctx.AdminOp, s)
+	assert.Error(t, err)
+
+	// secrets encrypted by controller with cipher
+	secretsManager, ok := ctx.LocalPoolController.(pool.PoolSecretsManager)
+	require.True(t, ok)
+	_, err = ctx.LocalPoolController.AddService(ctx.AdminOp, s)
+	require.NoError(t, err)
+	_, err = controller.FindService(ctx.AdminOp, "service1", true)
+	assert.Error(t, err)
+
+	// encrypted secrets are not returned as is if encryption is disabled
+	controller.SetSecretsCipher(nil)
+	_, err = controller.FindService(ctx.AdminOp, "service1", true)
+	assert.Error(t, err)
+
+	// secrets are decrypted with the same cipher
+	controller.SetSecretsCipher(secretsManager.SecretsCipher())
+	found, err := controller.FindService(ctx.AdminOp, "service1", true)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "secret1", found.Secret1())
+}
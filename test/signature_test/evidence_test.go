@@ -0,0 +1,113 @@
+package signature_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/signature_evidence"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, privateKey []byte, message []byte) string {
+	signer := crypt_utils.NewRsaSigner()
+	require.NoError(t, signer.LoadKey(privateKey, ""))
+	sig, err := crypt_utils.Sign(signer, message)
+	require.NoError(t, err)
+	return sig
+}
+
+func TestSignatureEvidence(t *testing.T) {
+	app, users, keys, manager, ctx := initSignatureTest(t)
+	onExit := func() {
+		ctx.Close()
+		app.Close()
+	}
+	defer onExit()
+
+	// prepare user with two keys
+	login := "user1"
+	user1, err := users.Add(ctx, login, "password1")
+	require.NoError(t, err)
+	privateKey1, publicKey1 := generateKeyPair(t)
+	key1Id, err := keys.AddLabeledPubKey(ctx, login, publicKey1, "laptop", time.Time{}, true)
+	require.NoError(t, err)
+	privateKey2, publicKey2 := generateKeyPair(t)
+	_, err = keys.AddLabeledPubKey(ctx, login, publicKey2, "phone", time.Time{}, true)
+	require.NoError(t, err)
+
+	// verify messages signed with the first key
+	message1 := []byte("message 1")
+	userCtx1 := test_utils.UserOpContext(app, "op1", user1)
+	require.NoError(t, manager.VerifyWithKey(userCtx1, key1Id, sign(t, privateKey1, message1), message1, "POST", "/op1"))
+	userCtx1.Close()
+
+	userCtx2 := test_utils.UserOpContext(app, "op2", user1)
+	err = manager.VerifyWithKey(userCtx2, key1Id, sign(t, privateKey2, message1), message1)
+	checkError(t, userCtx2, err, signature.ErrorCodeInvalidSignature)
+	userCtx2.Close()
+
+	message3 := []byte("message 3")
+	userCtx3 := test_utils.UserOpContext(app, "op3", user1)
+	require.NoError(t, manager.VerifyWithKey(userCtx3, crypt_utils.H256B64([]byte(publicKey2)), sign(t, privateKey2, message3), message3))
+	userCtx3.Close()
+
+	k1, err := keys.FindUserPubKey(ctx, user1.GetID(), key1Id)
+	require.NoError(t, err)
+	assert.False(t, k1.PubKeyLastUsedAt().IsZero())
+
+	// flush oplogs of setup context
+	ctx.Close()
+	ctx = test_utils.SimpleOpContext(app, "export")
+
+	// init exporter
+	issuerPrivateKey, issuerPublicKey := generateKeyPair(t)
+	issuerKeyFile := filepath.Join(t.TempDir(), "issuer.pem")
+	require.NoError(t, os.WriteFile(issuerKeyFile, issuerPrivateKey, 0600))
+	app.Cfg().Set("signature.evidence.private_key_file", issuerKeyFile)
+	app.Cfg().Set("signature.evidence.issuer", "test_issuer")
+	exporter := signature_evidence.NewExporter(manager, keys)
+	require.NoError(t, exporter.Init(app.Cfg(), app.Logger(), app.Validator()))
+
+	// export by context
+	pkg, err := exporter.Export(ctx, &signature_evidence.Query{Context: userCtx1.ID()})
+	require.NoError(t, err)
+	evidence, err := signature_evidence.VerifyPackage(pkg.Evidence, pkg.Signature, []byte(issuerPublicKey))
+	require.NoError(t, err)
+	assert.Equal(t, "test_issuer", evidence.Issuer)
+	require.Len(t, evidence.Messages, 1)
+	msg := evidence.Messages[0]
+	raw, err := msg.RawMessage()
+	require.NoError(t, err)
+	assert.Equal(t, message1, raw)
+	assert.Equal(t, publicKey1, msg.PubKey)
+	assert.Equal(t, key1Id, msg.PubKeyId)
+	assert.Equal(t, "POST+/op1", msg.ExtraData)
+	require.NotEmpty(t, msg.KeyHistory)
+	assert.Equal(t, "add_pubkey", msg.KeyHistory[0].Operation())
+
+	// export by user and time range
+	pkg, err = exporter.Export(ctx, &signature_evidence.Query{UserId: user1.GetID(), From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	evidence, err = signature_evidence.VerifyPackage(pkg.Evidence, pkg.Signature, []byte(issuerPublicKey))
+	require.NoError(t, err)
+	require.Len(t, evidence.Messages, 2)
+	assert.Equal(t, publicKey2, evidence.Messages[1].PubKey)
+
+	// tampered evidence must be rejected
+	tampered := append([]byte{}, pkg.Evidence...)
+	tampered[len(tampered)-2] = ' '
+	_, err = signature_evidence.VerifyPackage(tampered, pkg.Signature, []byte(issuerPublicKey))
+	assert.Error(t, err)
+	_, err = signature_evidence.VerifyPackage(pkg.Evidence, pkg.Signature, []byte(publicKey1))
+	assert.Error(t, err)
+
+	// tampered message must be rejected
+	evidence.Messages[0].Message = "bWVzc2FnZSAy"
+	assert.Error(t, signature_evidence.VerifyMessage(evidence.Messages[0]))
+}
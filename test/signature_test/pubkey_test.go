@@ -0,0 +1,172 @@
+package signature_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/app_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/crypt_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature"
+	"github.com/evgeniums/go-backend-helpers/pkg/signature/user_pubkey"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/user"
+	"github.com/evgeniums/go-backend-helpers/pkg/user/user_default"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _, testBasePath, _, _ = runtime.Caller(0)
+var testDir = filepath.Dir(testBasePath)
+
+type User = user_default.User
+type Pubkey = user_pubkey.UserPubkey
+type PubkeyController = user_pubkey.PubkeyControllerBase[*Pubkey, *User]
+
+func dbModels() []interface{} {
+	return append(signature.DbModels(), &User{}, &user.OpLogUser{}, &Pubkey{})
+}
+
+func initTest(t *testing.T) (app_context.Context, *user_default.Users, *PubkeyController, op_context.Context) {
+	app, users, keys, _, ctx := initSignatureTest(t)
+	return app, users, keys, ctx
+}
+
+func initSignatureTest(t *testing.T) (app_context.Context, *user_default.Users, *PubkeyController, *signature.SignatureManagerBase, op_context.Context) {
+	app := test_utils.InitAppContext(t, testDir, dbModels(), "signature_test.json")
+
+	users := user_default.NewUsers()
+	users.Init(app.Validator())
+
+	manager := signature.NewSignatureManager()
+	require.NoError(t, manager.Init(app.Cfg(), app.Logger(), app.Validator()))
+
+	keys := user_pubkey.NewPubkeyController[*Pubkey, *User](func() *Pubkey { return &Pubkey{} }, manager)
+	keys.SetUserFinder(users)
+	manager.SetPubKeyStore(keys)
+
+	ctx := test_utils.SimpleOpContext(app, t.Name())
+
+	return app, users, keys, manager, ctx
+}
+
+func generateKeyPair(t *testing.T) (privateKey []byte, publicKey string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pubBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	publicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes}))
+	return
+}
+
+func generateKey(t *testing.T) string {
+	_, publicKey := generateKeyPair(t)
+	return publicKey
+}
+
+func checkError(t *testing.T, ctx op_context.Context, err error, expectedCode string) {
+	require.Error(t, err)
+	require.NotNil(t, ctx.GenericError())
+	assert.Equal(t, expectedCode, ctx.GenericError().Code())
+	ctx.SetGenericError(nil)
+}
+
+func TestMultiplePubkeys(t *testing.T) {
+	app, users, keys, ctx := initTest(t)
+	onExit := func() {
+		ctx.Close()
+		app.Close()
+	}
+	defer onExit()
+
+	login := "user1"
+	user1, err := users.Add(ctx, login, "password1")
+	require.NoError(t, err)
+
+	// add two keys, both must be active
+	key1 := generateKey(t)
+	key1Id, err := keys.AddLabeledPubKey(ctx, login, key1, "laptop", time.Time{}, true)
+	require.NoError(t, err)
+	key2 := generateKey(t)
+	key2Id, err := keys.AddLabeledPubKey(ctx, user1.GetID(), key2, "phone", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = keys.AddLabeledPubKey(ctx, login, key1, "duplicate", time.Time{}, true)
+	checkError(t, ctx, err, user_pubkey.ErrorCodeDuplicateKey)
+
+	filter := db.NewFilter()
+	filter.AddField("active", true)
+	active, count, err := keys.ListUserPubKeys(ctx, login, filter, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Len(t, active, 2)
+
+	// select keys by ID and by hash
+	k1, err := keys.FindUserPubKey(ctx, user1.GetID(), key1Id)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop", k1.PubKeyLabel())
+	k2, err := keys.FindUserPubKey(ctx, user1.GetID(), crypt_utils.H256B64([]byte(key2)))
+	require.NoError(t, err)
+	assert.Equal(t, key2Id, k2.GetID())
+	latest, err := keys.FindActivePubKey(ctx, login, true)
+	require.NoError(t, err)
+	assert.Equal(t, key2Id, latest.GetID())
+
+	// rename key
+	require.NoError(t, keys.RenamePubKey(ctx, login, key1Id, "desktop", true))
+	k1, err = keys.FindUserPubKey(ctx, user1.GetID(), key1Id)
+	require.NoError(t, err)
+	assert.Equal(t, "desktop", k1.PubKeyLabel())
+
+	// mark key as used
+	require.NoError(t, keys.MarkPubKeyUsed(ctx, key1Id))
+	k1, err = keys.FindUserPubKey(ctx, user1.GetID(), key1Id)
+	require.NoError(t, err)
+	assert.False(t, k1.PubKeyLastUsedAt().IsZero())
+
+	// expired key
+	key3 := generateKey(t)
+	key3Id, err := keys.AddLabeledPubKey(ctx, login, key3, "old", time.Now().Add(-time.Hour), true)
+	require.NoError(t, err)
+	_, err = keys.FindUserPubKey(ctx, user1.GetID(), key3Id)
+	checkError(t, ctx, err, user_pubkey.ErrorCodeKeyExpired)
+	k3, err := keys.FindPubKey(ctx, user1.GetID(), key3Id)
+	require.NoError(t, err)
+	require.NotNil(t, k3)
+	assert.True(t, k3.IsPubKeyExpired())
+
+	// revoke key
+	require.NoError(t, keys.DeactivatePubKey(ctx, login, key2Id, true))
+	_, err = keys.FindUserPubKey(ctx, user1.GetID(), key2Id)
+	checkError(t, ctx, err, user_pubkey.ErrorCodeActiveKeyNotFound)
+	k, err := keys.FindPubKey(ctx, user1.GetID(), key2Id)
+	require.NoError(t, err)
+	assert.Nil(t, k)
+	err = keys.DeactivatePubKey(ctx, login, key2Id, true)
+	checkError(t, ctx, err, user_pubkey.ErrorCodeActiveKeyNotFound)
+
+	// key without label and expiration
+	key4 := generateKey(t)
+	key4Id, err := keys.AddPubKey(ctx, login, key4, true)
+	require.NoError(t, err)
+	k4, err := keys.FindUserPubKey(ctx, user1.GetID(), key4Id)
+	require.NoError(t, err)
+	assert.Empty(t, k4.PubKeyLabel())
+	assert.False(t, k4.IsPubKeyExpired())
+	k1, err = keys.FindUserPubKey(ctx, user1.GetID(), key1Id)
+	require.NoError(t, err)
+	assert.True(t, k1.IsActive())
+
+	all, count, err := keys.ListUserPubKeys(ctx, login, nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	assert.Len(t, all, 4)
+}
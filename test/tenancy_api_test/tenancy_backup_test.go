@@ -0,0 +1,161 @@
+package tenancy_api_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitBackupJob(t *testing.T, ctx *TenancyTestContext, job *multitenancy.TenancyBackup) *multitenancy.TenancyBackup {
+	var err error
+	for i := 0; i < 100 && job.IsRunning(); i++ {
+		time.Sleep(50 * time.Millisecond)
+		job, err = ctx.RemoteTenancyController.FindBackup(ctx.ClientOp, job.GetID())
+		require.NoError(t, err)
+	}
+	require.False(t, job.IsRunning())
+	return job
+}
+
+func checkRestoredData(t *testing.T, ctx *TenancyTestContext, tenancyId string) {
+	tenancy, err := ctx.AppWithTenancy.Multitenancy().Tenancy(tenancyId)
+	require.NoError(t, err)
+	var samples []*InTenancySample
+	_, err = tenancy.Db().FindWithFilter(ctx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	assert.Len(t, samples, 5)
+	var items []*PartitionedItem
+	_, err = tenancy.Db().FindWithFilter(ctx.AdminOp, nil, &items)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestBackupRestoreTenancy(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+
+	// keep archives in temporary directory and restore data in small batches
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	manager.BACKUP_PATH = t.TempDir()
+	manager.MOVE_BATCH_SIZE = 2
+
+	// add tenancies
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	domain := "backup.example.com"
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), domain))
+
+	// fill tenancy database
+	loadedTenancy1, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		sample := &InTenancySample{Field1: fmt.Sprintf("sample%d", i), Field2: i}
+		sample.GenerateID()
+		require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, sample))
+	}
+	for i := 0; i < 3; i++ {
+		item := &PartitionedItem{Field4: fmt.Sprintf("item%d", i), Field5: i}
+		item.InitObject()
+		require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, item))
+	}
+
+	// backup tenancy
+	job, err := multiPoolCtx.RemoteTenancyController.Backup(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, multitenancy.BackupOperationBackup, job.OPERATION)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	require.Equal(t, multitenancy.BackupStatusDone, job.Status(), job.LAST_ERROR)
+	assert.Equal(t, int64(8), job.ROWS)
+	assert.False(t, job.FINISHED_AT.IsZero())
+	_, err = os.Stat(filepath.Join(manager.BACKUP_PATH, job.ARCHIVE))
+	require.NoError(t, err)
+	archive := job.ARCHIVE
+
+	// invalid and unknown archives
+	_, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: "../" + archive})
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyArchiveInvalid)
+	_, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: "unknown" + multitenancy.TenancyArchiveExtension})
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyArchiveNotFound)
+	_, err = multiPoolCtx.RemoteTenancyController.FindBackup(multiPoolCtx.ClientOp, "unknown")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyBackupNotFound)
+
+	// restore into existing empty tenancy
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, TARGET: tenancy2.GetID()})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	require.Equal(t, multitenancy.BackupStatusDone, job.Status(), job.LAST_ERROR)
+	assert.Equal(t, tenancy2.GetID(), job.TENANCY_ID)
+	assert.Equal(t, int64(8), job.ROWS)
+	checkRestoredData(t, multiPoolCtx, tenancy2.GetID())
+
+	// restore into tenancy with data fails
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, TARGET: tenancy2.GetID()})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	assert.Equal(t, multitenancy.BackupStatusFailed, job.Status())
+	assert.NotEmpty(t, job.LAST_ERROR)
+
+	// restore into new tenancy with other role, domain stays bound to original tenancy
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, ROLE: "restored", PATH: "restored"})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	require.Equal(t, multitenancy.BackupStatusDone, job.Status(), job.LAST_ERROR)
+	require.NotEmpty(t, job.TENANCY_ID)
+	assert.NotEqual(t, tenancy1.GetID(), job.TENANCY_ID)
+	restored, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, job.TENANCY_ID)
+	require.NoError(t, err)
+	assert.Equal(t, "restored", restored.Role())
+	assert.Equal(t, tenancy1.CustomerId(), restored.CustomerId())
+	checkRestoredData(t, multiPoolCtx, restored.GetID())
+	domains, err := multiPoolCtx.RemoteTenancyController.ListDomains(multiPoolCtx.ClientOp, restored.GetID())
+	require.NoError(t, err)
+	assert.Empty(t, domains)
+
+	// keeping ID of existing tenancy is not allowed
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, ROLE: "restored2", PATH: "restored2", KEEP_ID: true})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	assert.Equal(t, multitenancy.BackupStatusFailed, job.Status())
+
+	// restore of purged tenancy into its old database with data fails
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy1.GetID(), false))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Purge(multiPoolCtx.ClientOp, tenancy1.GetID()))
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, KEEP_ID: true})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	assert.Equal(t, multitenancy.BackupStatusFailed, job.Status())
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy1.GetID(), false))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Purge(multiPoolCtx.ClientOp, tenancy1.GetID()))
+
+	// restore purged tenancy into new database with the same ID and domain
+	job, err = multiPoolCtx.RemoteTenancyController.Restore(multiPoolCtx.ClientOp, &multitenancy.TenancyRestoreOptions{ARCHIVE: archive, KEEP_ID: true, DBNAME: "tenancy_customer1_dev_restored"})
+	require.NoError(t, err)
+	job = waitBackupJob(t, multiPoolCtx, job)
+	require.Equal(t, multitenancy.BackupStatusDone, job.Status(), job.LAST_ERROR)
+	assert.Equal(t, tenancy1.GetID(), job.TENANCY_ID)
+	restored, err = multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.Role(), restored.Role())
+	assert.Equal(t, tenancy1.Path(), restored.Path())
+	checkRestoredData(t, multiPoolCtx, tenancy1.GetID())
+	domains, err = multiPoolCtx.RemoteTenancyController.ListDomains(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	assert.Equal(t, domain, domains[0].Domain())
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
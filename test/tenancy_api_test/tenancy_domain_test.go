@@ -0,0 +1,106 @@
+package tenancy_api_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client/rest_api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server/rest_api_gin_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenancyDomains(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+
+	// add domains
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "Acme.Example.com"))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, "customer1/stage", "shop.example.com", true))
+	domains, err := multiPoolCtx.RemoteTenancyController.ListDomains(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	assert.Equal(t, "acme.example.com", domains[0].Domain())
+	assert.Equal(t, tenancy1.GetID(), domains[0].TENANCY_ID)
+
+	// domains must be unique
+	err = multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy2.GetID(), "acme.example.com")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyConflictDomain)
+	err = multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy2.GetID(), "not a domain")
+	assert.Error(t, err)
+	multiPoolCtx.ClientOp.ClearError()
+
+	// find tenancies by domains
+	found, err := multiPoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("ACME.example.com:8080")
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.GetID(), found.GetID())
+	assert.Equal(t, []string{"acme.example.com"}, found.Domains())
+	found, err = singlePoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("acme.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.GetID(), found.GetID())
+	_, err = singlePoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("shop.example.com")
+	assert.Error(t, err)
+
+	// add document to tenancy database
+	loadedTenancy1, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	sample1 := &InTenancySample{Field1: "hello world", Field2: 10}
+	sample1.GenerateID()
+	require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, sample1))
+
+	// resolve tenancy by host
+	server, ok := multiPoolCtx.Server.ApiServer().(*rest_api_gin_server.Server)
+	require.True(t, ok)
+	server.TENANCY_RESOLVER = rest_api_gin_server.TenancyResolverHost
+	api_server.AddServiceToServer(server, NewSampleService(), true)
+	restApiClient, ok := multiPoolCtx.RestApiClient.Transport().(rest_api_client.RestApiClient)
+	require.True(t, ok)
+	resp := &ListResponse{}
+	_, err = restApiClient.Get(multiPoolCtx.ClientOp, "/samples/sample", nil, resp, map[string]string{"Host": "acme.example.com"})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, sample1.GetID(), resp.Items[0].GetID())
+	resp = &ListResponse{}
+	_, err = restApiClient.Get(multiPoolCtx.ClientOp, "/samples/sample", nil, resp, map[string]string{"Host": "shop.example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Items)
+	httpResp, err := restApiClient.Get(multiPoolCtx.ClientOp, "/samples/sample", nil, nil, map[string]string{"Host": "unknown.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, httpResp.Code())
+
+	// resolve tenancy by header
+	server.TENANCY_RESOLVER = rest_api_gin_server.TenancyResolverHeader
+	resp = &ListResponse{}
+	_, err = restApiClient.Get(multiPoolCtx.ClientOp, "/samples/sample", nil, resp, map[string]string{server.TENANCY_HEADER: tenancy1.Path()})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 1)
+
+	// delete domain
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.DeleteDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "acme.example.com"))
+	_, err = multiPoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("acme.example.com")
+	assert.Error(t, err)
+	_, err = singlePoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("acme.example.com")
+	assert.Error(t, err)
+	err = multiPoolCtx.RemoteTenancyController.DeleteDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "shop.example.com")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyDomainNotFound)
+
+	// domains are released when tenancy is purged
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy2.GetID(), false))
+	_, err = multiPoolCtx.AppWithTenancy.Multitenancy().TenancyByDomain("shop.example.com")
+	assert.Error(t, err)
+	err = multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "shop.example.com")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyConflictDomain)
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Purge(multiPoolCtx.ClientOp, tenancy2.GetID()))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "shop.example.com"))
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
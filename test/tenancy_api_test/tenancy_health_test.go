@@ -0,0 +1,32 @@
+package tenancy_api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/pool"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolsHealthMonitor(t *testing.T) {
+
+	// health monitor is started only if it is configured
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t, "tenancy_health")
+	assert.Nil(t, singlePoolCtx.AppWithTenancy.HealthMonitor())
+	require.NotNil(t, multiPoolCtx.AppWithTenancy.HealthMonitor())
+
+	// services are checked when application starts
+	controller := multiPoolCtx.AppWithTenancy.Pools().PoolController()
+	require.Eventually(t, func() bool {
+		health, err := controller.FindServiceHealth(multiPoolCtx.AdminOp, "database_service1", true)
+		return err == nil && health.Status() == pool.HealthStatusUp
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// close apps
+	multiPoolCtx.Close()
+	assert.Nil(t, multiPoolCtx.AppWithTenancy.HealthMonitor())
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
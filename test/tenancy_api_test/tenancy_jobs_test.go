@@ -0,0 +1,64 @@
+package tenancy_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenancyJobHandler struct {
+	started chan bool
+	resume  chan bool
+}
+
+func (h *tenancyJobHandler) Handle(ctx op_context.Context, job *job_queue.Job) error {
+	h.started <- true
+	<-h.resume
+	sample := &InTenancySample{Field1: "job", Field2: 1}
+	sample.GenerateID()
+	return ctx.Db().Create(ctx, sample)
+}
+
+func TestTenancyJobs(t *testing.T) {
+
+	// prepare app with lazy loading of tenancies
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t, "tenancy_lazy")
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	tenancy1, _ := AddTenancies(t, multiPoolCtx)
+
+	jobs := job_queue.NewJobManager()
+	require.NoError(t, jobs.Init(multiPoolCtx.AppWithTenancy))
+	jobs.SetMultitenancy(manager)
+	handler := &tenancyJobHandler{started: make(chan bool), resume: make(chan bool)}
+	jobs.RegisterHandler("tenancy_job", handler)
+
+	job, err := jobs.Enqueue(multiPoolCtx.AdminOp, "tenancy_job", nil, &job_queue.JobOptions{TenancyId: tenancy1.GetID()})
+	require.NoError(t, err)
+
+	// database of tenancy is not closed if tenancy is unloaded while job is running
+	processed := make(chan int)
+	go func() {
+		count, _ := jobs.ProcessJobs(multiPoolCtx.AdminOp)
+		processed <- count
+	}()
+	<-handler.started
+	assert.True(t, manager.UnloadTenancy(tenancy1.GetID()))
+	handler.resume <- true
+	assert.Equal(t, 1, <-processed)
+	finished, err := jobs.Find(multiPoolCtx.AdminOp, job.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, job_queue.JobDone, finished.Status())
+	assert.Empty(t, finished.LastError())
+
+	// close apps
+	jobs.Close()
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,128 @@
+package tenancy_api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyTenancies(t *testing.T) {
+
+	// prepare app with lazy loading of tenancies
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t, "tenancy_lazy")
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	require.True(t, manager.LAZY_LOADING)
+
+	// added tenancies are indexed but not loaded
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	stats := manager.Stats()
+	assert.Equal(t, 2, stats.Known)
+	assert.Equal(t, 0, stats.Loaded)
+	assert.ElementsMatch(t, []string{tenancy1.GetID(), tenancy2.GetID()}, manager.TenancyIds())
+
+	// load tenancies on demand
+	loaded1, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.GetID(), loaded1.GetID())
+	assert.Len(t, manager.Tenancies(), 1)
+	sample := &InTenancySample{Field1: "sample1", Field2: 1}
+	sample.GenerateID()
+	require.NoError(t, loaded1.Db().Create(multiPoolCtx.AdminOp, sample))
+
+	// loading of other tenancy evicts least recently used tenancy
+	loaded2, err := manager.TenancyByPath(tenancy2.Path())
+	require.NoError(t, err)
+	assert.Equal(t, tenancy2.GetID(), loaded2.GetID())
+	stats = manager.Stats()
+	assert.Equal(t, 1, stats.Loaded)
+	assert.Equal(t, int64(2), stats.Loads)
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, []multitenancy.Tenancy{loaded2}, manager.Tenancies())
+
+	// evicted tenancy is loaded again with its data
+	loaded1, err = manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	found := &InTenancySample{}
+	ok, err = loaded1.Db().FindByField(multiPoolCtx.AdminOp, "id", sample.GetID(), found)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// lookup of loaded tenancy is counted as hit
+	_, err = manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	stats = manager.Stats()
+	assert.Equal(t, int64(3), stats.Loads)
+	assert.Equal(t, int64(1), stats.Hits)
+
+	// statistics are available in tenancy API
+	remoteStats, err := multiPoolCtx.RemoteTenancyController.Stats(multiPoolCtx.ClientOp)
+	require.NoError(t, err)
+	require.NotNil(t, remoteStats)
+	assert.Equal(t, stats, *remoteStats)
+
+	// evict idle tenancies
+	manager.IDLE_TIMEOUT = 1
+	manager.EvictIdleTenancies(multiPoolCtx.AdminOp)
+	assert.Equal(t, 1, manager.Stats().Loaded)
+	time.Sleep(1100 * time.Millisecond)
+	manager.EvictIdleTenancies(multiPoolCtx.AdminOp)
+	assert.Equal(t, 0, manager.Stats().Loaded)
+	assert.Equal(t, int64(3), manager.Stats().Evictions)
+
+	// database of tenancy that is unloaded while acquired is closed only after tenancy is released
+	acquired, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	require.True(t, manager.AcquireTenancy(acquired))
+	require.True(t, manager.AcquireTenancy(acquired))
+	assert.True(t, manager.UnloadTenancy(acquired.GetID()))
+	assert.Equal(t, 0, manager.Stats().Loaded)
+	manager.ReleaseTenancy(acquired)
+	ok, err = acquired.Db().FindByField(multiPoolCtx.AdminOp, "id", sample.GetID(), found)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	manager.ReleaseTenancy(acquired)
+	_, err = acquired.Db().FindByField(multiPoolCtx.AdminOp, "id", sample.GetID(), found)
+	assert.Error(t, err)
+	assert.False(t, manager.AcquireTenancy(acquired))
+
+	// index is updated with notifications
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetPath(multiPoolCtx.ClientOp, tenancy1.GetID(), "newpath"))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.AddDomain(multiPoolCtx.ClientOp, tenancy1.GetID(), "acme.example.com"))
+	assert.Equal(t, 0, manager.Stats().Loaded)
+	_, err = manager.TenancyByPath(tenancy1.Path())
+	assert.Error(t, err)
+	loaded1, err = manager.TenancyByPath("newpath")
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.GetID(), loaded1.GetID())
+	loaded1, err = manager.TenancyByDomain("acme.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, tenancy1.GetID(), loaded1.GetID())
+
+	// loaded tenancy is reloaded on notification
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Deactivate(multiPoolCtx.ClientOp, tenancy1.GetID()))
+	reloaded, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.False(t, reloaded.(*tenancy_manager.TenancyBase).TenancyDb.IsActive())
+	assert.Equal(t, 1, manager.Stats().Loaded)
+
+	// deleted tenancy is removed from index
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy2.GetID(), false))
+	_, err = manager.Tenancy(tenancy2.GetID())
+	assert.Error(t, err)
+	assert.Equal(t, 1, manager.Stats().Known)
+
+	// upgrade databases of all tenancies
+	err = multitenancy.UpgradeTenancyDatabases(multiPoolCtx.AdminOp, manager, tenancyDbModels())
+	require.NoError(t, err)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,106 @@
+package tenancy_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenancyMetering(t *testing.T) {
+
+	// prepare app with metering
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t, "tenancy_metering")
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	metering, ok := manager.Metering().(*tenancy_manager.TenancyMetering)
+	require.True(t, ok)
+	assert.Nil(t, singlePoolCtx.AppWithTenancy.Multitenancy().Metering())
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+
+	// limit requests of the first tenancy
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), multitenancy.QuotaKey(multitenancy.MetricRequests), "2"))
+
+	// add sample service
+	api_server.AddServiceToServer(multiPoolCtx.Server.ApiServer(), NewSampleService(), true)
+	sampleClient := NewSampleClient(multiPoolCtx.RestApiClient)
+	tenancyResource := api.NamedResource("tenancy")
+	tenancyResource.AddChild(sampleClient)
+
+	// requests are rejected when quota is exceeded
+	tenancyResource.SetId(tenancy1.Path())
+	for i := 0; i < 2; i++ {
+		_, err := sampleClient.List(multiPoolCtx.ClientOp)
+		require.NoError(t, err)
+	}
+	_, err := sampleClient.List(multiPoolCtx.ClientOp)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyQuotaExceeded)
+	multiPoolCtx.ClientOp.Reset()
+
+	// tenancy without quota is not limited
+	tenancyResource.SetId(tenancy2.Path())
+	for i := 0; i < 3; i++ {
+		_, err = sampleClient.List(multiPoolCtx.ClientOp)
+		require.NoError(t, err)
+	}
+
+	// check counted usage
+	month := utils.CurrentMonth()
+	usage, err := metering.Usage(multiPoolCtx.AdminOp, tenancy1.GetID(), multitenancy.MetricRequests, month)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), usage)
+	usage, err = metering.Usage(multiPoolCtx.AdminOp, tenancy2.GetID(), multitenancy.MetricRequests, month)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), usage)
+
+	// flush usage and measure storage, storage of tenancy that is not loaded is measured too
+	assert.True(t, manager.UnloadTenancy(tenancy2.GetID()))
+	meteringCtx := test_utils.SimpleOpContext(multiPoolCtx.AppWithTenancy, "metering")
+	require.NoError(t, metering.Flush(meteringCtx))
+	require.NoError(t, metering.MeasureStorage(meteringCtx))
+	meteringCtx.Close()
+	assert.Equal(t, 1, manager.Stats().Loaded)
+	usage, err = metering.Usage(multiPoolCtx.AdminOp, tenancy2.GetID(), multitenancy.MetricStorage, month)
+	require.NoError(t, err)
+	assert.Greater(t, usage, int64(0))
+
+	// usage is reloaded from database after flushing
+	usage, err = metering.Usage(multiPoolCtx.AdminOp, tenancy2.GetID(), multitenancy.MetricRequests, month)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), usage)
+
+	// report usage
+	filter := db.NewFilter()
+	filter.AddField("tenancy_id", tenancy1.GetID())
+	filter.SetSorting("metric")
+	usages, count, err := multiPoolCtx.RemoteTenancyController.ListUsage(multiPoolCtx.ClientOp, filter)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+	require.Len(t, usages, 3)
+	assert.Equal(t, multitenancy.MetricActiveUsers, usages[0].METRIC)
+	assert.Equal(t, int64(1), usages[0].VALUE)
+	assert.Equal(t, multitenancy.MetricRequests, usages[1].METRIC)
+	assert.Equal(t, int64(2), usages[1].VALUE)
+	assert.Equal(t, multitenancy.MetricStorage, usages[2].METRIC)
+	assert.Greater(t, usages[2].VALUE, int64(0))
+	assert.Equal(t, month, usages[2].Month)
+
+	// raise quota
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), multitenancy.QuotaKey(multitenancy.MetricRequests), "3"))
+	tenancyResource.SetId(tenancy1.Path())
+	_, err = sampleClient.List(multiPoolCtx.ClientOp)
+	require.NoError(t, err)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
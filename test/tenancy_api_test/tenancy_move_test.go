@@ -0,0 +1,220 @@
+package tenancy_api_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Wait until move of tenancy is finished.
+func waitMove(t *testing.T, ctx *TenancyTestContext, tenancyId string) *multitenancy.TenancyMove {
+	for i := 0; i < 100; i++ {
+		move, err := ctx.RemoteTenancyController.FindMove(ctx.ClientOp, tenancyId)
+		require.NoError(t, err)
+		require.NotNil(t, move)
+		if !move.IsRunning() {
+			return move
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.FailNow(t, "move of tenancy is not finished")
+	return nil
+}
+
+func TestMoveTenancy(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+
+	// use small batches to copy data
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	manager.MOVE_BATCH_SIZE = 2
+
+	// add tenancies
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	move, err := multiPoolCtx.RemoteTenancyController.FindMove(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Nil(t, move)
+
+	// fill tenancy database
+	loadedTenancy1, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		sample := &InTenancySample{Field1: fmt.Sprintf("sample%d", i), Field2: i}
+		sample.GenerateID()
+		require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, sample))
+	}
+	for i := 0; i < 3; i++ {
+		item := &PartitionedItem{Field4: fmt.Sprintf("item%d", i), Field5: i}
+		item.InitObject()
+		require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, item))
+	}
+
+	// moving to the same database is not allowed
+	_, err = multiPoolCtx.RemoteTenancyController.Move(multiPoolCtx.ClientOp, tenancy1.GetID(), "", "")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyMoveSameDatabase)
+
+	// moving to database of other tenancy fails and leaves tenancy in maintenance mode
+	move, err = multiPoolCtx.RemoteTenancyController.Move(multiPoolCtx.ClientOp, tenancy1.GetID(), tenancy2.PoolId(), tenancy2.DbName())
+	require.NoError(t, err)
+	require.NotNil(t, move)
+	move = waitMove(t, multiPoolCtx, tenancy1.GetID())
+	assert.Equal(t, multitenancy.MoveStatusFailed, move.Status())
+	assert.NotEmpty(t, move.LAST_ERROR)
+	found, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.True(t, found.IsMaintenance())
+	assert.Equal(t, tenancy1.PoolId(), found.PoolId())
+	singleAppTenancy, err := singlePoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.True(t, singleAppTenancy.IsMaintenance())
+
+	// move to other pool and database
+	newDb := "tenancy_customer1_dev_moved"
+	started, err := multiPoolCtx.RemoteTenancyController.Move(multiPoolCtx.ClientOp, tenancy1.GetID(), tenancy2.PoolId(), newDb)
+	require.NoError(t, err)
+	require.NotNil(t, started)
+	assert.True(t, started.IsRunning())
+	move = waitMove(t, multiPoolCtx, tenancy1.GetID())
+	assert.Equal(t, started.GetID(), move.GetID())
+	assert.True(t, move.IsDone())
+	assert.Equal(t, tenancy1.PoolId(), move.SOURCE_POOL_ID)
+	assert.Equal(t, tenancy1.DbName(), move.SOURCE_DBNAME)
+	assert.Equal(t, tenancy2.PoolId(), move.TARGET_POOL_ID)
+	assert.Equal(t, newDb, move.TARGET_DBNAME)
+	assert.Equal(t, int64(9), move.ROWS_COPIED)
+	assert.Equal(t, move.TABLES_TOTAL, move.TABLES_DONE)
+	assert.False(t, move.FINISHED_AT.IsZero())
+
+	// check tenancy
+	found, err = multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.False(t, found.IsMaintenance())
+	assert.Equal(t, tenancy2.PoolId(), found.PoolId())
+	assert.Equal(t, newDb, found.DbName())
+	_, err = singlePoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	assert.Error(t, err)
+	movedTenancy, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.False(t, movedTenancy.IsMaintenance())
+	assert.Equal(t, newDb, movedTenancy.DbName())
+
+	// check data in new database
+	var samples []*InTenancySample
+	filter := db.NewFilter()
+	filter.SetSorting("field2")
+	_, err = movedTenancy.Db().FindWithFilter(multiPoolCtx.AdminOp, filter, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 5)
+	assert.Equal(t, "sample4", samples[4].Field1)
+	var items []*PartitionedItem
+	_, err = movedTenancy.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &items)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	// check status of move
+	lastMove, err := multiPoolCtx.RemoteTenancyController.FindMove(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.NotNil(t, lastMove)
+	assert.Equal(t, move.GetID(), lastMove.GetID())
+	assert.True(t, lastMove.IsDone())
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
+
+func TestResumeMoveTenancy(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	manager.MOVE_BATCH_SIZE = 2
+
+	// add tenancies and fill tenancy database
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	loadedTenancy1, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		sample := &InTenancySample{Field1: fmt.Sprintf("sample%d", i), Field2: i}
+		sample.GenerateID()
+		require.NoError(t, loadedTenancy1.Db().Create(multiPoolCtx.AdminOp, sample))
+	}
+	var samples []*InTenancySample
+	filter := db.NewFilter()
+	filter.SetSorting("id")
+	_, err = loadedTenancy1.Db().FindWithFilter(multiPoolCtx.AdminOp, filter, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 5)
+
+	// simulate move interrupted after copying some rows
+	newDb := "tenancy_customer1_dev_resumed"
+	targetPool, err := manager.Pools.Pool(tenancy2.PoolId())
+	require.NoError(t, err)
+	target := tenancy_manager.NewTenancy(manager)
+	target.TenancyDb = tenancy1.TenancyDb
+	target.POOL_ID = targetPool.GetID()
+	target.DBNAME = newDb
+	target.TenancyBaseData.Pool = targetPool
+	require.NoError(t, target.ConnectDatabase(multiPoolCtx.AdminOp, true))
+	require.NoError(t, multitenancy.UpgradeTenancyDatabase(multiPoolCtx.AdminOp, target, tenancyDbModels()))
+	require.NoError(t, target.Db().Create(multiPoolCtx.AdminOp, samples[0]))
+	require.NoError(t, target.Db().Create(multiPoolCtx.AdminOp, samples[1]))
+	target.Db().Close()
+
+	interrupted := &multitenancy.TenancyMove{}
+	interrupted.InitObject()
+	interrupted.TENANCY_ID = tenancy1.GetID()
+	interrupted.SOURCE_POOL_ID = tenancy1.PoolId()
+	interrupted.SOURCE_DBNAME = tenancy1.DbName()
+	interrupted.TARGET_POOL_ID = targetPool.GetID()
+	interrupted.TARGET_DBNAME = newDb
+	interrupted.STATUS = multitenancy.MoveStatusCopying
+	interrupted.ROWS_COPIED = 2
+	require.NoError(t, multiPoolCtx.AdminOp.Db().Create(multiPoolCtx.AdminOp, interrupted))
+
+	// moving to other database is not allowed while move is unfinished
+	_, err = multiPoolCtx.RemoteTenancyController.Move(multiPoolCtx.ClientOp, tenancy1.GetID(), tenancy1.PoolId(), "tenancy_customer1_dev_other")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyMoveInProgress)
+
+	// resume move
+	resumed, err := multiPoolCtx.RemoteTenancyController.Move(multiPoolCtx.ClientOp, tenancy1.GetID(), "", "")
+	require.NoError(t, err)
+	require.NotNil(t, resumed)
+	assert.Equal(t, interrupted.GetID(), resumed.GetID())
+	move := waitMove(t, multiPoolCtx, tenancy1.GetID())
+	assert.Equal(t, interrupted.GetID(), move.GetID())
+	require.True(t, move.IsDone(), move.LAST_ERROR)
+	// two rows copied before interruption, tenancy meta and three remaining samples
+	assert.Equal(t, int64(6), move.ROWS_COPIED)
+
+	// check data in new database
+	movedTenancy, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, newDb, movedTenancy.DbName())
+	assert.False(t, movedTenancy.IsMaintenance())
+	var moved []*InTenancySample
+	_, err = movedTenancy.Db().FindWithFilter(multiPoolCtx.AdminOp, filter, &moved)
+	require.NoError(t, err)
+	require.Len(t, moved, 5)
+	for i, sample := range samples {
+		assert.Equal(t, sample.GetID(), moved[i].GetID())
+		assert.Equal(t, sample.Field1, moved[i].Field1)
+	}
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
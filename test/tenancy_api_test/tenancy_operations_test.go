@@ -12,6 +12,7 @@ import (
 	"github.com/evgeniums/go-backend-helpers/pkg/common"
 	"github.com/evgeniums/go-backend-helpers/pkg/customer"
 	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/job_queue"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/app_with_multitenancy"
 	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_api/tenancy_client"
@@ -64,7 +65,7 @@ type SampleModel1 struct {
 }
 
 func dbModels() []interface{} {
-	return utils.ConcatSlices([]interface{}{&SampleModel1{}}, admin.DbModels(), pool.DbModels(), customer.DbModels(), multitenancy.DbModels())
+	return utils.ConcatSlices([]interface{}{&SampleModel1{}}, admin.DbModels(), pool.DbModels(), customer.DbModels(), multitenancy.DbModels(), job_queue.DbModels())
 }
 
 type TenancyTestContext struct {
@@ -79,11 +80,15 @@ type TenancyTestContext struct {
 }
 
 func initContext(t *testing.T, newDb bool, configPrefix ...string) *TenancyTestContext {
+	return initContextWithModels(t, newDb, tenancyDbModels(), configPrefix...)
+}
+
+func initContextWithModels(t *testing.T, newDb bool, models *multitenancy.TenancyDbModels, configPrefix ...string) *TenancyTestContext {
 
 	var appWithTenancy *app_with_multitenancy.AppWithMultitenancyBase
 
 	buildApp := func(t *testing.T, buildConfig *app_context.BuildConfig) app_context.Context {
-		appWithTenancy = app_with_multitenancy.NewApp(buildConfig, tenancyDbModels())
+		appWithTenancy = app_with_multitenancy.NewApp(buildConfig, models)
 		return appWithTenancy
 	}
 	initApp := func(t *testing.T, app app_context.Context, configFile string, args []string, configType ...string) error {
@@ -100,6 +105,9 @@ func initContext(t *testing.T, newDb bool, configPrefix ...string) *TenancyTestC
 	ctx := &TenancyTestContext{}
 	ctx.PoolTestContext = &pool_test_utils.PoolTestContext{}
 	ctx.TestContext = api_test.InitTest(t, utils.OptionalArg("tenancy", configPrefix...), testDir, dbModels(), newDb)
+	if newDb {
+		test_utils.CreatePartitionedDbModels(t, ctx.ServerApp, multitenancy.PartitionedDbModels())
+	}
 	require.NotNil(t, appWithTenancy)
 	ctx.LocalPoolController = appWithTenancy.Pools().PoolController()
 	ctx.RemotePoolController = pool_client.NewPoolClient(ctx.RestApiClient)
@@ -109,6 +117,7 @@ func initContext(t *testing.T, newDb bool, configPrefix ...string) *TenancyTestC
 	ctx.RemoteTenancyController = tenancy_client.NewTenancyClient(ctx.RestApiClient)
 
 	poolService := pool_service.NewPoolService(appWithTenancy.Pools().PoolController())
+	poolService.ShowSecrets = true
 	api_server.AddServiceToServer(ctx.Server.ApiServer(), poolService)
 
 	tenancyService := tenancy_service.NewTenancyService(ctx.LocalTenancyController)
@@ -219,11 +228,15 @@ func preparePoolAndServices(t *testing.T, activatePools bool) (p1 pool.Pool, poo
 }
 
 func PrepareAppWithTenancies(t *testing.T, multiPoolConfig ...string) (multiPoolCtx *TenancyTestContext, singlePoolCtx *TenancyTestContext) {
+	return prepareAppWithTenancyModels(t, tenancyDbModels(), multiPoolConfig...)
+}
+
+func prepareAppWithTenancyModels(t *testing.T, models *multitenancy.TenancyDbModels, multiPoolConfig ...string) (multiPoolCtx *TenancyTestContext, singlePoolCtx *TenancyTestContext) {
 
 	preparePoolAndServices(t, true)
 
-	multiPoolCtx = initContext(t, false, multiPoolConfig...)
-	singlePoolCtx = initContext(t, false, "tenancy_single")
+	multiPoolCtx = initContextWithModels(t, false, models, multiPoolConfig...)
+	singlePoolCtx = initContextWithModels(t, false, models, "tenancy_single")
 
 	customer1, err := multiPoolCtx.LocalCustomerManager.Add(multiPoolCtx.AdminOp, "customer1", "12345678")
 	require.NoError(t, err)
@@ -629,7 +642,23 @@ func TestFindDelete(t *testing.T) {
 	require.NotNil(t, multiAppTenancy2)
 	assert.Equal(t, tenancy2.DbName(), multiAppTenancy2.DbName())
 
-	// try to find deleted tenancy
+	// deleted tenancy can be found until it is purged
+	deleted, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, id, true)
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.False(t, deleted.IsActive())
+	err = multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, id, false, true)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyDeleted)
+	err = multiPoolCtx.RemoteTenancyController.Activate(multiPoolCtx.ClientOp, id, true)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyDeleted)
+
+	// purge deleted tenancy
+	err = multiPoolCtx.RemoteTenancyController.Purge(multiPoolCtx.ClientOp, tenancy2.GetID())
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyNotDeleted)
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Purge(multiPoolCtx.ClientOp, id, true))
+
+	// try to find purged tenancy
+	multiPoolCtx.ClientOp.Reset()
 	_, err = multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, id, true)
 	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyNotFound)
 
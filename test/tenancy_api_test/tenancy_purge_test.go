@@ -0,0 +1,117 @@
+package tenancy_api_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/generic_error"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUndeletePurge(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithSharedTenancies(t)
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+
+	// add tenancies
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	shared1 := addSharedTenancy(t, multiPoolCtx, "customer1")
+	shared2 := addSharedTenancy(t, multiPoolCtx, "customer2")
+	for _, tenancy := range []multitenancy.Tenancy{shared1, shared2} {
+		sample := &SharedSample{Field1: "shared", Field2: 1}
+		sample.GenerateID()
+		require.NoError(t, tenancy.Db().Create(multiPoolCtx.AdminOp, sample))
+	}
+	dbPath1 := test_utils.SqliteDbPath(tenancy1.DbName())
+	_, err := os.Stat(dbPath1)
+	require.NoError(t, err)
+
+	// dropping of databases is disabled by default
+	err = multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy1.GetID(), true)
+	test_utils.CheckGenericError(t, err, generic_error.ErrorCodeUnsupported)
+	multiPoolCtx.ClientOp.Reset()
+	manager.DROP_DATABASES = true
+
+	// deleted tenancy is not accessible in applications
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy1.GetID(), true))
+	_, err = multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	assert.Error(t, err)
+	_, err = singlePoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	assert.Error(t, err)
+	deleted, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.True(t, deleted.IsPurgeDatabase())
+
+	// undeleted tenancy is loaded again but stays inactive
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Undelete(multiPoolCtx.ClientOp, tenancy1.GetID()))
+	undeleted, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.False(t, undeleted.IsDeleted())
+	assert.False(t, undeleted.IsPurgeDatabase())
+	assert.False(t, undeleted.IsActive())
+	loaded, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.False(t, loaded.IsActive())
+	err = multiPoolCtx.RemoteTenancyController.Undelete(multiPoolCtx.ClientOp, tenancy1.GetID())
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyNotDeleted)
+	multiPoolCtx.ClientOp.Reset()
+
+	// delete tenancies
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy1.GetID(), true))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, tenancy2.GetID(), false))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.Delete(multiPoolCtx.ClientOp, shared1.GetID(), true))
+
+	// tenancies are not purged during grace period
+	count, err := manager.PurgeDeletedTenancies(multiPoolCtx.AdminOp)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	_, err = multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+
+	// purge tenancies after grace period
+	manager.DELETION_GRACE_PERIOD = 0
+	purgeCtx := test_utils.SimpleOpContext(multiPoolCtx.AppWithTenancy, "purge")
+	count, err = manager.PurgeDeletedTenancies(purgeCtx)
+	purgeCtx.Close()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	for _, id := range []string{tenancy1.GetID(), tenancy2.GetID(), shared1.GetID()} {
+		_, err = multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, id)
+		test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyNotFound)
+		multiPoolCtx.ClientOp.Reset()
+	}
+
+	// database is dropped only if requested
+	_, err = os.Stat(dbPath1)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(test_utils.SqliteDbPath(tenancy2.DbName()))
+	assert.NoError(t, err)
+
+	// data of other tenancies in shared database is kept
+	var samples []*SharedSample
+	_, err = shared2.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	assert.Len(t, samples, 1)
+
+	// purging is recorded in oplog
+	filter := db.NewFilter()
+	filter.AddField("operation", multitenancy.OpPurge)
+	var oplogs []*multitenancy.OpLogTenancy
+	_, err = multiPoolCtx.AppWithTenancy.Db().FindWithFilter(multiPoolCtx.AdminOp, filter, &oplogs)
+	require.NoError(t, err)
+	assert.Len(t, oplogs, 3)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,102 @@
+package tenancy_api_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/db/partition_retention"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addMonthItems(t *testing.T, ctx *TenancyTestContext, tenancy multitenancy.Tenancy, months ...utils.Month) {
+	for _, month := range months {
+		item := &SharedPartitionedItem{Field4: month.String()}
+		item.InitObject()
+		item.SetMonth(month)
+		require.NoError(t, tenancy.Db().Create(ctx.AdminOp, item))
+	}
+}
+
+func countMonthItems(t *testing.T, ctx *TenancyTestContext, tenancy multitenancy.Tenancy, month utils.Month) int64 {
+	filter := &db.Filter{Fields: db.Fields{"month": month}, FilterConfig: db.FilterConfig{Count: true}}
+	count, err := tenancy.Db().FindWithFilter(ctx.AdminOp, filter, &[]*SharedPartitionedItem{})
+	require.NoError(t, err)
+	return count
+}
+
+func TestTenancyPartitionRetention(t *testing.T) {
+
+	// prepare tenancies with own databases and tenancies sharing database
+	multiPoolCtx, singlePoolCtx := PrepareAppWithSharedTenancies(t)
+	manager := multiPoolCtx.AppWithTenancy.Multitenancy()
+	item1, item2 := AddTenancies(t, multiPoolCtx)
+	tenancy1, err := manager.Tenancy(item1.GetID())
+	require.NoError(t, err)
+	tenancy2, err := manager.Tenancy(item2.GetID())
+	require.NoError(t, err)
+	shared1 := addSharedTenancy(t, multiPoolCtx, "customer1")
+	shared2 := addSharedTenancy(t, multiPoolCtx, "customer2")
+
+	// fill items of expired and current months
+	current := utils.CurrentMonth()
+	expired := current.Prev()
+	expired = expired.Prev()
+	addMonthItems(t, multiPoolCtx, tenancy1, expired, current)
+	addMonthItems(t, multiPoolCtx, shared1, expired, current)
+	addMonthItems(t, multiPoolCtx, shared2, expired, current)
+
+	// partitions of shared database can not be managed in tenancy scope
+	_, err = shared1.Db().ListMonthPartitions(multiPoolCtx.AdminOp, &SharedPartitionedItem{})
+	assert.Error(t, err)
+	assert.Error(t, shared1.Db().DropMonthPartition(multiPoolCtx.AdminOp, &SharedPartitionedItem{}, expired))
+
+	// setup retention of tenancy databases
+	retention := partition_retention.New()
+	require.NoError(t, retention.Init(multiPoolCtx.AppWithTenancy))
+	archiveDir := t.TempDir()
+	rule := &partition_retention.RetentionRule{}
+	rule.TABLE = "shared_partitioned_items"
+	rule.KEEP_MONTHS = 2
+	rule.ARCHIVE_DIR = archiveDir
+	retention.AddRule(rule)
+	retention.SetTenancies(manager, &SharedPartitionedItem{})
+
+	// apply retention
+	dropped, err := retention.Apply(multiPoolCtx.AdminOp)
+	require.NoError(t, err)
+	sharedScope := fmt.Sprintf("shared_%s_%s", shared1.PoolId(), shared1.DbName())
+	scopes := make([]string, 0, len(dropped))
+	for _, partition := range dropped {
+		assert.Equal(t, expired, partition.Month)
+		scopes = append(scopes, partition.Scope)
+	}
+	assert.ElementsMatch(t, []string{tenancy1.GetID(), sharedScope}, scopes)
+
+	// expired items are archived and deleted, current items are kept
+	for _, tenancy := range []multitenancy.Tenancy{tenancy1, shared1, shared2} {
+		assert.Equal(t, int64(0), countMonthItems(t, multiPoolCtx, tenancy, expired))
+		assert.Equal(t, int64(1), countMonthItems(t, multiPoolCtx, tenancy, current))
+	}
+	assert.Equal(t, int64(0), countMonthItems(t, multiPoolCtx, tenancy2, current))
+	for _, scope := range scopes {
+		_, err = os.Stat(partition_retention.ArchivePath(filepath.Join(archiveDir, scope), "shared_partitioned_items", expired))
+		assert.NoError(t, err)
+	}
+
+	// nothing to drop next time
+	dropped, err = retention.Apply(multiPoolCtx.AdminOp)
+	require.NoError(t, err)
+	assert.Empty(t, dropped)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,90 @@
+package tenancy_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addSchemaTenancy(t *testing.T, ctx *TenancyTestContext, customer string, schema string) multitenancy.Tenancy {
+	tenancyData := &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "schema"
+	tenancyData.CUSTOMER_ID = customer
+	tenancyData.ISOLATION = multitenancy.IsolationSchema
+	tenancyData.SCHEMA = schema
+	added, err := ctx.RemoteTenancyController.Add(ctx.ClientOp, tenancyData)
+	require.NoError(t, err)
+	require.NotNil(t, added)
+	assert.True(t, added.IsSchema())
+	tenancy, err := ctx.AppWithTenancy.Multitenancy().Tenancy(added.GetID())
+	require.NoError(t, err)
+	return tenancy
+}
+
+func TestSchemaTenancies(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+
+	// add schema tenancies
+	tenancy1 := addSchemaTenancy(t, multiPoolCtx, "customer1", "")
+	tenancy2 := addSchemaTenancy(t, multiPoolCtx, "customer2", "custom_schema")
+	assert.Equal(t, "tenancy_schemas", tenancy1.DbName())
+	assert.Equal(t, tenancy1.DbName(), tenancy2.DbName())
+	assert.Equal(t, "tenancy_customer1_schema", tenancy1.Schema())
+	assert.Equal(t, "custom_schema", tenancy2.Schema())
+
+	// add documents to each tenancy
+	sample1 := &InTenancySample{Field1: "sample1", Field2: 1}
+	sample1.GenerateID()
+	require.NoError(t, tenancy1.Db().Create(multiPoolCtx.AdminOp, sample1))
+	sample2 := &InTenancySample{Field1: "sample2", Field2: 2}
+	sample2.GenerateID()
+	require.NoError(t, tenancy2.Db().Create(multiPoolCtx.AdminOp, sample2))
+
+	// each tenancy sees only own documents
+	var samples []*InTenancySample
+	_, err := tenancy1.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "sample1", samples[0].Field1)
+	samples = nil
+	_, err = tenancy2.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "sample2", samples[0].Field1)
+
+	// tenancy can not use schema of other tenancy
+	tenancyData := &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "other"
+	tenancyData.CUSTOMER_ID = "customer1"
+	tenancyData.ISOLATION = multitenancy.IsolationSchema
+	tenancyData.SCHEMA = tenancy2.Schema()
+	_, err = multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, tenancyData)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeForeignDatabase)
+
+	// tenancy with own database can be added to the same pool
+	tenancyData = &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "dedicated"
+	tenancyData.CUSTOMER_ID = "customer1"
+	added, err := multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, tenancyData)
+	require.NoError(t, err)
+	assert.False(t, added.IsSchema())
+	assert.Empty(t, added.Schema())
+
+	// upgrade of schemas
+	err = multitenancy.UpgradeTenancyDatabases(multiPoolCtx.AdminOp, multiPoolCtx.AppWithTenancy.Multitenancy(), tenancyDbModels())
+	require.NoError(t, err)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,145 @@
+package tenancy_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/config/object_config"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenancySettings(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+
+	// register setting
+	key := "sample.settings.limit"
+	spec := &multitenancy.TenancySettingSpec{Key: key, Type: multitenancy.SettingInt, Rules: "gt=0,lte=100"}
+	multiPoolCtx.AppWithTenancy.Multitenancy().SettingsSchema().Register(spec)
+	singlePoolCtx.AppWithTenancy.Multitenancy().SettingsSchema().Register(spec)
+	multiPoolCtx.AppWithTenancy.Cfg().Set(key, 10)
+
+	// only registered settings with valid values can be overridden
+	err := multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), "sample.settings.unknown", "1")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingUnknown)
+	multiPoolCtx.ClientOp.Reset()
+	err = multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), key, "abc")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingInvalid)
+	multiPoolCtx.ClientOp.Reset()
+	err = multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), key, "1000")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingInvalid)
+	multiPoolCtx.ClientOp.Reset()
+
+	// override setting
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), "Sample.Settings.Limit", "20"))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), key, "30"))
+	settings, err := multiPoolCtx.RemoteTenancyController.ListSettings(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.Len(t, settings, 1)
+	assert.Equal(t, key, settings[0].Key())
+	assert.Equal(t, "30", settings[0].Value())
+
+	// tenancy configuration resolves override, application configuration is not changed
+	loaded1, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 30, loaded1.Cfg().GetInt(key))
+	loaded2, err := multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy2.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 10, loaded2.Cfg().GetInt(key))
+	assert.Equal(t, 10, multiPoolCtx.AppWithTenancy.Cfg().GetInt(key))
+	singleLoaded1, err := singlePoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 30, singleLoaded1.Cfg().GetInt(key))
+
+	// tenancy context uses configuration of tenancy
+	ctx := multitenancy.NewContext()
+	ctx.Init(multiPoolCtx.AppWithTenancy, multiPoolCtx.AppWithTenancy.Logger(), multiPoolCtx.AppWithTenancy.Db())
+	assert.Equal(t, 10, multitenancy.CfgInt(ctx, key, 5))
+	ctx.SetTenancy(loaded1)
+	assert.Equal(t, 30, multitenancy.CfgInt(ctx, key, 5))
+	assert.Equal(t, 5, multitenancy.CfgInt(ctx, "sample.settings.other", 5))
+
+	// unset setting
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.UnsetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), key))
+	settings, err = multiPoolCtx.RemoteTenancyController.ListSettings(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Empty(t, settings)
+	loaded1, err = multiPoolCtx.AppWithTenancy.Multitenancy().Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 10, loaded1.Cfg().GetInt(key))
+	err = multiPoolCtx.RemoteTenancyController.UnsetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), key)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingNotFound)
+	multiPoolCtx.ClientOp.Reset()
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
+
+func TestTenancyRateLimitSettings(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	tenancy1, tenancy2 := AddTenancies(t, multiPoolCtx)
+	manager := multiPoolCtx.AppWithTenancy.Multitenancy()
+
+	// rate limits of SMS handler are registered in schema of tenancy manager by API server
+	path := "server.auth.manager.methods.sms"
+	delayKey := object_config.Key(path, "sms_delay_seconds")
+	triesKey := object_config.Key(path, "max_tries")
+	spec, ok := manager.SettingsSchema().Spec(delayKey)
+	require.True(t, ok)
+	assert.Equal(t, multitenancy.SettingInt, spec.Type)
+	assert.Equal(t, "gt=0", spec.Rules)
+	spec, ok = manager.SettingsSchema().Spec(triesKey)
+	require.True(t, ok)
+	assert.Equal(t, multitenancy.SettingInt, spec.Type)
+	assert.Equal(t, "gt=1", spec.Rules)
+	_, ok = singlePoolCtx.AppWithTenancy.Multitenancy().SettingsSchema().Spec(delayKey)
+	assert.True(t, ok)
+	_, ok = manager.SettingsSchema().Spec(object_config.Key(path, "code_length"))
+	assert.False(t, ok)
+
+	// values of rate limits are validated with rules of handler configuration
+	err := multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), delayKey, "0")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingInvalid)
+	multiPoolCtx.ClientOp.Reset()
+	err = multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), triesKey, "1")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingInvalid)
+	multiPoolCtx.ClientOp.Reset()
+	err = multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), object_config.Key(path, "code_length"), "6")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancySettingUnknown)
+	multiPoolCtx.ClientOp.Reset()
+
+	// override rate limits in tenancy
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), delayKey, "60"))
+	require.NoError(t, multiPoolCtx.RemoteTenancyController.SetSetting(multiPoolCtx.ClientOp, tenancy1.GetID(), triesKey, "5"))
+
+	// tenancy context resolves rate limits of its tenancy, other tenancies use application configuration
+	cfg := multiPoolCtx.AppWithTenancy.Cfg()
+	appDelay := cfg.GetInt(delayKey)
+	appTries := cfg.GetInt(triesKey)
+	loaded1, err := manager.Tenancy(tenancy1.GetID())
+	require.NoError(t, err)
+	loaded2, err := manager.Tenancy(tenancy2.GetID())
+	require.NoError(t, err)
+	ctx := multitenancy.NewContext()
+	ctx.Init(multiPoolCtx.AppWithTenancy, multiPoolCtx.AppWithTenancy.Logger(), multiPoolCtx.AppWithTenancy.Db())
+	ctx.SetTenancy(loaded1)
+	assert.Equal(t, 60, multitenancy.CfgInt(ctx, delayKey, 30))
+	assert.Equal(t, 5, multitenancy.CfgInt(ctx, triesKey, 3))
+	ctx.SetTenancy(loaded2)
+	assert.Equal(t, appDelay, multitenancy.CfgInt(ctx, delayKey, 30))
+	assert.Equal(t, appTries, multitenancy.CfgInt(ctx, triesKey, 3))
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,287 @@
+package tenancy_api_test
+
+import (
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/api"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_client"
+	"github.com/evgeniums/go-backend-helpers/pkg/api/api_server"
+	"github.com/evgeniums/go-backend-helpers/pkg/common"
+	"github.com/evgeniums/go-backend-helpers/pkg/db"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type SharedSample struct {
+	common.IDBase
+	multitenancy.WithTenancyScopeBase
+	Field1 string
+	Field2 int
+}
+
+type SharedItem struct {
+	common.IDBase
+	multitenancy.WithTenancyScopeBase
+	Field4 string
+	Field5 bool
+}
+
+type SharedPartitionedItem struct {
+	common.ObjectWithMonth
+	multitenancy.WithTenancyScopeBase
+	Field4 string
+	Field5 int
+}
+
+func sharedTenancyDbModels() *multitenancy.TenancyDbModels {
+	models := &multitenancy.TenancyDbModels{}
+	models.DbModels = []interface{}{&SharedSample{}, &SharedItem{}}
+	models.PartitionedDbModels = []interface{}{&SharedPartitionedItem{}}
+	return models
+}
+
+// Prepare apps whose tenancy models can be stored in shared tenancy databases.
+func PrepareAppWithSharedTenancies(t *testing.T, multiPoolConfig ...string) (multiPoolCtx *TenancyTestContext, singlePoolCtx *TenancyTestContext) {
+	return prepareAppWithTenancyModels(t, sharedTenancyDbModels(), multiPoolConfig...)
+}
+
+type SharedListResponse = api.ResponseList[*SharedSample]
+
+type SharedListEndpoint struct {
+	SampleEndpoint
+}
+
+func (e *SharedListEndpoint) HandleRequest(request api_server.Request) error {
+
+	// setup
+	var err error
+	c := request.TraceInMethod("shared_samples.List")
+	defer request.TraceOutMethod()
+
+	// get
+	resp := &SharedListResponse{}
+	resp.Count, err = request.Db().FindWithFilter(request, nil, &resp.Items)
+	if err != nil {
+		return c.SetError(err)
+	}
+	// set response message
+	request.Response().SetMessage(resp)
+
+	// done
+	return nil
+}
+
+func NewSharedSampleService() *SampleService {
+
+	s := &SampleService{}
+
+	s.Init("shared_samples")
+	s.SampleResource = api.NewResource("sample")
+	s.AddChild(s.SampleResource)
+	e := &SharedListEndpoint{}
+	e.Construct(s, api.List("list_shared_samples"))
+	s.SampleResource.AddOperation(e)
+
+	return s
+}
+
+type SharedSampleClient struct {
+	api_client.ServiceClient
+	SampleResource api.Resource
+	list           api.Operation
+}
+
+func NewSharedSampleClient(client api_client.Client) *SharedSampleClient {
+
+	c := &SharedSampleClient{}
+
+	c.Init(client, "shared_samples")
+	c.SampleResource = api.NewResource("sample")
+	c.AddChild(c.SampleResource)
+	c.list = api.List("list_shared_samples")
+	c.SampleResource.AddOperation(c.list)
+
+	return c
+}
+
+func (s *SharedSampleClient) List(ctx op_context.Context) ([]*SharedSample, error) {
+	handler := api_client.NewHandlerResult(&SharedListResponse{})
+	err := s.list.Exec(ctx, api_client.MakeOperationHandler(s.Client(), handler))
+	if err != nil {
+		return nil, err
+	}
+	return handler.Result.Items, nil
+}
+
+func addSharedTenancy(t *testing.T, ctx *TenancyTestContext, customer string) multitenancy.Tenancy {
+	tenancyData := &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "shared"
+	tenancyData.CUSTOMER_ID = customer
+	tenancyData.ISOLATION = multitenancy.IsolationShared
+	added, err := ctx.RemoteTenancyController.Add(ctx.ClientOp, tenancyData)
+	require.NoError(t, err)
+	require.NotNil(t, added)
+	assert.True(t, added.IsShared())
+	tenancy, err := ctx.AppWithTenancy.Multitenancy().Tenancy(added.GetID())
+	require.NoError(t, err)
+	return tenancy
+}
+
+func TestSharedTenancies(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithSharedTenancies(t)
+
+	// add shared tenancies
+	tenancy1 := addSharedTenancy(t, multiPoolCtx, "customer1")
+	tenancy2 := addSharedTenancy(t, multiPoolCtx, "customer2")
+	assert.Equal(t, "tenancy_shared", tenancy1.DbName())
+	assert.Equal(t, tenancy1.DbName(), tenancy2.DbName())
+
+	// add documents to each tenancy
+	sample1 := &SharedSample{Field1: "sample1", Field2: 1}
+	sample1.GenerateID()
+	require.NoError(t, tenancy1.Db().Create(multiPoolCtx.AdminOp, sample1))
+	assert.Equal(t, tenancy1.GetID(), sample1.TenancyScope())
+	sample2 := &SharedSample{Field1: "sample2", Field2: 2}
+	sample2.GenerateID()
+	require.NoError(t, tenancy2.Db().Create(multiPoolCtx.AdminOp, sample2))
+	items := []*SharedPartitionedItem{{Field4: "item1"}, {Field4: "item2"}}
+	for _, item := range items {
+		item.InitObject()
+	}
+	require.NoError(t, tenancy2.Db().Create(multiPoolCtx.AdminOp, &items))
+	assert.Equal(t, tenancy2.GetID(), items[1].TenancyScope())
+
+	// each tenancy sees only own documents
+	var samples []*SharedSample
+	_, err := tenancy1.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "sample1", samples[0].Field1)
+	found := &SharedSample{}
+	ok, err := tenancy1.Db().FindByField(multiPoolCtx.AdminOp, "id", sample2.GetID(), found)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	exists, err := tenancy1.Db().Exists(multiPoolCtx.AdminOp, nil, &SharedPartitionedItem{})
+	require.NoError(t, err)
+	assert.False(t, exists)
+	var allSamples []*SharedSample
+	_, err = multitenancy.UnscopedDb(tenancy1.Db()).FindWithFilter(multiPoolCtx.AdminOp, nil, &allSamples)
+	require.NoError(t, err)
+	assert.Len(t, allSamples, 2)
+
+	// documents of other tenancy can not be updated or deleted
+	require.NoError(t, tenancy1.Db().Update(multiPoolCtx.AdminOp, &SharedSample{}, db.Fields{"id": sample2.GetID()}, db.Fields{"field1": "changed"}))
+	require.NoError(t, tenancy1.Db().UpdateAll(multiPoolCtx.AdminOp, &SharedSample{}, db.Fields{"field2": 100}))
+	require.NoError(t, tenancy1.Db().Delete(multiPoolCtx.AdminOp, items[1]))
+	require.NoError(t, tenancy1.Db().DeleteByField(multiPoolCtx.AdminOp, "id", sample2.GetID(), &SharedSample{}))
+	err = tenancy1.Db().Update(multiPoolCtx.AdminOp, &SharedSample{}, db.Fields{"id": sample1.GetID()}, db.Fields{multitenancy.TenancyScopeField: tenancy2.GetID()})
+	assert.Error(t, err)
+	samples = nil
+	_, err = tenancy2.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "sample2", samples[0].Field1)
+	assert.Equal(t, 2, samples[0].Field2)
+	var foundItems []*SharedPartitionedItem
+	_, err = tenancy2.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &foundItems)
+	require.NoError(t, err)
+	assert.Len(t, foundItems, 2)
+	samples = nil
+	_, err = tenancy1.Db().FindWithFilter(multiPoolCtx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 100, samples[0].Field2)
+
+	// transactions are scoped too
+	opCtx := multiPoolCtx.AdminOp
+	err = tenancy1.Db().Transaction(func(tx db.Transaction) error {
+		var txSamples []*SharedSample
+		_, err := tx.FindWithFilter(opCtx, nil, &txSamples)
+		if err != nil {
+			return err
+		}
+		assert.Len(t, txSamples, 1)
+		return tx.DeleteByFields(opCtx, db.Fields{"field1": "sample2"}, &SharedSample{})
+	})
+	require.NoError(t, err)
+	ok, err = tenancy2.Db().FindByField(multiPoolCtx.AdminOp, "id", sample2.GetID(), found)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// operations bypassing tenancy scope are not allowed
+	assert.Error(t, tenancy1.Db().DropDatabase(multiPoolCtx.AdminOp, tenancy1.DbName()))
+	assert.Error(t, tenancy1.Db().DropSchema(multiPoolCtx.AdminOp, "public"))
+	assert.NotNil(t, tenancy1.Db().NativeHandler())
+	assert.Equal(t, multitenancy.UnscopedDb(tenancy1.Db()).NativeHandler(), tenancy1.Db().NativeHandler())
+
+	// requests to tenancy service are scoped
+	sampleService := NewSharedSampleService()
+	api_server.AddServiceToServer(multiPoolCtx.Server.ApiServer(), sampleService, true)
+	sampleClient := NewSharedSampleClient(multiPoolCtx.RestApiClient)
+	tenancyResource := api.NamedResource("tenancy")
+	tenancyResource.AddChild(sampleClient)
+	tenancyResource.SetId(tenancy2.Path())
+	samples, err = sampleClient.List(multiPoolCtx.ClientOp)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, sample2.GetID(), samples[0].GetID())
+
+	// tenancy with own database can not use shared database
+	tenancyData := &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "dedicated"
+	tenancyData.CUSTOMER_ID = "customer1"
+	tenancyData.DBNAME = tenancy1.DbName()
+	_, err = multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, tenancyData)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeForeignDatabase)
+
+	// upgrade of shared databases
+	err = multitenancy.UpgradeTenancyDatabases(multiPoolCtx.AdminOp, multiPoolCtx.AppWithTenancy.Multitenancy(), sharedTenancyDbModels())
+	require.NoError(t, err)
+	err = multitenancy.UpgradeTenancyDatabase(multiPoolCtx.AdminOp, tenancy1, tenancyDbModels())
+	assert.Error(t, err)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
+
+func TestSharedTenancyNotScopedModels(t *testing.T) {
+
+	// prepare app with models that are not tenancy scoped
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+
+	// shared tenancy can not be created
+	tenancyData := &multitenancy.TenancyData{}
+	tenancyData.POOL_ID = "pool1"
+	tenancyData.ROLE = "shared"
+	tenancyData.CUSTOMER_ID = "customer1"
+	tenancyData.ISOLATION = multitenancy.IsolationShared
+	_, err := multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, tenancyData)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyDbInitializationFailed)
+	multiPoolCtx.ClientOp.Reset()
+
+	// shared tenancy can not be loaded
+	tenancy1, _ := AddTenancies(t, multiPoolCtx)
+	shared := tenancy1.TenancyDb
+	shared.ISOLATION = multitenancy.IsolationShared
+	shared.DBNAME = "tenancy_shared"
+	_, err = manager.LoadTenancyFromData(multiPoolCtx.AdminOp, &shared)
+	assert.Error(t, err)
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
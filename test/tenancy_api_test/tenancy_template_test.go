@@ -0,0 +1,172 @@
+package tenancy_api_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy"
+	"github.com/evgeniums/go-backend-helpers/pkg/multitenancy/tenancy_manager"
+	"github.com/evgeniums/go-backend-helpers/pkg/op_context"
+	"github.com/evgeniums/go-backend-helpers/pkg/pubsub/pubsub_providers/pubsub_factory"
+	"github.com/evgeniums/go-backend-helpers/pkg/test_utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sampleTemplateUsers struct {
+	logins []string
+}
+
+func (s *sampleTemplateUsers) AddTemplateUser(ctx op_context.Context, user *multitenancy.TenancyTemplateUser) error {
+	for _, login := range s.logins {
+		if login == user.Login {
+			return nil
+		}
+	}
+	s.logins = append(s.logins, user.Login)
+	return nil
+}
+
+const templateV1 = `
+name: basic
+version: 1
+fixtures:
+  - model: InTenancySample
+    rows:
+      - {id: sample1, Field1: first, Field2: 1}
+      - {id: sample2, Field1: second, Field2: 2}
+users:
+  - {login: admin, password: "12345678"}
+settings:
+  sample.template.limit: "5"
+`
+
+const templateV2 = `
+name: basic
+version: 2
+fixtures:
+  - model: InTenancySample
+    rows:
+      - {id: sample1, Field1: changed, Field2: 1}
+      - {id: sample2, Field1: second, Field2: 2}
+      - {id: sample3, Field1: third, Field2: 3}
+users:
+  - {login: admin, password: "12345678"}
+  - {login: operator, password: "12345678"}
+settings:
+  sample.template.limit: "7"
+  sample.template.other: "1"
+`
+
+func tenancySamples(t *testing.T, ctx *TenancyTestContext, tenancyId string) map[string]*InTenancySample {
+	tenancy, err := ctx.AppWithTenancy.Multitenancy().Tenancy(tenancyId)
+	require.NoError(t, err)
+	var samples []*InTenancySample
+	_, err = tenancy.Db().FindWithFilter(ctx.AdminOp, nil, &samples)
+	require.NoError(t, err)
+	result := make(map[string]*InTenancySample)
+	for _, sample := range samples {
+		result[sample.GetID()] = sample
+	}
+	return result
+}
+
+func TestTenancyTemplates(t *testing.T) {
+
+	// prepare app with multiple pools and single pool
+	multiPoolCtx, singlePoolCtx := PrepareAppWithTenancies(t)
+	manager, ok := multiPoolCtx.AppWithTenancy.Multitenancy().(*tenancy_manager.TenancyManager)
+	require.True(t, ok)
+	templatesPath := t.TempDir()
+	manager.TEMPLATES_PATH = templatesPath
+	users := &sampleTemplateUsers{}
+	manager.SetTemplateUsers(users)
+	manager.SettingsSchema().Register(&multitenancy.TenancySettingSpec{Key: "sample.template.limit", Type: multitenancy.SettingInt})
+	manager.SettingsSchema().Register(&multitenancy.TenancySettingSpec{Key: "sample.template.other", Type: multitenancy.SettingInt})
+	require.NoError(t, os.WriteFile(filepath.Join(templatesPath, "basic.yaml"), []byte(templateV1), 0600))
+
+	// unknown template can not be selected
+	data := &multitenancy.TenancyData{}
+	data.POOL_ID = "pool2"
+	data.ROLE = "dev"
+	data.CUSTOMER_ID = "customer1"
+	data.TEMPLATE = "unknown"
+	_, err := multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, data)
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyTemplateNotFound)
+	multiPoolCtx.ClientOp.Reset()
+
+	// create tenancy with template
+	data.TEMPLATE = "basic"
+	tenancy1, err := multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, data)
+	require.NoError(t, err)
+	assert.Equal(t, "basic", tenancy1.TEMPLATE)
+	assert.Equal(t, 1, tenancy1.TEMPLATE_VERSION)
+	samples := tenancySamples(t, multiPoolCtx, tenancy1.GetID())
+	require.Len(t, samples, 2)
+	assert.Equal(t, "first", samples["sample1"].Field1)
+	assert.Equal(t, 2, samples["sample2"].Field2)
+	assert.Equal(t, []string{"admin"}, users.logins)
+	settings, err := multiPoolCtx.RemoteTenancyController.ListSettings(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	require.Len(t, settings, 1)
+	assert.Equal(t, "5", settings[0].Value())
+
+	// tenancy without template has no seed data
+	data2 := &multitenancy.TenancyData{}
+	data2.POOL_ID = "pool1"
+	data2.ROLE = "stage"
+	data2.CUSTOMER_ID = "customer1"
+	tenancy2, err := multiPoolCtx.RemoteTenancyController.Add(multiPoolCtx.ClientOp, data2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tenancy2.TEMPLATE_VERSION)
+	assert.Empty(t, tenancySamples(t, multiPoolCtx, tenancy2.GetID()))
+	_, err = multiPoolCtx.RemoteTenancyController.ApplyTemplate(multiPoolCtx.ClientOp, tenancy2.GetID(), "")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyTemplateNotFound)
+	multiPoolCtx.ClientOp.Reset()
+
+	// re-apply new version of template, existing data is kept
+	require.NoError(t, os.WriteFile(filepath.Join(templatesPath, "basic.yaml"), []byte(templateV2), 0600))
+	for i := 0; i < 2; i++ {
+		applied, err := multiPoolCtx.RemoteTenancyController.ApplyTemplate(multiPoolCtx.ClientOp, tenancy1.GetID(), "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, applied.TEMPLATE_VERSION)
+		samples = tenancySamples(t, multiPoolCtx, tenancy1.GetID())
+		require.Len(t, samples, 3)
+		assert.Equal(t, "first", samples["sample1"].Field1)
+		assert.Equal(t, "third", samples["sample3"].Field1)
+		assert.Equal(t, []string{"admin", "operator"}, users.logins)
+		settings, err = multiPoolCtx.RemoteTenancyController.ListSettings(multiPoolCtx.ClientOp, tenancy1.GetID())
+		require.NoError(t, err)
+		require.Len(t, settings, 2)
+		assert.Equal(t, "5", settings[0].Value())
+		assert.Equal(t, "1", settings[1].Value())
+	}
+	found, err := multiPoolCtx.RemoteTenancyController.Find(multiPoolCtx.ClientOp, tenancy1.GetID())
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.TEMPLATE_VERSION)
+
+	// apply registered template to tenancy without template
+	require.NoError(t, manager.AddTemplate(&multitenancy.TenancyTemplate{Name: "registered", Version: 3}))
+	applied, err := multiPoolCtx.RemoteTenancyController.ApplyTemplate(multiPoolCtx.ClientOp, tenancy2.GetID(), "registered")
+	require.NoError(t, err)
+	assert.Equal(t, "registered", applied.TEMPLATE)
+	assert.Equal(t, 3, applied.TEMPLATE_VERSION)
+
+	// invalid templates are not applied
+	invalid := &multitenancy.TenancyTemplate{Name: "invalid", Version: 1}
+	invalid.Fixtures = []*multitenancy.TenancyTemplateFixture{{Model: "unknown"}}
+	require.NoError(t, manager.AddTemplate(invalid))
+	_, err = multiPoolCtx.RemoteTenancyController.ApplyTemplate(multiPoolCtx.ClientOp, tenancy2.GetID(), "invalid")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyTemplateInvalid)
+	multiPoolCtx.ClientOp.Reset()
+	require.NoError(t, os.WriteFile(filepath.Join(templatesPath, "broken.json"), []byte(`{"name":"broken"}`), 0600))
+	_, err = multiPoolCtx.RemoteTenancyController.ApplyTemplate(multiPoolCtx.ClientOp, tenancy2.GetID(), "broken")
+	test_utils.CheckGenericError(t, err, multitenancy.ErrorCodeTenancyTemplateInvalid)
+	multiPoolCtx.ClientOp.Reset()
+
+	// close apps
+	multiPoolCtx.Close()
+	singlePoolCtx.Close()
+	pubsub_factory.ResetSingletonInmemPubsub()
+}
@@ -0,0 +1,84 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evgeniums/go-backend-helpers/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriteReopen(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer := &utils.FileWriteReopen{Path: path}
+	defer writer.Close()
+
+	_, err := writer.Write([]byte("one\n"))
+	require.NoError(t, err)
+
+	// file must be reopened after deletion
+	require.NoError(t, os.Remove(path))
+	_, err = writer.Write([]byte("two\n"))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "two\n", string(content))
+}
+
+func TestFileRotationBySize(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer := &utils.FileWriteReopen{Path: path, MaxSize: 10, MaxBackups: 2}
+	defer writer.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err := writer.Write([]byte("12345678\n"))
+		require.NoError(t, err)
+		// names of rotated files have milliseconds resolution
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	rotated, err := writer.RotatedFiles()
+	require.NoError(t, err)
+	assert.Len(t, rotated, 2)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678\n", string(content))
+}
+
+func TestFileRotationByTime(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer := &utils.FileWriteReopen{Path: path, Interval: 50 * time.Millisecond}
+	defer writer.Close()
+
+	_, err := writer.Write([]byte("one\n"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("two\n"))
+	require.NoError(t, err)
+
+	rotated, err := writer.RotatedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, rotated)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = writer.Write([]byte("three\n"))
+	require.NoError(t, err)
+
+	rotated, err = writer.RotatedFiles()
+	require.NoError(t, err)
+	require.Len(t, rotated, 1)
+
+	content, err := os.ReadFile(rotated[0])
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(content))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(content))
+}
@@ -25,3 +25,14 @@ func TestGenerateId(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestGenerateIdLength(t *testing.T) {
+
+	// counter wraps around 0x10000 so run through more IDs than that
+	for i := 0; i < 0x10000+10; i++ {
+		id := utils.GenerateID()
+		if len(id) != 20 {
+			t.Fatalf("invalid length of ID %s: expected 20, got %d", id, len(id))
+		}
+	}
+}